@@ -1,32 +1,281 @@
 package main
 
 import (
+	"log"
+	"time"
+
 	"github.com/Ansalps/UserEcommerceClean/internal/controllers"
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
 	"github.com/Ansalps/UserEcommerceClean/internal/database"
+	"github.com/Ansalps/UserEcommerceClean/internal/invoice"
+	"github.com/Ansalps/UserEcommerceClean/internal/jobs"
 	"github.com/Ansalps/UserEcommerceClean/internal/middleware"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
+	"github.com/Ansalps/UserEcommerceClean/internal/payment"
 	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/search"
 	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/Ansalps/UserEcommerceClean/internal/shipping"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+	"github.com/Ansalps/UserEcommerceClean/internal/tax"
 	"github.com/gin-gonic/gin"
 )
 
 func init() {
 	database.Initialize()
 	database.AutoMigrate()
+	database.SeedAdmin()
 }
 func main() {
 	router := gin.Default()
 	userRepo := repository.NewUserRepository(database.DB)
-	userService := services.NewUserService(userRepo)
-	userController := controllers.NewUserController(userService)
+	mailer := notification.NewMailerFromEnv()
+	blobStorage := storage.NewStorageFromEnv()
+	if local, ok := blobStorage.(*storage.LocalStorage); ok {
+		router.Static(storage.LocalMediaPath, local.Root)
+	}
+	currencyRepo := repository.NewCurrencyRepository(database.DB)
+	currencyService := services.NewCurrencyService(currencyRepo, userRepo, currency.RatesFromEnv())
+	currencyController := controllers.NewCurrencyController(currencyService)
+	referralRepo := repository.NewReferralRepository(database.DB)
+	referralService := services.NewReferralService(referralRepo)
+	referralController := controllers.NewReferralController(referralService)
+	userService := services.NewUserService(userRepo, referralService, currencyService, mailer, blobStorage)
+	addressRepo := repository.NewAddressRepository(database.DB)
+	addressService := services.NewAddressService(addressRepo)
+	addressController := controllers.NewAddressController(addressService)
+	adminRepo := repository.NewAdminRepository(database.DB)
+	adminService := services.NewAdminService(adminRepo)
+	adminController := controllers.NewAdminController(adminService)
+	taxRepo := repository.NewTaxRepository(database.DB)
+	categoryRepo := repository.NewCategoryRepository(database.DB)
+	taxService := services.NewTaxService(taxRepo, categoryRepo, tax.ConfigFromEnv())
+	taxController := controllers.NewTaxController(taxService)
+	productRepo := repository.NewProductRepository(database.DB)
+	searchService := services.NewSearchService(search.NewPostgresEngine(database.DB), productRepo, categoryRepo)
+	searchController := controllers.NewSearchController(searchService)
+	categoryService := services.NewCategoryService(categoryRepo, taxRepo, productRepo, searchService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	productService := services.NewProductService(productRepo, categoryRepo, searchService)
+	recommendationRepo := repository.NewRecommendationRepository(database.DB)
+	recommendationService := services.NewRecommendationService(recommendationRepo, productRepo)
+	recommendationController := controllers.NewRecommendationController(recommendationService)
+	productController := controllers.NewProductController(productService, recommendationService, currencyService)
+	inventoryRepo := repository.NewInventoryRepository(database.DB)
+	inventoryService := services.NewInventoryService(inventoryRepo, mailer)
+	inventoryController := controllers.NewInventoryController(inventoryService)
+	cartRepo := repository.NewCartRepository(database.DB)
+	cartService := services.NewCartService(cartRepo, productRepo)
+	couponRepo := repository.NewCouponRepository(database.DB)
+	couponService := services.NewCouponService(couponRepo, categoryRepo, cartService)
+	couponController := controllers.NewCouponController(couponService)
+	cartController := controllers.NewCartController(cartService, couponService)
+	userController := controllers.NewUserController(userService, cartService)
+	notificationRepo := repository.NewNotificationRepository(database.DB)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, mailer)
+	notificationController := controllers.NewNotificationController(notificationService)
+	wishlistRepo := repository.NewWishlistRepository(database.DB)
+	wishlistService := services.NewWishlistService(wishlistRepo, productRepo, notificationService)
+	wishlistController := controllers.NewWishlistController(wishlistService)
+	reviewRepo := repository.NewReviewRepository(database.DB)
+	reviewService := services.NewReviewService(reviewRepo, productRepo, blobStorage)
+	reviewController := controllers.NewReviewController(reviewService)
+	paymentProvider, err := payment.NewProviderFromEnv()
+	if err != nil {
+		log.Fatal("payment provider: ", err)
+	}
+	paymentRepo := repository.NewPaymentRepository(database.DB)
+	returnRepo := repository.NewReturnRepository(database.DB)
+	refundService := services.NewRefundService(paymentRepo, returnRepo, paymentProvider)
+	orderRepo := repository.NewOrderRepository(database.DB)
+	shippingProvider, err := shipping.NewProviderFromEnv()
+	if err != nil {
+		log.Fatal("shipping provider: ", err)
+	}
+	shippingRepo := repository.NewShippingRepository(database.DB)
+	shippingService := services.NewShippingService(shippingRepo, orderRepo, addressRepo, cartService, couponService, notificationService, shippingProvider, blobStorage, shipping.OriginFromEnv())
+	shippingController := controllers.NewShippingController(shippingService)
+	loyaltyRepo := repository.NewLoyaltyRepository(database.DB)
+	loyaltyService := services.NewLoyaltyService(loyaltyRepo, categoryRepo)
+	loyaltyController := controllers.NewLoyaltyController(loyaltyService)
+	orderService := services.NewOrderService(orderRepo, addressRepo, cartService, couponService, refundService, taxService, shippingService, loyaltyService)
+	orderController := controllers.NewOrderController(orderService)
+	returnService := services.NewReturnService(returnRepo, orderRepo, refundService)
+	returnController := controllers.NewReturnController(returnService)
+	invoiceRepo := repository.NewInvoiceRepository(database.DB)
+	invoiceService := services.NewInvoiceService(invoiceRepo, orderRepo, blobStorage, invoice.SellerFromEnv())
+	invoiceController := controllers.NewInvoiceController(invoiceService)
+	walletRepo := repository.NewWalletRepository(database.DB)
+	walletService := services.NewWalletService(walletRepo)
+	walletController := controllers.NewWalletController(walletService)
+	giftCardRepo := repository.NewGiftCardRepository(database.DB)
+	giftCardService := services.NewGiftCardService(giftCardRepo, mailer)
+	giftCardController := controllers.NewGiftCardController(giftCardService)
+	paymentService := services.NewPaymentService(paymentRepo, orderRepo, walletRepo, giftCardRepo, loyaltyRepo, paymentProvider, mailer)
+	paymentController := controllers.NewPaymentController(paymentService)
 	//User Routes
 	//router.POST("storename", userController.StoreName)
 
 	router.POST("user-signup", userController.UserSignUp)
 	router.POST("user-login", userController.UserLogin)
+	router.POST("login/magic-link", middleware.RateLimit(5, time.Minute), userController.RequestMagicLink)
+	router.GET("login/magic", middleware.RateLimit(20, time.Minute), userController.MagicLinkLogin)
+	router.POST("token/refresh", middleware.RateLimit(20, time.Minute), userController.RefreshToken)
+	router.GET("categories", categoryController.GetCategories)
+	router.GET("currencies", currencyController.GetCurrencies)
+	router.GET("products", middleware.OptionalUser(), productController.GetProducts)
+	router.GET("products/:id", middleware.OptionalUser(), productController.GetProduct)
+	router.GET("products/:id/reviews", reviewController.GetProductReviews)
+	router.GET("products/:id/recommendations", recommendationController.GetRecommendations)
+	router.GET("recently-viewed", recommendationController.GetRecentlyViewed)
+	router.GET("search", searchController.Search)
+	router.POST("webhooks/payments", paymentController.Webhook)
+	router.POST("webhooks/shipping", shippingController.Webhook)
+	guestCartGroup := router.Group("cart")
+	guestCartGroup.GET("", cartController.GetCart)
+	guestCartGroup.DELETE("", cartController.ClearCart)
+	guestCartGroup.POST("acknowledge-prices", cartController.AcknowledgePrices)
+	guestCartGroup.POST("items", cartController.AddItem)
+	guestCartGroup.PUT("items/:variantId", cartController.UpdateItem)
+	guestCartGroup.DELETE("items/:variantId", cartController.RemoveItem)
 	userGroup := router.Group("user/")
 	userGroup.Use(middleware.JWTMIddleware("user"))
 	userGroup.GET("profile", userController.GetProfile)
 	userGroup.PUT("profile", userController.UpdateProfile)
+	userGroup.PATCH("profile", userController.PatchProfile)
+	userGroup.PUT("avatar", userController.UpdateAvatar)
+	userGroup.GET("addresses", addressController.GetAddresses)
+	userGroup.POST("addresses", addressController.CreateAddress)
+	userGroup.GET("addresses/:id", addressController.GetAddress)
+	userGroup.PUT("addresses/:id", addressController.UpdateAddress)
+	userGroup.DELETE("addresses/:id", addressController.DeleteAddress)
+	userGroup.DELETE("account", userController.DeleteAccount)
+	userGroup.GET("export", middleware.RateLimit(5, time.Hour), userController.ExportUserData)
+	userGroup.GET("cart", cartController.GetCart)
+	userGroup.DELETE("cart", cartController.ClearCart)
+	userGroup.POST("cart/acknowledge-prices", cartController.AcknowledgePrices)
+	userGroup.POST("cart/items", cartController.AddItem)
+	userGroup.PUT("cart/items/:variantId", cartController.UpdateItem)
+	userGroup.DELETE("cart/items/:variantId", cartController.RemoveItem)
+	userGroup.POST("cart/coupon", cartController.ApplyCoupon)
+	userGroup.DELETE("cart/coupon/:code", cartController.RemoveCoupon)
+	userGroup.POST("checkout", orderController.Checkout)
+	userGroup.GET("orders", orderController.GetOrders)
+	userGroup.GET("orders/:id", orderController.GetOrder)
+	userGroup.POST("orders/:id/cancel", orderController.CancelOrder)
+	userGroup.GET("orders/:id/history", orderController.GetOrderHistory)
+	userGroup.GET("orders/:id/invoice", invoiceController.GetInvoice)
+	userGroup.GET("orders/:id/shipments", shippingController.GetOrderShipments)
+	userGroup.GET("shipping/quotes", shippingController.GetQuotes)
+	userGroup.POST("orders/:id/returns", returnController.CreateReturn)
+	userGroup.GET("returns", returnController.GetReturns)
+	userGroup.GET("returns/:id", returnController.GetReturn)
+	userGroup.POST("orders/:id/pay", paymentController.CreatePayment)
+	userGroup.GET("wallet", walletController.GetWallet)
+	userGroup.POST("wallet/topup", paymentController.TopUpWallet)
+	userGroup.GET("loyalty", loyaltyController.GetLoyalty)
+	userGroup.GET("referrals", referralController.GetReferrals)
+	userGroup.POST("gift-cards", paymentController.PurchaseGiftCard)
+	userGroup.GET("gift-cards", giftCardController.GetPurchasedGiftCards)
+	userGroup.GET("gift-cards/:code/balance", middleware.RateLimit(10, time.Minute), giftCardController.GetBalance)
+	userGroup.GET("products/:id/review", reviewController.GetUserReview)
+	userGroup.POST("products/:id/review", reviewController.CreateReview)
+	userGroup.PUT("products/:id/review", reviewController.UpdateReview)
+	userGroup.DELETE("products/:id/review", reviewController.DeleteReview)
+	userGroup.POST("products/:id/review/images", reviewController.AddReviewImage)
+	userGroup.DELETE("products/:id/review/images/:index", reviewController.RemoveReviewImage)
+	userGroup.POST("reviews/:id/helpful", reviewController.VoteHelpful)
+	userGroup.DELETE("reviews/:id/helpful", reviewController.RemoveVote)
+	userGroup.GET("recently-viewed", recommendationController.GetRecentlyViewed)
+	userGroup.GET("wishlist", wishlistController.GetWishlist)
+	userGroup.POST("wishlist", wishlistController.AddItem)
+	userGroup.DELETE("wishlist/:variantId", wishlistController.RemoveItem)
+	userGroup.GET("notifications", notificationController.GetNotifications)
+	userGroup.POST("notifications/read", notificationController.MarkAllRead)
+	userGroup.POST("notifications/:id/read", notificationController.MarkRead)
+	userGroup.GET("notification-preferences", notificationController.GetPreferences)
+	userGroup.PATCH("notification-preferences", notificationController.UpdatePreferences)
+
+	//Admin Routes
+	router.POST("admin-login", middleware.RateLimit(10, time.Minute), adminController.AdminLogin)
+	adminGroup := router.Group("admin/")
+	adminGroup.Use(middleware.JWTMIddleware("admin"))
+	adminGroup.POST("categories", categoryController.CreateCategory)
+	adminGroup.PUT("categories/:id", categoryController.UpdateCategory)
+	adminGroup.DELETE("categories/:id", categoryController.DeleteCategory)
+	adminGroup.GET("products", productController.AdminGetProducts)
+	adminGroup.POST("products", productController.CreateProduct)
+	adminGroup.GET("products/:id", productController.AdminGetProduct)
+	adminGroup.PUT("products/:id", productController.UpdateProduct)
+	adminGroup.DELETE("products/:id", productController.DeleteProduct)
+	adminGroup.POST("products/:id/variants", productController.CreateVariant)
+	adminGroup.PUT("products/:id/variants/:variantId", productController.UpdateVariant)
+	adminGroup.DELETE("products/:id/variants/:variantId", productController.DeleteVariant)
+	adminGroup.POST("search/reindex", searchController.Reindex)
+	adminGroup.GET("orders", orderController.AdminGetOrders)
+	adminGroup.GET("orders/:id", orderController.AdminGetOrder)
+	adminGroup.PATCH("orders/:id/status", orderController.UpdateOrderStatus)
+	adminGroup.GET("orders/:id/history", orderController.AdminGetOrderHistory)
+	adminGroup.GET("orders/:id/shipments", shippingController.AdminGetOrderShipments)
+	adminGroup.POST("orders/:id/shipments", shippingController.CreateShipment)
+	adminGroup.GET("shipments/:id/label", shippingController.GetShipmentLabel)
+	adminGroup.GET("shipping-methods", shippingController.GetShippingMethods)
+	adminGroup.POST("shipping-methods", shippingController.CreateShippingMethod)
+	adminGroup.PUT("shipping-methods/:id", shippingController.UpdateShippingMethod)
+	adminGroup.DELETE("shipping-methods/:id", shippingController.DeleteShippingMethod)
+	adminGroup.GET("returns", returnController.AdminGetReturns)
+	adminGroup.GET("returns/:id", returnController.AdminGetReturn)
+	adminGroup.PATCH("returns/:id/status", returnController.UpdateReturnStatus)
+	adminGroup.GET("reviews", reviewController.AdminGetReviews)
+	adminGroup.PATCH("reviews/:id/status", reviewController.ModerateReview)
+	adminGroup.DELETE("reviews/:id", reviewController.AdminDeleteReview)
+	adminGroup.GET("tax-classes", taxController.GetTaxClasses)
+	adminGroup.POST("tax-classes", taxController.CreateTaxClass)
+	adminGroup.PUT("tax-classes/:id", taxController.UpdateTaxClass)
+	adminGroup.DELETE("tax-classes/:id", taxController.DeleteTaxClass)
+	adminGroup.GET("coupons", couponController.GetCoupons)
+	adminGroup.POST("coupons", couponController.CreateCoupon)
+	adminGroup.GET("coupons/:id", couponController.GetCoupon)
+	adminGroup.PUT("coupons/:id", couponController.UpdateCoupon)
+	adminGroup.DELETE("coupons/:id", couponController.DeleteCoupon)
+	adminGroup.GET("cod-pincodes", paymentController.GetCODPincodes)
+	adminGroup.POST("cod-pincodes", paymentController.AddCODPincodes)
+	adminGroup.DELETE("cod-pincodes/:pincode", paymentController.RemoveCODPincode)
+	adminGroup.GET("wallets/audit", walletController.Audit)
+	adminGroup.GET("loyalty-rules", loyaltyController.GetRules)
+	adminGroup.PUT("loyalty-rules", loyaltyController.SaveRule)
+	adminGroup.DELETE("loyalty-rules/:categoryId", loyaltyController.DeleteRule)
+	adminGroup.PUT("exchange-rates/:currency", currencyController.SaveRate)
+	adminGroup.DELETE("exchange-rates/:currency", currencyController.DeleteRate)
+	adminGroup.GET("gift-cards", giftCardController.GetGiftCards)
+	adminGroup.POST("gift-cards", giftCardController.IssueGiftCard)
+	adminGroup.GET("gift-cards/:id", giftCardController.GetGiftCard)
+	adminGroup.PATCH("gift-cards/:id", giftCardController.SetGiftCardActive)
+	adminGroup.GET("inventory/low-stock", inventoryController.GetLowStock)
+	adminGroup.GET("inventory/:sku", inventoryController.GetInventory)
+	adminGroup.POST("inventory/:sku/adjust", inventoryController.AdjustStock)
+	adminGroup.GET("inventory/:sku/movements", inventoryController.GetMovements)
+	// the fake gateway's stand-in for the customer paying
+	if _, ok := paymentProvider.(*payment.FakeProvider); ok {
+		adminGroup.POST("dev/payments/:intentId/complete", paymentController.SimulatePayment)
+	}
+	if _, ok := shippingProvider.(*shipping.FakeCarrier); ok {
+		adminGroup.POST("dev/shipments/:tracking/advance", shippingController.SimulateTracking)
+	}
+
+	jobs.RunEvery("purge deleted accounts", time.Hour, userService.PurgeDeletedAccounts)
+	jobs.RunEvery("release expired reservations", time.Minute, inventoryService.ReleaseExpiredReservations)
+	jobs.RunEvery("cancel unpaid orders", time.Minute, orderService.CancelExpiredOrders)
+	jobs.RunEvery("low stock alerts", 15*time.Minute, inventoryService.SendLowStockAlerts)
+	jobs.RunEvery("delete abandoned guest carts", 24*time.Hour, cartService.DeleteAbandonedGuestCarts)
+	jobs.RunEvery("wishlist notifications", 30*time.Minute, wishlistService.CheckWishlists)
+	jobs.RunEvery("rebuild search index", 24*time.Hour, searchService.Reindex)
+	jobs.RunEvery("compute recommendations", 6*time.Hour, recommendationService.ComputeRecommendations)
+	jobs.RunEvery("delete old guest product views", 24*time.Hour, recommendationService.DeleteOldGuestViews)
+	jobs.RunEvery("expire loyalty points", 24*time.Hour, loyaltyService.ExpirePoints)
+	jobs.RunEvery("retry pending refunds", 15*time.Minute, refundService.RetryPendingRefunds)
+	jobs.RunEvery("deliver gift cards", 15*time.Minute, paymentService.DeliverGiftCards)
+	jobs.RunEvery("store invoices", 5*time.Minute, invoiceService.StoreInvoices)
 	//router.RegisterUrls(router)
 	//router.LoadHTMLGlob("templates/*")
 	router.Run(":5000")
@@ -3,6 +3,7 @@ module github.com/Ansalps/UserEcommerceClean
 go 1.22.2
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.4.1
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-playground/validator v9.31.0+incompatible
 	github.com/golang-jwt/jwt v3.2.2+incompatible
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AddressController struct {
+	AddressService services.IAddressService
+}
+
+func NewAddressController(AddressService services.IAddressService) *AddressController {
+	return &AddressController{AddressService: AddressService}
+}
+
+func (c *AddressController) GetAddresses(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	addresses, err := c.AddressService.GetAddresses(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch addresses"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"addresses": dto.ToAddressResponses(addresses)})
+}
+func (c *AddressController) GetAddress(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	address, err := c.AddressService.GetAddress(userID, addressID)
+	if err != nil {
+		addressError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToAddressResponse(address))
+}
+func (c *AddressController) CreateAddress(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	request, ok := bindAddressRequest(ctx)
+	if !ok {
+		return
+	}
+	address, err := c.AddressService.CreateAddress(userID, request)
+	if err != nil {
+		addressError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.ToAddressResponse(address))
+}
+func (c *AddressController) UpdateAddress(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	request, ok := bindAddressRequest(ctx)
+	if !ok {
+		return
+	}
+	address, err := c.AddressService.UpdateAddress(userID, addressID, request)
+	if err != nil {
+		addressError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToAddressResponse(address))
+}
+func (c *AddressController) DeleteAddress(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	err := c.AddressService.DeleteAddress(userID, addressID)
+	if err != nil {
+		addressError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "address deleted"})
+}
+
+func bindAddressRequest(ctx *gin.Context) (dto.AddressRequest, bool) {
+	var request dto.AddressRequest
+	ok := bindRequest(ctx, &request)
+	return request, ok
+}
+func addressError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.AddressNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.InvalidPostalCode:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": false, "message": err.Error(), "error_code": http.StatusBadRequest})
+	case models.AddressLimitReached:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminController struct {
+	AdminService services.IAdminService
+}
+
+func NewAdminController(AdminService services.IAdminService) *AdminController {
+	return &AdminController{AdminService: AdminService}
+}
+
+func (c *AdminController) AdminLogin(ctx *gin.Context) {
+	var request dto.LoginRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	admin, err := c.AdminService.AdminLogin(request)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": models.InvalidInput})
+		return
+	}
+	accessToken, _ := utils.GenerateJWT(admin.Email, admin.ID, "admin", accessTokenExpiry)
+	ctx.JSON(http.StatusOK, gin.H{"message": models.LoginSuccesful, "token": accessToken})
+}
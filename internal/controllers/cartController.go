@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	guestCartCookie = "guest_cart"
+	// guest cart cookie lifetime in seconds
+	guestCartCookieAge = 30 * 24 * 60 * 60
+)
+
+type CartController struct {
+	CartService services.ICartService
+	// prices coupons on user carts; guests cannot apply coupons
+	CouponService services.ICouponService
+}
+
+func NewCartController(CartService services.ICartService, CouponService services.ICouponService) *CartController {
+	return &CartController{CartService: CartService, CouponService: CouponService}
+}
+
+func (c *CartController) GetCart(ctx *gin.Context) {
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+
+// AcknowledgePrices accepts the changed prices reported on the cart. Until
+// then GET keeps reporting them and checkout is refused.
+func (c *CartController) AcknowledgePrices(ctx *gin.Context) {
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	if err := c.CartService.AcknowledgePrices(owner); err != nil {
+		cartError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+func (c *CartController) AddItem(ctx *gin.Context) {
+	var request dto.CartItemRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	owner, ok := cartOwner(ctx, true)
+	if !ok {
+		return
+	}
+	if err := c.CartService.AddItem(owner, request); err != nil {
+		cartError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+func (c *CartController) UpdateItem(ctx *gin.Context) {
+	variantID, ok := idParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+	var request dto.CartQuantityRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	if err := c.CartService.UpdateItem(owner, variantID, request.Quantity); err != nil {
+		cartError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+func (c *CartController) RemoveItem(ctx *gin.Context) {
+	variantID, ok := idParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	if err := c.CartService.RemoveItem(owner, variantID); err != nil {
+		cartError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+func (c *CartController) ClearCart(ctx *gin.Context) {
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	if err := c.CartService.ClearCart(owner); err != nil {
+		cartError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, owner)
+}
+
+func (c *CartController) ApplyCoupon(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.ApplyCouponRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	if err := c.CouponService.ApplyCoupon(userID, request.Code); err != nil {
+		couponError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, models.CartOwner{UserID: userID})
+}
+func (c *CartController) RemoveCoupon(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	if err := c.CouponService.RemoveCoupon(userID, ctx.Param("code")); err != nil {
+		couponError(ctx, err)
+		return
+	}
+	c.writeCart(ctx, models.CartOwner{UserID: userID})
+}
+
+// writeCart responds with the revalidated cart. User carts include the
+// discount of their applied coupons.
+func (c *CartController) writeCart(ctx *gin.Context, owner models.CartOwner) {
+	cart, lines, err := c.CartService.GetCart(owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch cart"})
+		return
+	}
+	response := dto.ToCartResponse(lines)
+	if owner.UserID != 0 && c.CouponService != nil {
+		pricing, err := c.CouponService.PriceCart(owner.UserID, cart, lines)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch cart"})
+			return
+		}
+		response = response.WithPricing(pricing)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// cartOwner identifies the cart for the request: the logged-in user on /user
+// routes, otherwise the guest token from the signed cookie. When create is set
+// and the guest has no cookie yet, a new token is issued.
+func cartOwner(ctx *gin.Context, create bool) (models.CartOwner, bool) {
+	if _, exists := ctx.Get("ID"); exists {
+		userID, ok := userIDFromContext(ctx)
+		return models.CartOwner{UserID: userID}, ok
+	}
+	if token, ok := guestCartToken(ctx); ok {
+		return models.CartOwner{GuestToken: token}, true
+	}
+	if !create {
+		// no cookie means an empty cart; an empty token matches no rows
+		return models.CartOwner{}, true
+	}
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create cart"})
+		return models.CartOwner{}, false
+	}
+	setGuestCartCookie(ctx, utils.SignValue(token), guestCartCookieAge)
+	return models.CartOwner{GuestToken: token}, true
+}
+func guestCartToken(ctx *gin.Context) (string, bool) {
+	cookie, err := ctx.Cookie(guestCartCookie)
+	if err != nil || cookie == "" {
+		return "", false
+	}
+	return utils.VerifySignedValue(cookie)
+}
+func setGuestCartCookie(ctx *gin.Context, value string, maxAge int) {
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(guestCartCookie, value, maxAge, "/", "", ctx.Request.TLS != nil, true)
+}
+
+// mergeGuestCart moves a guest cart into the user's cart after login and drops
+// the cookie. A failed merge does not fail the login.
+func mergeGuestCart(ctx *gin.Context, cartService services.ICartService, userID uint) {
+	if cartService == nil {
+		return
+	}
+	token, ok := guestCartToken(ctx)
+	if !ok {
+		return
+	}
+	if err := cartService.MergeGuestCart(token, userID); err != nil {
+		return
+	}
+	setGuestCartCookie(ctx, "", -1)
+}
+func cartError(ctx *gin.Context, err error) {
+	var stockErr *models.StockError
+	if errors.As(err, &stockErr) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "sku": stockErr.SKU})
+		return
+	}
+	switch err.Error() {
+	case models.CartItemNotFound, models.VariantNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.ProductUnavailable:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.QuantityLimitExceeded:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryController struct {
+	CategoryService services.ICategoryService
+}
+
+func NewCategoryController(CategoryService services.ICategoryService) *CategoryController {
+	return &CategoryController{CategoryService: CategoryService}
+}
+
+// GetCategories returns the whole category tree.
+func (c *CategoryController) GetCategories(ctx *gin.Context) {
+	categories, err := c.CategoryService.GetCategories()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch categories"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"categories": dto.ToCategoryTree(categories)})
+}
+func (c *CategoryController) CreateCategory(ctx *gin.Context) {
+	var request dto.CategoryRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	category, err := c.CategoryService.CreateCategory(request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.ToCategoryResponse(category))
+}
+func (c *CategoryController) UpdateCategory(ctx *gin.Context) {
+	categoryID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.CategoryRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	category, err := c.CategoryService.UpdateCategory(categoryID, request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToCategoryResponse(category))
+}
+func (c *CategoryController) DeleteCategory(ctx *gin.Context) {
+	categoryID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	err := c.CategoryService.DeleteCategory(categoryID)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "category deleted"})
+}
+
+// catalogError maps category and product service errors to responses.
+func catalogError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.CategoryNotFound, models.ProductNotFound, models.VariantNotFound, models.TaxClassNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.DuplicateSlug, models.DuplicateSKU, models.DuplicateProduct, models.CategoryNotEmpty:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.InvalidParentCategory, models.UnsupportedCurrency:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": false, "message": err.Error(), "error_code": http.StatusBadRequest})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CouponController struct {
+	CouponService services.ICouponService
+}
+
+func NewCouponController(CouponService services.ICouponService) *CouponController {
+	return &CouponController{CouponService: CouponService}
+}
+
+func (c *CouponController) GetCoupons(ctx *gin.Context) {
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	coupons, total, err := c.CouponService.GetCoupons(page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch coupons"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"coupons":    coupons,
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *CouponController) GetCoupon(ctx *gin.Context) {
+	couponID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	coupon, err := c.CouponService.GetCoupon(couponID)
+	if err != nil {
+		couponError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, coupon)
+}
+func (c *CouponController) CreateCoupon(ctx *gin.Context) {
+	var request dto.CouponRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	coupon, err := c.CouponService.CreateCoupon(request)
+	if err != nil {
+		couponError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, coupon)
+}
+func (c *CouponController) UpdateCoupon(ctx *gin.Context) {
+	couponID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.CouponRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	coupon, err := c.CouponService.UpdateCoupon(couponID, request)
+	if err != nil {
+		couponError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, coupon)
+}
+func (c *CouponController) DeleteCoupon(ctx *gin.Context) {
+	couponID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.CouponService.DeleteCoupon(couponID); err != nil {
+		couponError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "coupon deleted"})
+}
+func couponError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.CouponNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.DuplicateCouponCode, models.CouponAlreadyApplied, models.CouponNotStackable, models.TooManyCoupons, models.CouponUsageExceeded:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.InvalidCoupon, models.CouponNotActive, models.CouponMinOrderValue, models.CouponNotApplicable, models.CartEmpty:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
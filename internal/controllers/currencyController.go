@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyController struct {
+	CurrencyService services.ICurrencyService
+}
+
+func NewCurrencyController(CurrencyService services.ICurrencyService) *CurrencyController {
+	return &CurrencyController{CurrencyService: CurrencyService}
+}
+
+// GetCurrencies lists the currencies prices can be displayed in.
+func (c *CurrencyController) GetCurrencies(ctx *gin.Context) {
+	rates, err := c.CurrencyService.GetRates()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch currencies"})
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToCurrenciesResponse(rates))
+}
+
+// SaveRate creates or replaces the exchange rate of a currency.
+func (c *CurrencyController) SaveRate(ctx *gin.Context) {
+	var request dto.ExchangeRateRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	rate, err := c.CurrencyService.SaveRate(ctx.Param("currency"), request)
+	if err != nil {
+		currencyError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rate)
+}
+func (c *CurrencyController) DeleteRate(ctx *gin.Context) {
+	if err := c.CurrencyService.DeleteRate(ctx.Param("currency")); err != nil {
+		currencyError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "exchange rate deleted"})
+}
+func currencyError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.UnsupportedCurrency, models.InvalidExchangeRate:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case models.ExchangeRateNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
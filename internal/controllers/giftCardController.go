@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type GiftCardController struct {
+	GiftCardService services.IGiftCardService
+}
+
+func NewGiftCardController(GiftCardService services.IGiftCardService) *GiftCardController {
+	return &GiftCardController{GiftCardService: GiftCardService}
+}
+
+// GetBalance looks a gift card up by code. The route is rate limited, as
+// codes could otherwise be guessed by trying them here.
+func (c *GiftCardController) GetBalance(ctx *gin.Context) {
+	card, err := c.GiftCardService.GetBalance(ctx.Param("code"))
+	if err != nil {
+		giftCardError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToGiftCardBalanceResponse(card))
+}
+
+// GetPurchasedGiftCards lists the cards the user bought. Their codes were
+// sent to the recipients and are not shown.
+func (c *GiftCardController) GetPurchasedGiftCards(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	cards, err := c.GiftCardService.GetPurchasedGiftCards(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch gift cards"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"gift_cards": cards})
+}
+func (c *GiftCardController) GetGiftCards(ctx *gin.Context) {
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	cards, total, err := c.GiftCardService.GetGiftCards(page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch gift cards"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"gift_cards": cards,
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *GiftCardController) GetGiftCard(ctx *gin.Context) {
+	cardID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	card, transactions, err := c.GiftCardService.GetGiftCard(cardID)
+	if err != nil {
+		giftCardError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"gift_card": card, "transactions": transactions})
+}
+
+// IssueGiftCard creates a gift card without payment. The code is in the
+// response only, so it must be passed on or the recipient email set.
+func (c *GiftCardController) IssueGiftCard(ctx *gin.Context) {
+	var request dto.GiftCardIssueRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	card, code, err := c.GiftCardService.IssueGiftCard(request)
+	if err != nil {
+		giftCardError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.IssuedGiftCardResponse{Code: code, GiftCard: card})
+}
+func (c *GiftCardController) SetGiftCardActive(ctx *gin.Context) {
+	cardID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.GiftCardStatusRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	card, err := c.GiftCardService.SetGiftCardActive(cardID, *request.IsActive)
+	if err != nil {
+		giftCardError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, card)
+}
+func giftCardError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.GiftCardNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.InvalidGiftCardAmount, models.InvalidGiftCardExpiry:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
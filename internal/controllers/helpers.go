@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// userIDFromContext reads the user ID set by the JWT middleware. It writes the
+// error response itself and returns false when the ID is missing or malformed.
+func userIDFromContext(ctx *gin.Context) (uint, bool) {
+	claims, exists := ctx.Get("ID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Claims not found"})
+		return 0, false
+	}
+	userID, ok := claims.(float64)
+	if !ok {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// idParam parses a numeric path parameter, writing a 400 response when it is invalid.
+func idParam(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// bindRequest binds the JSON body into request and validates it, writing the
+// 400 response itself when either step fails.
+func bindRequest(ctx *gin.Context, request interface{}) bool {
+	if err := ctx.BindJSON(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return false
+	}
+	if err := utils.Validate(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return false
+	}
+	return true
+}
+
+// maxImageUploadSize caps uploaded avatar and review images.
+const maxImageUploadSize = 5 << 20
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// readImageUpload reads the image in the multipart field, writing the error
+// response itself when it is missing, too large or not a JPEG, PNG or GIF.
+func readImageUpload(ctx *gin.Context, field string) ([]byte, bool) {
+	// leave room for the multipart envelope around the file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImageUploadSize+1<<20)
+	file, header, err := ctx.Request.FormFile(field)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": field + " must not exceed 5MB"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": field + " file is required"})
+		return nil, false
+	}
+	defer file.Close()
+	if header.Size > maxImageUploadSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": field + " must not exceed 5MB"})
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(file, maxImageUploadSize+1))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read " + field})
+		return nil, false
+	}
+	if len(data) > maxImageUploadSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": field + " must not exceed 5MB"})
+		return nil, false
+	}
+	if !allowedImageTypes[http.DetectContentType(data)] {
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": field + " must be a JPEG, PNG or GIF image"})
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryController struct {
+	InventoryService services.IInventoryService
+}
+
+func NewInventoryController(InventoryService services.IInventoryService) *InventoryController {
+	return &InventoryController{InventoryService: InventoryService}
+}
+
+func (c *InventoryController) GetInventory(ctx *gin.Context) {
+	variant, err := c.InventoryService.GetInventory(ctx.Param("sku"))
+	if err != nil {
+		inventoryError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToInventoryResponse(variant))
+}
+func (c *InventoryController) AdjustStock(ctx *gin.Context) {
+	var request dto.StockAdjustmentRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	variant, err := c.InventoryService.AdjustStock(ctx.Param("sku"), request)
+	if err != nil {
+		inventoryError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToInventoryResponse(variant))
+}
+func (c *InventoryController) GetLowStock(ctx *gin.Context) {
+	variants, err := c.InventoryService.GetLowStock()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch inventory"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"inventory": dto.ToInventoryResponses(variants)})
+}
+func (c *InventoryController) GetMovements(ctx *gin.Context) {
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	movements, total, err := c.InventoryService.GetMovements(ctx.Param("sku"), page, limit)
+	if err != nil {
+		inventoryError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"movements":  movements,
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+
+// inventoryError maps inventory service errors to responses. Stock errors
+// name the SKU that could not be reserved.
+func inventoryError(ctx *gin.Context, err error) {
+	var stockErr *models.StockError
+	if errors.As(err, &stockErr) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "sku": stockErr.SKU})
+		return
+	}
+	switch err.Error() {
+	case models.VariantNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.InvalidStockAdjustment:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceController struct {
+	InvoiceService services.IInvoiceService
+}
+
+func NewInvoiceController(InvoiceService services.IInvoiceService) *InvoiceController {
+	return &InvoiceController{InvoiceService: InvoiceService}
+}
+
+// GetInvoice downloads the PDF tax invoice of the user's order.
+func (c *InvoiceController) GetInvoice(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	invoice, data, err := c.InvoiceService.GetInvoice(userID, orderID)
+	if err != nil {
+		invoiceError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, invoice.Number))
+	ctx.Data(http.StatusOK, "application/pdf", data)
+}
+func invoiceError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.OrderNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.InvoiceNotAvailable:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
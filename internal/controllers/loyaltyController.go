@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyController struct {
+	LoyaltyService services.ILoyaltyService
+}
+
+func NewLoyaltyController(LoyaltyService services.ILoyaltyService) *LoyaltyController {
+	return &LoyaltyController{LoyaltyService: LoyaltyService}
+}
+
+func (c *LoyaltyController) GetLoyalty(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	account, expiring, transactions, total, err := c.LoyaltyService.GetLoyalty(userID, page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch loyalty points"})
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToLoyaltyResponse(account, expiring, transactions, dto.NewPagination(page, limit, total)))
+}
+func (c *LoyaltyController) GetRules(ctx *gin.Context) {
+	rules, err := c.LoyaltyService.GetRules()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch loyalty rules"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// SaveRule creates or replaces the rule of a category.
+func (c *LoyaltyController) SaveRule(ctx *gin.Context) {
+	var request dto.LoyaltyRuleRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	rule, err := c.LoyaltyService.SaveRule(request)
+	if err != nil {
+		loyaltyError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule removes the rule of a category, which then earns by the rule of
+// its parent. Category zero is the default rule.
+func (c *LoyaltyController) DeleteRule(ctx *gin.Context) {
+	// not idParam, as zero is valid here
+	categoryID, err := strconv.ParseUint(ctx.Param("categoryId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid categoryId"})
+		return
+	}
+	if err := c.LoyaltyService.DeleteRule(uint(categoryID)); err != nil {
+		loyaltyError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "loyalty rule deleted"})
+}
+func loyaltyError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.LoyaltyRuleNotFound, models.CategoryNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	NotificationService services.INotificationService
+}
+
+func NewNotificationController(NotificationService services.INotificationService) *NotificationController {
+	return &NotificationController{NotificationService: NotificationService}
+}
+
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	notifications, total, err := c.NotificationService.GetNotifications(userID, ctx.Query("unread") == "true", page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notifications"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": dto.ToNotificationResponses(notifications),
+		"pagination":    dto.NewPagination(page, limit, total),
+	})
+}
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	notificationID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.NotificationService.MarkRead(userID, notificationID); err != nil {
+		if err.Error() == models.NotificationNotFound {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	if err := c.NotificationService.MarkAllRead(userID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "notifications marked as read"})
+}
+func (c *NotificationController) GetPreferences(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	preferences, err := c.NotificationService.GetPreferences(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch notification preferences"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdatePreferences accepts a partial map of type to channel settings, e.g.
+// {"price_drop": {"email": false}}.
+func (c *NotificationController) UpdatePreferences(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request services.NotificationPreferences
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return
+	}
+	preferences, err := c.NotificationService.UpdatePreferences(userID, request)
+	if err != nil {
+		if err.Error() == models.InvalidNotificationPreference {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
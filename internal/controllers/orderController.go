@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderController struct {
+	OrderService services.IOrderService
+}
+
+func NewOrderController(OrderService services.IOrderService) *OrderController {
+	return &OrderController{OrderService: OrderService}
+}
+
+func (c *OrderController) Checkout(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.CheckoutRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	order, err := c.OrderService.Checkout(userID, request)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.ToOrderResponse(order))
+}
+func (c *OrderController) GetOrders(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	orders, total, err := c.OrderService.GetOrders(userID, ctx.Query("status"), page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch orders"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"orders":     dto.ToOrderSummaryResponses(orders),
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *OrderController) GetOrder(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	order, err := c.OrderService.GetOrder(userID, orderID)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToOrderResponse(order))
+}
+func (c *OrderController) AdminGetOrders(ctx *gin.Context) {
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	orders, total, err := c.OrderService.AdminGetOrders(ctx.Query("status"), page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch orders"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"orders":     dto.ToOrderSummaryResponses(orders),
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *OrderController) AdminGetOrder(ctx *gin.Context) {
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	order, err := c.OrderService.AdminGetOrder(orderID)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToOrderResponse(order))
+}
+func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.OrderStatusRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	order, err := c.OrderService.UpdateOrderStatus(orderID, request.Status)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToOrderResponse(order))
+}
+
+// CancelOrder cancels an order that has not shipped. The body is optional.
+func (c *OrderController) CancelOrder(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.CancelOrderRequest
+	if ctx.Request.ContentLength != 0 && !bindRequest(ctx, &request) {
+		return
+	}
+	order, err := c.OrderService.CancelOrder(userID, orderID, request)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToOrderResponse(order))
+}
+func (c *OrderController) GetOrderHistory(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	history, refunds, err := c.OrderService.GetOrderHistory(userID, orderID)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"history": history, "refunds": refunds})
+}
+func (c *OrderController) AdminGetOrderHistory(ctx *gin.Context) {
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	history, refunds, err := c.OrderService.AdminGetOrderHistory(orderID)
+	if err != nil {
+		orderError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"history": history, "refunds": refunds})
+}
+
+// orderError maps order service errors to responses. Stock errors name the
+// SKU that ran out between reading the cart and placing the order.
+func orderError(ctx *gin.Context, err error) {
+	var stockErr *models.StockError
+	if errors.As(err, &stockErr) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "sku": stockErr.SKU})
+		return
+	}
+	switch err.Error() {
+	case models.OrderNotFound, models.AddressNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.CartEmpty, models.ShippingNotAvailable:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case models.CartNeedsReview, models.InvalidOrderTransition, models.InvalidStockAdjustment, models.OrderNotCancellable, models.RefundExceedsPaid,
+		models.OrderNotPayable, models.ReservationNotActive,
+		models.CouponNotActive, models.CouponUsageExceeded, models.CouponMinOrderValue, models.CouponNotApplicable:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
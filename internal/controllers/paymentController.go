@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const maxWebhookSize = 1 << 20
+
+type PaymentController struct {
+	PaymentService services.IPaymentService
+}
+
+func NewPaymentController(PaymentService services.IPaymentService) *PaymentController {
+	return &PaymentController{PaymentService: PaymentService}
+}
+
+func (c *PaymentController) CreatePayment(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	// an empty body pays the whole order through the gateway
+	var request dto.PaymentRequest
+	if ctx.Request.ContentLength != 0 && !bindRequest(ctx, &request) {
+		return
+	}
+	payment, err := c.PaymentService.CreatePayment(userID, orderID, request)
+	if err != nil {
+		paymentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToPaymentResponse(payment))
+}
+func (c *PaymentController) TopUpWallet(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.TopUpRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	payment, err := c.PaymentService.TopUpWallet(userID, request.Amount)
+	if err != nil {
+		paymentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToPaymentResponse(payment))
+}
+
+// PurchaseGiftCard starts the payment for a gift card, which is activated and
+// emailed to the recipient once the payment succeeds.
+func (c *PaymentController) PurchaseGiftCard(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.GiftCardPurchaseRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	payment, card, err := c.PaymentService.PurchaseGiftCard(userID, request)
+	if err != nil {
+		paymentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.GiftCardPurchaseResponse{Payment: dto.ToPaymentResponse(payment), GiftCard: card})
+}
+
+// Webhook receives provider events. The raw body is needed to verify the
+// signature, so it is read before any JSON decoding.
+func (c *PaymentController) Webhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookSize))
+	if err != nil {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+		return
+	}
+	if err := c.PaymentService.HandleWebhook(payload, ctx.Request.Header); err != nil {
+		paymentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// SimulatePayment completes a fake gateway payment, declining it when
+// ?result=declined. Only routed, for admins, when the fake gateway is
+// configured.
+func (c *PaymentController) SimulatePayment(ctx *gin.Context) {
+	succeed := ctx.Query("result") != "declined"
+	if err := c.PaymentService.SimulatePayment(ctx.Param("intentId"), succeed); err != nil {
+		paymentError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "payment completed"})
+}
+func (c *PaymentController) GetCODPincodes(ctx *gin.Context) {
+	pincodes, err := c.PaymentService.GetCODPincodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch pincodes"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"pincodes": pincodes})
+}
+func (c *PaymentController) AddCODPincodes(ctx *gin.Context) {
+	var request dto.CODPincodesRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	if err := c.PaymentService.AddCODPincodes(request.PostalCodes); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "pincodes added"})
+}
+func (c *PaymentController) RemoveCODPincode(ctx *gin.Context) {
+	if err := c.PaymentService.RemoveCODPincode(ctx.Param("pincode")); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "pincode removed"})
+}
+func paymentError(ctx *gin.Context, err error) {
+	var stockErr *models.StockError
+	if errors.As(err, &stockErr) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error(), "sku": stockErr.SKU})
+		return
+	}
+	switch err.Error() {
+	case models.OrderNotFound, models.PaymentNotFound, models.GiftCardNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.OrderNotPayable, models.InvalidOrderTransition, models.CODNotAvailable, models.InsufficientWalletBalance,
+		models.GiftCardNotUsable, models.InsufficientGiftCardBalance, models.InsufficientLoyaltyPoints:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.InvalidWalletAmount, models.InvalidTopUpAmount, models.InvalidGiftCardAmount, models.GiftCardCodeRequired:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case models.InvalidWebhookSignature:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
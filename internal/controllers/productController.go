@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type ProductController struct {
+	ProductService        services.IProductService
+	RecommendationService services.IRecommendationService
+	CurrencyService       services.ICurrencyService
+}
+
+func NewProductController(ProductService services.IProductService, RecommendationService services.IRecommendationService, CurrencyService services.ICurrencyService) *ProductController {
+	return &ProductController{ProductService: ProductService, RecommendationService: RecommendationService, CurrencyService: CurrencyService}
+}
+
+// GetProducts lists active products. Supported query parameters are page,
+// limit, category_id, brand, min_price, max_price, size, colour, sort
+// (newest, price_asc, price_desc, name) and currency, which defaults to the
+// logged-in user's display currency. Price filters are in the base currency.
+func (c *ProductController) GetProducts(ctx *gin.Context) {
+	c.listProducts(ctx, false)
+}
+
+// AdminGetProducts lists products like GetProducts but includes inactive ones.
+func (c *ProductController) AdminGetProducts(ctx *gin.Context) {
+	c.listProducts(ctx, true)
+}
+func (c *ProductController) listProducts(ctx *gin.Context, includeInactive bool) {
+	filter, ok := parseProductFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.IncludeInactive = includeInactive
+	display := currency.BaseDisplay
+	if !includeInactive {
+		if display, ok = c.displayCurrency(ctx); !ok {
+			return
+		}
+	}
+	products, total, err := c.ProductService.GetProducts(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch products"})
+		return
+	}
+	responses := dto.ToProductResponses(products)
+	if !includeInactive {
+		for i := range responses {
+			responses[i].SetDisplayPrices(display)
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"products":   responses,
+		"pagination": dto.NewPagination(filter.Page, filter.Limit, total),
+	})
+}
+func (c *ProductController) GetProduct(ctx *gin.Context) {
+	c.getProduct(ctx, false)
+}
+func (c *ProductController) AdminGetProduct(ctx *gin.Context) {
+	c.getProduct(ctx, true)
+}
+func (c *ProductController) getProduct(ctx *gin.Context, includeInactive bool) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	display := currency.BaseDisplay
+	if !includeInactive {
+		if display, ok = c.displayCurrency(ctx); !ok {
+			return
+		}
+	}
+	product, err := c.ProductService.GetProduct(productID, includeInactive)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	response := dto.ToProductResponse(product)
+	if !includeInactive {
+		c.recordView(ctx, product.ID)
+		response.SetDisplayPrices(display)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// displayCurrency resolves the currency query parameter, or without one the
+// display currency of the logged-in user, writing the error response itself.
+func (c *ProductController) displayCurrency(ctx *gin.Context) (currency.Display, bool) {
+	var userID uint
+	if claims, exists := ctx.Get("ID"); exists {
+		if id, ok := claims.(float64); ok {
+			userID = uint(id)
+		}
+	}
+	display, err := c.CurrencyService.GetDisplay(userID, ctx.Query("currency"))
+	if err != nil {
+		if err.Error() == models.UnsupportedCurrency {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return display, false
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch exchange rates"})
+		return display, false
+	}
+	return display, true
+}
+
+// recordView records the view of the logged-in user or of the guest, who is
+// given a guest cookie if they have none yet. It must run before the response
+// is written. A failure does not fail the request.
+func (c *ProductController) recordView(ctx *gin.Context, productID uint) {
+	var owner models.CartOwner
+	if claims, exists := ctx.Get("ID"); exists {
+		if userID, ok := claims.(float64); ok {
+			owner.UserID = uint(userID)
+		}
+	} else if token, ok := guestCartToken(ctx); ok {
+		owner.GuestToken = token
+	} else if token, err := utils.GenerateRandomString(32); err == nil {
+		setGuestCartCookie(ctx, utils.SignValue(token), guestCartCookieAge)
+		owner.GuestToken = token
+	}
+	if err := c.RecommendationService.RecordView(owner, productID); err != nil {
+		fmt.Println("failed to record view of product", productID, err)
+	}
+}
+func (c *ProductController) CreateProduct(ctx *gin.Context) {
+	var request dto.ProductRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	product, err := c.ProductService.CreateProduct(request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.ToProductResponse(product))
+}
+func (c *ProductController) UpdateProduct(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.ProductRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	product, err := c.ProductService.UpdateProduct(productID, request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToProductResponse(product))
+}
+func (c *ProductController) DeleteProduct(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	err := c.ProductService.DeleteProduct(productID)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "product deleted"})
+}
+func (c *ProductController) CreateVariant(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.VariantRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	variant, err := c.ProductService.CreateVariant(productID, request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, dto.ToVariantResponse(variant))
+}
+func (c *ProductController) UpdateVariant(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := idParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+	var request dto.VariantRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	variant, err := c.ProductService.UpdateVariant(productID, variantID, request)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToVariantResponse(variant))
+}
+func (c *ProductController) DeleteVariant(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := idParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+	err := c.ProductService.DeleteVariant(productID, variantID)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "variant deleted"})
+}
+
+// parseProductFilter reads the list query parameters, writing a 400 response
+// when one of them is malformed.
+func parseProductFilter(ctx *gin.Context) (models.ProductFilter, bool) {
+	filter := models.ProductFilter{
+		Brand:  ctx.Query("brand"),
+		Size:   ctx.Query("size"),
+		Colour: ctx.Query("colour"),
+		Sort:   ctx.DefaultQuery("sort", models.SortNewest),
+	}
+	switch filter.Sort {
+	case models.SortNewest, models.SortPriceAsc, models.SortPriceDesc, models.SortName:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return filter, false
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return filter, false
+	}
+	filter.Page = page
+	filter.Limit = limit
+	categoryID, ok := int64Query(ctx, "category_id")
+	if !ok {
+		return filter, false
+	}
+	filter.CategoryID = uint(categoryID)
+	if filter.MinPrice, ok = int64Query(ctx, "min_price"); !ok {
+		return filter, false
+	}
+	if filter.MaxPrice, ok = int64Query(ctx, "max_price"); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// parsePage reads the page and limit query parameters with their defaults.
+func parsePage(ctx *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid page"})
+		return 0, 0, false
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(defaultPageLimit)))
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return 0, 0, false
+	}
+	return page, limit, true
+}
+
+// int64Query parses an optional non-negative integer query parameter, which
+// defaults to zero, writing a 400 response when it is malformed.
+func int64Query(ctx *gin.Context, name string) (int64, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return parsed, true
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultRecommendationLimit = 6
+
+type RecommendationController struct {
+	RecommendationService services.IRecommendationService
+}
+
+func NewRecommendationController(RecommendationService services.IRecommendationService) *RecommendationController {
+	return &RecommendationController{RecommendationService: RecommendationService}
+}
+
+// GetRecentlyViewed lists the products last viewed by the user or guest,
+// most recent first, up to ?limit=.
+func (c *RecommendationController) GetRecentlyViewed(ctx *gin.Context) {
+	owner, ok := cartOwner(ctx, false)
+	if !ok {
+		return
+	}
+	limit, ok := limitQuery(ctx, defaultPageLimit)
+	if !ok {
+		return
+	}
+	products, err := c.RecommendationService.GetRecentlyViewed(owner, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch recently viewed products"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"products": dto.ToProductResponses(products)})
+}
+
+// GetRecommendations lists the products frequently bought together with the
+// product and those its viewers also viewed, up to ?limit= of each.
+func (c *RecommendationController) GetRecommendations(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	limit, ok := limitQuery(ctx, defaultRecommendationLimit)
+	if !ok {
+		return
+	}
+	recommendations, err := c.RecommendationService.GetRecommendations(productID, limit)
+	if err != nil {
+		catalogError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"frequently_bought_together": dto.ToProductResponses(recommendations[models.RecommendationBoughtTogether]),
+		"customers_also_viewed":      dto.ToProductResponses(recommendations[models.RecommendationAlsoViewed]),
+	})
+}
+
+// limitQuery reads an optional ?limit= of at most MaxRecentlyViewed.
+func limitQuery(ctx *gin.Context, fallback int) (int, bool) {
+	limit, ok := int64Query(ctx, "limit")
+	if !ok {
+		return 0, false
+	}
+	if limit == 0 {
+		return fallback, true
+	}
+	if limit > models.MaxRecentlyViewed {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return 0, false
+	}
+	return int(limit), true
+}
@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReferralController struct {
+	ReferralService services.IReferralService
+}
+
+func NewReferralController(ReferralService services.IReferralService) *ReferralController {
+	return &ReferralController{ReferralService: ReferralService}
+}
+
+func (c *ReferralController) GetReferrals(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	code, referrals, err := c.ReferralService.GetReferrals(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch referrals"})
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToReferralsResponse(code, referrals))
+}
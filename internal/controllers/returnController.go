@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnController struct {
+	ReturnService services.IReturnService
+}
+
+func NewReturnController(ReturnService services.IReturnService) *ReturnController {
+	return &ReturnController{ReturnService: ReturnService}
+}
+
+func (c *ReturnController) CreateReturn(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.ReturnRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	returnRequest, err := c.ReturnService.CreateReturn(userID, orderID, request)
+	if err != nil {
+		returnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, returnRequest)
+}
+func (c *ReturnController) GetReturns(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	returns, total, err := c.ReturnService.GetReturns(userID, ctx.Query("status"), page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch returns"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"returns":    returns,
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *ReturnController) GetReturn(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	returnID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	returnRequest, err := c.ReturnService.GetReturn(userID, returnID)
+	if err != nil {
+		returnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, returnRequest)
+}
+func (c *ReturnController) AdminGetReturns(ctx *gin.Context) {
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	returns, total, err := c.ReturnService.AdminGetReturns(ctx.Query("status"), page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch returns"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"returns":    returns,
+		"pagination": dto.NewPagination(page, limit, total),
+	})
+}
+func (c *ReturnController) AdminGetReturn(ctx *gin.Context) {
+	returnID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	returnRequest, err := c.ReturnService.AdminGetReturn(returnID)
+	if err != nil {
+		returnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, returnRequest)
+}
+func (c *ReturnController) UpdateReturnStatus(ctx *gin.Context) {
+	returnID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.ReturnStatusRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	returnRequest, err := c.ReturnService.UpdateReturnStatus(returnID, request)
+	if err != nil {
+		returnError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, returnRequest)
+}
+func returnError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.ReturnNotFound, models.OrderNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.InvalidReturnItems:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case models.ReturnNotAllowed, models.ReturnWindowExpired, models.ReturnQuantityExceeded, models.InvalidReturnTransition,
+		models.InvalidOrderTransition, models.RefundExceedsPaid:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
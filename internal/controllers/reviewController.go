@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewController struct {
+	ReviewService services.IReviewService
+}
+
+func NewReviewController(ReviewService services.IReviewService) *ReviewController {
+	return &ReviewController{ReviewService: ReviewService}
+}
+
+// GetProductReviews lists the published reviews of a product with its
+// rating average and histogram.
+func (c *ReviewController) GetProductReviews(ctx *gin.Context) {
+	productID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	filter, ok := parseReviewFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.ProductID = productID
+	product, reviews, total, err := c.ReviewService.GetProductReviews(filter)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"rating":     product.Rating,
+		"reviews":    dto.ToReviewResponses(reviews),
+		"pagination": dto.NewPagination(filter.Page, filter.Limit, total),
+	})
+}
+func (c *ReviewController) GetUserReview(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	review, err := c.ReviewService.GetUserReview(userID, productID)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+func (c *ReviewController) CreateReview(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	var request dto.ReviewRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	review, err := c.ReviewService.CreateReview(userID, productID, request)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, review)
+}
+func (c *ReviewController) UpdateReview(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	var request dto.ReviewRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	review, err := c.ReviewService.UpdateReview(userID, productID, request)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+func (c *ReviewController) DeleteReview(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.ReviewService.DeleteReview(userID, productID); err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "review deleted"})
+}
+
+// AddReviewImage accepts a multipart upload in the "image" field.
+func (c *ReviewController) AddReviewImage(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	data, ok := readImageUpload(ctx, "image")
+	if !ok {
+		return
+	}
+	review, err := c.ReviewService.AddReviewImage(userID, productID, data)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+func (c *ReviewController) RemoveReviewImage(ctx *gin.Context) {
+	userID, productID, ok := userAndProductID(ctx)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(ctx.Param("index"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid index"})
+		return
+	}
+	review, err := c.ReviewService.RemoveReviewImage(userID, productID, index)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+func (c *ReviewController) VoteHelpful(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	reviewID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.ReviewService.VoteHelpful(userID, reviewID); err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "vote recorded"})
+}
+func (c *ReviewController) RemoveVote(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	reviewID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.ReviewService.RemoveVote(userID, reviewID); err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "vote removed"})
+}
+
+// AdminGetReviews lists reviews of all statuses, optionally filtered by
+// product, rating and status.
+func (c *ReviewController) AdminGetReviews(ctx *gin.Context) {
+	filter, ok := parseReviewFilter(ctx)
+	if !ok {
+		return
+	}
+	productID, ok := int64Query(ctx, "product_id")
+	if !ok {
+		return
+	}
+	filter.ProductID = uint(productID)
+	filter.Status = ctx.Query("status")
+	reviews, total, err := c.ReviewService.AdminGetReviews(filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch reviews"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"reviews":    reviews,
+		"pagination": dto.NewPagination(filter.Page, filter.Limit, total),
+	})
+}
+func (c *ReviewController) ModerateReview(ctx *gin.Context) {
+	reviewID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.ReviewModerationRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	review, err := c.ReviewService.ModerateReview(reviewID, request)
+	if err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, review)
+}
+func (c *ReviewController) AdminDeleteReview(ctx *gin.Context) {
+	reviewID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.ReviewService.AdminDeleteReview(reviewID); err != nil {
+		reviewError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "review deleted"})
+}
+func userAndProductID(ctx *gin.Context) (uint, uint, bool) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return 0, 0, false
+	}
+	productID, ok := idParam(ctx, "id")
+	return userID, productID, ok
+}
+func parseReviewFilter(ctx *gin.Context) (models.ReviewFilter, bool) {
+	filter := models.ReviewFilter{Sort: ctx.DefaultQuery("sort", models.ReviewSortHelpful)}
+	switch filter.Sort {
+	case models.ReviewSortHelpful, models.ReviewSortNewest, models.ReviewSortHighest, models.ReviewSortLowest:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return filter, false
+	}
+	rating, ok := int64Query(ctx, "rating")
+	if !ok {
+		return filter, false
+	}
+	if rating > 5 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid rating"})
+		return filter, false
+	}
+	filter.Rating = int(rating)
+	filter.Page, filter.Limit, ok = parsePage(ctx)
+	return filter, ok
+}
+func reviewError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.ReviewNotFound, models.ProductNotFound, models.ReviewImageNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.NotVerifiedBuyer, models.CannotVoteOwnReview:
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case models.ReviewAlreadyExists, models.ReviewImageLimit:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.InvalidImage, models.ImageTooLarge:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/search"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxSearchLength caps the length of the q parameter in characters.
+const maxSearchLength = 200
+
+type SearchController struct {
+	SearchService services.ISearchService
+}
+
+func NewSearchController(SearchService services.ISearchService) *SearchController {
+	return &SearchController{SearchService: SearchService}
+}
+
+// Search finds active products matching q, tolerating typos. Supported
+// filters are category_id, brand, min_price and max_price; sort is one of
+// relevance (the default), newest, price_asc, price_desc and name. The
+// response includes category, brand and price range facets.
+func (c *SearchController) Search(ctx *gin.Context) {
+	text := strings.TrimSpace(ctx.Query("q"))
+	if utf8.RuneCountInString(text) > maxSearchLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "search query too long"})
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	query := search.Query{Text: text, Brand: ctx.Query("brand"), Sort: ctx.Query("sort"), Page: page, Limit: limit}
+	categoryID, ok := int64Query(ctx, "category_id")
+	if !ok {
+		return
+	}
+	if query.MinPrice, ok = int64Query(ctx, "min_price"); !ok {
+		return
+	}
+	if query.MaxPrice, ok = int64Query(ctx, "max_price"); !ok {
+		return
+	}
+	result, products, err := c.SearchService.Search(query, uint(categoryID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "search failed"})
+		return
+	}
+	hits, facets := dto.ToSearchResponse(result, products)
+	ctx.JSON(http.StatusOK, gin.H{
+		"query":      text,
+		"results":    hits,
+		"facets":     facets,
+		"pagination": dto.NewPagination(page, limit, result.Total),
+	})
+}
+
+// Reindex rebuilds the search index of all products.
+func (c *SearchController) Reindex(ctx *gin.Context) {
+	if err := c.SearchService.Reindex(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "search index rebuilt"})
+}
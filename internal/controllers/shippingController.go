@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingController struct {
+	ShippingService services.IShippingService
+}
+
+func NewShippingController(ShippingService services.IShippingService) *ShippingController {
+	return &ShippingController{ShippingService: ShippingService}
+}
+
+func (c *ShippingController) GetShippingMethods(ctx *gin.Context) {
+	methods, err := c.ShippingService.GetShippingMethods()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch shipping methods"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"shipping_methods": methods})
+}
+func (c *ShippingController) CreateShippingMethod(ctx *gin.Context) {
+	var request dto.ShippingMethodRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	method, err := c.ShippingService.CreateShippingMethod(request)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, method)
+}
+func (c *ShippingController) UpdateShippingMethod(ctx *gin.Context) {
+	methodID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.ShippingMethodRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	method, err := c.ShippingService.UpdateShippingMethod(methodID, request)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, method)
+}
+func (c *ShippingController) DeleteShippingMethod(ctx *gin.Context) {
+	methodID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.ShippingService.DeleteShippingMethod(methodID); err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "shipping method deleted"})
+}
+
+// GetQuotes lists the shipping options for the user's cart delivered to
+// ?address_id=.
+func (c *ShippingController) GetQuotes(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	addressID, ok := int64Query(ctx, "address_id")
+	if !ok {
+		return
+	}
+	if addressID == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "address_id is required"})
+		return
+	}
+	quotes, err := c.ShippingService.GetQuotes(userID, uint(addressID))
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"quotes": dto.ToShippingQuoteResponses(quotes)})
+}
+func (c *ShippingController) GetOrderShipments(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	shipments, err := c.ShippingService.GetOrderShipments(userID, orderID)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+func (c *ShippingController) AdminGetOrderShipments(ctx *gin.Context) {
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	shipments, err := c.ShippingService.AdminGetOrderShipments(orderID)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"shipments": shipments})
+}
+
+// CreateShipment books the order with the carrier and creates its label.
+func (c *ShippingController) CreateShipment(ctx *gin.Context) {
+	orderID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	shipment, err := c.ShippingService.CreateShipment(orderID)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, shipment)
+}
+
+// GetShipmentLabel downloads the carrier label to print on the parcel.
+func (c *ShippingController) GetShipmentLabel(ctx *gin.Context) {
+	shipmentID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	shipment, data, err := c.ShippingService.GetShipmentLabel(shipmentID)
+	if err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="label-%s"`, shipment.TrackingNumber))
+	ctx.Data(http.StatusOK, shipment.LabelType, data)
+}
+
+// Webhook receives carrier tracking updates, verified against the raw body.
+func (c *ShippingController) Webhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookSize))
+	if err != nil {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "payload too large"})
+		return
+	}
+	if err := c.ShippingService.HandleWebhook(payload, ctx.Request.Header); err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
+
+// SimulateTracking moves a fake carrier parcel to ?status=. Only routed, for
+// admins, when the fake carrier is configured.
+func (c *ShippingController) SimulateTracking(ctx *gin.Context) {
+	if err := c.ShippingService.SimulateTracking(ctx.Param("tracking"), ctx.Query("status")); err != nil {
+		shippingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "tracking updated"})
+}
+func shippingError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.ShippingMethodNotFound, models.ShipmentNotFound, models.OrderNotFound, models.AddressNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.DuplicateShippingMethod, models.OrderNotShippable:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.ShippingNotAvailable:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case models.InvalidShipmentWebhook:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
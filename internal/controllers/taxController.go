@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxController struct {
+	TaxService services.ITaxService
+}
+
+func NewTaxController(TaxService services.ITaxService) *TaxController {
+	return &TaxController{TaxService: TaxService}
+}
+
+func (c *TaxController) GetTaxClasses(ctx *gin.Context) {
+	taxClasses, err := c.TaxService.GetTaxClasses()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tax classes"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"tax_classes": taxClasses})
+}
+func (c *TaxController) CreateTaxClass(ctx *gin.Context) {
+	var request dto.TaxClassRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	taxClass, err := c.TaxService.CreateTaxClass(request)
+	if err != nil {
+		taxError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, taxClass)
+}
+func (c *TaxController) UpdateTaxClass(ctx *gin.Context) {
+	taxClassID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	var request dto.TaxClassRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	taxClass, err := c.TaxService.UpdateTaxClass(taxClassID, request)
+	if err != nil {
+		taxError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, taxClass)
+}
+func (c *TaxController) DeleteTaxClass(ctx *gin.Context) {
+	taxClassID, ok := idParam(ctx, "id")
+	if !ok {
+		return
+	}
+	if err := c.TaxService.DeleteTaxClass(taxClassID); err != nil {
+		taxError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "tax class deleted"})
+}
+func taxError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.TaxClassNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.DuplicateTaxClass, models.TaxClassInUse:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case models.InvalidTaxSlabs:
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,26 +1,41 @@
 package controllers
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
 	"github.com/Ansalps/UserEcommerceClean/internal/models"
 	"github.com/Ansalps/UserEcommerceClean/internal/services"
 	"github.com/Ansalps/UserEcommerceClean/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// token lifetimes in hours
+	accessTokenExpiry  = 1
+	refreshTokenExpiry = 24 * 7
+	// header carrying a client generated device identifier, used to detect
+	// users referring themselves
+	deviceIDHeader = "X-Device-ID"
+)
+
 type UserController struct {
 	UserService services.IUserService
+	CartService services.ICartService
 }
 
-func NewUserController(UserService services.IUserService) *UserController {
-	return &UserController{UserService: UserService}
+func NewUserController(UserService services.IUserService, CartService services.ICartService) *UserController {
+	return &UserController{UserService: UserService, CartService: CartService}
 }
 
 func (c *UserController) UserSignUp(ctx *gin.Context) {
-	var user models.User
-	err := ctx.BindJSON(&user)
+	var request dto.SignUpRequest
+	err := ctx.BindJSON(&request)
 	fmt.Println("error", err)
 	response := gin.H{
 		"status":  false,
@@ -31,19 +46,26 @@ func (c *UserController) UserSignUp(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, response)
 		return
 	}
-	if err := utils.Validate(user); err != nil {
+	if err := utils.Validate(request); err != nil {
 		fmt.Println("", err)
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"message": err.Error(),
 		})
 		return
 	}
-	err = c.UserService.UserSignUp(&user)
+	user := request.ToUser()
+	user.SignupIP = ctx.ClientIP()
+	user.SignupDevice = ctx.GetHeader(deviceIDHeader)
+	err = c.UserService.UserSignUp(&user, request.ReferralCode)
 	if err != nil {
 		if err.Error() == models.UserAlreadyExists {
 			ctx.JSON(http.StatusConflict, gin.H{"message": err.Error()})
 			return
 		}
+		if err.Error() == models.InvalidReferralCode {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -52,7 +74,7 @@ func (c *UserController) UserSignUp(ctx *gin.Context) {
 	})
 }
 func (c *UserController) UserLogin(ctx *gin.Context) {
-	var loginRequest models.UserLogin
+	var loginRequest dto.LoginRequest
 	err := ctx.BindJSON(&loginRequest)
 	fmt.Println("error", err)
 	response := gin.H{
@@ -82,9 +104,98 @@ func (c *UserController) UserLogin(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "email or password is incorrect"})
 		return
 	}
-	accessToken, _ := utils.GenerateJWT(User.Email, User.ID, "user", 1)
-	//refreshToken, _ := utils.GenerateJWT(User.Email, User.ID, "user", 2)
-	ctx.JSON(http.StatusOK, gin.H{"message": "Login Succesful", "user": User, "token": accessToken})
+	if err := services.CheckUserStatus(User); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+	mergeGuestCart(ctx, c.CartService, User.ID)
+	accessToken, _ := utils.GenerateJWT(User.Email, User.ID, "user", accessTokenExpiry)
+	refreshToken, _ := utils.GenerateRefreshToken(User.Email, User.ID, "user", refreshTokenExpiry)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Login Succesful", "user": dto.ToUserResponse(User), "token": accessToken, "refresh_token": refreshToken})
+}
+func (c *UserController) RequestMagicLink(ctx *gin.Context) {
+	var request dto.MagicLinkRequest
+	err := ctx.BindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return
+	}
+	if err := utils.Validate(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return
+	}
+	err = c.UserService.RequestMagicLink(request.Email)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send login link"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": models.MagicLinkSent})
+}
+func (c *UserController) MagicLinkLogin(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	User, err := c.UserService.MagicLinkLogin(token)
+	if err != nil {
+		switch err.Error() {
+		case models.UserBlocked, models.UserDeleted:
+			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		case models.InvalidMagicLink:
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	mergeGuestCart(ctx, c.CartService, User.ID)
+	accessToken, _ := utils.GenerateJWT(User.Email, User.ID, "user", accessTokenExpiry)
+	refreshToken, _ := utils.GenerateRefreshToken(User.Email, User.ID, "user", refreshTokenExpiry)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Login Succesful", "user": dto.ToUserResponse(User), "token": accessToken, "refresh_token": refreshToken})
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token.
+func (c *UserController) RefreshToken(ctx *gin.Context) {
+	var request dto.RefreshTokenRequest
+	if err := ctx.BindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return
+	}
+	if err := utils.Validate(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return
+	}
+	User, err := c.UserService.RefreshToken(request.RefreshToken)
+	if err != nil {
+		switch err.Error() {
+		case models.UserBlocked, models.UserDeleted:
+			ctx.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		case models.InvalidRefreshToken:
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	accessToken, _ := utils.GenerateJWT(User.Email, User.ID, "user", accessTokenExpiry)
+	refreshToken, _ := utils.GenerateRefreshToken(User.Email, User.ID, "user", refreshTokenExpiry)
+	ctx.JSON(http.StatusOK, gin.H{"token": accessToken, "refresh_token": refreshToken})
 }
 func (c *UserController) GetProfile(ctx *gin.Context) {
 	claims, exists := ctx.Get("ID")
@@ -106,7 +217,80 @@ func (c *UserController) GetProfile(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user data"})
 		return
 	}
-	ctx.JSON(http.StatusOK, user)
+	etag := profileETag(user)
+	ctx.Header("ETag", etag)
+	if ctx.GetHeader("If-None-Match") == etag {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToUserResponse(user))
+}
+
+// PatchProfile applies a JSON Merge Patch to the profile. The request must
+// carry the ETag from GET /user/profile in If-Match; a stale ETag gets 412.
+func (c *UserController) PatchProfile(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": models.PreconditionRequired})
+		return
+	}
+	var patch map[string]interface{}
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&patch); err != nil || patch == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return
+	}
+	user, err := c.UserService.GetProfile(fmt.Sprint(userID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch user data"})
+		return
+	}
+	if ifMatch != "*" && ifMatch != profileETag(user) {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": models.VersionConflict})
+		return
+	}
+	current := dto.NewUpdateProfileRequest(user)
+	update, fields, err := current.ApplyMergePatch(patch)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return
+	}
+	if err := utils.Validate(update); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return
+	}
+	updated, err := c.UserService.PatchProfile(userID, user.Version, update, fields)
+	if err != nil {
+		if err.Error() == models.VersionConflict {
+			ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == models.UnsupportedCurrency {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("ETag", profileETag(updated))
+	ctx.JSON(http.StatusOK, dto.ToUserResponse(updated))
+}
+func profileETag(user *models.User) string {
+	return fmt.Sprintf(`"%d-%d"`, user.ID, user.Version)
 }
 func (c *UserController) UpdateProfile(ctx *gin.Context) {
 	claims, exists := ctx.Get("ID")
@@ -120,7 +304,7 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 	//userID := fmt.Sprintf("%.0f", userIDFloat)
-	var updateProfileRequest models.UserUpdate
+	var updateProfileRequest dto.UpdateProfileRequest
 	err := ctx.BindJSON(&updateProfileRequest)
 	fmt.Println("error", err)
 	response := gin.H{
@@ -142,8 +326,115 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 	}
 	err = c.UserService.UpdateProfile(uint(userID), updateProfileRequest)
 	if err != nil {
+		if err.Error() == models.UnsupportedCurrency {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "user updated successfully"})
 }
+
+func (c *UserController) DeleteAccount(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.DeleteAccountRequest
+	err := ctx.BindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  false,
+			"message": "failed to bind request",
+		})
+		return
+	}
+	if err := utils.Validate(request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":     false,
+			"message":    err.Error(),
+			"error_code": http.StatusBadRequest,
+		})
+		return
+	}
+	err = c.UserService.DeleteAccount(userID, request.Password)
+	if err != nil {
+		if err.Error() == models.IncorrectPassword {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": models.AccountDeleted})
+}
+
+// ExportUserData returns everything stored about the user as JSON, or as a ZIP
+// archive with one JSON file per table when called with ?format=zip.
+func (c *UserController) ExportUserData(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	data, err := c.UserService.ExportUserData(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export user data"})
+		return
+	}
+	if ctx.Query("format") != "zip" {
+		ctx.Header("Content-Disposition", "attachment; filename=user-data.json")
+		ctx.JSON(http.StatusOK, data)
+		return
+	}
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		file, err := archive.Create(name + ".json")
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export user data"})
+			return
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data[name]); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export user data"})
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export user data"})
+		return
+	}
+	ctx.Header("Content-Disposition", "attachment; filename=user-data.zip")
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// UpdateAvatar accepts a multipart upload in the "avatar" field. The content
+// type is sniffed from the file itself; the client supplied one is ignored.
+func (c *UserController) UpdateAvatar(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	data, ok := readImageUpload(ctx, "avatar")
+	if !ok {
+		return
+	}
+	user, err := c.UserService.UpdateAvatar(userID, data)
+	if err != nil {
+		switch err.Error() {
+		case models.InvalidImage, models.ImageTooLarge:
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update avatar"})
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToUserResponse(user))
+}
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
 	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
 	"github.com/Ansalps/UserEcommerceClean/internal/models"
 	"github.com/gin-gonic/gin"
@@ -28,7 +29,7 @@ func TestSignup(t *testing.T) {
 
 	type TypeCase struct {
 		name               string
-		requestBody        models.User
+		requestBody        dto.SignUpRequest
 		expectedStatusCode int
 		expectedResponse   string
 		returnError        error
@@ -37,7 +38,7 @@ func TestSignup(t *testing.T) {
 	tests := []TypeCase{
 		{
 			name: "successful signup",
-			requestBody: models.User{
+			requestBody: dto.SignUpRequest{
 				FirstName: "John",
 				LastName:  "Doe",
 				Email:     "johndoe@gmail.com",
@@ -51,7 +52,7 @@ func TestSignup(t *testing.T) {
 		},
 		{
 			name: "user already exists",
-			requestBody: models.User{
+			requestBody: dto.SignUpRequest{
 				FirstName: "John",
 				LastName:  "Doe",
 				Email:     "johndoe@gmail.com",
@@ -65,7 +66,7 @@ func TestSignup(t *testing.T) {
 		},
 		{
 			name: "invalid input - empty first name",
-			requestBody: models.User{
+			requestBody: dto.SignUpRequest{
 				FirstName: "",
 				LastName:  "Doe",
 				Email:     "johndoe@gmail.com",
@@ -81,10 +82,13 @@ func TestSignup(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			if test.expectSignupCall {
+				user := test.requestBody.ToUser()
+				// the remote address of httptest requests
+				user.SignupIP = "192.0.2.1"
 				if test.returnError != nil {
-					mockUserService.EXPECT().UserSignUp(&test.requestBody).Return(test.returnError).Times(1)
+					mockUserService.EXPECT().UserSignUp(&user, test.requestBody.ReferralCode).Return(test.returnError).Times(1)
 				} else {
-					mockUserService.EXPECT().UserSignUp(&test.requestBody).Return(nil).Times(1)
+					mockUserService.EXPECT().UserSignUp(&user, test.requestBody.ReferralCode).Return(nil).Times(1)
 				}
 			}
 
@@ -112,14 +116,14 @@ func TestLogin(t *testing.T) {
 	router.POST("user-login", UserController.UserLogin)
 	tests := []struct {
 		name               string
-		requestBody        models.UserLogin
+		requestBody        dto.LoginRequest
 		expectedStatusCode int
 		mockError          error
 		validateResponse   func(t *testing.T, response map[string]interface{})
 	}{
 		{
 			name: "successful login",
-			requestBody: models.UserLogin{
+			requestBody: dto.LoginRequest{
 				Email:    "test@example.com",
 				Password: "password",
 			},
@@ -131,11 +135,13 @@ func TestLogin(t *testing.T) {
 				user, ok := response["user"].(map[string]interface{})
 				assert.True(t, ok)
 				assert.NotNil(t, user)
+				assert.NotContains(t, user, "password")
+				assert.NotContains(t, user, "DeletedAt")
 			},
 		},
 		{
 			name: "wrong password",
-			requestBody: models.UserLogin{
+			requestBody: dto.LoginRequest{
 				Email:    "test@example.com",
 				Password: "WrongPass@123",
 			},
@@ -238,3 +244,248 @@ func TestGetProfile(t *testing.T) {
 		})
 	}
 }
+func TestMagicLinkLogin(t *testing.T) {
+	router := gin.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockIUserService(ctrl)
+	UserController := &UserController{UserService: mockUserService}
+	router.GET("login/magic", UserController.MagicLinkLogin)
+	tests := []struct {
+		name               string
+		token              string
+		mockUser           *models.User
+		mockError          error
+		expectedStatusCode int
+		validateResponse   func(t *testing.T, response map[string]interface{})
+	}{
+		{
+			name:               "successful login",
+			token:              "valid-token",
+			mockUser:           &models.User{Email: "test@example.com", Status: models.StatusActive},
+			expectedStatusCode: http.StatusOK,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, models.LoginSuccesful, response["message"])
+				assert.NotEmpty(t, response["token"])
+				assert.NotEmpty(t, response["refresh_token"])
+			},
+		},
+		{
+			name:               "missing token",
+			token:              "",
+			expectedStatusCode: http.StatusBadRequest,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, "token is required", response["error"])
+			},
+		},
+		{
+			name:               "used or expired link",
+			token:              "used-token",
+			mockError:          errors.New(models.InvalidMagicLink),
+			expectedStatusCode: http.StatusUnauthorized,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, models.InvalidMagicLink, response["error"])
+			},
+		},
+		{
+			name:               "blocked user",
+			token:              "blocked-token",
+			mockError:          errors.New(models.UserBlocked),
+			expectedStatusCode: http.StatusForbidden,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, models.UserBlocked, response["message"])
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.token != "" {
+				mockUserService.EXPECT().MagicLinkLogin(test.token).Return(test.mockUser, test.mockError)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/login/magic?token="+test.token, nil)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedStatusCode, resp.Code)
+
+			var response map[string]interface{}
+			err := json.NewDecoder(resp.Body).Decode(&response)
+			assert.NoError(t, err)
+
+			test.validateResponse(t, response)
+		})
+	}
+}
+func TestRefreshToken(t *testing.T) {
+	router := gin.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockIUserService(ctrl)
+	UserController := &UserController{UserService: mockUserService}
+	router.POST("token/refresh", UserController.RefreshToken)
+	tests := []struct {
+		name               string
+		refreshToken       string
+		mockUser           *models.User
+		mockError          error
+		expectedStatusCode int
+		validateResponse   func(t *testing.T, response map[string]interface{})
+	}{
+		{
+			name:               "new tokens",
+			refreshToken:       "valid-token",
+			mockUser:           &models.User{Email: "test@example.com", Status: models.StatusActive},
+			expectedStatusCode: http.StatusOK,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.NotEmpty(t, response["token"])
+				assert.NotEmpty(t, response["refresh_token"])
+			},
+		},
+		{
+			name:               "missing token",
+			expectedStatusCode: http.StatusBadRequest,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, false, response["status"])
+			},
+		},
+		{
+			name:               "access token or expired token",
+			refreshToken:       "access-token",
+			mockError:          errors.New(models.InvalidRefreshToken),
+			expectedStatusCode: http.StatusUnauthorized,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, models.InvalidRefreshToken, response["error"])
+			},
+		},
+		{
+			name:               "blocked user",
+			refreshToken:       "blocked-token",
+			mockError:          errors.New(models.UserBlocked),
+			expectedStatusCode: http.StatusForbidden,
+			validateResponse: func(t *testing.T, response map[string]interface{}) {
+				assert.Equal(t, models.UserBlocked, response["message"])
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.refreshToken != "" {
+				mockUserService.EXPECT().RefreshToken(test.refreshToken).Return(test.mockUser, test.mockError)
+			}
+			body, _ := json.Marshal(dto.RefreshTokenRequest{RefreshToken: test.refreshToken})
+			req := httptest.NewRequest(http.MethodPost, "/token/refresh", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedStatusCode, resp.Code)
+
+			var response map[string]interface{}
+			err := json.NewDecoder(resp.Body).Decode(&response)
+			assert.NoError(t, err)
+
+			test.validateResponse(t, response)
+		})
+	}
+}
+func TestPatchProfile(t *testing.T) {
+	router := gin.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserService := mocks.NewMockIUserService(ctrl)
+	UserController := &UserController{UserService: mockUserService}
+	router.Use(func(c *gin.Context) {
+		c.Set("ID", float64(7))
+		c.Next()
+	})
+	router.PATCH("/user/profile", UserController.PatchProfile)
+
+	current := &models.User{FirstName: "John", LastName: "Doe", Phone: "1234567890", Version: 3}
+	current.ID = 7
+	tests := []struct {
+		name               string
+		ifMatch            string
+		body               string
+		expectGetProfile   bool
+		expectPatch        bool
+		patchError         error
+		expectedStatusCode int
+	}{
+		{
+			name:               "missing If-Match",
+			body:               `{"first_name":"Jane"}`,
+			expectedStatusCode: http.StatusPreconditionRequired,
+		},
+		{
+			name:               "stale ETag",
+			ifMatch:            `"7-2"`,
+			body:               `{"first_name":"Jane"}`,
+			expectGetProfile:   true,
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "null clears a required field",
+			ifMatch:            `"7-3"`,
+			body:               `{"last_name":null}`,
+			expectGetProfile:   true,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown field",
+			ifMatch:            `"7-3"`,
+			body:               `{"email":"new@example.com"}`,
+			expectGetProfile:   true,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "concurrent update",
+			ifMatch:            `"7-3"`,
+			body:               `{"first_name":"Jane"}`,
+			expectGetProfile:   true,
+			expectPatch:        true,
+			patchError:         errors.New(models.VersionConflict),
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "successful patch",
+			ifMatch:            `"7-3"`,
+			body:               `{"first_name":"Jane"}`,
+			expectGetProfile:   true,
+			expectPatch:        true,
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.expectGetProfile {
+				mockUserService.EXPECT().GetProfile("7").Return(current, nil)
+			}
+			if test.expectPatch {
+				update := dto.UpdateProfileRequest{FirstName: "Jane", LastName: "Doe", Phone: "1234567890"}
+				updated := *current
+				updated.FirstName = "Jane"
+				updated.Version = 4
+				if test.patchError != nil {
+					mockUserService.EXPECT().PatchProfile(uint(7), uint(3), update, []string{"first_name"}).Return(nil, test.patchError)
+				} else {
+					mockUserService.EXPECT().PatchProfile(uint(7), uint(3), update, []string{"first_name"}).Return(&updated, nil)
+				}
+			}
+			req := httptest.NewRequest(http.MethodPatch, "/user/profile", bytes.NewReader([]byte(test.body)))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, test.expectedStatusCode, resp.Code)
+			if test.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, `"7-4"`, resp.Header().Get("ETag"))
+			}
+		})
+	}
+}
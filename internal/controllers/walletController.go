@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletController struct {
+	WalletService services.IWalletService
+}
+
+func NewWalletController(WalletService services.IWalletService) *WalletController {
+	return &WalletController{WalletService: WalletService}
+}
+
+func (c *WalletController) GetWallet(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	page, limit, ok := parsePage(ctx)
+	if !ok {
+		return
+	}
+	wallet, transactions, total, err := c.WalletService.GetWallet(userID, page, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch wallet"})
+		return
+	}
+	ctx.JSON(http.StatusOK, dto.ToWalletResponse(wallet, transactions, dto.NewPagination(page, limit, total)))
+}
+
+// Audit reports wallets and ledger transactions that do not reconcile. An
+// empty list means every balance matches its ledger.
+func (c *WalletController) Audit(ctx *gin.Context) {
+	issues, err := c.WalletService.Audit()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to audit wallets"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"balanced": len(issues) == 0, "issues": issues})
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WishlistController struct {
+	WishlistService services.IWishlistService
+}
+
+func NewWishlistController(WishlistService services.IWishlistService) *WishlistController {
+	return &WishlistController{WishlistService: WishlistService}
+}
+
+func (c *WishlistController) GetWishlist(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	items, products, err := c.WishlistService.GetWishlist(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch wishlist"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"items": dto.ToWishlistResponse(items, products)})
+}
+func (c *WishlistController) AddItem(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	var request dto.WishlistRequest
+	if !bindRequest(ctx, &request) {
+		return
+	}
+	if err := c.WishlistService.AddItem(userID, request); err != nil {
+		wishlistError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"message": "saved to wishlist"})
+}
+func (c *WishlistController) RemoveItem(ctx *gin.Context) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	variantID, ok := idParam(ctx, "variantId")
+	if !ok {
+		return
+	}
+	if err := c.WishlistService.RemoveItem(userID, variantID); err != nil {
+		wishlistError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "removed from wishlist"})
+}
+func wishlistError(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case models.WishlistItemNotFound, models.VariantNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case models.ProductUnavailable, models.WishlistLimitReached:
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
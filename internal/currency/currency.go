@@ -0,0 +1,202 @@
+// Package currency converts amounts from the base currency, which prices are
+// stored and orders charged in, to other currencies for display. Amounts are
+// integer minor units throughout. It has no database access: the caller
+// supplies the exchange rates.
+package currency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+// Base is the currency prices are stored in.
+const Base = models.DefaultCurrency
+
+// RateScale is the fixed point scale of exchange rates: a rate is the value
+// of one unit of the base currency in another currency, times RateScale.
+const RateScale = 1_000_000
+
+// rateDecimals is the number of decimals of RateScale.
+const rateDecimals = 6
+
+// exponents lists the supported currencies with the number of decimals of
+// their minor unit.
+var exponents = map[string]int{
+	"INR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"AED": 2,
+	"SAR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"CAD": 2,
+	"NZD": 2,
+	"CHF": 2,
+	"HKD": 2,
+	"LKR": 2,
+	"NPR": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"BHD": 3,
+	"OMR": 3,
+}
+
+// Known reports whether code is a supported currency.
+func Known(code string) bool {
+	_, ok := exponents[code]
+	return ok
+}
+
+// Exponent returns the number of decimals of the currency's minor unit.
+// Unknown currencies have two.
+func Exponent(code string) int {
+	if exponent, ok := exponents[code]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// Money is an amount in minor units of Currency. Approximate marks amounts
+// shown in a currency other than the base currency: they are for display
+// only, as orders are charged in the base currency.
+type Money struct {
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Approximate bool   `json:"approximate,omitempty"`
+}
+
+// String renders the amount with the decimals of its currency, e.g.
+// "USD 12.99".
+func (m Money) String() string {
+	return m.Currency + " " + FormatAmount(m.Amount, m.Currency)
+}
+
+// FormatAmount renders an amount in minor units of code as a decimal string,
+// e.g. 1299 as "12.99" in USD and as "1299" in JPY.
+func FormatAmount(amount int64, code string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	exponent := Exponent(code)
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	unit := pow10(exponent)
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/unit, exponent, amount%unit)
+}
+
+// ParseRate parses a decimal exchange rate such as "0.0119" into fixed point.
+// At most six decimals are allowed and the rate must be positive.
+func ParseRate(value string) (int64, error) {
+	invalid := errors.New(models.InvalidExchangeRate)
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+	if (whole == "" && fraction == "") || len(fraction) > rateDecimals || len(whole) > 12 {
+		return 0, invalid
+	}
+	var rate int64
+	for _, digit := range whole + fraction + strings.Repeat("0", rateDecimals-len(fraction)) {
+		if digit < '0' || digit > '9' {
+			return 0, invalid
+		}
+		rate = rate*10 + int64(digit-'0')
+	}
+	if rate == 0 {
+		return 0, invalid
+	}
+	return rate, nil
+}
+
+// FormatRate renders a fixed point rate as a decimal string without trailing
+// zeros, e.g. 11900 as "0.0119".
+func FormatRate(rate int64) string {
+	formatted := fmt.Sprintf("%d.%0*d", rate/RateScale, rateDecimals, rate%RateScale)
+	return strings.TrimSuffix(strings.TrimRight(formatted, "0"), ".")
+}
+
+// RatesFromFile reads exchange rates from a JSON file mapping currency codes
+// to decimal rates, e.g. {"USD": "0.0119", "EUR": "0.011"}.
+func RatesFromFile(path string) (map[string]int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	rates := make(map[string]int64, len(values))
+	for code, value := range values {
+		code = strings.ToUpper(code)
+		if !Known(code) || code == Base {
+			return nil, fmt.Errorf("%s: %s", models.UnsupportedCurrency, code)
+		}
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", code, err)
+		}
+		rates[code] = rate
+	}
+	return rates, nil
+}
+
+// RatesFromEnv reads the rates file named by EXCHANGE_RATES_FILE. Without one,
+// or when it cannot be read, there are no rates until they are set through
+// the admin API.
+func RatesFromEnv() map[string]int64 {
+	path := os.Getenv("EXCHANGE_RATES_FILE")
+	if path == "" {
+		return map[string]int64{}
+	}
+	rates, err := RatesFromFile(path)
+	if err != nil {
+		fmt.Println("failed to load exchange rates from", path, err)
+		return map[string]int64{}
+	}
+	return rates
+}
+
+// Display converts amounts in the base currency to Currency at Rate.
+type Display struct {
+	Currency string
+	Rate     int64
+}
+
+// BaseDisplay shows amounts in the base currency unchanged.
+var BaseDisplay = Display{Currency: Base, Rate: RateScale}
+
+// Convert returns amount, in minor units of the base currency, in the display
+// currency, rounded half away from zero to its minor unit. It fails with
+// AmountOutOfRange when the result does not fit in an int64.
+func (d Display) Convert(amount int64) (Money, error) {
+	if d.Currency == Base {
+		return Money{Amount: amount, Currency: Base}, nil
+	}
+	numerator := new(big.Int).Mul(big.NewInt(amount), big.NewInt(d.Rate))
+	numerator.Mul(numerator, big.NewInt(pow10(Exponent(d.Currency))))
+	denominator := big.NewInt(RateScale * pow10(Exponent(Base)))
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(denominator) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(numerator.Sign())))
+	}
+	if !quotient.IsInt64() {
+		return Money{}, errors.New(models.AmountOutOfRange)
+	}
+	return Money{Amount: quotient.Int64(), Currency: d.Currency, Approximate: true}, nil
+}
+func pow10(exponent int) int64 {
+	result := int64(1)
+	for range exponent {
+		result *= 10
+	}
+	return result
+}
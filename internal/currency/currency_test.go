@@ -0,0 +1,107 @@
+package currency
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		ok    bool
+	}{
+		{"0.012", 12000, true},
+		{"1", 1000000, true},
+		{"1.78", 1780000, true},
+		{".5", 500000, true},
+		{"0.000001", 1, true},
+		{"0.0000001", 0, false},
+		{"0", 0, false},
+		{"", 0, false},
+		{".", 0, false},
+		{"-1", 0, false},
+		{"1e3", 0, false},
+		{"1.2.3", 0, false},
+	}
+	for _, test := range tests {
+		rate, err := ParseRate(test.value)
+		if !test.ok {
+			assert.Error(t, err, test.value)
+			continue
+		}
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.want, rate, test.value)
+		assert.Equal(t, test.want, mustParseRate(t, FormatRate(rate)), test.value)
+	}
+}
+func mustParseRate(t *testing.T, value string) int64 {
+	rate, err := ParseRate(value)
+	assert.NoError(t, err, value)
+	return rate
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name    string
+		display Display
+		amount  int64
+		want    Money
+	}{
+		{"base", BaseDisplay, 123456, Money{123456, "INR", false}},
+		{"two decimals", Display{"USD", 12000}, 100000, Money{1200, "USD", true}},
+		{"no decimals", Display{"JPY", 1780000}, 100000, Money{1780, "JPY", true}},
+		{"three decimals", Display{"KWD", 3700}, 100000, Money{3700, "KWD", true}},
+		{"rounds down below half", Display{"USD", 12000}, 1, Money{0, "USD", true}},
+		{"rounds half up", Display{"USD", 10000}, 50, Money{1, "USD", true}},
+		{"rounds negative half away from zero", Display{"USD", 10000}, -50, Money{-1, "USD", true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			money, err := test.display.Convert(test.amount)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, money)
+		})
+	}
+}
+
+func TestConvertOverflow(t *testing.T) {
+	_, err := Display{"JPY", 200_000000}.Convert(math.MaxInt64)
+	assert.EqualError(t, err, models.AmountOutOfRange)
+	_, err = Display{"JPY", 200_000000}.Convert(math.MinInt64)
+	assert.EqualError(t, err, models.AmountOutOfRange)
+	// a rate below one keeps the largest amounts in range
+	_, err = Display{"USD", 12000}.Convert(math.MaxInt64)
+	assert.NoError(t, err)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "USD 12.99", Money{Amount: 1299, Currency: "USD"}.String())
+	assert.Equal(t, "JPY 1299", Money{Amount: 1299, Currency: "JPY"}.String())
+	assert.Equal(t, "KWD 1.299", Money{Amount: 1299, Currency: "KWD"}.String())
+	assert.Equal(t, "INR -0.05", Money{Amount: -5, Currency: "INR"}.String())
+}
+
+func TestRatesFromFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "rates.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	rates, err := RatesFromFile(write(`{"usd": "0.012", "JPY": "1.78"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"USD": 12000, "JPY": 1780000}, rates)
+
+	_, err = RatesFromFile(write(`{"XYZ": "1"}`))
+	assert.Error(t, err)
+	_, err = RatesFromFile(write(`{"INR": "1"}`))
+	assert.Error(t, err)
+	_, err = RatesFromFile(write(`{"USD": "abc"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type AddressRequest struct {
+	Name              string `json:"name" validate:"required,max=100"`
+	Phone             string `json:"phone" validate:"required,numeric,min=7,max=15"`
+	Line1             string `json:"line1" validate:"required,max=200"`
+	Line2             string `json:"line2" validate:"max=200"`
+	City              string `json:"city" validate:"required,max=100"`
+	State             string `json:"state" validate:"required,max=100"`
+	PostalCode        string `json:"postal_code" validate:"required,max=12"`
+	Country           string `json:"country" validate:"required,len=2,alpha"`
+	IsDefaultShipping bool   `json:"is_default_shipping"`
+	IsDefaultBilling  bool   `json:"is_default_billing"`
+}
+
+// ToAddress maps the request onto address, normalising the country code and postal code.
+func (r AddressRequest) ToAddress(address *models.Address) {
+	address.Name = r.Name
+	address.Phone = r.Phone
+	address.Line1 = r.Line1
+	address.Line2 = r.Line2
+	address.City = r.City
+	address.State = r.State
+	address.PostalCode = strings.ToUpper(strings.TrimSpace(r.PostalCode))
+	address.Country = strings.ToUpper(r.Country)
+	address.IsDefaultShipping = r.IsDefaultShipping
+	address.IsDefaultBilling = r.IsDefaultBilling
+}
+
+type AddressResponse struct {
+	ID                uint      `json:"id"`
+	Name              string    `json:"name"`
+	Phone             string    `json:"phone"`
+	Line1             string    `json:"line1"`
+	Line2             string    `json:"line2,omitempty"`
+	City              string    `json:"city"`
+	State             string    `json:"state"`
+	PostalCode        string    `json:"postal_code"`
+	Country           string    `json:"country"`
+	IsDefaultShipping bool      `json:"is_default_shipping"`
+	IsDefaultBilling  bool      `json:"is_default_billing"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func ToAddressResponse(address *models.Address) AddressResponse {
+	return AddressResponse{
+		ID:                address.ID,
+		Name:              address.Name,
+		Phone:             address.Phone,
+		Line1:             address.Line1,
+		Line2:             address.Line2,
+		City:              address.City,
+		State:             address.State,
+		PostalCode:        address.PostalCode,
+		Country:           address.Country,
+		IsDefaultShipping: address.IsDefaultShipping,
+		IsDefaultBilling:  address.IsDefaultBilling,
+		CreatedAt:         address.CreatedAt,
+		UpdatedAt:         address.UpdatedAt,
+	}
+}
+
+func ToAddressResponses(addresses []models.Address) []AddressResponse {
+	responses := make([]AddressResponse, 0, len(addresses))
+	for i := range addresses {
+		responses = append(responses, ToAddressResponse(&addresses[i]))
+	}
+	return responses
+}
@@ -0,0 +1,68 @@
+package dto
+
+import "github.com/Ansalps/UserEcommerceClean/internal/models"
+
+type CartItemRequest struct {
+	VariantID uint `json:"variant_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"required,min=1"`
+}
+type CartQuantityRequest struct {
+	Quantity int `json:"quantity" validate:"required,min=1"`
+}
+
+type CartLineResponse struct {
+	VariantID   uint     `json:"variant_id"`
+	ProductID   uint     `json:"product_id"`
+	ProductName string   `json:"product_name"`
+	SKU         string   `json:"sku"`
+	Size        string   `json:"size,omitempty"`
+	Colour      string   `json:"colour,omitempty"`
+	Quantity    int      `json:"quantity"`
+	UnitPrice   int64    `json:"unit_price"`
+	LineTotal   int64    `json:"line_total"`
+	Available   bool     `json:"available"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// CartResponse totals only the lines that can currently be bought.
+type CartResponse struct {
+	Items       []CartLineResponse `json:"items"`
+	ItemCount   int                `json:"item_count"`
+	Subtotal    int64              `json:"subtotal"`
+	HasWarnings bool               `json:"has_warnings"`
+	// coupon fields are only set for logged-in users
+	Coupons      []AppliedCouponResponse `json:"coupons,omitempty"`
+	Discount     int64                   `json:"discount"`
+	FreeShipping bool                    `json:"free_shipping"`
+	Total        int64                   `json:"total"`
+}
+
+func ToCartResponse(lines []models.CartLine) CartResponse {
+	response := CartResponse{Items: make([]CartLineResponse, 0, len(lines))}
+	for _, line := range lines {
+		variant := line.Item.Variant
+		item := CartLineResponse{
+			VariantID:   line.Item.VariantID,
+			ProductID:   variant.ProductID,
+			ProductName: line.Product.Name,
+			SKU:         variant.SKU,
+			Size:        variant.Size,
+			Colour:      variant.Colour,
+			Quantity:    line.Item.Quantity,
+			UnitPrice:   variant.Price,
+			LineTotal:   variant.Price * int64(line.Item.Quantity),
+			Available:   line.Available,
+			Warnings:    line.Warnings,
+		}
+		if line.Available {
+			response.ItemCount += item.Quantity
+			response.Subtotal += item.LineTotal
+		}
+		if len(line.Warnings) > 0 {
+			response.HasWarnings = true
+		}
+		response.Items = append(response.Items, item)
+	}
+	response.Total = response.Subtotal
+	return response
+}
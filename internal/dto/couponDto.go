@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/promotion"
+)
+
+type CouponRequest struct {
+	Code          string     `json:"code" validate:"required,max=32"`
+	Description   string     `json:"description" validate:"max=500"`
+	Type          string     `json:"type" validate:"required,oneof=percentage flat free_shipping buy_x_get_y"`
+	Value         int64      `json:"value" validate:"min=0"`
+	MaxDiscount   int64      `json:"max_discount" validate:"min=0"`
+	BuyQuantity   int        `json:"buy_quantity" validate:"min=0"`
+	GetQuantity   int        `json:"get_quantity" validate:"min=0"`
+	MinOrderValue int64      `json:"min_order_value" validate:"min=0"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	UsageLimit    int        `json:"usage_limit" validate:"min=0"`
+	PerUserLimit  int        `json:"per_user_limit" validate:"min=0"`
+	Stackable     bool       `json:"stackable"`
+	// defaults to true
+	IsActive    *bool  `json:"is_active"`
+	ProductIDs  []uint `json:"product_ids" validate:"max=500"`
+	CategoryIDs []uint `json:"category_ids" validate:"max=100"`
+}
+
+// ToCoupon maps the request onto a new coupon. Codes are case insensitive and
+// stored upper case.
+func (r CouponRequest) ToCoupon() models.Coupon {
+	return models.Coupon{
+		Code:          strings.ToUpper(strings.TrimSpace(r.Code)),
+		Description:   r.Description,
+		Type:          r.Type,
+		Value:         r.Value,
+		MaxDiscount:   r.MaxDiscount,
+		BuyQuantity:   r.BuyQuantity,
+		GetQuantity:   r.GetQuantity,
+		MinOrderValue: r.MinOrderValue,
+		StartsAt:      r.StartsAt,
+		EndsAt:        r.EndsAt,
+		UsageLimit:    r.UsageLimit,
+		PerUserLimit:  r.PerUserLimit,
+		Stackable:     r.Stackable,
+		IsActive:      r.IsActive == nil || *r.IsActive,
+		ProductIDs:    r.ProductIDs,
+		CategoryIDs:   r.CategoryIDs,
+	}
+}
+
+type ApplyCouponRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+// AppliedCouponResponse is a coupon applied to the cart. Problem explains why
+// it currently gives no discount; such coupons block checkout until removed.
+type AppliedCouponResponse struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type"`
+	Discount    int64  `json:"discount"`
+	Problem     string `json:"problem,omitempty"`
+}
+
+// WithPricing adds the applied coupons and the discounted total to the cart.
+func (r CartResponse) WithPricing(pricing *promotion.CartPricing) CartResponse {
+	r.Coupons = make([]AppliedCouponResponse, 0, len(pricing.Coupons))
+	for _, applied := range pricing.Coupons {
+		r.Coupons = append(r.Coupons, AppliedCouponResponse{
+			Code:        applied.Coupon.Code,
+			Description: applied.Coupon.Description,
+			Type:        applied.Coupon.Type,
+			Discount:    applied.Discount,
+			Problem:     applied.Problem,
+		})
+	}
+	r.Discount = pricing.Result.Discount
+	r.FreeShipping = pricing.Result.FreeShipping
+	r.Total = r.Subtotal - r.Discount
+	return r
+}
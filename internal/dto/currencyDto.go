@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"sort"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+)
+
+type ExchangeRateRequest struct {
+	// the value of one unit of the base currency, e.g. "0.012"
+	Rate string `json:"rate" validate:"required,max=20"`
+}
+
+type CurrencyResponse struct {
+	Code string `json:"code"`
+	// decimals of the minor unit
+	Exponent int    `json:"exponent"`
+	Rate     string `json:"rate"`
+}
+
+// CurrenciesResponse lists the currencies prices can be displayed in, with
+// their rates against the base currency.
+type CurrenciesResponse struct {
+	Base       string             `json:"base"`
+	Currencies []CurrencyResponse `json:"currencies"`
+}
+
+func ToCurrenciesResponse(rates map[string]int64) CurrenciesResponse {
+	response := CurrenciesResponse{
+		Base: currency.Base,
+		Currencies: []CurrencyResponse{{
+			Code:     currency.Base,
+			Exponent: currency.Exponent(currency.Base),
+			Rate:     currency.FormatRate(currency.RateScale),
+		}},
+	}
+	codes := make([]string, 0, len(rates))
+	for code := range rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		response.Currencies = append(response.Currencies, CurrencyResponse{
+			Code:     code,
+			Exponent: currency.Exponent(code),
+			Rate:     currency.FormatRate(rates[code]),
+		})
+	}
+	return response
+}
@@ -0,0 +1,61 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type GiftCardPurchaseRequest struct {
+	Amount         int64  `json:"amount" validate:"required,min=1"`
+	RecipientName  string `json:"recipient_name" validate:"required,max=100"`
+	RecipientEmail string `json:"recipient_email" validate:"required,email"`
+	Message        string `json:"message" validate:"max=500"`
+}
+
+type GiftCardIssueRequest struct {
+	Amount int64 `json:"amount" validate:"required,min=1"`
+	// defaults to a year from now
+	ExpiresAt     *time.Time `json:"expires_at"`
+	RecipientName string     `json:"recipient_name" validate:"max=100"`
+	// the code is emailed here when set
+	RecipientEmail string `json:"recipient_email" validate:"omitempty,email"`
+	Message        string `json:"message" validate:"max=500"`
+}
+
+type GiftCardStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+// GiftCardPurchaseResponse is the gateway payment for a gift card purchase
+// and the card it activates.
+type GiftCardPurchaseResponse struct {
+	Payment  PaymentResponse  `json:"payment"`
+	GiftCard *models.GiftCard `json:"gift_card"`
+}
+
+// IssuedGiftCardResponse carries the code of a newly issued gift card. The
+// code is not stored and cannot be shown again.
+type IssuedGiftCardResponse struct {
+	Code     string           `json:"code"`
+	GiftCard *models.GiftCard `json:"gift_card"`
+}
+
+// GiftCardBalanceResponse is what a code lookup reveals about a card.
+type GiftCardBalanceResponse struct {
+	CodeHint  string     `json:"code_hint"`
+	Balance   int64      `json:"balance"`
+	Currency  string     `json:"currency"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Usable    bool       `json:"usable"`
+}
+
+func ToGiftCardBalanceResponse(card *models.GiftCard) GiftCardBalanceResponse {
+	return GiftCardBalanceResponse{
+		CodeHint:  card.CodeHint,
+		Balance:   card.Balance,
+		Currency:  card.Currency,
+		ExpiresAt: card.ExpiresAt,
+		Usable:    card.Usable(time.Now()),
+	}
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type LoyaltyRuleRequest struct {
+	// zero for the default rule
+	CategoryID uint `json:"category_id"`
+	// points per 100 rupees spent
+	Rate *int64 `json:"rate" validate:"required,min=0,max=1000"`
+}
+
+type LoyaltyTransactionResponse struct {
+	ID           uint       `json:"id"`
+	Type         string     `json:"type"`
+	Points       int64      `json:"points"`
+	BalanceAfter int64      `json:"balance_after"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	OrderID      *uint      `json:"order_id,omitempty"`
+	Reference    string     `json:"reference"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// LoyaltyResponse is the points balance, its value when redeemed and the
+// points ledger. ExpiringPoints expire within models.LoyaltyExpiryNotice.
+type LoyaltyResponse struct {
+	Points         int64                        `json:"points"`
+	Value          int64                        `json:"value"`
+	PointValue     int64                        `json:"point_value"`
+	ExpiringPoints int64                        `json:"expiring_points"`
+	Transactions   []LoyaltyTransactionResponse `json:"transactions"`
+	Pagination     Pagination                   `json:"pagination"`
+}
+
+func ToLoyaltyResponse(account *models.LoyaltyAccount, expiring int64, transactions []models.LoyaltyTransaction, pagination Pagination) LoyaltyResponse {
+	response := LoyaltyResponse{
+		Points:         account.Balance,
+		Value:          account.Balance * models.LoyaltyPointValue,
+		PointValue:     models.LoyaltyPointValue,
+		ExpiringPoints: expiring,
+		Transactions:   make([]LoyaltyTransactionResponse, 0, len(transactions)),
+		Pagination:     pagination,
+	}
+	for _, transaction := range transactions {
+		response.Transactions = append(response.Transactions, LoyaltyTransactionResponse{
+			ID:           transaction.ID,
+			Type:         transaction.Type,
+			Points:       transaction.Points,
+			BalanceAfter: transaction.BalanceAfter,
+			ExpiresAt:    transaction.ExpiresAt,
+			OrderID:      transaction.OrderID,
+			Reference:    transaction.Reference,
+			CreatedAt:    transaction.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,148 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type CheckoutRequest struct {
+	ShippingAddressID uint `json:"shipping_address_id" validate:"required"`
+	// defaults to the shipping address
+	BillingAddressID uint `json:"billing_address_id"`
+	// defaults to the cheapest method available for the address
+	ShippingMethodID uint `json:"shipping_method_id"`
+}
+
+// OrderStatusRequest is an admin status change. Returns go through return
+// requests, which mark the order returned once every item is refunded.
+type OrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=paid confirmed shipped delivered cancelled"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+	// defaults to original
+	RefundMethod string `json:"refund_method" validate:"omitempty,oneof=original wallet"`
+}
+
+type OrderItemResponse struct {
+	ProductID   uint   `json:"product_id"`
+	VariantID   uint   `json:"variant_id"`
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku"`
+	Size        string `json:"size,omitempty"`
+	Colour      string `json:"colour,omitempty"`
+	UnitPrice   int64  `json:"unit_price"`
+	Quantity    int    `json:"quantity"`
+	Discount    int64  `json:"discount"`
+	TaxRate     int    `json:"tax_rate"`
+	CGST        int64  `json:"cgst,omitempty"`
+	SGST        int64  `json:"sgst,omitempty"`
+	IGST        int64  `json:"igst,omitempty"`
+	Tax         int64  `json:"tax"`
+	Total       int64  `json:"total"`
+}
+
+type OrderResponse struct {
+	ID              uint                `json:"id"`
+	OrderNumber     string              `json:"order_number"`
+	Status          string              `json:"status"`
+	ShippingAddress models.OrderAddress `json:"shipping_address"`
+	BillingAddress  models.OrderAddress `json:"billing_address"`
+	Items           []OrderItemResponse `json:"items"`
+	CouponCodes     []string            `json:"coupon_codes,omitempty"`
+	ShippingMethod  string              `json:"shipping_method,omitempty"`
+	Subtotal        int64               `json:"subtotal"`
+	DiscountTotal   int64               `json:"discount_total"`
+	TaxTotal        int64               `json:"tax_total"`
+	TaxInclusive    bool                `json:"tax_inclusive"`
+	ShippingTotal   int64               `json:"shipping_total"`
+	Total           int64               `json:"total"`
+	CreatedAt       time.Time           `json:"created_at"`
+	ExpiresAt       *time.Time          `json:"expires_at,omitempty"`
+	PaidAt          *time.Time          `json:"paid_at,omitempty"`
+	ConfirmedAt     *time.Time          `json:"confirmed_at,omitempty"`
+	ShippedAt       *time.Time          `json:"shipped_at,omitempty"`
+	DeliveredAt     *time.Time          `json:"delivered_at,omitempty"`
+	CancelledAt     *time.Time          `json:"cancelled_at,omitempty"`
+	ReturnedAt      *time.Time          `json:"returned_at,omitempty"`
+}
+
+// OrderSummaryResponse is the list view of an order.
+type OrderSummaryResponse struct {
+	ID          uint      `json:"id"`
+	OrderNumber string    `json:"order_number"`
+	Status      string    `json:"status"`
+	ItemCount   int       `json:"item_count"`
+	Total       int64     `json:"total"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToOrderResponse(order *models.Order) OrderResponse {
+	response := OrderResponse{
+		ID:              order.ID,
+		OrderNumber:     order.OrderNumber,
+		Status:          order.Status,
+		ShippingAddress: order.ShippingAddress,
+		BillingAddress:  order.BillingAddress,
+		Items:           make([]OrderItemResponse, 0, len(order.Items)),
+		CouponCodes:     order.CouponCodes,
+		ShippingMethod:  order.ShippingMethod,
+		Subtotal:        order.Subtotal,
+		DiscountTotal:   order.DiscountTotal,
+		TaxTotal:        order.TaxTotal,
+		TaxInclusive:    order.TaxInclusive,
+		ShippingTotal:   order.ShippingTotal,
+		Total:           order.Total,
+		CreatedAt:       order.CreatedAt,
+		PaidAt:          order.PaidAt,
+		ConfirmedAt:     order.ConfirmedAt,
+		ShippedAt:       order.ShippedAt,
+		DeliveredAt:     order.DeliveredAt,
+		CancelledAt:     order.CancelledAt,
+		ReturnedAt:      order.ReturnedAt,
+	}
+	if order.Status == models.OrderPending {
+		// only meaningful while the order is awaiting payment
+		response.ExpiresAt = &order.ExpiresAt
+	}
+	for _, item := range order.Items {
+		response.Items = append(response.Items, OrderItemResponse{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			ProductName: item.ProductName,
+			SKU:         item.SKU,
+			Size:        item.Size,
+			Colour:      item.Colour,
+			UnitPrice:   item.UnitPrice,
+			Quantity:    item.Quantity,
+			Discount:    item.Discount,
+			TaxRate:     item.TaxRate,
+			CGST:        item.CGST,
+			SGST:        item.SGST,
+			IGST:        item.IGST,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		})
+	}
+	return response
+}
+func ToOrderSummaryResponses(orders []models.Order) []OrderSummaryResponse {
+	responses := make([]OrderSummaryResponse, 0, len(orders))
+	for _, order := range orders {
+		count := 0
+		for _, item := range order.Items {
+			count += item.Quantity
+		}
+		responses = append(responses, OrderSummaryResponse{
+			ID:          order.ID,
+			OrderNumber: order.OrderNumber,
+			Status:      order.Status,
+			ItemCount:   count,
+			Total:       order.Total,
+			CreatedAt:   order.CreatedAt,
+		})
+	}
+	return responses
+}
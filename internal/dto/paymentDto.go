@@ -0,0 +1,50 @@
+package dto
+
+import "github.com/Ansalps/UserEcommerceClean/internal/models"
+
+// PaymentResponse carries what the client needs to complete the payment with
+// the provider's checkout.
+type PaymentResponse struct {
+	ID           uint   `json:"id"`
+	OrderID      uint   `json:"order_id"`
+	Provider     string `json:"provider"`
+	ProviderRef  string `json:"provider_ref"`
+	ClientSecret string `json:"client_secret"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+}
+
+func ToPaymentResponse(payment *models.Payment) PaymentResponse {
+	return PaymentResponse{
+		ID:           payment.ID,
+		OrderID:      payment.OrderID,
+		Provider:     payment.Provider,
+		ProviderRef:  payment.ProviderRef,
+		ClientSecret: payment.ClientSecret,
+		Amount:       payment.Amount,
+		Currency:     payment.Currency,
+		Status:       payment.Status,
+	}
+}
+
+type PaymentRequest struct {
+	// defaults to gateway
+	Method string `json:"method" validate:"omitempty,oneof=gateway wallet cod gift_card"`
+	// part of the total to pay from the wallet, gateway payments only
+	WalletAmount int64 `json:"wallet_amount" validate:"min=0"`
+	// the gift card for gift_card payments, or the one GiftCardAmount of a
+	// gateway payment is redeemed from
+	GiftCardCode   string `json:"gift_card_code" validate:"max=32"`
+	GiftCardAmount int64  `json:"gift_card_amount" validate:"min=0"`
+	// points to redeem towards the total, gateway payments only
+	LoyaltyPoints int64 `json:"loyalty_points" validate:"min=0"`
+}
+
+type TopUpRequest struct {
+	Amount int64 `json:"amount" validate:"required,min=1"`
+}
+
+type CODPincodesRequest struct {
+	PostalCodes []string `json:"postal_codes" validate:"required,min=1,dive,required,max=10"`
+}
@@ -0,0 +1,304 @@
+package dto
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type CategoryRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Slug        string `json:"slug" validate:"max=120"`
+	Description string `json:"description" validate:"max=1000"`
+	ParentID    *uint  `json:"parent_id"`
+	TaxClassID  *uint  `json:"tax_class_id"`
+}
+type CategoryResponse struct {
+	ID          uint               `json:"id"`
+	Name        string             `json:"name"`
+	Slug        string             `json:"slug"`
+	Description string             `json:"description,omitempty"`
+	ParentID    *uint              `json:"parent_id"`
+	TaxClassID  *uint              `json:"tax_class_id,omitempty"`
+	Children    []CategoryResponse `json:"children,omitempty"`
+}
+
+func ToCategoryResponse(category *models.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		Description: category.Description,
+		ParentID:    category.ParentID,
+		TaxClassID:  category.TaxClassID,
+	}
+}
+
+// ToCategoryTree nests a flat list of categories under their parents and
+// returns the root categories. Categories whose parent is not in the list are
+// treated as roots.
+func ToCategoryTree(categories []models.Category) []CategoryResponse {
+	present := make(map[uint]bool, len(categories))
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		present[category.ID] = true
+	}
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID == nil || !present[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+	var build func(nodes []models.Category) []CategoryResponse
+	build = func(nodes []models.Category) []CategoryResponse {
+		responses := make([]CategoryResponse, 0, len(nodes))
+		for i := range nodes {
+			response := ToCategoryResponse(&nodes[i])
+			response.Children = build(children[nodes[i].ID])
+			responses = append(responses, response)
+		}
+		return responses
+	}
+	return build(roots)
+}
+
+type VariantRequest struct {
+	SKU    string `json:"sku" validate:"required,max=64"`
+	Size   string `json:"size" validate:"max=32"`
+	Colour string `json:"colour" validate:"max=32"`
+	Price  int64  `json:"price" validate:"gt=0"`
+	// grams
+	Weight int `json:"weight" validate:"min=0"`
+	// initial stock, only used when the variant is created
+	Stock             int  `json:"stock" validate:"min=0"`
+	LowStockThreshold *int `json:"low_stock_threshold" validate:"omitempty,min=0"`
+	// prices in other currencies by currency code, replacing the ones set
+	// before; omit to keep them
+	Prices map[string]int64 `json:"prices" validate:"dive,gt=0"`
+}
+
+// ToVariant maps the request onto variant. Stock is only set on new variants;
+// afterwards it changes through inventory adjustments.
+func (r VariantRequest) ToVariant(variant *models.ProductVariant) {
+	variant.SKU = r.SKU
+	variant.Size = r.Size
+	variant.Colour = r.Colour
+	variant.Price = r.Price
+	variant.Weight = r.Weight
+	if variant.ID == 0 {
+		variant.Stock = r.Stock
+		variant.LowStockThreshold = 5
+	}
+	if r.LowStockThreshold != nil {
+		variant.LowStockThreshold = *r.LowStockThreshold
+	}
+	if r.Prices != nil {
+		variant.Prices = make([]models.VariantPrice, 0, len(r.Prices))
+		for code, price := range r.Prices {
+			variant.Prices = append(variant.Prices, models.VariantPrice{Currency: code, Price: price})
+		}
+		sort.Slice(variant.Prices, func(i, j int) bool { return variant.Prices[i].Currency < variant.Prices[j].Currency })
+	}
+}
+
+// UnsupportedPrice returns the first currency of the prices that is not
+// supported or is the base currency, or an empty string.
+func (r VariantRequest) UnsupportedPrice() string {
+	for code := range r.Prices {
+		if !currency.Known(code) || code == currency.Base {
+			return code
+		}
+	}
+	return ""
+}
+
+type ProductRequest struct {
+	Name        string           `json:"name" validate:"required,max=200"`
+	Slug        string           `json:"slug" validate:"max=220"`
+	Description string           `json:"description" validate:"max=5000"`
+	Brand       string           `json:"brand" validate:"max=100"`
+	CategoryID  uint             `json:"category_id" validate:"required"`
+	IsActive    *bool            `json:"is_active"`
+	Variants    []VariantRequest `json:"variants" validate:"dive"`
+}
+
+// ToProduct maps the request onto product. Variants are only mapped for new
+// products; existing variants are changed through the variant endpoints.
+func (r ProductRequest) ToProduct(product *models.Product) {
+	product.Name = r.Name
+	product.Slug = r.Slug
+	product.Description = r.Description
+	product.Brand = r.Brand
+	product.CategoryID = r.CategoryID
+	product.IsActive = r.IsActive == nil || *r.IsActive
+	if product.ID == 0 {
+		product.Variants = make([]models.ProductVariant, len(r.Variants))
+		for i, variant := range r.Variants {
+			variant.ToVariant(&product.Variants[i])
+		}
+	}
+}
+
+// VariantResponse exposes available stock only; on hand and reserved
+// quantities are part of the admin inventory API. Price is in the base
+// currency and Prices are the prices set for other currencies. DisplayPrice is
+// only set on catalog responses, in the currency the user chose; outside the
+// base currency it is marked approximate, as orders are charged in the base
+// currency.
+type VariantResponse struct {
+	ID           uint             `json:"id"`
+	SKU          string           `json:"sku"`
+	Size         string           `json:"size,omitempty"`
+	Colour       string           `json:"colour,omitempty"`
+	Price        int64            `json:"price"`
+	Prices       map[string]int64 `json:"prices,omitempty"`
+	DisplayPrice *currency.Money  `json:"display_price,omitempty"`
+	Weight       int              `json:"weight,omitempty"`
+	Stock        int              `json:"stock"`
+	InStock      bool             `json:"in_stock"`
+}
+
+func ToVariantResponse(variant *models.ProductVariant) VariantResponse {
+	response := VariantResponse{
+		ID:      variant.ID,
+		SKU:     variant.SKU,
+		Size:    variant.Size,
+		Colour:  variant.Colour,
+		Price:   variant.Price,
+		Weight:  variant.Weight,
+		Stock:   variant.Available(),
+		InStock: variant.Available() > 0,
+	}
+	if len(variant.Prices) > 0 {
+		response.Prices = make(map[string]int64, len(variant.Prices))
+		for _, price := range variant.Prices {
+			response.Prices[price.Currency] = price.Price
+		}
+	}
+	return response
+}
+
+type StockAdjustmentRequest struct {
+	// change to on hand stock, negative for write-offs
+	Delta  int    `json:"delta" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=200"`
+}
+
+type InventoryResponse struct {
+	VariantID         uint   `json:"variant_id"`
+	ProductID         uint   `json:"product_id"`
+	SKU               string `json:"sku"`
+	OnHand            int    `json:"on_hand"`
+	Reserved          int    `json:"reserved"`
+	Available         int    `json:"available"`
+	LowStockThreshold int    `json:"low_stock_threshold"`
+}
+
+func ToInventoryResponse(variant *models.ProductVariant) InventoryResponse {
+	return InventoryResponse{
+		VariantID:         variant.ID,
+		ProductID:         variant.ProductID,
+		SKU:               variant.SKU,
+		OnHand:            variant.Stock,
+		Reserved:          variant.Reserved,
+		Available:         variant.Available(),
+		LowStockThreshold: variant.LowStockThreshold,
+	}
+}
+func ToInventoryResponses(variants []models.ProductVariant) []InventoryResponse {
+	responses := make([]InventoryResponse, 0, len(variants))
+	for i := range variants {
+		responses = append(responses, ToInventoryResponse(&variants[i]))
+	}
+	return responses
+}
+
+type ProductResponse struct {
+	ID          uint             `json:"id"`
+	Name        string           `json:"name"`
+	Slug        string           `json:"slug"`
+	Description string           `json:"description"`
+	Brand       string           `json:"brand,omitempty"`
+	Category    CategoryResponse `json:"category"`
+	IsActive    bool             `json:"is_active"`
+	MinPrice    int64            `json:"min_price"`
+	// MinPrice in the display currency, see SetDisplayPrices
+	DisplayMinPrice *currency.Money      `json:"display_min_price,omitempty"`
+	Rating          models.ProductRating `json:"rating"`
+	Variants        []VariantResponse    `json:"variants"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+func ToProductResponse(product *models.Product) ProductResponse {
+	response := ProductResponse{
+		ID:          product.ID,
+		Name:        product.Name,
+		Slug:        product.Slug,
+		Description: product.Description,
+		Brand:       product.Brand,
+		Category:    ToCategoryResponse(&product.Category),
+		IsActive:    product.IsActive,
+		Rating:      product.Rating,
+		Variants:    make([]VariantResponse, 0, len(product.Variants)),
+		CreatedAt:   product.CreatedAt,
+		UpdatedAt:   product.UpdatedAt,
+	}
+	for i, variant := range product.Variants {
+		if i == 0 || variant.Price < response.MinPrice {
+			response.MinPrice = variant.Price
+		}
+		response.Variants = append(response.Variants, ToVariantResponse(&product.Variants[i]))
+	}
+	return response
+}
+
+// SetDisplayPrices sets the prices of the variants in the display currency:
+// the price set for the currency if there is one, otherwise the base price
+// converted at the display rate. Variants whose price cannot be converted get
+// no display price.
+func (r *ProductResponse) SetDisplayPrices(display currency.Display) {
+	for i := range r.Variants {
+		variant := &r.Variants[i]
+		price, err := display.Convert(variant.Price)
+		if override, ok := variant.Prices[display.Currency]; ok {
+			price = currency.Money{Amount: override, Currency: display.Currency, Approximate: display.Currency != currency.Base}
+			err = nil
+		}
+		if err != nil {
+			continue
+		}
+		variant.DisplayPrice = &price
+		if r.DisplayMinPrice == nil || price.Amount < r.DisplayMinPrice.Amount {
+			r.DisplayMinPrice = &price
+		}
+	}
+}
+func ToProductResponses(products []models.Product) []ProductResponse {
+	responses := make([]ProductResponse, 0, len(products))
+	for i := range products {
+		responses = append(responses, ToProductResponse(&products[i]))
+	}
+	return responses
+}
+
+// Pagination describes the page returned by a list endpoint.
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func NewPagination(page, limit int, total int64) Pagination {
+	return Pagination{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + int64(limit) - 1) / int64(limit),
+	}
+}
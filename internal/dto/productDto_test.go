@@ -0,0 +1,69 @@
+package dto
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func category(id uint, name string, parentID *uint) models.Category {
+	c := models.Category{Name: name, ParentID: parentID}
+	c.ID = id
+	return c
+}
+
+func TestToCategoryTree(t *testing.T) {
+	one, two, missing := uint(1), uint(2), uint(99)
+	tree := ToCategoryTree([]models.Category{
+		category(1, "Clothing", nil),
+		category(2, "Men", &one),
+		category(3, "Shirts", &two),
+		category(4, "Women", &one),
+		category(5, "Orphan", &missing),
+	})
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Clothing", tree[0].Name)
+	assert.Equal(t, "Orphan", tree[1].Name)
+	assert.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "Men", tree[0].Children[0].Name)
+	assert.Equal(t, "Shirts", tree[0].Children[0].Children[0].Name)
+	assert.Empty(t, tree[0].Children[1].Children)
+}
+
+func TestToProductResponseMinPrice(t *testing.T) {
+	product := models.Product{Variants: []models.ProductVariant{{Price: 500}, {Price: 300}, {Price: 900}}}
+	assert.Equal(t, int64(300), ToProductResponse(&product).MinPrice)
+}
+
+func TestSetDisplayPrices(t *testing.T) {
+	product := models.Product{Variants: []models.ProductVariant{
+		{Price: 100000},
+		{Price: 90000, Prices: []models.VariantPrice{{Currency: "USD", Price: 999}}},
+	}}
+	response := ToProductResponse(&product)
+	response.SetDisplayPrices(currency.Display{Currency: "USD", Rate: 12000})
+	assert.Equal(t, &currency.Money{Amount: 1200, Currency: "USD", Approximate: true}, response.Variants[0].DisplayPrice)
+	// the price set for USD is used instead of the converted 1080
+	assert.Equal(t, &currency.Money{Amount: 999, Currency: "USD", Approximate: true}, response.Variants[1].DisplayPrice)
+	assert.Equal(t, &currency.Money{Amount: 999, Currency: "USD", Approximate: true}, response.DisplayMinPrice)
+	assert.Equal(t, int64(90000), response.MinPrice)
+}
+
+func TestSetDisplayPricesOutOfRange(t *testing.T) {
+	product := models.Product{Variants: []models.ProductVariant{{Price: math.MaxInt64}, {Price: 100000}}}
+	response := ToProductResponse(&product)
+	response.SetDisplayPrices(currency.Display{Currency: "JPY", Rate: 200_000000})
+	assert.Nil(t, response.Variants[0].DisplayPrice)
+	assert.Equal(t, &currency.Money{Amount: 200000, Currency: "JPY", Approximate: true}, response.DisplayMinPrice)
+}
+
+func TestSetDisplayPricesBase(t *testing.T) {
+	product := models.Product{Variants: []models.ProductVariant{{Price: 100000}}}
+	response := ToProductResponse(&product)
+	response.SetDisplayPrices(currency.BaseDisplay)
+	assert.Equal(t, &currency.Money{Amount: 100000, Currency: currency.Base}, response.Variants[0].DisplayPrice)
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+// ReferredUserResponse shows a referral to the referrer without the referred
+// user's contact details.
+type ReferredUserResponse struct {
+	FirstName  string     `json:"first_name"`
+	Status     string     `json:"status"`
+	Reward     int64      `json:"reward"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at"`
+}
+
+// ReferralsResponse is the user's referral code with the users they referred.
+// Earned sums the rewards of rewarded referrals.
+type ReferralsResponse struct {
+	ReferralCode string                 `json:"referral_code"`
+	Pending      int                    `json:"pending"`
+	Rewarded     int                    `json:"rewarded"`
+	Earned       int64                  `json:"earned"`
+	Referrals    []ReferredUserResponse `json:"referrals"`
+}
+
+func ToReferralsResponse(code string, referrals []models.Referral) ReferralsResponse {
+	response := ReferralsResponse{
+		ReferralCode: code,
+		Referrals:    make([]ReferredUserResponse, 0, len(referrals)),
+	}
+	for _, referral := range referrals {
+		switch referral.Status {
+		case models.ReferralRewarded:
+			response.Rewarded++
+			response.Earned += referral.ReferrerReward
+		case models.ReferralPending:
+			response.Pending++
+		}
+		response.Referrals = append(response.Referrals, ReferredUserResponse{
+			FirstName:  referral.User.FirstName,
+			Status:     referral.Status,
+			Reward:     referral.ReferrerReward,
+			CreatedAt:  referral.CreatedAt,
+			RewardedAt: referral.RewardedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,19 @@
+package dto
+
+type ReturnItemRequest struct {
+	OrderItemID uint `json:"order_item_id" validate:"required"`
+	Quantity    int  `json:"quantity" validate:"required,min=1"`
+}
+
+type ReturnRequest struct {
+	Items   []ReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+	Reason  string              `json:"reason" validate:"required,oneof=damaged defective wrong_item not_as_described size_fit changed_mind other"`
+	Comment string              `json:"comment" validate:"max=1000"`
+	// defaults to original
+	RefundMethod string `json:"refund_method" validate:"omitempty,oneof=original wallet"`
+}
+
+type ReturnStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=approved rejected picked_up refunded"`
+	Note   string `json:"note" validate:"max=1000"`
+}
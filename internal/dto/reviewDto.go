@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" validate:"max=200"`
+	Body   string `json:"body" validate:"max=5000"`
+}
+
+type ReviewModerationRequest struct {
+	Status string `json:"status" validate:"required,oneof=published hidden"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+// ReviewResponse is the public view of a review. Every review comes from a
+// verified buyer, and the author is shown by first name and last initial.
+type ReviewResponse struct {
+	ID           uint      `json:"id"`
+	ProductID    uint      `json:"product_id"`
+	Author       string    `json:"author"`
+	Rating       int       `json:"rating"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	Images       []string  `json:"images"`
+	HelpfulCount int       `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func ToReviewResponse(review *models.Review) ReviewResponse {
+	response := ReviewResponse{
+		ID:           review.ID,
+		ProductID:    review.ProductID,
+		Author:       review.User.FirstName,
+		Rating:       review.Rating,
+		Title:        review.Title,
+		Body:         review.Body,
+		Images:       make([]string, 0, len(review.Images)),
+		HelpfulCount: review.HelpfulCount,
+		CreatedAt:    review.CreatedAt,
+		UpdatedAt:    review.UpdatedAt,
+	}
+	if lastName := []rune(review.User.LastName); len(lastName) > 0 {
+		response.Author += " " + string(lastName[0]) + "."
+	}
+	for _, image := range review.Images {
+		response.Images = append(response.Images, image.URL)
+	}
+	return response
+}
+func ToReviewResponses(reviews []models.Review) []ReviewResponse {
+	responses := make([]ReviewResponse, 0, len(reviews))
+	for i := range reviews {
+		responses = append(responses, ToReviewResponse(&reviews[i]))
+	}
+	return responses
+}
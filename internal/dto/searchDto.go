@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/search"
+)
+
+// SearchHighlight holds the matched fields with the matches wrapped in
+// <mark> tags. Description is a snippet around the matches.
+type SearchHighlight struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+type SearchHitResponse struct {
+	Product   ProductResponse `json:"product"`
+	Score     float64         `json:"score"`
+	Highlight SearchHighlight `json:"highlight"`
+}
+type CategoryFacetResponse struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+type BrandFacetResponse struct {
+	Brand string `json:"brand"`
+	Count int64  `json:"count"`
+}
+
+// PriceRangeFacetResponse counts the products whose cheapest variant costs
+// at least Min and less than Max; the last range has no Max.
+type PriceRangeFacetResponse struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max"`
+	Count int64  `json:"count"`
+}
+type SearchFacetsResponse struct {
+	Categories  []CategoryFacetResponse   `json:"categories"`
+	Brands      []BrandFacetResponse      `json:"brands"`
+	PriceRanges []PriceRangeFacetResponse `json:"price_ranges"`
+}
+
+// ToSearchResponse pairs the hits with their products, which must contain
+// every hit.
+func ToSearchResponse(result *search.Result, products map[uint]models.Product) ([]SearchHitResponse, SearchFacetsResponse) {
+	hits := make([]SearchHitResponse, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		product := products[hit.ProductID]
+		hits = append(hits, SearchHitResponse{
+			Product:   ToProductResponse(&product),
+			Score:     hit.Score,
+			Highlight: SearchHighlight{Name: hit.NameHighlight, Description: hit.DescriptionHighlight},
+		})
+	}
+	facets := SearchFacetsResponse{
+		Categories:  make([]CategoryFacetResponse, 0, len(result.Facets.Categories)),
+		Brands:      make([]BrandFacetResponse, 0, len(result.Facets.Brands)),
+		PriceRanges: make([]PriceRangeFacetResponse, 0, len(result.Facets.PriceRanges)),
+	}
+	for _, category := range result.Facets.Categories {
+		facets.Categories = append(facets.Categories, CategoryFacetResponse{ID: category.CategoryID, Name: category.Name, Count: category.Count})
+	}
+	for _, brand := range result.Facets.Brands {
+		facets.Brands = append(facets.Brands, BrandFacetResponse{Brand: brand.Brand, Count: brand.Count})
+	}
+	for _, priceRange := range result.Facets.PriceRanges {
+		response := PriceRangeFacetResponse{Min: priceRange.Min, Count: priceRange.Count}
+		if priceRange.Max > 0 {
+			upper := priceRange.Max
+			response.Max = &upper
+		}
+		facets.PriceRanges = append(facets.PriceRanges, response)
+	}
+	return hits, facets
+}
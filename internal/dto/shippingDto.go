@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type ShippingMethodRequest struct {
+	Code          string                `json:"code" validate:"required,max=32"`
+	Name          string                `json:"name" validate:"required,max=100"`
+	Description   string                `json:"description" validate:"max=500"`
+	CarrierRates  bool                  `json:"carrier_rates"`
+	EstimatedDays int                   `json:"estimated_days" validate:"min=0,max=60"`
+	Rules         []ShippingRuleRequest `json:"rules" validate:"required,min=1,max=50,dive"`
+	// defaults to true
+	IsActive *bool `json:"is_active"`
+}
+type ShippingRuleRequest struct {
+	States         []string `json:"states" validate:"max=40,dive,max=50"`
+	PostalPrefixes []string `json:"postal_prefixes" validate:"max=500,dive,numeric,max=6"`
+	MinWeight      int      `json:"min_weight" validate:"min=0"`
+	MaxWeight      int      `json:"max_weight" validate:"min=0"`
+	MinOrderValue  int64    `json:"min_order_value" validate:"min=0"`
+	MaxOrderValue  int64    `json:"max_order_value" validate:"min=0"`
+	Rate           int64    `json:"rate" validate:"min=0"`
+	PerKg          int64    `json:"per_kg" validate:"min=0"`
+}
+
+// ToShippingMethod maps the request onto method.
+func (r ShippingMethodRequest) ToShippingMethod(method *models.ShippingMethod) {
+	method.Code = r.Code
+	method.Name = r.Name
+	method.Description = r.Description
+	method.CarrierRates = r.CarrierRates
+	method.EstimatedDays = r.EstimatedDays
+	method.IsActive = r.IsActive == nil || *r.IsActive
+	method.Rules = make([]models.ShippingRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		method.Rules = append(method.Rules, models.ShippingRule{
+			States:         rule.States,
+			PostalPrefixes: rule.PostalPrefixes,
+			MinWeight:      rule.MinWeight,
+			MaxWeight:      rule.MaxWeight,
+			MinOrderValue:  rule.MinOrderValue,
+			MaxOrderValue:  rule.MaxOrderValue,
+			Rate:           rule.Rate,
+			PerKg:          rule.PerKg,
+		})
+	}
+}
+
+type ShippingQuoteResponse struct {
+	MethodID      uint   `json:"method_id"`
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	Amount        int64  `json:"amount"`
+	EstimatedDays int    `json:"estimated_days"`
+}
+
+func ToShippingQuoteResponses(quotes []models.ShippingQuote) []ShippingQuoteResponse {
+	responses := make([]ShippingQuoteResponse, 0, len(quotes))
+	for _, quote := range quotes {
+		responses = append(responses, ShippingQuoteResponse{
+			MethodID:      quote.Method.ID,
+			Code:          quote.Method.Code,
+			Name:          quote.Method.Name,
+			Description:   quote.Method.Description,
+			Amount:        quote.Amount,
+			EstimatedDays: quote.EstimatedDays,
+		})
+	}
+	return responses
+}
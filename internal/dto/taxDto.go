@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"sort"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type TaxClassRequest struct {
+	Name        string           `json:"name" validate:"required,max=100"`
+	Description string           `json:"description" validate:"max=500"`
+	Slabs       []TaxSlabRequest `json:"slabs" validate:"required,min=1,max=10,dive"`
+	IsDefault   bool             `json:"is_default"`
+}
+type TaxSlabRequest struct {
+	MinUnitValue int64 `json:"min_unit_value" validate:"min=0"`
+	// basis points, e.g. 1800 for 18%
+	Rate int `json:"rate" validate:"min=0,max=10000"`
+}
+
+// ToTaxSlabs returns the slabs sorted by threshold.
+func (r TaxClassRequest) ToTaxSlabs() []models.TaxSlab {
+	slabs := make([]models.TaxSlab, 0, len(r.Slabs))
+	for _, slab := range r.Slabs {
+		slabs = append(slabs, models.TaxSlab{MinUnitValue: slab.MinUnitValue, Rate: slab.Rate})
+	}
+	sort.Slice(slabs, func(i, j int) bool { return slabs[i].MinUnitValue < slabs[j].MinUnitValue })
+	return slabs
+}
@@ -0,0 +1,123 @@
+// Package dto holds the request and response types of the HTTP API. Handlers
+// never bind to or serialize the gorm models directly, so persistence changes
+// do not leak into the API contract.
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type SignUpRequest struct {
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
+	Email     string `json:"email"  validate:"required"`
+	Password  string `json:"password" validate:"required"`
+	Phone     string `json:"phone" validate:"required,numeric,len=10"`
+	// the referral code of the user who invited them
+	ReferralCode string `json:"referral_code" validate:"max=16"`
+}
+
+func (r SignUpRequest) ToUser() models.User {
+	return models.User{
+		FirstName: r.FirstName,
+		LastName:  r.LastName,
+		Email:     r.Email,
+		Password:  r.Password,
+		Phone:     r.Phone,
+	}
+}
+
+type LoginRequest struct {
+	Email    string `validate:"required,email" json:"email"`
+	Password string `validate:"required" json:"password"`
+}
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
+	Phone     string `json:"phone" validate:"required,numeric,len=10"`
+	// display currency; empty for the base currency
+	Currency string `json:"currency" validate:"omitempty,len=3,alpha"`
+}
+
+func NewUpdateProfileRequest(user *models.User) UpdateProfileRequest {
+	return UpdateProfileRequest{FirstName: user.FirstName, LastName: user.LastName, Phone: user.Phone, Currency: user.Currency}
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396) document to the
+// profile fields and returns the patched copy together with the fields that
+// were supplied. A null value clears the field, which validation then rejects
+// for required fields.
+func (r UpdateProfileRequest) ApplyMergePatch(patch map[string]interface{}) (UpdateProfileRequest, []string, error) {
+	fields := make([]string, 0, len(patch))
+	for key, value := range patch {
+		var target *string
+		switch key {
+		case "first_name":
+			target = &r.FirstName
+		case "last_name":
+			target = &r.LastName
+		case "phone":
+			target = &r.Phone
+		case "currency":
+			target = &r.Currency
+		default:
+			return r, nil, fmt.Errorf("%s cannot be updated", key)
+		}
+		if value == nil {
+			*target = ""
+		} else {
+			str, ok := value.(string)
+			if !ok {
+				return r, nil, fmt.Errorf("%s must be a string", key)
+			}
+			*target = str
+		}
+		fields = append(fields, key)
+	}
+	return r, fields, nil
+}
+
+type MagicLinkRequest struct {
+	Email string `validate:"required,email" json:"email"`
+}
+type RefreshTokenRequest struct {
+	RefreshToken string `validate:"required" json:"refresh_token"`
+}
+type DeleteAccountRequest struct {
+	Password string `validate:"required" json:"password"`
+}
+
+// UserResponse is the public representation of a user. It deliberately has
+// no password or soft delete fields.
+type UserResponse struct {
+	ID        uint              `json:"id"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Email     string            `json:"email"`
+	Phone     string            `json:"phone"`
+	Status    string            `json:"status"`
+	Currency  string            `json:"currency,omitempty"`
+	Version   uint              `json:"version"`
+	Avatar    map[string]string `json:"avatar,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+func ToUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		Status:    user.Status,
+		Currency:  user.Currency,
+		Version:   user.Version,
+		Avatar:    user.Avatar,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type WalletTransactionResponse struct {
+	ID           uint      `json:"id"`
+	Type         string    `json:"type"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balance_after"`
+	Reference    string    `json:"reference"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type WalletResponse struct {
+	Balance      int64                       `json:"balance"`
+	Currency     string                      `json:"currency"`
+	Transactions []WalletTransactionResponse `json:"transactions"`
+	Pagination   Pagination                  `json:"pagination"`
+}
+
+func ToWalletResponse(wallet *models.Wallet, transactions []models.WalletTransaction, pagination Pagination) WalletResponse {
+	response := WalletResponse{
+		Balance:      wallet.Balance,
+		Currency:     models.DefaultCurrency,
+		Transactions: make([]WalletTransactionResponse, 0, len(transactions)),
+		Pagination:   pagination,
+	}
+	for _, transaction := range transactions {
+		response.Transactions = append(response.Transactions, WalletTransactionResponse{
+			ID:           transaction.ID,
+			Type:         transaction.Type,
+			Amount:       transaction.Amount,
+			BalanceAfter: transaction.BalanceAfter,
+			Reference:    transaction.Reference,
+			CreatedAt:    transaction.CreatedAt,
+		})
+	}
+	return response
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+type WishlistRequest struct {
+	VariantID uint `json:"variant_id" validate:"required"`
+}
+
+type WishlistItemResponse struct {
+	VariantID    uint      `json:"variant_id"`
+	ProductID    uint      `json:"product_id"`
+	ProductName  string    `json:"product_name"`
+	SKU          string    `json:"sku"`
+	Size         string    `json:"size,omitempty"`
+	Colour       string    `json:"colour,omitempty"`
+	SavedPrice   int64     `json:"saved_price"`
+	CurrentPrice int64     `json:"current_price"`
+	InStock      bool      `json:"in_stock"`
+	Available    bool      `json:"available"`
+	AddedAt      time.Time `json:"added_at"`
+}
+
+func ToWishlistResponse(items []models.WishlistItem, products map[uint]models.Product) []WishlistItemResponse {
+	responses := make([]WishlistItemResponse, 0, len(items))
+	for _, item := range items {
+		variant := item.Variant
+		product := products[variant.ProductID]
+		available := !variant.DeletedAt.Valid && !product.DeletedAt.Valid && product.IsActive
+		responses = append(responses, WishlistItemResponse{
+			VariantID:    item.VariantID,
+			ProductID:    variant.ProductID,
+			ProductName:  product.Name,
+			SKU:          variant.SKU,
+			Size:         variant.Size,
+			Colour:       variant.Colour,
+			SavedPrice:   item.SavedPrice,
+			CurrentPrice: variant.Price,
+			InStock:      available && variant.Available() > 0,
+			Available:    available,
+			AddedAt:      item.CreatedAt,
+		})
+	}
+	return responses
+}
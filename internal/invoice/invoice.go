@@ -0,0 +1,256 @@
+// Package invoice renders tax invoices as PDF documents without any external
+// service or library.
+package invoice
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+)
+
+// Party is the seller or a buyer address block on an invoice.
+type Party struct {
+	Name  string
+	Lines []string
+	// GSTIN of a registered business, printed when set
+	TaxID string
+}
+
+// SellerFromEnv reads the seller details from SELLER_NAME, SELLER_ADDRESS,
+// whose lines are separated by ";", and SELLER_GSTIN.
+func SellerFromEnv() Party {
+	seller := Party{Name: os.Getenv("SELLER_NAME"), TaxID: os.Getenv("SELLER_GSTIN")}
+	if seller.Name == "" {
+		seller.Name = "UserEcommerce"
+	}
+	for _, line := range strings.Split(os.Getenv("SELLER_ADDRESS"), ";") {
+		if line = strings.TrimSpace(line); line != "" {
+			seller.Lines = append(seller.Lines, line)
+		}
+	}
+	return seller
+}
+
+// Line is one invoiced item. Amounts are in minor units; Taxable is the
+// value tax is charged on, after discounts.
+type Line struct {
+	Description string
+	// printed below the description, e.g. SKU and variant
+	Detail    string
+	Quantity  int
+	UnitPrice int64
+	Discount  int64
+	Taxable   int64
+	Tax       int64
+	Total     int64
+}
+
+// TaxLine is one row of the tax breakdown, e.g. a tax at one rate.
+type TaxLine struct {
+	Label   string
+	Taxable int64
+	Amount  int64
+}
+
+// Amount is one row of the totals, e.g. shipping or the grand total.
+type Amount struct {
+	Label  string
+	Amount int64
+	Bold   bool
+}
+
+// Document holds everything printed on an invoice.
+type Document struct {
+	Number      string
+	Date        time.Time
+	OrderNumber string
+	Currency    string
+	Seller      Party
+	BillTo      Party
+	ShipTo      Party
+	Lines       []Line
+	Taxes       []TaxLine
+	Totals      []Amount
+	Notes       []string
+}
+
+// Layout in points.
+const (
+	margin       = 40.0
+	contentRight = pageWidth - margin
+	bodySize     = 9.0
+	smallSize    = 7.5
+	rowHeight    = 24.0
+	// lowest baseline of body content; the page number goes below
+	bottomLimit = pageHeight - 60
+)
+
+// Table columns: the left edge of the text columns and the right edge of
+// the amount columns.
+var (
+	columnIndex  = margin
+	columnItem   = margin + 20
+	itemWidth    = 190.0
+	amountRights = []float64{285, 345, 405, 465, 505, contentRight}
+	amountLabels = []string{"Qty", "Unit price", "Discount", "Taxable", "Tax", "Total"}
+)
+
+// Render lays out doc on as many A4 pages as its lines need and returns the
+// PDF file.
+func Render(doc Document) []byte {
+	r := &renderer{doc: doc}
+	r.pdf.addPage()
+	r.header()
+	r.parties()
+	r.tableHeader()
+	for i, line := range doc.Lines {
+		r.line(i+1, line)
+	}
+	r.summary()
+	r.notes()
+	r.pageNumbers()
+	return r.pdf.bytes()
+}
+
+type renderer struct {
+	pdf pdf
+	doc Document
+	// baseline of the next row on the current page
+	y float64
+}
+
+func (r *renderer) header() {
+	r.pdf.text(margin, 60, 18, true, "TAX INVOICE")
+	r.y = 85
+	r.party("Sold by", r.doc.Seller, margin)
+	y := 85.0
+	for _, field := range [][2]string{
+		{"Invoice number", r.doc.Number},
+		{"Invoice date", r.doc.Date.Format("02 Jan 2006")},
+		{"Order number", r.doc.OrderNumber},
+	} {
+		r.pdf.textRight(contentRight-130, y, bodySize, true, field[0])
+		r.pdf.textRight(contentRight, y, bodySize, false, field[1])
+		y += 14
+	}
+	r.y = max(r.y, y) + 10
+	r.pdf.line(margin, r.y, contentRight, r.y, 0.5)
+	r.y += 20
+}
+
+// parties prints the billing and shipping addresses side by side.
+func (r *renderer) parties() {
+	top := r.y
+	r.party("Bill to", r.doc.BillTo, margin)
+	bottom := r.y
+	r.y = top
+	r.party("Ship to", r.doc.ShipTo, pageWidth/2)
+	r.y = max(r.y, bottom) + 10
+}
+
+// party prints a titled address block at x from r.y downwards.
+func (r *renderer) party(title string, party Party, x float64) {
+	width := pageWidth/2 - margin - 10
+	r.pdf.text(x, r.y, smallSize, true, strings.ToUpper(title))
+	r.y += 13
+	r.pdf.text(x, r.y, bodySize, true, fitText(party.Name, width, bodySize, true))
+	r.y += 12
+	for _, line := range party.Lines {
+		r.pdf.text(x, r.y, bodySize, false, fitText(line, width, bodySize, false))
+		r.y += 12
+	}
+	if party.TaxID != "" {
+		r.pdf.text(x, r.y, bodySize, false, "GSTIN: "+party.TaxID)
+		r.y += 12
+	}
+}
+func (r *renderer) tableHeader() {
+	r.pdf.fillRect(margin, r.y-12, contentRight-margin, 18, 0.9)
+	r.pdf.text(columnIndex+2, r.y, bodySize, true, "#")
+	r.pdf.text(columnItem, r.y, bodySize, true, "Item")
+	for i, label := range amountLabels {
+		r.pdf.textRight(amountRights[i], r.y, bodySize, true, label)
+	}
+	r.y += 20
+}
+func (r *renderer) line(index int, line Line) {
+	r.ensureSpace(rowHeight)
+	r.pdf.text(columnIndex+2, r.y, bodySize, false, fmt.Sprint(index))
+	r.pdf.text(columnItem, r.y, bodySize, false, fitText(line.Description, itemWidth, bodySize, false))
+	if line.Detail != "" {
+		r.pdf.text(columnItem, r.y+10, smallSize, false, fitText(line.Detail, itemWidth, smallSize, false))
+	}
+	values := []string{
+		fmt.Sprint(line.Quantity),
+		currency.FormatAmount(line.UnitPrice, currency.Base),
+		currency.FormatAmount(line.Discount, currency.Base),
+		currency.FormatAmount(line.Taxable, currency.Base),
+		currency.FormatAmount(line.Tax, currency.Base),
+		currency.FormatAmount(line.Total, currency.Base),
+	}
+	for i, value := range values {
+		r.pdf.textRight(amountRights[i], r.y, bodySize, false, value)
+	}
+	r.pdf.line(margin, r.y+rowHeight-10, contentRight, r.y+rowHeight-10, 0.25)
+	r.y += rowHeight
+}
+
+// summary prints the tax breakdown on the left and the totals on the right.
+func (r *renderer) summary() {
+	rows := max(len(r.doc.Taxes)+1, len(r.doc.Totals))
+	r.ensureSpace(float64(rows)*14 + 30)
+	r.y += 10
+	top := r.y
+	if len(r.doc.Taxes) > 0 {
+		r.pdf.text(margin, r.y, bodySize, true, "Tax")
+		r.pdf.textRight(margin+170, r.y, bodySize, true, "Taxable")
+		r.pdf.textRight(margin+240, r.y, bodySize, true, "Amount")
+		for _, tax := range r.doc.Taxes {
+			r.y += 14
+			r.pdf.text(margin, r.y, bodySize, false, fitText(tax.Label, 110, bodySize, false))
+			r.pdf.textRight(margin+170, r.y, bodySize, false, currency.FormatAmount(tax.Taxable, currency.Base))
+			r.pdf.textRight(margin+240, r.y, bodySize, false, currency.FormatAmount(tax.Amount, currency.Base))
+		}
+	}
+	bottom := r.y
+	r.y = top
+	for _, total := range r.doc.Totals {
+		label := total.Label
+		if total.Bold && r.doc.Currency != "" {
+			label += " (" + r.doc.Currency + ")"
+		}
+		r.pdf.textRight(contentRight-90, r.y, bodySize, total.Bold, label)
+		r.pdf.textRight(contentRight, r.y, bodySize, total.Bold, currency.FormatAmount(total.Amount, currency.Base))
+		r.y += 14
+	}
+	r.y = max(r.y, bottom) + 20
+}
+func (r *renderer) notes() {
+	for _, note := range r.doc.Notes {
+		r.ensureSpace(12)
+		r.pdf.text(margin, r.y, smallSize, false, note)
+		r.y += 12
+	}
+}
+
+// ensureSpace starts a new page, repeating the table header, unless height
+// points are left on the current one.
+func (r *renderer) ensureSpace(height float64) {
+	if r.y+height <= bottomLimit {
+		return
+	}
+	r.pdf.addPage()
+	r.y = 60
+	r.pdf.text(margin, r.y, bodySize, true, r.doc.Number)
+	r.y += 25
+	r.tableHeader()
+}
+func (r *renderer) pageNumbers() {
+	for i, page := range r.pdf.pages {
+		r.pdf.page = page
+		r.pdf.textRight(contentRight, pageHeight-30, smallSize, false, fmt.Sprintf("Page %d of %d", i+1, len(r.pdf.pages)))
+	}
+}
@@ -0,0 +1,76 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDocument(lines int) Document {
+	doc := Document{
+		Number:      "INV-2024-25-000001",
+		Date:        time.Date(2024, time.May, 2, 0, 0, 0, 0, time.UTC),
+		OrderNumber: "ORD-20240502-00000001",
+		Currency:    "INR",
+		Seller:      Party{Name: "Shop (India)", Lines: []string{"1 Main Road", "Kochi"}, TaxID: "32ABCDE1234F1Z5"},
+		BillTo:      Party{Name: "Asha", Lines: []string{"2 Side Street"}},
+		ShipTo:      Party{Name: "Asha", Lines: []string{"2 Side Street"}},
+		Taxes:       []TaxLine{{Label: "Tax", Taxable: 1000, Amount: 50}},
+		Totals:      []Amount{{Label: "Total", Amount: 1050, Bold: true}},
+	}
+	for i := 0; i < lines; i++ {
+		doc.Lines = append(doc.Lines, Line{Description: fmt.Sprintf("Item %d", i), Quantity: 1, UnitPrice: 1000, Taxable: 1000, Tax: 50, Total: 1050})
+	}
+	return doc
+}
+
+func TestRender(t *testing.T) {
+	out := Render(testDocument(3))
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 1 ")
+	assert.Contains(t, string(out), `(Shop \(India\)) Tj`)
+	assert.Contains(t, string(out), "(10.50) Tj", "amounts are formatted")
+}
+
+func TestRenderXref(t *testing.T) {
+	out := Render(testDocument(3))
+
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, start)
+	xref, err := strconv.Atoi(string(start[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestRenderPaginates(t *testing.T) {
+	out := string(Render(testDocument(60)))
+
+	assert.Contains(t, out, "/Count 3 ")
+	assert.Contains(t, out, "(Page 3 of 3) Tj")
+	assert.Contains(t, out, "(Item 59) Tj")
+}
+
+func TestFitText(t *testing.T) {
+	assert.Equal(t, "short", fitText("short", 100, 9, false))
+	long := fitText("a very long product name that does not fit the column", 80, 9, false)
+	assert.LessOrEqual(t, textWidth(long, 9, false), 80.0)
+	assert.Regexp(t, `\.\.\.$`, long)
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\(b\)c\\ ?`, escapeText(`a(b)c\ ₹`))
+}
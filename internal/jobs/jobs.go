@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunEvery runs fn in a background goroutine once immediately and then every
+// interval for the lifetime of the process. Errors are logged and do not stop the job.
+func RunEvery(name string, interval time.Duration, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := fn(); err != nil {
+				fmt.Printf("job %s failed: %v\n", name, err)
+			}
+			<-ticker.C
+		}
+	}()
+}
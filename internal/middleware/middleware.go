@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
@@ -28,7 +29,7 @@ func JWTMIddleware(requiredRole string) gin.HandlerFunc {
 		}
 		// Extract claims
 		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || claims["exp"] == nil {
+		if !ok || claims["exp"] == nil || utils.IsRefreshToken(claims) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			c.Abort()
 			return
@@ -47,6 +48,8 @@ func JWTMIddleware(requiredRole string) gin.HandlerFunc {
 		}
 		if requiredRole != "" && requiredRole != clientRole {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient previleges"})
+			c.Abort()
+			return
 		}
 		c.Set("ID", claims["ID"])
 		c.Set("email", claims["email"])
@@ -55,3 +58,30 @@ func JWTMIddleware(requiredRole string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalUser sets the claims of a valid user token like JWTMIddleware but
+// lets requests without one through, for public routes that personalise the
+// response for logged-in users.
+func OptionalUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" {
+			c.Next()
+			return
+		}
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return []byte("secret"), nil
+		})
+		if err == nil && token.Valid {
+			// expiry is checked by Valid
+			claims, ok := token.Claims.(jwt.MapClaims)
+			if ok && claims["exp"] != nil && claims["role"] == "user" && !utils.IsRefreshToken(claims) {
+				c.Set("ID", claims["ID"])
+				c.Set("email", claims["email"])
+				c.Set("expiry", claims["exp"])
+				c.Set("role", claims["role"])
+			}
+		}
+		c.Next()
+	}
+}
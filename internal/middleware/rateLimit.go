@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows at most limit requests per client IP within each fixed
+// window. Every call creates its own counters, so routes are limited independently.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	windows := make(map[string]*rateWindow)
+	lastCleanup := time.Now()
+	return func(c *gin.Context) {
+		key := c.ClientIP()
+		now := time.Now()
+		mu.Lock()
+		if now.Sub(lastCleanup) > window {
+			for k, w := range windows {
+				if now.Sub(w.start) > window {
+					delete(windows, k)
+				}
+			}
+			lastCleanup = now
+		}
+		w, ok := windows[key]
+		if !ok || now.Sub(w.start) > window {
+			w = &rateWindow{start: now}
+			windows[key] = w
+		}
+		w.count++
+		allowed := w.count <= limit
+		mu.Unlock()
+		if !allowed {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many requests, please try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
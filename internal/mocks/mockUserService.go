@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: C:\Go\UserEcommerceCleanDemo\internal\services\userService.go
+// Source: internal/services/userService.go
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,6 +7,7 @@ package mocks
 import (
 	reflect "reflect"
 
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
 	models "github.com/Ansalps/UserEcommerceClean/internal/models"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,7 +36,7 @@ func (m *MockIUserService) EXPECT() *MockIUserServiceMockRecorder {
 }
 
 // ComparePassword mocks base method.
-func (m *MockIUserService) ComparePassword(providedUser models.UserLogin, user models.User) bool {
+func (m *MockIUserService) ComparePassword(providedUser dto.LoginRequest, user models.User) bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ComparePassword", providedUser, user)
 	ret0, _ := ret[0].(bool)
@@ -48,6 +49,35 @@ func (mr *MockIUserServiceMockRecorder) ComparePassword(providedUser, user inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ComparePassword", reflect.TypeOf((*MockIUserService)(nil).ComparePassword), providedUser, user)
 }
 
+// DeleteAccount mocks base method.
+func (m *MockIUserService) DeleteAccount(userID uint, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockIUserServiceMockRecorder) DeleteAccount(userID, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockIUserService)(nil).DeleteAccount), userID, password)
+}
+
+// ExportUserData mocks base method.
+func (m *MockIUserService) ExportUserData(userID uint) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserData", userID)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportUserData indicates an expected call of ExportUserData.
+func (mr *MockIUserServiceMockRecorder) ExportUserData(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserData", reflect.TypeOf((*MockIUserService)(nil).ExportUserData), userID)
+}
+
 // GetProfile mocks base method.
 func (m *MockIUserService) GetProfile(userID string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -63,8 +93,82 @@ func (mr *MockIUserServiceMockRecorder) GetProfile(userID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProfile", reflect.TypeOf((*MockIUserService)(nil).GetProfile), userID)
 }
 
+// MagicLinkLogin mocks base method.
+func (m *MockIUserService) MagicLinkLogin(token string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MagicLinkLogin", token)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MagicLinkLogin indicates an expected call of MagicLinkLogin.
+func (mr *MockIUserServiceMockRecorder) MagicLinkLogin(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MagicLinkLogin", reflect.TypeOf((*MockIUserService)(nil).MagicLinkLogin), token)
+}
+
+// PatchProfile mocks base method.
+func (m *MockIUserService) PatchProfile(userID, version uint, user dto.UpdateProfileRequest, fields []string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchProfile", userID, version, user, fields)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchProfile indicates an expected call of PatchProfile.
+func (mr *MockIUserServiceMockRecorder) PatchProfile(userID, version, user, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchProfile", reflect.TypeOf((*MockIUserService)(nil).PatchProfile), userID, version, user, fields)
+}
+
+// RefreshToken mocks base method.
+func (m *MockIUserService) RefreshToken(refreshToken string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshToken", refreshToken)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshToken indicates an expected call of RefreshToken.
+func (mr *MockIUserServiceMockRecorder) RefreshToken(refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshToken", reflect.TypeOf((*MockIUserService)(nil).RefreshToken), refreshToken)
+}
+
+// RequestMagicLink mocks base method.
+func (m *MockIUserService) RequestMagicLink(email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestMagicLink", email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestMagicLink indicates an expected call of RequestMagicLink.
+func (mr *MockIUserServiceMockRecorder) RequestMagicLink(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestMagicLink", reflect.TypeOf((*MockIUserService)(nil).RequestMagicLink), email)
+}
+
+// UpdateAvatar mocks base method.
+func (m *MockIUserService) UpdateAvatar(userID uint, image []byte) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAvatar", userID, image)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAvatar indicates an expected call of UpdateAvatar.
+func (mr *MockIUserServiceMockRecorder) UpdateAvatar(userID, image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAvatar", reflect.TypeOf((*MockIUserService)(nil).UpdateAvatar), userID, image)
+}
+
 // UpdateProfile mocks base method.
-func (m *MockIUserService) UpdateProfile(userID uint, user models.UserUpdate) error {
+func (m *MockIUserService) UpdateProfile(userID uint, user dto.UpdateProfileRequest) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateProfile", userID, user)
 	ret0, _ := ret[0].(error)
@@ -78,7 +182,7 @@ func (mr *MockIUserServiceMockRecorder) UpdateProfile(userID, user interface{})
 }
 
 // UserLogin mocks base method.
-func (m *MockIUserService) UserLogin(user *models.UserLogin) (*models.User, error) {
+func (m *MockIUserService) UserLogin(user *dto.LoginRequest) (*models.User, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UserLogin", user)
 	ret0, _ := ret[0].(*models.User)
@@ -93,15 +197,15 @@ func (mr *MockIUserServiceMockRecorder) UserLogin(user interface{}) *gomock.Call
 }
 
 // UserSignUp mocks base method.
-func (m *MockIUserService) UserSignUp(user *models.User) error {
+func (m *MockIUserService) UserSignUp(user *models.User, referralCode string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UserSignUp", user)
+	ret := m.ctrl.Call(m, "UserSignUp", user, referralCode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UserSignUp indicates an expected call of UserSignUp.
-func (mr *MockIUserServiceMockRecorder) UserSignUp(user interface{}) *gomock.Call {
+func (mr *MockIUserServiceMockRecorder) UserSignUp(user, referralCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSignUp", reflect.TypeOf((*MockIUserService)(nil).UserSignUp), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserSignUp", reflect.TypeOf((*MockIUserService)(nil).UserSignUp), user, referralCode)
 }
@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+// MaxAddressesPerUser caps the size of a user's address book.
+const MaxAddressesPerUser = 10
+
+type Address struct {
+	gorm.Model
+	UserID            uint   `gorm:"index;not null" json:"user_id"`
+	Name              string `json:"name"`
+	Phone             string `json:"phone"`
+	Line1             string `json:"line1"`
+	Line2             string `json:"line2"`
+	City              string `json:"city"`
+	State             string `json:"state"`
+	PostalCode        string `json:"postal_code"`
+	Country           string `gorm:"type:char(2)" json:"country"`
+	IsDefaultShipping bool   `gorm:"not null;default:false" json:"is_default_shipping"`
+	IsDefaultBilling  bool   `gorm:"not null;default:false" json:"is_default_billing"`
+}
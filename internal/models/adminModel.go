@@ -0,0 +1,9 @@
+package models
+
+import "gorm.io/gorm"
+
+type Admin struct {
+	gorm.Model
+	Email    string `gorm:"uniqueIndex;not null" json:"email"`
+	Password string `json:"-"`
+}
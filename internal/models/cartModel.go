@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// MaxCartItemQuantity caps the quantity of a single cart line.
+const MaxCartItemQuantity = 10
+
+// Cart belongs either to a user or, for anonymous visitors, to the guest
+// token stored in their signed cart cookie.
+type Cart struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	UserID     *uint      `gorm:"uniqueIndex" json:"user_id"`
+	GuestToken *string    `gorm:"uniqueIndex" json:"-"`
+	Items      []CartItem `gorm:"constraint:OnDelete:CASCADE" json:"items"`
+}
+type CartItem struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	CartID    uint           `gorm:"uniqueIndex:idx_cart_item_variant;not null" json:"cart_id"`
+	VariantID uint           `gorm:"uniqueIndex:idx_cart_item_variant;not null" json:"variant_id"`
+	Variant   ProductVariant `json:"-"`
+	Quantity  int            `gorm:"not null;check:quantity > 0" json:"quantity"`
+	// unit price the user last saw, used to warn about price changes
+	SeenPrice int64 `gorm:"not null" json:"seen_price"`
+}
+
+// CartOwner identifies a cart, or a visitor's other data such as product
+// views, by user ID or, when UserID is zero, by guest token.
+type CartOwner struct {
+	UserID     uint
+	GuestToken string
+}
+
+// CartLine is a cart item revalidated against the current catalog.
+type CartLine struct {
+	Item      CartItem
+	Product   Product
+	Available bool
+	Warnings  []string
+}
@@ -1,9 +1,109 @@
 package models
 
 const (
-	SignupSuccessful       = "user created"
-	UserAlreadyExists      = "user already exists"
-	ErrRequiredFieldsEmpty = "required field"
-	LoginSuccesful         = "Login Succesful"
-	InvalidInput           = "email or password is incorrect"
+	SignupSuccessful              = "user created"
+	UserAlreadyExists             = "user already exists"
+	ErrRequiredFieldsEmpty        = "required field"
+	LoginSuccesful                = "Login Succesful"
+	InvalidInput                  = "email or password is incorrect"
+	UserBlocked                   = "user is blocked"
+	UserDeleted                   = "user is deleted"
+	MagicLinkSent                 = "if the email is registered, a login link has been sent"
+	InvalidMagicLink              = "login link is invalid or has expired"
+	InvalidRefreshToken           = "refresh token is invalid or has expired"
+	TooManyRequests               = "too many requests, please try again later"
+	IncorrectPassword             = "password is incorrect"
+	AccountDeleted                = "account scheduled for deletion"
+	VersionConflict               = "profile was modified by another request"
+	PreconditionRequired          = "If-Match header is required"
+	InvalidImage                  = "unsupported or corrupt image"
+	ImageTooLarge                 = "image dimensions are too large"
+	AddressNotFound               = "address not found"
+	AddressLimitReached           = "address book is full"
+	InvalidPostalCode             = "postal code is not valid for the country"
+	CategoryNotFound              = "category not found"
+	CategoryNotEmpty              = "category has subcategories or products"
+	InvalidParentCategory         = "category cannot be its own ancestor"
+	ProductNotFound               = "product not found"
+	VariantNotFound               = "product variant not found"
+	DuplicateSlug                 = "slug is already in use"
+	DuplicateSKU                  = "sku is already in use"
+	DuplicateProduct              = "slug or sku is already in use"
+	InsufficientStock             = "insufficient stock"
+	InvalidStockAdjustment        = "adjustment would leave less stock than is reserved"
+	ReservationNotActive          = "stock reservation is no longer active"
+	CartItemNotFound              = "item is not in the cart"
+	ProductUnavailable            = "product is no longer available"
+	QuantityLimitExceeded         = "quantity exceeds the per item limit"
+	WishlistItemNotFound          = "item is not in the wishlist"
+	WishlistLimitReached          = "wishlist is full"
+	NotificationNotFound          = "notification not found"
+	InvalidNotificationPreference = "unknown notification type or channel"
+	CartEmpty                     = "cart is empty"
+	CartNeedsReview               = "some cart items changed, review the cart before checking out"
+	OrderNotFound                 = "order not found"
+	InvalidOrderTransition        = "order cannot move to the requested status"
+	OrderNotPayable               = "order is not awaiting payment"
+	PaymentNotFound               = "payment not found"
+	InvalidWebhookSignature       = "invalid webhook signature"
+	InsufficientWalletBalance     = "insufficient wallet balance"
+	InvalidWalletAmount           = "wallet, gift card and loyalty point amounts must be less than the order total"
+	CODNotAvailable               = "cash on delivery is not available for this order"
+	InvalidTopUpAmount            = "top up amount is out of range"
+	CouponNotFound                = "coupon not found"
+	CouponNotActive               = "coupon is not valid at this time"
+	CouponMinOrderValue           = "order value is below the coupon minimum"
+	CouponNotApplicable           = "coupon does not apply to any item in the cart"
+	CouponUsageExceeded           = "coupon usage limit reached"
+	CouponNotStackable            = "coupon cannot be combined with the other applied coupons"
+	TooManyCoupons                = "too many coupons applied"
+	CouponAlreadyApplied          = "coupon is already applied"
+	InvalidCoupon                 = "coupon value does not match its type"
+	DuplicateCouponCode           = "coupon code is already in use"
+	OrderNotCancellable           = "order can no longer be cancelled"
+	ReturnNotFound                = "return request not found"
+	ReturnNotAllowed              = "order is not eligible for return"
+	ReturnWindowExpired           = "return window has expired"
+	InvalidReturnItems            = "return items do not match the order"
+	ReturnQuantityExceeded        = "return quantity exceeds what can still be returned"
+	InvalidReturnTransition       = "return request cannot move to the requested status"
+	RefundExceedsPaid             = "refund exceeds the amount paid"
+	ReviewNotFound                = "review not found"
+	ReviewAlreadyExists           = "you have already reviewed this product"
+	NotVerifiedBuyer              = "only customers who received this product can review it"
+	ReviewImageLimit              = "review already has the maximum number of images"
+	ReviewImageNotFound           = "review image not found"
+	CannotVoteOwnReview           = "you cannot vote on your own review"
+	InvoiceNotFound               = "invoice not found"
+	InvoiceNotAvailable           = "invoice is issued once the order is paid or confirmed"
+	TaxClassNotFound              = "tax class not found"
+	DuplicateTaxClass             = "tax class name is already in use"
+	TaxClassInUse                 = "tax class is assigned to categories"
+	InvalidTaxSlabs               = "tax slabs must start at zero and have distinct thresholds"
+	ShippingMethodNotFound        = "shipping method not found"
+	DuplicateShippingMethod       = "shipping method code is already in use"
+	ShippingNotAvailable          = "shipping method is not available for this address"
+	ShipmentNotFound              = "shipment not found"
+	OrderNotShippable             = "order is not ready to ship"
+	InvalidShipmentWebhook        = "invalid shipment webhook signature"
+	GiftCardNotFound              = "gift card not found"
+	GiftCardNotUsable             = "gift card has expired or is disabled"
+	InsufficientGiftCardBalance   = "insufficient gift card balance"
+	InvalidGiftCardAmount         = "gift card amount is out of range"
+	InvalidGiftCardExpiry         = "gift card expiry must be in the future"
+	GiftCardCodeRequired          = "gift card code is required"
+	InsufficientLoyaltyPoints     = "insufficient loyalty points"
+	LoyaltyRuleNotFound           = "loyalty rule not found"
+	InvalidReferralCode           = "invalid referral code"
+	UnsupportedCurrency           = "unsupported currency"
+	InvalidExchangeRate           = "exchange rate must be a positive decimal with at most 6 decimals"
+	AmountOutOfRange              = "amount is too large to convert"
+	ExchangeRateNotFound          = "exchange rate not found"
+)
+
+// User status values stored in users.status.
+const (
+	StatusActive  = "Active"
+	StatusBlocked = "Blocked"
+	StatusDeleted = "Deleted"
 )
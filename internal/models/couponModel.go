@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Coupon types.
+const (
+	// Value percent off the eligible items, capped at MaxDiscount if set
+	CouponPercentage = "percentage"
+	// Value off the eligible items
+	CouponFlat = "flat"
+	// no shipping charge
+	CouponFreeShipping = "free_shipping"
+	// for every BuyQuantity eligible units, GetQuantity more are free; the
+	// cheapest units are the free ones
+	CouponBuyXGetY = "buy_x_get_y"
+)
+
+// MaxCouponsPerOrder caps how many stackable coupons can be combined.
+const MaxCouponsPerOrder = 3
+
+// Coupon is an admin managed discount code. A coupon without ProductIDs and
+// CategoryIDs applies to every item; otherwise only to items of the listed
+// products or of the listed categories and their subcategories.
+type Coupon struct {
+	gorm.Model
+	Code          string     `gorm:"uniqueIndex;not null" json:"code"`
+	Description   string     `json:"description"`
+	Type          string     `gorm:"type:varchar(20);not null" json:"type"`
+	Value         int64      `gorm:"not null;default:0" json:"value"`
+	MaxDiscount   int64      `gorm:"not null;default:0" json:"max_discount"`
+	BuyQuantity   int        `gorm:"not null;default:0" json:"buy_quantity"`
+	GetQuantity   int        `gorm:"not null;default:0" json:"get_quantity"`
+	MinOrderValue int64      `gorm:"not null;default:0" json:"min_order_value"`
+	StartsAt      *time.Time `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at"`
+	// total redemptions allowed, 0 for unlimited
+	UsageLimit int `gorm:"not null;default:0" json:"usage_limit"`
+	// redemptions allowed per user, 0 for unlimited
+	PerUserLimit int    `gorm:"not null;default:0" json:"per_user_limit"`
+	UsedCount    int    `gorm:"not null;default:0" json:"used_count"`
+	Stackable    bool   `gorm:"not null;default:false" json:"stackable"`
+	IsActive     bool   `gorm:"not null" json:"is_active"`
+	ProductIDs   []uint `gorm:"serializer:json" json:"product_ids"`
+	CategoryIDs  []uint `gorm:"serializer:json" json:"category_ids"`
+}
+
+// CartCoupon is a coupon applied to a cart, to be redeemed at checkout.
+type CartCoupon struct {
+	CartID    uint      `gorm:"primaryKey" json:"cart_id"`
+	Cart      Cart      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CouponID  uint      `gorm:"primaryKey" json:"coupon_id"`
+	Coupon    Coupon    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CouponRedemption records the use of a coupon by an order. Cancelling the
+// order deletes it and gives the use back.
+type CouponRedemption struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	CouponID  uint      `gorm:"not null;uniqueIndex:idx_coupon_redemption_order" json:"coupon_id"`
+	OrderID   uint      `gorm:"not null;uniqueIndex:idx_coupon_redemption_order;index" json:"order_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Code      string    `gorm:"not null" json:"code"`
+	Discount  int64     `gorm:"not null" json:"discount"`
+}
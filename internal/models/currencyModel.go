@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ExchangeRate is an exchange rate set through the admin API. It takes
+// precedence over a rate for the same currency from the rates file. Rate is
+// the value of one unit of the base currency in Currency, in millionths.
+type ExchangeRate struct {
+	Currency  string    `gorm:"type:varchar(3);primaryKey" json:"currency"`
+	Rate      int64     `gorm:"not null;check:rate > 0" json:"rate"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// VariantPrice overrides the converted price of a variant in one currency.
+// Price is in minor units of Currency.
+type VariantPrice struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	CreatedAt time.Time `json:"-"`
+	VariantID uint      `gorm:"uniqueIndex:idx_variant_prices_currency;not null" json:"-"`
+	Currency  string    `gorm:"type:varchar(3);uniqueIndex:idx_variant_prices_currency;not null" json:"currency"`
+	Price     int64     `gorm:"not null;check:price > 0" json:"price"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Gift card value limits in minor units, and how long a card is valid when
+// no expiry is given.
+const (
+	MinGiftCardAmount = 100_00
+	MaxGiftCardAmount = 50000_00
+	GiftCardValidity  = 365 * 24 * time.Hour
+)
+
+// How a gift card came about.
+const (
+	GiftCardPurchased = "purchased"
+	GiftCardIssued    = "issued"
+)
+
+// Gift card transaction types.
+const (
+	GiftCardLoad   = "load"
+	GiftCardRedeem = "redeem"
+	GiftCardRefund = "refund"
+)
+
+// GiftCard is a stored value card redeemable against orders until it
+// expires. Only a hash of its code is stored; the code itself is shown once
+// when the card is issued and emailed to the recipient.
+//
+// A purchased card is created inactive and without a code when the purchase
+// payment starts; the code is generated and the card activated once the
+// payment succeeds. The code is then kept in PendingCode until it has been
+// emailed to the recipient.
+type GiftCard struct {
+	gorm.Model
+	CodeHash *string `gorm:"uniqueIndex" json:"-"`
+	// last characters of the code, to tell cards apart
+	CodeHint string `gorm:"type:varchar(4);not null;default:''" json:"code_hint"`
+	Source   string `gorm:"type:varchar(20);not null" json:"source"`
+	Amount   int64  `gorm:"not null;check:amount > 0" json:"amount"`
+	Balance  int64  `gorm:"not null;default:0;check:balance >= 0" json:"balance"`
+	Currency string `gorm:"type:char(3);not null" json:"currency"`
+	// set on activation for purchased cards
+	ExpiresAt *time.Time `json:"expires_at"`
+	IsActive  bool       `gorm:"not null;default:false" json:"is_active"`
+	// the buyer of a purchased card
+	UserID *uint `gorm:"index" json:"user_id,omitempty"`
+	// the purchase payment of a purchased card
+	PaymentID      *uint  `gorm:"uniqueIndex" json:"payment_id,omitempty"`
+	RecipientName  string `json:"recipient_name"`
+	RecipientEmail string `json:"recipient_email"`
+	Message        string `json:"message"`
+	// the code of an activated purchased card not yet emailed
+	PendingCode *string `json:"-"`
+}
+
+// Usable reports whether the card can be redeemed at now.
+func (g *GiftCard) Usable(now time.Time) bool {
+	return g.IsActive && g.ExpiresAt != nil && now.Before(*g.ExpiresAt)
+}
+
+// GiftCardTransaction is one change to a gift card balance. Amount is
+// positive for loads and refunds and negative for redemptions.
+type GiftCardTransaction struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	GiftCardID   uint      `gorm:"index;not null" json:"gift_card_id"`
+	Type         string    `gorm:"type:varchar(20);not null" json:"type"`
+	Amount       int64     `gorm:"not null" json:"amount"`
+	BalanceAfter int64     `gorm:"not null" json:"balance_after"`
+	OrderID      *uint     `gorm:"index" json:"order_id,omitempty"`
+	Reference    string    `json:"reference"`
+}
+
+// codeAlphabet leaves out characters that are easily confused, such as 0
+// and O. Its 32 characters give 5 bits of entropy each, 80 bits to a 16
+// character gift card code.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const giftCardCodeLength = 16
+
+// randomCode returns length random characters of codeAlphabet.
+func randomCode(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i, v := range b {
+		// 256 is a multiple of 32, so every character is equally likely
+		b[i] = codeAlphabet[int(v)%len(codeAlphabet)]
+	}
+	return string(b), nil
+}
+
+// GenerateGiftCardCode returns a random code formatted in groups of four,
+// e.g. "K7QX-3MNP-W9TD-HZ4R".
+func GenerateGiftCardCode() (string, error) {
+	raw, err := randomCode(giftCardCodeLength)
+	if err != nil {
+		return "", err
+	}
+	var code strings.Builder
+	for i := 0; i < len(raw); i += 4 {
+		if i > 0 {
+			code.WriteByte('-')
+		}
+		code.WriteString(raw[i : i+4])
+	}
+	return code.String(), nil
+}
+
+// NormalizeGiftCardCode uppercases the code and strips separators, so codes
+// match however they are typed.
+func NormalizeGiftCardCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == ' ':
+			return -1
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		}
+		return r
+	}, code)
+}
+
+// HashGiftCardCode returns the stored hash of a code.
+func HashGiftCardCode(code string) string {
+	sum := sha256.Sum256([]byte(NormalizeGiftCardCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// GiftCardCodeHint returns the last four characters of a code.
+func GiftCardCodeHint(code string) string {
+	normalized := NormalizeGiftCardCode(code)
+	return normalized[max(0, len(normalized)-4):]
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateGiftCardCode(t *testing.T) {
+	code, err := GenerateGiftCardCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 19)
+	groups := strings.Split(code, "-")
+	assert.Len(t, groups, 4)
+	for _, group := range groups {
+		assert.Len(t, group, 4)
+		for _, r := range group {
+			assert.True(t, strings.ContainsRune(codeAlphabet, r), "unexpected character %q", r)
+		}
+	}
+	other, err := GenerateGiftCardCode()
+	assert.NoError(t, err)
+	assert.NotEqual(t, code, other)
+}
+
+func TestGiftCardCodeNormalization(t *testing.T) {
+	assert.Equal(t, "K7QX3MNPW9TDHZ4R", NormalizeGiftCardCode("k7qx-3mnp w9td-hz4r"))
+	assert.Equal(t, HashGiftCardCode("K7QX-3MNP-W9TD-HZ4R"), HashGiftCardCode("k7qx3mnpw9tdhz4r"))
+	assert.NotEqual(t, HashGiftCardCode("K7QX-3MNP-W9TD-HZ4R"), HashGiftCardCode("K7QX-3MNP-W9TD-HZ4S"))
+	assert.Equal(t, "HZ4R", GiftCardCodeHint("K7QX-3MNP-W9TD-hz4r"))
+}
+
+func TestGiftCardUsable(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+	earlier := now.Add(-time.Hour)
+	assert.True(t, (&GiftCard{IsActive: true, ExpiresAt: &later}).Usable(now))
+	assert.False(t, (&GiftCard{IsActive: true, ExpiresAt: &earlier}).Usable(now), "expired")
+	assert.False(t, (&GiftCard{IsActive: false, ExpiresAt: &later}).Usable(now), "disabled")
+	assert.False(t, (&GiftCard{IsActive: true}).Usable(now), "not activated")
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stock reservation statuses.
+const (
+	ReservationActive    = "active"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Inventory movement types.
+const (
+	MovementInitial    = "initial"
+	MovementAdjustment = "adjustment"
+	MovementReserve    = "reserve"
+	MovementRelease    = "release"
+	MovementExpire     = "expire"
+	MovementSale       = "sale"
+	MovementReturn     = "return"
+)
+
+// StockReservation holds back stock of a variant for a limited time, e.g.
+// between checkout and payment, without removing it from on hand stock.
+type StockReservation struct {
+	gorm.Model
+	VariantID uint      `gorm:"index;not null" json:"variant_id"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	Quantity  int       `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Status    string    `gorm:"type:varchar(10);index;not null;default:'active'" json:"status"`
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+	// free form reference to what the stock is held for, e.g. an order number
+	Reference string `gorm:"index" json:"reference"`
+}
+
+// InventoryMovement is an append-only ledger row describing one change to the
+// on hand or reserved quantity of a variant.
+type InventoryMovement struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	VariantID     uint      `gorm:"index;not null" json:"variant_id"`
+	Type          string    `gorm:"type:varchar(20);not null" json:"type"`
+	StockDelta    int       `gorm:"not null" json:"stock_delta"`
+	ReservedDelta int       `gorm:"not null" json:"reserved_delta"`
+	StockAfter    int       `gorm:"not null" json:"stock_after"`
+	ReservedAfter int       `gorm:"not null" json:"reserved_after"`
+	ReservationID *uint     `gorm:"index" json:"reservation_id"`
+	Reason        string    `json:"reason"`
+}
+
+// StockError reports that a variant does not have enough available stock.
+// Its message is InsufficientStock so it can be compared like other errors.
+type StockError struct {
+	SKU string
+}
+
+func (e *StockError) Error() string {
+	return InsufficientStock
+}
+
+// ReservationItem requests Quantity units of a variant.
+type ReservationItem struct {
+	VariantID uint
+	Quantity  int
+}
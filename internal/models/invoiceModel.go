@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// InvoiceLocation is the time zone invoice dates and financial years are
+// reckoned in.
+var InvoiceLocation = time.FixedZone("IST", 5*60*60+30*60)
+
+// FinancialYear returns the Indian financial year, April to March, that t
+// falls in, e.g. "2024-25" for 31 March 2025.
+func FinancialYear(t time.Time) string {
+	t = t.In(InvoiceLocation)
+	start := t.Year()
+	if t.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%d-%02d", start, (start+1)%100)
+}
+
+// InvoiceNumber formats the sequence number of an invoice within its
+// financial year, e.g. INV-2024-25-000042.
+func InvoiceNumber(financialYear string, sequence int) string {
+	return fmt.Sprintf("INV-%s-%06d", financialYear, sequence)
+}
+
+// InvoiceSequence holds the last invoice number issued in a financial year.
+// It is incremented in the same transaction that creates the invoice, so
+// numbers are sequential without gaps.
+type InvoiceSequence struct {
+	FinancialYear string `gorm:"primaryKey;type:varchar(7)"`
+	LastNumber    int    `gorm:"not null"`
+}
+
+// InvoiceKey returns the storage key of an invoice PDF. The random part keeps
+// the file private where storage is served publicly.
+func InvoiceKey(financialYear string, number string) (string, error) {
+	suffix, err := randomCode(16)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("invoices/%s/%s-%s.pdf", financialYear, number, suffix), nil
+}
+
+// Invoice is the tax invoice issued for an order when it is paid or
+// confirmed. The rendered PDF is stored under Key after the invoice is
+// recorded and never changes once issued.
+type Invoice struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	OrderID       uint      `gorm:"uniqueIndex;not null" json:"order_id"`
+	UserID        uint      `gorm:"index;not null" json:"user_id"`
+	Number        string    `gorm:"uniqueIndex;not null" json:"number"`
+	FinancialYear string    `gorm:"type:varchar(7);uniqueIndex:idx_invoice_sequence;not null" json:"financial_year"`
+	Sequence      int       `gorm:"uniqueIndex:idx_invoice_sequence;not null" json:"sequence"`
+	IssuedAt      time.Time `gorm:"not null" json:"issued_at"`
+	Total         int64     `gorm:"not null" json:"total"`
+	Key           string    `gorm:"not null" json:"-"`
+	// set until the PDF has been stored under Key
+	StorePending bool `gorm:"index;not null;default:false" json:"-"`
+}
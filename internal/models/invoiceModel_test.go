@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinancialYear(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		year string
+	}{
+		{time.Date(2024, time.April, 1, 0, 0, 0, 0, InvoiceLocation), "2024-25"},
+		{time.Date(2025, time.March, 31, 23, 59, 0, 0, InvoiceLocation), "2024-25"},
+		{time.Date(2025, time.January, 15, 12, 0, 0, 0, InvoiceLocation), "2024-25"},
+		{time.Date(2099, time.December, 1, 0, 0, 0, 0, InvoiceLocation), "2099-00"},
+		// 31 March 19:00 UTC is already 1 April in India
+		{time.Date(2025, time.March, 31, 19, 0, 0, 0, time.UTC), "2025-26"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.year, FinancialYear(test.at), test.at.String())
+	}
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	assert.Equal(t, "INV-2024-25-000042", InvoiceNumber("2024-25", 42))
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// LoyaltyPointValue is what one point is worth when redeemed, in minor
+// units, and LoyaltyPointsValidity how long points last once earned. Points
+// expiring within LoyaltyExpiryNotice are pointed out to the user.
+const (
+	LoyaltyPointValue     = 100
+	LoyaltyPointsValidity = 365 * 24 * time.Hour
+	LoyaltyExpiryNotice   = 30 * 24 * time.Hour
+	// rates are points per this spend, i.e. per 100 rupees
+	LoyaltyRateSpend = 100_00
+)
+
+// Loyalty transaction types.
+const (
+	LoyaltyEarn    = "earn"
+	LoyaltyRedeem  = "redeem"
+	LoyaltyExpire  = "expire"
+	LoyaltyReverse = "reverse"
+	LoyaltyRestore = "restore"
+)
+
+// LoyaltyRule sets the points earned per 100 rupees spent on products of a
+// category and its subcategories, unless one of them has its own rule. The
+// rule with CategoryID zero applies to products in no such category; without
+// it those earn nothing. A rate of zero excludes a category.
+type LoyaltyRule struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	CategoryID uint      `gorm:"uniqueIndex;not null;default:0" json:"category_id"`
+	Rate       int64     `gorm:"not null;check:rate >= 0" json:"rate"`
+}
+
+// LoyaltyPoints returns the points earned on amount at rate.
+func LoyaltyPoints(amount int64, rate int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return amount * rate / LoyaltyRateSpend
+}
+
+// LoyaltyAccount holds the user's points balance, which always equals the
+// remaining points of the user's unexpired lots.
+type LoyaltyAccount struct {
+	UserID    uint      `gorm:"primarykey" json:"user_id"`
+	Balance   int64     `gorm:"not null;default:0;check:balance >= 0" json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoyaltyTransaction is one change to a points balance. Points is positive
+// for earned and restored points and negative otherwise. Credits form lots
+// that expire at ExpiresAt; Remaining is what is left of a lot after
+// redemptions, reversals and expiry, which use up the lots expiring first.
+type LoyaltyTransaction struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UserID       uint       `gorm:"index;not null" json:"user_id"`
+	Type         string     `gorm:"type:varchar(20);not null" json:"type"`
+	Points       int64      `gorm:"not null" json:"points"`
+	BalanceAfter int64      `gorm:"not null" json:"balance_after"`
+	Remaining    int64      `gorm:"not null;default:0;check:remaining >= 0" json:"remaining,omitempty"`
+	ExpiresAt    *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	OrderID      *uint      `gorm:"index" json:"order_id,omitempty"`
+	Reference    string     `json:"reference"`
+}
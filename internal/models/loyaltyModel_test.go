@@ -0,0 +1,15 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoyaltyPoints(t *testing.T) {
+	assert.Equal(t, int64(5), LoyaltyPoints(500_00, 1), "one point per 100 rupees")
+	assert.Equal(t, int64(4), LoyaltyPoints(499_99, 1), "partial hundreds earn nothing")
+	assert.Equal(t, int64(15), LoyaltyPoints(500_00, 3))
+	assert.Equal(t, int64(0), LoyaltyPoints(500_00, 0), "excluded category")
+	assert.Equal(t, int64(0), LoyaltyPoints(-100_00, 1))
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Notification types. Users can opt out of each type per channel.
+const (
+	NotificationPriceDrop   = "price_drop"
+	NotificationBackInStock = "back_in_stock"
+	NotificationShipment    = "shipment"
+)
+
+// NotificationTypes lists every type a user can set preferences for.
+var NotificationTypes = []string{NotificationPriceDrop, NotificationBackInStock, NotificationShipment}
+
+// Notification channels.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+var NotificationChannels = []string{ChannelInApp, ChannelEmail}
+
+// Notification is an in-app message shown in the user's notification list.
+type Notification struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	Type      string     `gorm:"type:varchar(30);not null" json:"type"`
+	Title     string     `gorm:"not null" json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// NotificationOptOut records that the user does not want notifications of a
+// type on a channel. Everything without a row is sent.
+type NotificationOptOut struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	Type      string    `gorm:"primaryKey;type:varchar(30)" json:"type"`
+	Channel   string    `gorm:"primaryKey;type:varchar(10)" json:"channel"`
+	CreatedAt time.Time `json:"created_at"`
+}
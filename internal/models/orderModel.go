@@ -0,0 +1,148 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Order statuses.
+const (
+	OrderPending = "pending"
+	OrderPaid    = "paid"
+	// accepted without prepayment, e.g. cash on delivery
+	OrderConfirmed = "confirmed"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCancelled = "cancelled"
+	OrderReturned  = "returned"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Cancelled and returned orders are final.
+var orderTransitions = map[string][]string{
+	OrderPending:   {OrderPaid, OrderConfirmed, OrderCancelled},
+	OrderPaid:      {OrderShipped, OrderCancelled},
+	OrderConfirmed: {OrderShipped, OrderCancelled},
+	OrderShipped:   {OrderDelivered},
+	OrderDelivered: {OrderReturned},
+}
+
+// CanTransition reports whether an order in status from may move to status to.
+func CanTransition(from, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Who changed an order, recorded in its history.
+const (
+	ActorUser   = "user"
+	ActorAdmin  = "admin"
+	ActorSystem = "system"
+)
+
+// OrderAddress is a copy of an address taken at checkout, so later edits to
+// the address book do not change placed orders.
+type OrderAddress struct {
+	Name       string `json:"name"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	PostalCode string `json:"postal_code"`
+	Country    string `gorm:"type:char(2)" json:"country"`
+}
+
+func NewOrderAddress(address *Address) OrderAddress {
+	return OrderAddress{
+		Name:       address.Name,
+		Phone:      address.Phone,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+// Order is created at checkout from a snapshot of the cart. Apart from its
+// status and status timestamps an order is never updated. Amounts are in
+// minor units; Total = Subtotal - DiscountTotal + TaxTotal + ShippingTotal,
+// except that TaxTotal is not added when TaxInclusive is set, since the
+// prices already contain it.
+type Order struct {
+	gorm.Model
+	OrderNumber     string       `gorm:"uniqueIndex;not null" json:"order_number"`
+	UserID          uint         `gorm:"index;not null" json:"user_id"`
+	Status          string       `gorm:"type:varchar(20);index;not null;default:'pending'" json:"status"`
+	ShippingAddress OrderAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
+	BillingAddress  OrderAddress `gorm:"embedded;embeddedPrefix:billing_" json:"billing_address"`
+	Subtotal        int64        `gorm:"not null" json:"subtotal"`
+	DiscountTotal   int64        `gorm:"not null;default:0" json:"discount_total"`
+	TaxTotal        int64        `gorm:"not null;default:0" json:"tax_total"`
+	TaxInclusive    bool         `gorm:"not null;default:false" json:"tax_inclusive"`
+	ShippingTotal   int64        `gorm:"not null;default:0" json:"shipping_total"`
+	Total           int64        `gorm:"not null;check:total >= 0" json:"total"`
+	Items           []OrderItem  `json:"items"`
+	CouponCodes     []string     `gorm:"serializer:json" json:"coupon_codes"`
+	// nil when no shipping methods were configured at checkout
+	ShippingMethodID *uint  `json:"shipping_method_id"`
+	ShippingMethod   string `json:"shipping_method"`
+	// total weight of the items in grams
+	Weight int `gorm:"not null;default:0" json:"weight"`
+	// loyalty points credited when the order is delivered
+	LoyaltyPoints int64 `gorm:"not null;default:0" json:"loyalty_points"`
+	// unpaid orders are cancelled and their stock released after this time
+	ExpiresAt   time.Time  `gorm:"index" json:"expires_at"`
+	PaidAt      *time.Time `json:"paid_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	ShippedAt   *time.Time `json:"shipped_at"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CancelledAt *time.Time `json:"cancelled_at"`
+	ReturnedAt  *time.Time `json:"returned_at"`
+}
+
+// OrderItem is an immutable copy of a cart line at checkout.
+type OrderItem struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	OrderID     uint      `gorm:"index;not null" json:"order_id"`
+	ProductID   uint      `gorm:"index;not null" json:"product_id"`
+	VariantID   uint      `gorm:"index;not null" json:"variant_id"`
+	ProductName string    `gorm:"not null" json:"product_name"`
+	SKU         string    `gorm:"not null" json:"sku"`
+	Size        string    `json:"size"`
+	Colour      string    `json:"colour"`
+	UnitPrice   int64     `gorm:"not null" json:"unit_price"`
+	Quantity    int       `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Discount    int64     `gorm:"not null;default:0" json:"discount"`
+	// rate in basis points, split into CGST and SGST or charged as IGST
+	TaxRate       int   `gorm:"not null;default:0" json:"tax_rate"`
+	CGST          int64 `gorm:"not null;default:0" json:"cgst"`
+	SGST          int64 `gorm:"not null;default:0" json:"sgst"`
+	IGST          int64 `gorm:"not null;default:0" json:"igst"`
+	Tax           int64 `gorm:"not null;default:0" json:"tax"`
+	Total         int64 `gorm:"not null" json:"total"`
+	LoyaltyPoints int64 `gorm:"not null;default:0" json:"loyalty_points"`
+	ReservationID *uint `json:"-"`
+}
+
+// OrderHistory records one status change of an order, or of one of its
+// return requests when ReturnRequestID is set. FromStatus is empty for the
+// first entry.
+type OrderHistory struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	OrderID         uint      `gorm:"index;not null" json:"order_id"`
+	ReturnRequestID *uint     `json:"return_request_id,omitempty"`
+	FromStatus      string    `gorm:"type:varchar(20)" json:"from_status"`
+	ToStatus        string    `gorm:"type:varchar(20);not null" json:"to_status"`
+	Actor           string    `gorm:"type:varchar(20);not null" json:"actor"`
+	Note            string    `json:"note,omitempty"`
+}
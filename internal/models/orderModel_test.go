@@ -0,0 +1,34 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderShipped, false},
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCancelled, true},
+		{OrderPaid, OrderPending, false},
+		{OrderPending, OrderConfirmed, true},
+		{OrderConfirmed, OrderShipped, true},
+		{OrderConfirmed, OrderCancelled, true},
+		{OrderConfirmed, OrderPaid, false},
+		{OrderShipped, OrderDelivered, true},
+		{OrderShipped, OrderCancelled, false},
+		{OrderDelivered, OrderReturned, true},
+		{OrderCancelled, OrderPaid, false},
+		{OrderReturned, OrderDelivered, false},
+		{"unknown", OrderPaid, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, CanTransition(test.from, test.to), "%s -> %s", test.from, test.to)
+	}
+}
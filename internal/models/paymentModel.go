@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultCurrency is the currency orders are charged in.
+const DefaultCurrency = "INR"
+
+// Payment methods a customer can choose at payment.
+const (
+	PaymentMethodGateway  = "gateway"
+	PaymentMethodCOD      = "cod"
+	PaymentMethodWallet   = "wallet"
+	PaymentMethodGiftCard = "gift_card"
+	PaymentMethodLoyalty  = "loyalty"
+)
+
+// What a payment is for.
+const (
+	PaymentForOrder       = "order"
+	PaymentForWalletTopUp = "wallet_topup"
+	PaymentForGiftCard    = "gift_card"
+)
+
+// Payment statuses.
+const (
+	PaymentCreated    = "created"
+	PaymentAuthorized = "authorized"
+	PaymentSucceeded  = "succeeded"
+	PaymentFailed     = "failed"
+	PaymentRefunded   = "refunded"
+)
+
+// Payment is one attempt to pay an order, to top up a wallet or to buy a gift
+// card, through a payment provider. ProviderRef is the provider's ID for the
+// payment, e.g. a Stripe payment intent. Cash on delivery, wallet, gift card
+// and loyalty point payments use "cod", "wallet", "gift_card" and "loyalty"
+// as provider.
+type Payment struct {
+	gorm.Model
+	Purpose        string     `gorm:"type:varchar(20);not null;default:'order'" json:"purpose"`
+	OrderID        uint       `gorm:"index;not null" json:"order_id"`
+	UserID         uint       `gorm:"index;not null" json:"user_id"`
+	Provider       string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_payment_provider_ref" json:"provider"`
+	ProviderRef    string     `gorm:"not null;uniqueIndex:idx_payment_provider_ref" json:"provider_ref"`
+	ClientSecret   string     `json:"-"`
+	Amount         int64      `gorm:"not null;check:amount > 0" json:"amount"`
+	Currency       string     `gorm:"type:char(3);not null" json:"currency"`
+	Status         string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	FailureReason  string     `json:"failure_reason"`
+	RefundedAmount int64      `gorm:"not null;default:0" json:"refunded_amount"`
+	CapturedAt     *time.Time `json:"captured_at"`
+	// part of the order total paid from the wallet, debited when this
+	// payment succeeds
+	WalletAmount int64 `gorm:"not null;default:0" json:"wallet_amount"`
+	// the gift card redeemed by this payment, or of which GiftCardAmount
+	// is redeemed when this gateway payment succeeds
+	GiftCardID     *uint `gorm:"index" json:"gift_card_id,omitempty"`
+	GiftCardAmount int64 `gorm:"not null;default:0" json:"gift_card_amount"`
+	// loyalty points redeemed by this payment, or redeemed when this
+	// gateway payment succeeds
+	LoyaltyPoints int64 `gorm:"not null;default:0" json:"loyalty_points"`
+}
+
+// PaymentEvent records a processed webhook so redelivered events are ignored.
+type PaymentEvent struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	Provider  string `gorm:"type:varchar(20);not null;uniqueIndex:idx_payment_event"`
+	EventID   string `gorm:"not null;uniqueIndex:idx_payment_event"`
+	Type      string `gorm:"not null"`
+	IntentID  string `gorm:"index"`
+}
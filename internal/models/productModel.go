@@ -0,0 +1,100 @@
+package models
+
+import "gorm.io/gorm"
+
+// ProductRating aggregates the published reviews of a product. It is kept
+// up to date incrementally as reviews are added, edited and moderated.
+type ProductRating struct {
+	Count   int     `gorm:"not null;default:0" json:"count"`
+	Sum     int64   `gorm:"not null;default:0" json:"-"`
+	Average float64 `gorm:"not null;default:0" json:"average"`
+	// number of reviews with 1 to 5 stars
+	Stars1 int `gorm:"not null;default:0" json:"stars_1"`
+	Stars2 int `gorm:"not null;default:0" json:"stars_2"`
+	Stars3 int `gorm:"not null;default:0" json:"stars_3"`
+	Stars4 int `gorm:"not null;default:0" json:"stars_4"`
+	Stars5 int `gorm:"not null;default:0" json:"stars_5"`
+}
+
+// Category is a node of the category tree. Root categories have no parent.
+// Slugs are only unique among categories that are not deleted, so a deleted
+// category's slug can be reused.
+type Category struct {
+	gorm.Model
+	Name        string `gorm:"not null" json:"name"`
+	Slug        string `gorm:"uniqueIndex:idx_categories_live_slug,where:deleted_at IS NULL;not null" json:"slug"`
+	Description string `json:"description"`
+	ParentID    *uint  `gorm:"index" json:"parent_id"`
+	TaxClassID  *uint  `gorm:"index" json:"tax_class_id"`
+}
+
+// Product slugs, like variant SKUs, are only unique among records that are
+// not deleted.
+type Product struct {
+	gorm.Model
+	Name        string           `gorm:"not null" json:"name"`
+	Slug        string           `gorm:"uniqueIndex:idx_products_live_slug,where:deleted_at IS NULL;not null" json:"slug"`
+	Description string           `json:"description"`
+	Brand       string           `gorm:"index" json:"brand"`
+	CategoryID  uint             `gorm:"index;not null" json:"category_id"`
+	Category    Category         `json:"category"`
+	IsActive    bool             `gorm:"not null;default:true" json:"is_active"`
+	Variants    []ProductVariant `json:"variants"`
+	Rating      ProductRating    `gorm:"embedded;embeddedPrefix:rating_" json:"rating"`
+}
+
+// ProductVariant is a sellable unit of a product identified by its SKU.
+// Prices are integer amounts in the minor unit of the currency (paise).
+// Prices lists the prices set for other currencies, which are otherwise
+// converted from Price at the exchange rate.
+//
+// Stock is the quantity on hand and Reserved the part of it held by active
+// reservations. Both are only changed through the inventory repository, which
+// records every change as an InventoryMovement.
+type ProductVariant struct {
+	gorm.Model
+	ProductID uint   `gorm:"index;not null" json:"product_id"`
+	SKU       string `gorm:"uniqueIndex:idx_product_variants_live_sku,where:deleted_at IS NULL;not null" json:"sku"`
+	Size      string `gorm:"index" json:"size"`
+	Colour    string `gorm:"index" json:"colour"`
+	Price     int64  `gorm:"not null;check:price > 0" json:"price"`
+	// shipping weight in grams
+	Weight   int `gorm:"not null;default:0" json:"weight"`
+	Stock    int `gorm:"not null;default:0;check:stock >= 0" json:"stock"`
+	Reserved int `gorm:"not null;default:0;check:reserved >= 0 AND reserved <= stock" json:"reserved"`
+	// an alert is sent once available stock drops to this level
+	LowStockThreshold int            `gorm:"not null;default:5" json:"low_stock_threshold"`
+	LowStockAlerted   bool           `gorm:"not null;default:false" json:"-"`
+	Prices            []VariantPrice `gorm:"foreignKey:VariantID" json:"prices,omitempty"`
+}
+
+// Available returns the stock that can still be reserved.
+func (v *ProductVariant) Available() int {
+	return v.Stock - v.Reserved
+}
+
+// Product list sort orders. Search results are sorted by relevance by
+// default.
+const (
+	SortRelevance = "relevance"
+	SortNewest    = "newest"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortName      = "name"
+)
+
+// ProductFilter selects products for the public catalog listing. Zero values
+// mean no filtering on that field.
+type ProductFilter struct {
+	CategoryID uint
+	Brand      string
+	MinPrice   int64
+	MaxPrice   int64
+	Size       string
+	Colour     string
+	Sort       string
+	// include inactive products, for the admin listing
+	IncludeInactive bool
+	Page            int
+	Limit           int
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// MaxRecentlyViewed caps the recently viewed list.
+const MaxRecentlyViewed = 50
+
+// ProductView records that a user, or a guest identified by their cart
+// token, viewed a product. There is one row per viewer and product, updated
+// on every view.
+type ProductView struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	UserID     *uint     `gorm:"uniqueIndex:idx_product_view_user" json:"user_id"`
+	GuestToken *string   `gorm:"uniqueIndex:idx_product_view_guest" json:"-"`
+	ProductID  uint      `gorm:"not null;index;uniqueIndex:idx_product_view_user;uniqueIndex:idx_product_view_guest" json:"product_id"`
+	ViewCount  int       `gorm:"not null;default:1" json:"view_count"`
+	ViewedAt   time.Time `gorm:"not null;index" json:"viewed_at"`
+}
+
+// Recommendation kinds.
+const (
+	// products often ordered together with the product
+	RecommendationBoughtTogether = "bought_together"
+	// products often viewed by the viewers of the product
+	RecommendationAlsoViewed = "also_viewed"
+)
+
+// ProductRecommendation links a product to a recommended one. The rows are
+// recomputed periodically from order and view co-occurrence; Score is the
+// number of orders or viewers the two products share.
+type ProductRecommendation struct {
+	ProductID     uint      `gorm:"primaryKey;autoIncrement:false"`
+	Kind          string    `gorm:"primaryKey"`
+	RecommendedID uint      `gorm:"primaryKey;autoIncrement:false"`
+	Score         int64     `gorm:"not null"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
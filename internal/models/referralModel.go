@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Referral statuses. A pending referral is rewarded once the referred user's
+// first order is delivered, and reversed if that order is then returned in
+// full; rejected referrals failed the fraud checks at signup and are never
+// rewarded.
+const (
+	ReferralPending  = "pending"
+	ReferralRewarded = "rewarded"
+	ReferralReversed = "reversed"
+	ReferralRejected = "rejected"
+)
+
+// Why the fraud checks rejected a referral.
+const (
+	ReferralSamePhone   = "same phone number as the referrer"
+	ReferralPhoneReused = "phone number already registered"
+	ReferralSameIP      = "signed up from the IP address of the referrer or an earlier referral"
+	ReferralSameDevice  = "signed up from the device of the referrer or an earlier referral"
+)
+
+const referralCodeLength = 8
+
+// Referral records that the user signed up with the referral code of
+// ReferrerID. The rewards are fixed at signup.
+type Referral struct {
+	gorm.Model
+	// the referred user
+	UserID         uint   `gorm:"uniqueIndex;not null" json:"user_id"`
+	User           User   `json:"-"`
+	ReferrerID     uint   `gorm:"index;not null" json:"referrer_id"`
+	Status         string `gorm:"type:varchar(20);index;not null" json:"status"`
+	RejectReason   string `json:"reject_reason,omitempty"`
+	ReferrerReward int64  `gorm:"not null;default:0" json:"referrer_reward"`
+	RefereeReward  int64  `gorm:"not null;default:0" json:"referee_reward"`
+	// the delivered order that earned the rewards
+	OrderID    *uint      `json:"order_id,omitempty"`
+	RewardedAt *time.Time `json:"rewarded_at"`
+	ReversedAt *time.Time `json:"reversed_at,omitempty"`
+}
+
+// GenerateReferralCode returns a random code, e.g. "K7QX3MNP".
+func GenerateReferralCode() (string, error) {
+	return randomCode(referralCodeLength)
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReturnWindow is how long after delivery items can be returned.
+const ReturnWindow = 7 * 24 * time.Hour
+
+// Return request statuses.
+const (
+	ReturnRequested = "requested"
+	// accepted, pickup scheduled
+	ReturnApproved = "approved"
+	ReturnRejected = "rejected"
+	// collected from the customer and restocked
+	ReturnPickedUp = "picked_up"
+	ReturnRefunded = "refunded"
+)
+
+// returnTransitions lists the statuses a return request may move to from
+// each status. Rejected and refunded requests are final.
+var returnTransitions = map[string][]string{
+	ReturnRequested: {ReturnApproved, ReturnRejected},
+	ReturnApproved:  {ReturnPickedUp, ReturnRejected},
+	ReturnPickedUp:  {ReturnRefunded},
+}
+
+// CanTransitionReturn reports whether a return request in status from may
+// move to status to.
+func CanTransitionReturn(from, to string) bool {
+	for _, status := range returnTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Where a refund is paid to.
+const (
+	// back to the payment method used; cash on delivery is refunded to the wallet
+	RefundToOriginal = "original"
+	RefundToWallet   = "wallet"
+)
+
+// ReturnRequest asks to return some or all items of a delivered order.
+// RefundAmount is fixed when the request is made from the item totals paid.
+type ReturnRequest struct {
+	gorm.Model
+	OrderID      uint         `gorm:"index;not null" json:"order_id"`
+	UserID       uint         `gorm:"index;not null" json:"user_id"`
+	Status       string       `gorm:"type:varchar(20);index;not null" json:"status"`
+	Reason       string       `gorm:"type:varchar(30);not null" json:"reason"`
+	Comment      string       `json:"comment"`
+	RefundMethod string       `gorm:"type:varchar(20);not null" json:"refund_method"`
+	RefundAmount int64        `gorm:"not null" json:"refund_amount"`
+	AdminNote    string       `json:"admin_note"`
+	Items        []ReturnItem `json:"items"`
+	ApprovedAt   *time.Time   `json:"approved_at"`
+	RejectedAt   *time.Time   `json:"rejected_at"`
+	PickedUpAt   *time.Time   `json:"picked_up_at"`
+	RefundedAt   *time.Time   `json:"refunded_at"`
+}
+
+// ReturnItem is a quantity of one order item included in a return request.
+type ReturnItem struct {
+	ID              uint  `gorm:"primarykey" json:"id"`
+	ReturnRequestID uint  `gorm:"index;not null" json:"return_request_id"`
+	OrderItemID     uint  `gorm:"index;not null" json:"order_item_id"`
+	VariantID       uint  `gorm:"not null" json:"variant_id"`
+	Quantity        int   `gorm:"not null;check:quantity > 0" json:"quantity"`
+	Amount          int64 `gorm:"not null" json:"amount"`
+}
+
+// Refund statuses. Only gateway refunds are ever pending: they are recorded
+// first and sent to the provider once the recording transaction has
+// committed, so a rollback cannot leave money refunded without a record.
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+)
+
+// Refund is money returned for an order, either through the payment it was
+// paid with, to the gift card or loyalty points it was paid with or to the
+// wallet. A refund of several payments is recorded as
+// one row per payment.
+type Refund struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	OrderID         uint      `gorm:"index;not null" json:"order_id"`
+	UserID          uint      `gorm:"index;not null" json:"user_id"`
+	ReturnRequestID *uint     `gorm:"index" json:"return_request_id,omitempty"`
+	PaymentID       uint      `gorm:"index;not null" json:"payment_id"`
+	// gateway, gift_card, loyalty or wallet
+	Method string `gorm:"type:varchar(20);not null" json:"method"`
+	Amount int64  `gorm:"not null;check:amount > 0" json:"amount"`
+	// the provider's refund ID, or the wallet, gift card or loyalty
+	// transaction ID
+	Reference string `json:"reference"`
+	Status    string `gorm:"type:varchar(20);index;not null;default:'succeeded'" json:"status"`
+	// the provider's error on the last attempt of a pending refund
+	FailureReason string   `json:"failure_reason,omitempty"`
+	Payment       *Payment `json:"-"`
+}
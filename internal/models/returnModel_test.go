@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionReturn(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{ReturnRequested, ReturnApproved, true},
+		{ReturnRequested, ReturnRejected, true},
+		{ReturnRequested, ReturnPickedUp, false},
+		{ReturnRequested, ReturnRefunded, false},
+		{ReturnApproved, ReturnPickedUp, true},
+		{ReturnApproved, ReturnRejected, true},
+		{ReturnApproved, ReturnRefunded, false},
+		{ReturnPickedUp, ReturnRefunded, true},
+		{ReturnPickedUp, ReturnRejected, false},
+		{ReturnRejected, ReturnApproved, false},
+		{ReturnRefunded, ReturnPickedUp, false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.allowed, CanTransitionReturn(test.from, test.to), "%s -> %s", test.from, test.to)
+	}
+}
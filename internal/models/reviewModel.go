@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Review statuses. Reviews are published right away; admins can hide them.
+const (
+	ReviewPublished = "published"
+	ReviewHidden    = "hidden"
+)
+
+const (
+	// MaxReviewImages caps the images attached to one review.
+	MaxReviewImages = 4
+	// ReviewImageMaxSide is the longest edge review images are scaled to.
+	ReviewImageMaxSide = 1600
+)
+
+// Review sort orders.
+const (
+	ReviewSortHelpful = "helpful"
+	ReviewSortNewest  = "newest"
+	ReviewSortHighest = "rating_desc"
+	ReviewSortLowest  = "rating_asc"
+)
+
+// ReviewImage is an image uploaded with a review.
+type ReviewImage struct {
+	Key string `json:"-"`
+	URL string `json:"url"`
+}
+
+// Review is a verified buyer's rating of a product. A user has at most one
+// review per product, which they can edit. Reviews are deleted outright so
+// the user can write a new one.
+type Review struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	ProductID    uint          `gorm:"not null;uniqueIndex:idx_review_product_user" json:"product_id"`
+	UserID       uint          `gorm:"not null;uniqueIndex:idx_review_product_user;index" json:"user_id"`
+	User         User          `json:"-"`
+	Rating       int           `gorm:"not null;check:rating BETWEEN 1 AND 5" json:"rating"`
+	Title        string        `json:"title"`
+	Body         string        `json:"body"`
+	Images       []ReviewImage `gorm:"serializer:json" json:"images"`
+	Status       string        `gorm:"type:varchar(20);index;not null" json:"status"`
+	HelpfulCount int           `gorm:"not null;default:0" json:"helpful_count"`
+	// reason given by the admin who hid the review
+	ModerationNote string `json:"moderation_note,omitempty"`
+}
+
+// ReviewVote marks a review as helpful to a user.
+type ReviewVote struct {
+	ReviewID  uint      `gorm:"primaryKey" json:"review_id"`
+	Review    Review    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID    uint      `gorm:"primaryKey;index" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReviewFilter selects the reviews of a product for listing. Zero values
+// mean no filtering on that field.
+type ReviewFilter struct {
+	ProductID uint
+	Rating    int
+	Status    string
+	Sort      string
+	Page      int
+	Limit     int
+}
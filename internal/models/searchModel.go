@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// SearchDocument is the search index entry of a product used by the Postgres
+// search engine. It is derived from the product and its variants and
+// rewritten whenever they change.
+type SearchDocument struct {
+	ProductID  uint   `gorm:"primaryKey;autoIncrement:false"`
+	CategoryID uint   `gorm:"index;not null"`
+	Brand      string `gorm:"index;not null;default:''"`
+	// price range of the variants
+	MinPrice    int64  `gorm:"not null;default:0"`
+	MaxPrice    int64  `gorm:"not null;default:0"`
+	IsActive    bool   `gorm:"not null;default:true"`
+	Name        string `gorm:"not null"`
+	Description string `gorm:"not null;default:''"`
+	// name, brand and category, matched by trigram similarity to tolerate typos
+	SearchText string `gorm:"not null;index:idx_search_documents_trigram,type:gin,expression:search_text gin_trgm_ops"`
+	// weighted tsvector of all searchable text
+	Document  string `gorm:"type:tsvector;not null;index:idx_search_documents_document,type:gin"`
+	UpdatedAt time.Time
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShippingRule prices shipping to a zone for a range of weights and order
+// values. A zone is given by states and pincode prefixes; empty lists match
+// everywhere. Weights are in grams and a zero maximum means no limit.
+// The charge is Rate plus PerKg for every kilogram started after the first.
+type ShippingRule struct {
+	States         []string `json:"states,omitempty"`
+	PostalPrefixes []string `json:"postal_prefixes,omitempty"`
+	MinWeight      int      `json:"min_weight"`
+	MaxWeight      int      `json:"max_weight"`
+	MinOrderValue  int64    `json:"min_order_value"`
+	MaxOrderValue  int64    `json:"max_order_value"`
+	Rate           int64    `json:"rate"`
+	PerKg          int64    `json:"per_kg"`
+}
+
+// Matches reports whether the rule covers a parcel of weight grams and an
+// order of value shipped to the state and pincode.
+func (r *ShippingRule) Matches(state string, postalCode string, weight int, value int64) bool {
+	if len(r.States) > 0 && !containsFold(r.States, strings.TrimSpace(state)) {
+		return false
+	}
+	if len(r.PostalPrefixes) > 0 {
+		matched := false
+		for _, prefix := range r.PostalPrefixes {
+			if strings.HasPrefix(postalCode, prefix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if weight < r.MinWeight || (r.MaxWeight > 0 && weight > r.MaxWeight) {
+		return false
+	}
+	return value >= r.MinOrderValue && (r.MaxOrderValue == 0 || value <= r.MaxOrderValue)
+}
+
+// Charge returns the rule's price for a parcel of weight grams.
+func (r *ShippingRule) Charge(weight int) int64 {
+	extraKgs := max((weight-1)/1000, 0)
+	return r.Rate + r.PerKg*int64(extraKgs)
+}
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShippingMethod is a delivery option offered at checkout, e.g. standard or
+// express. It is available where one of its rules matches, and the first
+// matching rule sets the price unless CarrierRates asks the carrier for a
+// quote instead. Shipments are booked with the configured carrier.
+type ShippingMethod struct {
+	gorm.Model
+	Code          string         `gorm:"uniqueIndex;not null" json:"code"`
+	Name          string         `gorm:"not null" json:"name"`
+	Description   string         `json:"description"`
+	CarrierRates  bool           `gorm:"not null" json:"carrier_rates"`
+	EstimatedDays int            `gorm:"not null;default:0" json:"estimated_days"`
+	Rules         []ShippingRule `gorm:"serializer:json;not null" json:"rules"`
+	IsActive      bool           `gorm:"not null" json:"is_active"`
+}
+
+// MatchRule returns the first rule covering the shipment, or nil.
+func (m *ShippingMethod) MatchRule(state string, postalCode string, weight int, value int64) *ShippingRule {
+	for i := range m.Rules {
+		if m.Rules[i].Matches(state, postalCode, weight, value) {
+			return &m.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ShippingQuote is the price of a shipping method for the current cart.
+type ShippingQuote struct {
+	Method        ShippingMethod
+	Amount        int64
+	EstimatedDays int
+}
+
+// Shipment is a parcel booked with a carrier for an order. Status follows
+// the carrier's tracking updates.
+type Shipment struct {
+	gorm.Model
+	OrderID        uint            `gorm:"index;not null" json:"order_id"`
+	UserID         uint            `gorm:"index;not null" json:"user_id"`
+	Carrier        string          `gorm:"type:varchar(20);not null;uniqueIndex:idx_shipment_tracking" json:"carrier"`
+	TrackingNumber string          `gorm:"not null;uniqueIndex:idx_shipment_tracking" json:"tracking_number"`
+	Status         string          `gorm:"type:varchar(20);not null" json:"status"`
+	Weight         int             `gorm:"not null" json:"weight"`
+	LabelKey       string          `json:"-"`
+	LabelType      string          `json:"-"`
+	ShippedAt      *time.Time      `json:"shipped_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	Events         []ShipmentEvent `json:"events,omitempty"`
+}
+
+// ShipmentEvent is one tracking update of a shipment. EventID is unique per
+// carrier so redelivered webhooks are applied once.
+type ShipmentEvent struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ShipmentID  uint      `gorm:"index;not null" json:"shipment_id"`
+	Carrier     string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_shipment_event" json:"-"`
+	EventID     string    `gorm:"not null;uniqueIndex:idx_shipment_event" json:"-"`
+	Status      string    `gorm:"type:varchar(20);not null" json:"status"`
+	Location    string    `json:"location,omitempty"`
+	Description string    `json:"description,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
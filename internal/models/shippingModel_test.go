@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShippingMethodMatchRule(t *testing.T) {
+	method := ShippingMethod{Rules: []ShippingRule{
+		// free above 999.00 within Kerala
+		{States: []string{"Kerala"}, MinOrderValue: 99900, Rate: 0},
+		{States: []string{"Kerala"}, MaxWeight: 5000, Rate: 4000, PerKg: 1500},
+		{PostalPrefixes: []string{"56", "60"}, MaxWeight: 5000, Rate: 7000},
+	}}
+	tests := []struct {
+		name       string
+		state      string
+		postalCode string
+		weight     int
+		value      int64
+		rule       int
+		charge     int64
+	}{
+		{"free over minimum value", "kerala", "682001", 800, 100000, 0, 0},
+		{"first kilogram", "Kerala", "682001", 1000, 50000, 1, 4000},
+		{"further kilograms", "Kerala", "682001", 2500, 50000, 1, 7000},
+		{"pincode zone", "Karnataka", "560001", 2500, 50000, 2, 7000},
+		{"too heavy", "Karnataka", "560001", 5001, 50000, -1, 0},
+		{"outside every zone", "Punjab", "141001", 500, 50000, -1, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule := method.MatchRule(test.state, test.postalCode, test.weight, test.value)
+			if test.rule < 0 {
+				assert.Nil(t, rule)
+				return
+			}
+			assert.Same(t, &method.Rules[test.rule], rule)
+			assert.Equal(t, test.charge, rule.Charge(test.weight))
+		})
+	}
+}
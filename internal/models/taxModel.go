@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+// MaxTaxRate is the highest tax rate in basis points.
+const MaxTaxRate = 10000
+
+// TaxSlab charges Rate, in basis points (1800 is 18%), on units whose
+// taxable value is at least MinUnitValue.
+type TaxSlab struct {
+	MinUnitValue int64 `json:"min_unit_value"`
+	Rate         int   `json:"rate"`
+}
+
+// TaxClass is a set of rates shared by similar goods, e.g. apparel taxed at
+// 5% up to 1000 a piece and at 12% above. Slabs are sorted by MinUnitValue
+// and the first starts at zero. A category without a class takes the class
+// of its nearest ancestor that has one; products in no such category use
+// the default class, or are not taxed when there is none.
+type TaxClass struct {
+	gorm.Model
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Description string    `json:"description"`
+	Slabs       []TaxSlab `gorm:"serializer:json;not null" json:"slabs"`
+	IsDefault   bool      `gorm:"not null" json:"is_default"`
+}
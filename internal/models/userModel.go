@@ -1,25 +1,42 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
 	//ID        uint   `gorm:"primary key" json:"id"`
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-	Email     string `json:"email"  validate:"required"`
-	Password  string `json:"password" validate:"required"`
-	Phone     string `json:"phone" validate:"required,numeric,len=10"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"-"`
+	Phone     string `json:"phone"`
 	Status    string `gorm:"type:varchar(10); check(status IN ('Active', 'Blocked', 'Deleted')) ;default:'Active'" json:"status"`
+	// storage key prefix of the current avatar thumbnails
+	AvatarKey string `json:"-"`
+	// thumbnail URLs keyed by size name
+	Avatar map[string]string `gorm:"serializer:json" json:"avatar"`
+	// incremented on every profile change, used for the profile ETag
+	Version uint `gorm:"not null;default:1" json:"version"`
+	// set once the anonymization job has scrubbed a deleted account
+	PurgedAt *time.Time `json:"-"`
+	// the code the user invites others with
+	ReferralCode *string `gorm:"uniqueIndex" json:"referral_code,omitempty"`
+	// where the user signed up from, for the referral fraud checks
+	SignupIP     string `json:"-"`
+	SignupDevice string `json:"-"`
+	// currency prices are displayed in; empty for the base currency
+	Currency string `gorm:"type:varchar(3);not null;default:''" json:"currency"`
 }
-type UserLogin struct {
-	Email    string `gorm:"unique" validate:"required,email" json:"email"`
-	Password string `validate:"required" json:"password"`
-}
-type UserUpdate struct {
-	FirstName string `json:"first_name" validate:"required"`
-	LastName  string `json:"last_name" validate:"required"`
-	Phone     string `json:"phone" validate:"required,numeric,len=10"`
+
+// MagicLink records an issued passwordless login link so it can be used only once.
+type MagicLink struct {
+	gorm.Model
+	UserID    uint       `gorm:"index" json:"user_id"`
+	JTI       string     `gorm:"uniqueIndex;size:64" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
 }
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Wallet transaction types.
+const (
+	WalletTopUp   = "topup"
+	WalletPayment = "payment"
+	WalletRefund  = "refund"
+	// a referral reward
+	WalletReferral = "referral"
+)
+
+// System ledger accounts. Every wallet transaction moves money between the
+// user's wallet account and one of these.
+const (
+	// money received through payment gateways
+	AccountGateway = "gateway"
+	// money paid for orders
+	AccountSales = "sales"
+	// money returned to customers
+	AccountRefunds = "refunds"
+	// rewards paid for referrals
+	AccountReferrals = "referrals"
+)
+
+// WalletAccount is the ledger account of a user's wallet.
+func WalletAccount(userID uint) string {
+	return fmt.Sprintf("wallet:%d", userID)
+}
+
+// Wallet holds a user's stored balance in minor units. Balance is a cached
+// sum of the ledger entries of the user's wallet account.
+type Wallet struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uint      `gorm:"uniqueIndex;not null" json:"user_id"`
+	Balance   int64     `gorm:"not null;default:0;check:balance >= 0" json:"balance"`
+}
+
+// WalletTransaction is one change to a wallet balance. Amount is positive for
+// credits and negative for debits. Its ledger entries always sum to zero.
+type WalletTransaction struct {
+	ID           uint          `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UserID       uint          `gorm:"index;not null" json:"user_id"`
+	Type         string        `gorm:"type:varchar(20);not null" json:"type"`
+	Amount       int64         `gorm:"not null" json:"amount"`
+	BalanceAfter int64         `gorm:"not null" json:"balance_after"`
+	Reference    string        `gorm:"index" json:"reference"`
+	Entries      []LedgerEntry `gorm:"foreignKey:TransactionID" json:"entries,omitempty"`
+}
+
+// LedgerEntry is one side of a double-entry booking. Amount is positive when
+// the account is credited and negative when it is debited.
+type LedgerEntry struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	TransactionID uint      `gorm:"index;not null" json:"transaction_id"`
+	Account       string    `gorm:"index;not null" json:"account"`
+	Amount        int64     `gorm:"not null" json:"amount"`
+}
+
+// WalletAuditIssue describes a wallet whose balance does not match its
+// ledger, or a transaction whose entries do not balance.
+type WalletAuditIssue struct {
+	UserID        uint   `json:"user_id,omitempty"`
+	TransactionID uint   `json:"transaction_id,omitempty"`
+	Problem       string `json:"problem"`
+	Expected      int64  `json:"expected"`
+	Actual        int64  `json:"actual"`
+}
+
+// CODPincode is a postal code where cash on delivery is offered.
+type CODPincode struct {
+	PostalCode string    `gorm:"primaryKey;type:varchar(10)" json:"postal_code"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// MaxWishlistItems caps the size of a user's wishlist.
+const MaxWishlistItems = 100
+
+// WishlistItem is a variant saved by a user. The wishlist checker compares
+// the variant against SavedPrice and InStock to decide when to notify.
+type WishlistItem struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	UserID    uint           `gorm:"not null;uniqueIndex:idx_wishlist_user_variant" json:"user_id"`
+	VariantID uint           `gorm:"not null;uniqueIndex:idx_wishlist_user_variant;index" json:"variant_id"`
+	Variant   ProductVariant `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// price when the item was saved
+	SavedPrice int64 `gorm:"not null" json:"saved_price"`
+	// lowest price the user was already told about, 0 if none
+	NotifiedPrice int64 `gorm:"not null;default:0" json:"-"`
+	// whether the variant had stock available at the last check
+	InStock bool `gorm:"not null" json:"-"`
+}
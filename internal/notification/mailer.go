@@ -0,0 +1,51 @@
+package notification
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+)
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// LogMailer prints emails to stdout instead of sending them. It is used when
+// no SMTP server is configured, e.g. during local development.
+type LogMailer struct{}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	fmt.Printf("email to %s\nsubject: %s\n%s\n", to, subject, body)
+	return nil
+}
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{host: host, port: port, username: username, password: password, from: from}
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n", m.from, to, subject, body)
+	auth := smtp.PlainAuth("", m.username, m.password, m.host)
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(msg))
+}
+
+// NewMailerFromEnv returns an SMTPMailer when SMTP_HOST is set and a LogMailer otherwise.
+func NewMailerFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return &LogMailer{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), os.Getenv("SMTP_FROM"))
+}
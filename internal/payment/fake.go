@@ -0,0 +1,159 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+const fakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider is an in-memory gateway for tests and local development. IDs
+// are sequential and nothing is random, so runs are reproducible. Payments
+// are completed with Complete, which returns the signed webhook the gateway
+// would have sent.
+type FakeProvider struct {
+	webhookSecret string
+	mu            sync.Mutex
+	intents       map[string]*fakeIntent
+	// intent IDs by idempotency key
+	keys map[string]string
+	// refund IDs by idempotency key
+	refunds  map[string]string
+	sequence int
+}
+
+type fakeIntent struct {
+	Intent
+	captured int64
+	refunded int64
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{webhookSecret: webhookSecret, intents: map[string]*fakeIntent{}, keys: map[string]string{}, refunds: map[string]string{}}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+func (f *FakeProvider) nextID(prefix string) string {
+	f.sequence++
+	return fmt.Sprintf("%s_%06d", prefix, f.sequence)
+}
+func (f *FakeProvider) CreateIntent(amount int64, currency string, reference string, idempotencyKey string) (*Intent, error) {
+	if amount <= 0 {
+		return nil, errors.New("fake: amount must be positive")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id, ok := f.keys[idempotencyKey]; ok {
+		copied := f.intents[id].Intent
+		return &copied, nil
+	}
+	id := f.nextID("fake_pi")
+	f.keys[idempotencyKey] = id
+	intent := &fakeIntent{Intent: Intent{
+		ID:           id,
+		ClientSecret: id + "_secret",
+		Amount:       amount,
+		Currency:     currency,
+		Status:       IntentRequiresPayment,
+	}}
+	f.intents[id] = intent
+	copied := intent.Intent
+	return &copied, nil
+}
+
+// Complete simulates the customer paying (authorized) or the card being
+// declined, and returns the webhook payload and headers for the outcome.
+func (f *FakeProvider) Complete(intentID string, succeed bool) ([]byte, http.Header, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return nil, nil, errors.New("fake: unknown payment intent")
+	}
+	if intent.Status != IntentRequiresPayment {
+		return nil, nil, errors.New("fake: payment intent is already completed")
+	}
+	event := Event{ID: f.nextID("fake_evt"), IntentID: intentID, Amount: intent.Amount}
+	if succeed {
+		intent.Status = IntentAuthorized
+		event.Type = EventPaymentAuthorized
+	} else {
+		intent.Status = IntentFailed
+		event.Type = EventPaymentFailed
+		event.Reason = "card declined"
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(fakeSignatureHeader, f.sign(payload))
+	return payload, header, nil
+}
+func (f *FakeProvider) Capture(intentID string, amount int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return errors.New("fake: unknown payment intent")
+	}
+	if intent.Status == IntentSucceeded && intent.captured == amount {
+		// repeated capture of the same amount is a no-op, like a retried request
+		return nil
+	}
+	if intent.Status != IntentAuthorized {
+		return errors.New("fake: payment intent is not authorized")
+	}
+	if amount <= 0 || amount > intent.Amount {
+		return errors.New("fake: invalid capture amount")
+	}
+	intent.Status = IntentSucceeded
+	intent.captured = amount
+	return nil
+}
+func (f *FakeProvider) Refund(intentID string, amount int64, idempotencyKey string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if refundID, ok := f.refunds[idempotencyKey]; ok {
+		return refundID, nil
+	}
+	intent, ok := f.intents[intentID]
+	if !ok {
+		return "", errors.New("fake: unknown payment intent")
+	}
+	if intent.Status != IntentSucceeded {
+		return "", errors.New("fake: payment intent is not captured")
+	}
+	if amount <= 0 || intent.refunded+amount > intent.captured {
+		return "", errors.New("fake: refund exceeds captured amount")
+	}
+	intent.refunded += amount
+	refundID := f.nextID("fake_re")
+	f.refunds[idempotencyKey] = refundID
+	return refundID, nil
+}
+
+// VerifyWebhook expects the hex HMAC-SHA256 of the payload in X-Fake-Signature.
+func (f *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	if !hmac.Equal([]byte(header.Get(fakeSignatureHeader)), []byte(f.sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+func (f *FakeProvider) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(f.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
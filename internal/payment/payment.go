@@ -0,0 +1,85 @@
+package payment
+
+import (
+	"errors"
+	"net/http"
+	"os"
+)
+
+// Intent statuses reported by a provider.
+const (
+	IntentRequiresPayment = "requires_payment"
+	IntentAuthorized      = "authorized"
+	IntentSucceeded       = "succeeded"
+	IntentFailed          = "failed"
+)
+
+// Webhook event types, normalized across providers.
+const (
+	EventPaymentAuthorized = "payment.authorized"
+	EventPaymentSucceeded  = "payment.succeeded"
+	EventPaymentFailed     = "payment.failed"
+	EventRefundSucceeded   = "refund.succeeded"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Intent is a provider side payment for an amount in minor units. The client
+// completes it with ClientSecret, e.g. in the provider's checkout widget.
+type Intent struct {
+	ID           string
+	ClientSecret string
+	Amount       int64
+	Currency     string
+	Status       string
+}
+
+// Event is a verified webhook notification. ID is unique per provider and is
+// used to process every event at most once.
+type Event struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+	Amount   int64  `json:"amount"`
+	// provider's reason for a failed payment
+	Reason string `json:"reason,omitempty"`
+}
+
+// Provider is a payment gateway. Amounts are in minor units of currency.
+type Provider interface {
+	Name() string
+	// CreateIntent starts a payment; reference identifies it on the provider's
+	// dashboard, e.g. the order number. Retries with the same idempotencyKey
+	// return the same intent.
+	CreateIntent(amount int64, currency string, reference string, idempotencyKey string) (*Intent, error)
+	// Capture collects an authorized payment.
+	Capture(intentID string, amount int64) error
+	// Refund returns amount of a captured payment and returns the refund ID.
+	// Retries with the same idempotencyKey refund only once.
+	Refund(intentID string, amount int64, idempotencyKey string) (string, error)
+	// VerifyWebhook checks the signature of a webhook request and parses it.
+	VerifyWebhook(payload []byte, header http.Header) (*Event, error)
+}
+
+// NewProviderFromEnv returns the gateway named by PAYMENT_PROVIDER: the
+// Stripe adapter for "stripe", which needs STRIPE_SECRET_KEY and
+// STRIPE_WEBHOOK_SECRET, or the local fake gateway for "fake", which signs
+// its webhooks with PAYMENT_WEBHOOK_SECRET. There is no default, so a
+// deployment never ends up on the fake gateway by accident.
+func NewProviderFromEnv() (Provider, error) {
+	switch os.Getenv("PAYMENT_PROVIDER") {
+	case "stripe":
+		secretKey, webhookSecret := os.Getenv("STRIPE_SECRET_KEY"), os.Getenv("STRIPE_WEBHOOK_SECRET")
+		if secretKey == "" || webhookSecret == "" {
+			return nil, errors.New("STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET are required")
+		}
+		return NewStripeProvider(secretKey, webhookSecret), nil
+	case "fake":
+		secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+		if secret == "" {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET is required")
+		}
+		return NewFakeProvider(secret), nil
+	}
+	return nil, errors.New(`PAYMENT_PROVIDER must be "stripe" or "fake"`)
+}
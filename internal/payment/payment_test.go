@@ -0,0 +1,109 @@
+package payment
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeProvider(t *testing.T) {
+	provider := NewFakeProvider("secret")
+	intent, err := provider.CreateIntent(5000, "INR", "ORD-1", "payment-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "fake_pi_000001", intent.ID)
+	assert.Equal(t, IntentRequiresPayment, intent.Status)
+	retried, err := provider.CreateIntent(5000, "INR", "ORD-1", "payment-1")
+	assert.NoError(t, err, "retried intent")
+	assert.Equal(t, intent.ID, retried.ID)
+
+	assert.Error(t, provider.Capture(intent.ID, 5000), "capture before authorization")
+
+	payload, header, err := provider.Complete(intent.ID, true)
+	assert.NoError(t, err)
+	event, err := provider.VerifyWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, Event{ID: "fake_evt_000002", Type: EventPaymentAuthorized, IntentID: intent.ID, Amount: 5000}, *event)
+
+	_, err = provider.VerifyWebhook(append(payload, ' '), header)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	assert.NoError(t, provider.Capture(intent.ID, 5000))
+	assert.NoError(t, provider.Capture(intent.ID, 5000), "repeated capture")
+
+	refundID, err := provider.Refund(intent.ID, 3000, "refund-1")
+	assert.NoError(t, err)
+	retriedRefund, err := provider.Refund(intent.ID, 3000, "refund-1")
+	assert.NoError(t, err, "retried refund")
+	assert.Equal(t, refundID, retriedRefund)
+	_, err = provider.Refund(intent.ID, 3000, "refund-2")
+	assert.Error(t, err, "refund above captured amount")
+
+	declined, _ := provider.CreateIntent(100, "INR", "ORD-1", "payment-2")
+	payload, header, _ = provider.Complete(declined.ID, false)
+	event, err = provider.VerifyWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, EventPaymentFailed, event.Type)
+	assert.Equal(t, "card declined", event.Reason)
+}
+
+func TestStripeVerifyWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	provider := NewStripeProvider("sk_test", "whsec_test")
+	provider.now = func() time.Time { return now }
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_1","amount":2500}}}`)
+	signed := func(at time.Time, body []byte) http.Header {
+		timestamp := fmt.Sprint(at.Unix())
+		header := http.Header{}
+		header.Set("Stripe-Signature", "t="+timestamp+",v1="+stripeSignature("whsec_test", timestamp, body))
+		return header
+	}
+
+	event, err := provider.VerifyWebhook(payload, signed(now, payload))
+	assert.NoError(t, err)
+	assert.Equal(t, Event{ID: "evt_1", Type: EventPaymentSucceeded, IntentID: "pi_1", Amount: 2500}, *event)
+
+	_, err = provider.VerifyWebhook(payload, signed(now.Add(-time.Hour), payload))
+	assert.Equal(t, ErrInvalidSignature, err, "stale timestamp")
+
+	_, err = provider.VerifyWebhook([]byte(`{"id":"evt_2"}`), signed(now, payload))
+	assert.Equal(t, ErrInvalidSignature, err, "payload does not match signature")
+
+	_, err = provider.VerifyWebhook(payload, http.Header{})
+	assert.Equal(t, ErrInvalidSignature, err, "missing header")
+
+	refund := []byte(`{"id":"evt_3","type":"charge.refunded","data":{"object":{"id":"ch_1","amount":2500,"amount_refunded":1000,"payment_intent":"pi_1"}}}`)
+	event, err = provider.VerifyWebhook(refund, signed(now, refund))
+	assert.NoError(t, err)
+	assert.Equal(t, Event{ID: "evt_3", Type: EventRefundSucceeded, IntentID: "pi_1", Amount: 1000}, *event)
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"unset", map[string]string{}, ""},
+		{"fake without secret", map[string]string{"PAYMENT_PROVIDER": "fake"}, ""},
+		{"fake", map[string]string{"PAYMENT_PROVIDER": "fake", "PAYMENT_WEBHOOK_SECRET": "s"}, "fake"},
+		{"stripe without keys", map[string]string{"PAYMENT_PROVIDER": "stripe", "STRIPE_SECRET_KEY": "sk"}, ""},
+		{"stripe", map[string]string{"PAYMENT_PROVIDER": "stripe", "STRIPE_SECRET_KEY": "sk", "STRIPE_WEBHOOK_SECRET": "wh"}, "stripe"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"PAYMENT_PROVIDER", "PAYMENT_WEBHOOK_SECRET", "STRIPE_SECRET_KEY", "STRIPE_WEBHOOK_SECRET"} {
+				t.Setenv(name, test.env[name])
+			}
+			provider, err := NewProviderFromEnv()
+			if test.want == "" {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, provider.Name())
+		})
+	}
+}
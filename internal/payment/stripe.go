@@ -0,0 +1,210 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stripeAPI = "https://api.stripe.com/v1"
+	// webhooks signed longer ago than this are rejected to stop replays
+	stripeSignatureTolerance = 5 * time.Minute
+)
+
+// StripeProvider talks to the Stripe payment intents API. Intents are created
+// with manual capture, so a payment is authorized first and collected by
+// Capture.
+type StripeProvider struct {
+	secretKey     string
+	webhookSecret string
+	baseURL       string
+	client        *http.Client
+	now           func() time.Time
+}
+
+func NewStripeProvider(secretKey, webhookSecret string) *StripeProvider {
+	return &StripeProvider{
+		secretKey:     secretKey,
+		webhookSecret: webhookSecret,
+		baseURL:       stripeAPI,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		now:           time.Now,
+	}
+}
+
+func (s *StripeProvider) Name() string {
+	return "stripe"
+}
+
+type stripeIntent struct {
+	ID           string `json:"id"`
+	ClientSecret string `json:"client_secret"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Status       string `json:"status"`
+}
+
+func (s *StripeProvider) CreateIntent(amount int64, currency string, reference string, idempotencyKey string) (*Intent, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	form.Set("currency", strings.ToLower(currency))
+	form.Set("capture_method", "manual")
+	form.Set("metadata[reference]", reference)
+	var intent stripeIntent
+	if err := s.post("/payment_intents", form, idempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+	return &Intent{
+		ID:           intent.ID,
+		ClientSecret: intent.ClientSecret,
+		Amount:       intent.Amount,
+		Currency:     strings.ToUpper(intent.Currency),
+		Status:       stripeIntentStatus(intent.Status),
+	}, nil
+}
+func (s *StripeProvider) Capture(intentID string, amount int64) error {
+	form := url.Values{}
+	form.Set("amount_to_capture", strconv.FormatInt(amount, 10))
+	return s.post("/payment_intents/"+url.PathEscape(intentID)+"/capture", form, "capture-"+intentID, nil)
+}
+func (s *StripeProvider) Refund(intentID string, amount int64, idempotencyKey string) (string, error) {
+	form := url.Values{}
+	form.Set("payment_intent", intentID)
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	var refund struct {
+		ID string `json:"id"`
+	}
+	if err := s.post("/refunds", form, idempotencyKey, &refund); err != nil {
+		return "", err
+	}
+	return refund.ID, nil
+}
+
+// post sends a form encoded request. The idempotency key makes retries of the
+// same operation safe.
+func (s *StripeProvider) post(path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, s.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("stripe: %s: %s", resp.Status, body.Error.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// VerifyWebhook checks the Stripe-Signature header, "t=<unix time>,v1=<hex>",
+// where v1 is the HMAC-SHA256 of "<t>.<payload>" with the endpoint secret.
+func (s *StripeProvider) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get("Stripe-Signature"), ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	if s.now().Sub(time.Unix(seconds, 0)).Abs() > stripeSignatureTolerance {
+		return nil, ErrInvalidSignature
+	}
+	expected := stripeSignature(s.webhookSecret, timestamp, payload)
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	var body struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object struct {
+				ID               string `json:"id"`
+				Object           string `json:"object"`
+				Amount           int64  `json:"amount"`
+				AmountRefunded   int64  `json:"amount_refunded"`
+				PaymentIntent    string `json:"payment_intent"`
+				LastPaymentError struct {
+					Message string `json:"message"`
+				} `json:"last_payment_error"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, err
+	}
+	object := body.Data.Object
+	event := &Event{ID: body.ID, IntentID: object.ID, Amount: object.Amount}
+	switch body.Type {
+	case "payment_intent.amount_capturable_updated":
+		event.Type = EventPaymentAuthorized
+	case "payment_intent.succeeded":
+		event.Type = EventPaymentSucceeded
+	case "payment_intent.payment_failed":
+		event.Type = EventPaymentFailed
+		event.Reason = object.LastPaymentError.Message
+	case "charge.refunded":
+		event.Type = EventRefundSucceeded
+		event.IntentID = object.PaymentIntent
+		event.Amount = object.AmountRefunded
+	default:
+		// other events are acknowledged and ignored
+		event.Type = body.Type
+	}
+	return event, nil
+}
+func stripeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+func stripeIntentStatus(status string) string {
+	switch status {
+	case "requires_capture":
+		return IntentAuthorized
+	case "succeeded":
+		return IntentSucceeded
+	case "canceled":
+		return IntentFailed
+	}
+	return IntentRequiresPayment
+}
@@ -0,0 +1,221 @@
+// Package promotion evaluates coupons against the lines of a cart. It has no
+// database access: usage limits are checked by the caller, and coupon
+// CategoryIDs must already include subcategories.
+package promotion
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+// Line is one cart line. Key identifies it in the result, e.g. the variant ID.
+type Line struct {
+	Key        uint
+	ProductID  uint
+	CategoryID uint
+	UnitPrice  int64
+	Quantity   int
+}
+
+func (l Line) amount() int64 {
+	return l.UnitPrice * int64(l.Quantity)
+}
+
+// Result is the outcome of applying coupons. Discounts are in minor units;
+// LineDiscounts is keyed by Line.Key and CouponDiscounts by coupon ID.
+type Result struct {
+	Discount        int64
+	LineDiscounts   map[uint]int64
+	CouponDiscounts map[uint]int64
+	FreeShipping    bool
+}
+
+// typeOrder is the order coupon types are applied in. Item discounts come
+// before order level ones so percentages apply to what is left to pay.
+var typeOrder = map[string]int{
+	models.CouponBuyXGetY:     0,
+	models.CouponPercentage:   1,
+	models.CouponFlat:         2,
+	models.CouponFreeShipping: 3,
+}
+
+// Subtotal returns the undiscounted value of the lines.
+func Subtotal(lines []Line) int64 {
+	var subtotal int64
+	for _, line := range lines {
+		subtotal += line.amount()
+	}
+	return subtotal
+}
+
+// Check returns why the coupon cannot be used on the lines at now, or nil.
+func Check(coupon *models.Coupon, lines []Line, now time.Time) error {
+	if !coupon.IsActive || (coupon.StartsAt != nil && now.Before(*coupon.StartsAt)) || (coupon.EndsAt != nil && now.After(*coupon.EndsAt)) {
+		return errors.New(models.CouponNotActive)
+	}
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return errors.New(models.CouponUsageExceeded)
+	}
+	if Subtotal(lines) < coupon.MinOrderValue {
+		return errors.New(models.CouponMinOrderValue)
+	}
+	for _, line := range lines {
+		if Applies(coupon, line) {
+			return nil
+		}
+	}
+	return errors.New(models.CouponNotApplicable)
+}
+
+// CheckStacking enforces the stacking rules: a coupon that is not stackable
+// must be used alone, at most MaxCouponsPerOrder coupons can be combined, and
+// a coupon cannot be used twice.
+func CheckStacking(coupons []models.Coupon) error {
+	if len(coupons) > models.MaxCouponsPerOrder {
+		return errors.New(models.TooManyCoupons)
+	}
+	seen := map[uint]bool{}
+	for _, coupon := range coupons {
+		if seen[coupon.ID] {
+			return errors.New(models.CouponAlreadyApplied)
+		}
+		seen[coupon.ID] = true
+		if !coupon.Stackable && len(coupons) > 1 {
+			return errors.New(models.CouponNotStackable)
+		}
+	}
+	return nil
+}
+
+// Applies reports whether the coupon covers the line.
+func Applies(coupon *models.Coupon, line Line) bool {
+	if len(coupon.ProductIDs) == 0 && len(coupon.CategoryIDs) == 0 {
+		return true
+	}
+	return slices.Contains(coupon.ProductIDs, line.ProductID) || slices.Contains(coupon.CategoryIDs, line.CategoryID)
+}
+
+// Apply computes the discounts of coupons that already passed Check and
+// CheckStacking. A line is never discounted below zero.
+func Apply(coupons []models.Coupon, lines []Line) Result {
+	result := Result{LineDiscounts: map[uint]int64{}, CouponDiscounts: map[uint]int64{}}
+	sorted := append([]models.Coupon{}, coupons...)
+	sort.SliceStable(sorted, func(i, j int) bool { return typeOrder[sorted[i].Type] < typeOrder[sorted[j].Type] })
+	for i := range sorted {
+		coupon := &sorted[i]
+		var eligible []Line
+		for _, line := range lines {
+			if Applies(coupon, line) {
+				eligible = append(eligible, line)
+			}
+		}
+		var discounts map[uint]int64
+		switch coupon.Type {
+		case models.CouponFreeShipping:
+			result.FreeShipping = true
+			continue
+		case models.CouponBuyXGetY:
+			discounts = buyXGetY(coupon, eligible)
+		case models.CouponPercentage:
+			remaining := remainingAmounts(eligible, result.LineDiscounts)
+			discount := total(remaining) * coupon.Value / 100
+			if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+				discount = coupon.MaxDiscount
+			}
+			discounts = allocate(discount, eligible, remaining)
+		case models.CouponFlat:
+			remaining := remainingAmounts(eligible, result.LineDiscounts)
+			discounts = allocate(min(coupon.Value, total(remaining)), eligible, remaining)
+		}
+		for key, discount := range discounts {
+			result.LineDiscounts[key] += discount
+			result.CouponDiscounts[coupon.ID] += discount
+			result.Discount += discount
+		}
+	}
+	return result
+}
+
+// buyXGetY makes the cheapest GetQuantity units of every group of
+// BuyQuantity+GetQuantity units free, grouping units from the most expensive.
+func buyXGetY(coupon *models.Coupon, lines []Line) map[uint]int64 {
+	discounts := map[uint]int64{}
+	group := coupon.BuyQuantity + coupon.GetQuantity
+	if coupon.BuyQuantity < 1 || coupon.GetQuantity < 1 {
+		return discounts
+	}
+	type unit struct {
+		key   uint
+		price int64
+	}
+	var units []unit
+	for _, line := range lines {
+		for i := 0; i < line.Quantity; i++ {
+			units = append(units, unit{line.Key, line.UnitPrice})
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool { return units[i].price > units[j].price })
+	for start := 0; start+group <= len(units); start += group {
+		for _, free := range units[start+coupon.BuyQuantity : start+group] {
+			discounts[free.key] += free.price
+		}
+	}
+	return discounts
+}
+
+// remainingAmounts returns what is left to pay on each line after earlier discounts.
+func remainingAmounts(lines []Line, discounted map[uint]int64) []int64 {
+	remaining := make([]int64, len(lines))
+	for i, line := range lines {
+		remaining[i] = max(line.amount()-discounted[line.Key], 0)
+	}
+	return remaining
+}
+func total(amounts []int64) int64 {
+	var sum int64
+	for _, amount := range amounts {
+		sum += amount
+	}
+	return sum
+}
+
+// allocate splits discount across lines in proportion to their remaining
+// amounts. Rounding leftovers go to the last line that can absorb them, so
+// the parts always add up to discount.
+func allocate(discount int64, lines []Line, remaining []int64) map[uint]int64 {
+	discounts := map[uint]int64{}
+	base := total(remaining)
+	if discount <= 0 || base == 0 {
+		return discounts
+	}
+	var allocated int64
+	for i, line := range lines {
+		share := discount * remaining[i] / base
+		discounts[line.Key] += share
+		allocated += share
+	}
+	for i := len(lines) - 1; i >= 0 && allocated < discount; i-- {
+		extra := min(discount-allocated, remaining[i]-discounts[lines[i].Key])
+		discounts[lines[i].Key] += extra
+		allocated += extra
+	}
+	return discounts
+}
+
+// AppliedCoupon is a coupon applied to a cart with the discount it gives, or
+// the reason it currently gives none.
+type AppliedCoupon struct {
+	Coupon   models.Coupon
+	Discount int64
+	Problem  string
+}
+
+// CartPricing is the discount of a cart with its applied coupons.
+type CartPricing struct {
+	Coupons []AppliedCoupon
+	Result  Result
+}
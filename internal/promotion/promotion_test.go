@@ -0,0 +1,124 @@
+package promotion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLines = []Line{
+	{Key: 1, ProductID: 10, CategoryID: 100, UnitPrice: 1000, Quantity: 2},
+	{Key: 2, ProductID: 20, CategoryID: 200, UnitPrice: 500, Quantity: 1},
+	{Key: 3, ProductID: 30, CategoryID: 200, UnitPrice: 300, Quantity: 3},
+}
+
+func coupon(id uint, couponType string, value int64) models.Coupon {
+	c := models.Coupon{Type: couponType, Value: value, IsActive: true, Stackable: true}
+	c.ID = id
+	return c
+}
+
+func TestApply(t *testing.T) {
+	capped := coupon(1, models.CouponPercentage, 50)
+	capped.MaxDiscount = 700
+	scoped := coupon(1, models.CouponFlat, 400)
+	scoped.CategoryIDs = []uint{200}
+	bogo := coupon(1, models.CouponBuyXGetY, 0)
+	bogo.BuyQuantity, bogo.GetQuantity = 1, 1
+	buyTwo := coupon(1, models.CouponBuyXGetY, 0)
+	buyTwo.BuyQuantity, buyTwo.GetQuantity = 2, 1
+	buyTwo.ProductIDs = []uint{30}
+
+	tests := []struct {
+		name          string
+		coupons       []models.Coupon
+		discount      int64
+		lineDiscounts map[uint]int64
+		freeShipping  bool
+	}{
+		{"percentage", []models.Coupon{coupon(1, models.CouponPercentage, 10)}, 340,
+			map[uint]int64{1: 200, 2: 50, 3: 90}, false},
+		{"percentage capped", []models.Coupon{capped}, 700,
+			map[uint]int64{1: 411, 2: 102, 3: 187}, false},
+		{"flat scoped to category", []models.Coupon{scoped}, 400,
+			map[uint]int64{2: 142, 3: 258}, false},
+		{"flat larger than eligible amount", []models.Coupon{coupon(1, models.CouponFlat, 10000)}, 3400,
+			map[uint]int64{1: 2000, 2: 500, 3: 900}, false},
+		// units by price: 1000 1000 | 500 300 | 300 300; the cheaper of each pair is free
+		{"buy one get one", []models.Coupon{bogo}, 1600,
+			map[uint]int64{1: 1000, 3: 600}, false},
+		{"buy two get one scoped to product", []models.Coupon{buyTwo}, 300,
+			map[uint]int64{3: 300}, false},
+		{"free shipping", []models.Coupon{coupon(1, models.CouponFreeShipping, 0)}, 0,
+			map[uint]int64{}, true},
+		// the flat discount applies before the percentage in the input, but
+		// percentages are taken first: 10% of 3400, then 100 off the rest
+		{"stacked", []models.Coupon{coupon(2, models.CouponFlat, 100), coupon(1, models.CouponPercentage, 10)}, 440,
+			map[uint]int64{1: 258, 2: 64, 3: 118}, false},
+	}
+	for _, test := range tests {
+		result := Apply(test.coupons, testLines)
+		assert.Equal(t, test.discount, result.Discount, test.name)
+		assert.Equal(t, test.lineDiscounts, result.LineDiscounts, test.name)
+		assert.Equal(t, test.freeShipping, result.FreeShipping, test.name)
+		var sum int64
+		for _, discount := range result.CouponDiscounts {
+			sum += discount
+		}
+		assert.Equal(t, test.discount, sum, test.name)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	valid := coupon(1, models.CouponFlat, 100)
+	inactive := valid
+	inactive.IsActive = false
+	notStarted := valid
+	notStarted.StartsAt = &future
+	ended := valid
+	ended.EndsAt = &past
+	used := valid
+	used.UsageLimit, used.UsedCount = 5, 5
+	minimum := valid
+	minimum.MinOrderValue = 3401
+	otherProduct := valid
+	otherProduct.ProductIDs = []uint{99}
+
+	tests := []struct {
+		name   string
+		coupon models.Coupon
+		err    string
+	}{
+		{"valid", valid, ""},
+		{"inactive", inactive, models.CouponNotActive},
+		{"not started", notStarted, models.CouponNotActive},
+		{"ended", ended, models.CouponNotActive},
+		{"used up", used, models.CouponUsageExceeded},
+		{"below minimum", minimum, models.CouponMinOrderValue},
+		{"no eligible items", otherProduct, models.CouponNotApplicable},
+	}
+	for _, test := range tests {
+		err := Check(&test.coupon, testLines, now)
+		if test.err == "" {
+			assert.NoError(t, err, test.name)
+		} else {
+			assert.EqualError(t, err, test.err, test.name)
+		}
+	}
+}
+
+func TestCheckStacking(t *testing.T) {
+	single := coupon(1, models.CouponFlat, 100)
+	single.Stackable = false
+	assert.NoError(t, CheckStacking([]models.Coupon{single}))
+	assert.EqualError(t, CheckStacking([]models.Coupon{single, coupon(2, models.CouponFlat, 100)}), models.CouponNotStackable)
+	assert.EqualError(t, CheckStacking([]models.Coupon{coupon(1, models.CouponFlat, 1), coupon(1, models.CouponFlat, 1)}), models.CouponAlreadyApplied)
+	assert.EqualError(t, CheckStacking([]models.Coupon{
+		coupon(1, models.CouponFlat, 1), coupon(2, models.CouponFlat, 1),
+		coupon(3, models.CouponFlat, 1), coupon(4, models.CouponFlat, 1),
+	}), models.TooManyCoupons)
+}
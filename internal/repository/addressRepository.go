@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IAddressRepository interface {
+	GetAddresses(userID uint) ([]models.Address, error)
+	GetAddress(userID uint, addressID uint) (*models.Address, error)
+	CreateAddress(address *models.Address) error
+	UpdateAddress(address *models.Address) error
+	DeleteAddress(userID uint, addressID uint) error
+}
+type AddressRepository struct {
+	db *gorm.DB
+}
+
+func NewAddressRepository(db *gorm.DB) *AddressRepository {
+	return &AddressRepository{db: db}
+}
+func (c *AddressRepository) GetAddresses(userID uint) ([]models.Address, error) {
+	var addresses []models.Address
+	err := c.db.Where("user_id = ?", userID).Order("id").Find(&addresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+func (c *AddressRepository) GetAddress(userID uint, addressID uint) (*models.Address, error) {
+	var address models.Address
+	err := c.db.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.AddressNotFound)
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateAddress enforces the per user address cap. The user row is locked so
+// concurrent requests cannot both pass the count check.
+func (c *AddressRepository) CreateAddress(address *models.Address) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := lockUser(tx, address.UserID)
+		if err != nil {
+			return err
+		}
+		var count int64
+		err = tx.Model(&models.Address{}).Where("user_id = ?", address.UserID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count >= models.MaxAddressesPerUser {
+			return errors.New(models.AddressLimitReached)
+		}
+		// the first address becomes the default for both shipping and billing
+		if count == 0 {
+			address.IsDefaultShipping = true
+			address.IsDefaultBilling = true
+		}
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Create(address).Error
+	})
+}
+func (c *AddressRepository) UpdateAddress(address *models.Address) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := lockUser(tx, address.UserID)
+		if err != nil {
+			return err
+		}
+		if err := clearDefaults(tx, address); err != nil {
+			return err
+		}
+		return tx.Save(address).Error
+	})
+}
+
+// DeleteAddress removes the address and hands any default flag it carried to
+// the most recently added remaining address.
+func (c *AddressRepository) DeleteAddress(userID uint, addressID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := lockUser(tx, userID)
+		if err != nil {
+			return err
+		}
+		var address models.Address
+		err = tx.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New(models.AddressNotFound)
+			}
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefaultShipping && !address.IsDefaultBilling {
+			return nil
+		}
+		var next models.Address
+		err = tx.Where("user_id = ?", userID).Order("id DESC").First(&next).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		updates := map[string]interface{}{}
+		if address.IsDefaultShipping {
+			updates["is_default_shipping"] = true
+		}
+		if address.IsDefaultBilling {
+			updates["is_default_billing"] = true
+		}
+		return tx.Model(&next).Updates(updates).Error
+	})
+}
+
+// clearDefaults unsets the default flags on the user's other addresses for
+// every flag set on address, so each user has at most one default of each kind.
+func clearDefaults(tx *gorm.DB, address *models.Address) error {
+	if address.IsDefaultShipping {
+		err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_shipping", address.UserID, address.ID).
+			Update("is_default_shipping", false).Error
+		if err != nil {
+			return err
+		}
+	}
+	if address.IsDefaultBilling {
+		err := tx.Model(&models.Address{}).
+			Where("user_id = ? AND id <> ? AND is_default_billing", address.UserID, address.ID).
+			Update("is_default_billing", false).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockUser takes a row lock on the user for the rest of the transaction. It
+// serializes writes to data owned by the same user.
+func lockUser(tx *gorm.DB, userID uint) error {
+	var user models.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+)
+
+type IAdminRepository interface {
+	GetAdminByEmail(email string) (*models.Admin, error)
+}
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db: db}
+}
+func (c *AdminRepository) GetAdminByEmail(email string) (*models.Admin, error) {
+	var admin models.Admin
+	err := c.db.Where("email = ?", email).First(&admin).Error
+	if err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
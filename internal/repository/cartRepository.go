@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICartRepository interface {
+	GetCart(owner models.CartOwner) (*models.Cart, error)
+	GetOrCreateCart(owner models.CartOwner) (*models.Cart, error)
+	SaveItem(item *models.CartItem) error
+	UpdateSeenPrices(items []models.CartItem) error
+	RemoveItem(cartID uint, variantID uint) error
+	ClearCart(cartID uint) error
+	MergeGuestCart(guestToken string, userID uint) error
+	DeleteGuestCartsBefore(before time.Time) error
+}
+type CartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+func ownerScope(owner models.CartOwner) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if owner.UserID != 0 {
+			return db.Where("user_id = ?", owner.UserID)
+		}
+		return db.Where("guest_token = ?", owner.GuestToken)
+	}
+}
+
+// GetCart returns the owner's cart with its items, their variants and
+// products, including soft deleted ones so removed products can be reported.
+// It returns a cart without items when the owner has none yet.
+func (c *CartRepository) GetCart(owner models.CartOwner) (*models.Cart, error) {
+	var cart models.Cart
+	err := c.db.Scopes(ownerScope(owner)).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Variant", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		First(&cart).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Cart{}, nil
+		}
+		return nil, err
+	}
+	return &cart, nil
+}
+func (c *CartRepository) GetOrCreateCart(owner models.CartOwner) (*models.Cart, error) {
+	cart := models.Cart{}
+	if owner.UserID != 0 {
+		cart.UserID = &owner.UserID
+	} else {
+		cart.GuestToken = &owner.GuestToken
+	}
+	err := c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	if cart.ID != 0 {
+		return &cart, nil
+	}
+	return c.GetCart(owner)
+}
+
+// SaveItem inserts the item or, when the variant is already in the cart,
+// overwrites its quantity and seen price.
+func (c *CartRepository) SaveItem(item *models.CartItem) error {
+	err := c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cart_id"}, {Name: "variant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "seen_price", "updated_at"}),
+	}).Create(item).Error
+	if err != nil {
+		return err
+	}
+	// keeps guest carts that are in use from being cleaned up
+	return c.db.Model(&models.Cart{}).Where("id = ?", item.CartID).UpdateColumn("updated_at", time.Now()).Error
+}
+
+func (c *CartRepository) UpdateSeenPrices(items []models.CartItem) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			err := tx.Model(&models.CartItem{}).Where("id = ?", item.ID).UpdateColumn("seen_price", item.SeenPrice).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+func (c *CartRepository) RemoveItem(cartID uint, variantID uint) error {
+	result := c.db.Where("cart_id = ? AND variant_id = ?", cartID, variantID).Delete(&models.CartItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.CartItemNotFound)
+	}
+	return nil
+}
+func (c *CartRepository) ClearCart(cartID uint) error {
+	return c.db.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+// MergeGuestCart moves the guest cart's items into the user's cart. Quantities
+// of variants present in both are added up to the per item limit. The guest
+// cart is deleted afterwards.
+func (c *CartRepository) MergeGuestCart(guestToken string, userID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewCartRepository(tx)
+		var guest models.Cart
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("guest_token = ?", guestToken).Preload("Items").First(&guest).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		cart, err := repo.GetOrCreateCart(models.CartOwner{UserID: userID})
+		if err != nil {
+			return err
+		}
+		quantities := make(map[uint]int, len(cart.Items))
+		for _, item := range cart.Items {
+			quantities[item.VariantID] = item.Quantity
+		}
+		for _, item := range guest.Items {
+			quantity := quantities[item.VariantID] + item.Quantity
+			if quantity > models.MaxCartItemQuantity {
+				quantity = models.MaxCartItemQuantity
+			}
+			err := repo.SaveItem(&models.CartItem{
+				CartID:    cart.ID,
+				VariantID: item.VariantID,
+				Quantity:  quantity,
+				SeenPrice: item.SeenPrice,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&guest).Error
+	})
+}
+
+// DeleteGuestCartsBefore removes abandoned guest carts last touched before the given time.
+func (c *CartRepository) DeleteGuestCartsBefore(before time.Time) error {
+	return c.db.Where("guest_token IS NOT NULL AND updated_at < ?", before).Delete(&models.Cart{}).Error
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"gorm.io/gorm"
+)
+
+type ICategoryRepository interface {
+	GetCategories() ([]models.Category, error)
+	GetCategory(categoryID uint) (*models.Category, error)
+	GetDescendantIDs(categoryID uint) ([]uint, error)
+	CreateCategory(category *models.Category) error
+	UpdateCategory(category *models.Category) error
+	DeleteCategory(categoryID uint) error
+}
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+func (c *CategoryRepository) GetCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := c.db.Order("name").Find(&categories).Error
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+func (c *CategoryRepository) GetCategory(categoryID uint) (*models.Category, error) {
+	var category models.Category
+	err := c.db.Where("id = ?", categoryID).First(&category).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.CategoryNotFound)
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetDescendantIDs returns the ID of the category and of all categories below it.
+func (c *CategoryRepository) GetDescendantIDs(categoryID uint) ([]uint, error) {
+	var ids []uint
+	err := c.db.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.id FROM categories c JOIN tree t ON c.parent_id = t.id WHERE c.deleted_at IS NULL
+		)
+		SELECT id FROM tree`, categoryID).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// CreateCategory inserts the category. A category whose name gives no slug
+// is given one from its ID once inserted.
+func (c *CategoryRepository) CreateCategory(category *models.Category) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		if category.Slug != "" {
+			return nil
+		}
+		category.Slug = utils.IDSlug("category", category.ID)
+		return tx.Model(category).Update("slug", category.Slug).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateSlug)
+	}
+	return err
+}
+func (c *CategoryRepository) UpdateCategory(category *models.Category) error {
+	if category.Slug == "" {
+		category.Slug = utils.IDSlug("category", category.ID)
+	}
+	err := c.db.Save(category).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateSlug)
+	}
+	return err
+}
+
+// DeleteCategory refuses to delete categories that still have children or
+// products, so no product is left without a category.
+func (c *CategoryRepository) DeleteCategory(categoryID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var children, products int64
+		err := tx.Model(&models.Category{}).Where("parent_id = ?", categoryID).Count(&children).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&models.Product{}).Where("category_id = ?", categoryID).Count(&products).Error
+		if err != nil {
+			return err
+		}
+		if children > 0 || products > 0 {
+			return errors.New(models.CategoryNotEmpty)
+		}
+		result := tx.Delete(&models.Category{}, categoryID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.CategoryNotFound)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateCategory(t *testing.T) {
+	t.Run("slug from the name", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+		mock.ExpectCommit()
+		category := models.Category{Name: "Shoes", Slug: "shoes"}
+		assert.NoError(t, NewCategoryRepository(db).CreateCategory(&category))
+		assert.Equal(t, "shoes", category.Slug)
+	})
+
+	t.Run("slug from the ID", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "categories" SET "slug"=$1,"updated_at"=$2 WHERE "categories"."deleted_at" IS NULL AND "id" = $3`)).
+			WithArgs("category-12", sqlmock.AnyArg(), uint(12)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		category := models.Category{Name: "जूते"}
+		assert.NoError(t, NewCategoryRepository(db).CreateCategory(&category))
+		assert.Equal(t, "category-12", category.Slug)
+	})
+
+	t.Run("duplicate slug", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "categories"`).WillReturnError(gorm.ErrDuplicatedKey)
+		mock.ExpectRollback()
+		err := NewCategoryRepository(db).CreateCategory(&models.Category{Name: "Shoes", Slug: "shoes"})
+		assert.EqualError(t, err, models.DuplicateSlug)
+	})
+}
+
+func TestUpdateCategory(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "categories" SET .*"slug"=\$\d+`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "जूते", "category-12", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), uint(12)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	category := models.Category{Model: gorm.Model{ID: 12}, Name: "जूते"}
+	assert.NoError(t, NewCategoryRepository(db).UpdateCategory(&category))
+	assert.Equal(t, "category-12", category.Slug)
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICouponRepository interface {
+	GetCoupons(page int, limit int) ([]models.Coupon, int64, error)
+	GetCoupon(couponID uint) (*models.Coupon, error)
+	GetCouponByCode(code string) (*models.Coupon, error)
+	CreateCoupon(coupon *models.Coupon) error
+	UpdateCoupon(coupon *models.Coupon) error
+	DeleteCoupon(couponID uint) error
+	GetCartCoupons(cartID uint) ([]models.Coupon, error)
+	AddCartCoupon(cartID uint, couponID uint) error
+	RemoveCartCoupon(cartID uint, couponID uint) error
+	CountUserRedemptions(couponID uint, userID uint) (int64, error)
+}
+type CouponRepository struct {
+	db *gorm.DB
+}
+
+func NewCouponRepository(db *gorm.DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+func (c *CouponRepository) GetCoupons(page int, limit int) ([]models.Coupon, int64, error) {
+	var total int64
+	if err := c.db.Model(&models.Coupon{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var coupons []models.Coupon
+	err := c.db.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&coupons).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return coupons, total, nil
+}
+func (c *CouponRepository) GetCoupon(couponID uint) (*models.Coupon, error) {
+	return c.getCoupon(c.db.Where("id = ?", couponID))
+}
+func (c *CouponRepository) GetCouponByCode(code string) (*models.Coupon, error) {
+	return c.getCoupon(c.db.Where("code = ?", code))
+}
+func (c *CouponRepository) getCoupon(query *gorm.DB) (*models.Coupon, error) {
+	var coupon models.Coupon
+	err := query.First(&coupon).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.CouponNotFound)
+		}
+		return nil, err
+	}
+	return &coupon, nil
+}
+func (c *CouponRepository) CreateCoupon(coupon *models.Coupon) error {
+	err := c.db.Create(coupon).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateCouponCode)
+	}
+	return err
+}
+
+// UpdateCoupon saves the admin editable fields. UsedCount is left alone as it
+// is only changed by redemptions.
+func (c *CouponRepository) UpdateCoupon(coupon *models.Coupon) error {
+	err := c.db.Model(coupon).Select("*").Omit("id", "created_at", "deleted_at", "used_count").Updates(coupon).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateCouponCode)
+	}
+	return err
+}
+func (c *CouponRepository) DeleteCoupon(couponID uint) error {
+	result := c.db.Delete(&models.Coupon{}, couponID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.CouponNotFound)
+	}
+	return nil
+}
+
+// GetCartCoupons returns the coupons applied to the cart in the order they
+// were applied. Deleted coupons are left out.
+func (c *CouponRepository) GetCartCoupons(cartID uint) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	err := c.db.Joins("JOIN cart_coupons ON cart_coupons.coupon_id = coupons.id").
+		Where("cart_coupons.cart_id = ?", cartID).
+		Order("cart_coupons.created_at").
+		Find(&coupons).Error
+	if err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+func (c *CouponRepository) AddCartCoupon(cartID uint, couponID uint) error {
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CartCoupon{CartID: cartID, CouponID: couponID}).Error
+}
+func (c *CouponRepository) RemoveCartCoupon(cartID uint, couponID uint) error {
+	result := c.db.Where("cart_id = ? AND coupon_id = ?", cartID, couponID).Delete(&models.CartCoupon{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.CouponNotFound)
+	}
+	return nil
+}
+func (c *CouponRepository) CountUserRedemptions(couponID uint, userID uint) (int64, error) {
+	var count int64
+	err := c.db.Model(&models.CouponRedemption{}).Where("coupon_id = ? AND user_id = ?", couponID, userID).Count(&count).Error
+	return count, err
+}
+
+// redeemCoupons consumes one use of every coupon for the order. The user row
+// is locked so concurrent orders of one user count redemptions one at a time,
+// and the global count is raised with a conditional update, so neither limit
+// can be exceeded by concurrent orders.
+func redeemCoupons(tx *gorm.DB, order *models.Order, redemptions []models.CouponRedemption) error {
+	if len(redemptions) == 0 {
+		return nil
+	}
+	if err := lockUser(tx, order.UserID); err != nil {
+		return err
+	}
+	for i := range redemptions {
+		redemption := &redemptions[i]
+		result := tx.Model(&models.Coupon{}).
+			Where("id = ? AND (usage_limit = 0 OR used_count < usage_limit)", redemption.CouponID).
+			Where("per_user_limit = 0 OR per_user_limit > (?)",
+				tx.Model(&models.CouponRedemption{}).Select("COUNT(*)").Where("coupon_id = ? AND user_id = ?", redemption.CouponID, order.UserID)).
+			UpdateColumn("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.CouponUsageExceeded)
+		}
+		redemption.OrderID = order.ID
+		redemption.UserID = order.UserID
+	}
+	return tx.Create(&redemptions).Error
+}
+
+// releaseCoupons gives back the coupon uses of a cancelled order.
+func releaseCoupons(tx *gorm.DB, orderID uint) error {
+	var redemptions []models.CouponRedemption
+	err := tx.Clauses(clause.Returning{}).Where("order_id = ?", orderID).Delete(&redemptions).Error
+	if err != nil {
+		return err
+	}
+	for _, redemption := range redemptions {
+		err := tx.Model(&models.Coupon{}).Where("id = ?", redemption.CouponID).
+			UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ICurrencyRepository interface {
+	GetRates() ([]models.ExchangeRate, error)
+	GetRate(code string) (*models.ExchangeRate, error)
+	SaveRate(rate *models.ExchangeRate) error
+	DeleteRate(code string) error
+}
+type CurrencyRepository struct {
+	db *gorm.DB
+}
+
+func NewCurrencyRepository(db *gorm.DB) *CurrencyRepository {
+	return &CurrencyRepository{db: db}
+}
+func (c *CurrencyRepository) GetRates() ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+	if err := c.db.Order("currency").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+func (c *CurrencyRepository) GetRate(code string) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := c.db.Where("currency = ?", code).First(&rate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ExchangeRateNotFound)
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// SaveRate creates or replaces the rate of the currency.
+func (c *CurrencyRepository) SaveRate(rate *models.ExchangeRate) error {
+	return c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+}
+func (c *CurrencyRepository) DeleteRate(code string) error {
+	result := c.db.Where("currency = ?", code).Delete(&models.ExchangeRate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.ExchangeRateNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IGiftCardRepository interface {
+	CreateGiftCard(card *models.GiftCard) error
+	GetGiftCard(cardID uint) (*models.GiftCard, error)
+	GetGiftCardByCode(codeHash string) (*models.GiftCard, error)
+	GetGiftCardByPayment(paymentID uint) (*models.GiftCard, error)
+	GetGiftCards(page int, limit int) ([]models.GiftCard, int64, error)
+	GetPurchasedGiftCards(userID uint) ([]models.GiftCard, error)
+	ActivateGiftCard(card *models.GiftCard, code string, codeHash string, codeHint string, expiresAt time.Time) (bool, error)
+	GetUndeliveredGiftCards() ([]models.GiftCard, error)
+	MarkGiftCardDelivered(card *models.GiftCard) error
+	SetGiftCardActive(cardID uint, active bool) error
+	GetTransactions(cardID uint) ([]models.GiftCardTransaction, error)
+	Post(cardID uint, transactionType string, amount int64, orderID *uint, reference string) (*models.GiftCardTransaction, error)
+	Transaction(fn func(giftCards *GiftCardRepository) error) error
+}
+type GiftCardRepository struct {
+	db *gorm.DB
+}
+
+func NewGiftCardRepository(db *gorm.DB) *GiftCardRepository {
+	return &GiftCardRepository{db: db}
+}
+func (c *GiftCardRepository) CreateGiftCard(card *models.GiftCard) error {
+	return c.db.Create(card).Error
+}
+func (c *GiftCardRepository) GetGiftCard(cardID uint) (*models.GiftCard, error) {
+	return c.getGiftCard(c.db.Where("id = ?", cardID))
+}
+func (c *GiftCardRepository) GetGiftCardByCode(codeHash string) (*models.GiftCard, error) {
+	return c.getGiftCard(c.db.Where("code_hash = ?", codeHash))
+}
+func (c *GiftCardRepository) GetGiftCardByPayment(paymentID uint) (*models.GiftCard, error) {
+	return c.getGiftCard(c.db.Where("payment_id = ?", paymentID))
+}
+func (c *GiftCardRepository) getGiftCard(query *gorm.DB) (*models.GiftCard, error) {
+	var card models.GiftCard
+	err := query.First(&card).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.GiftCardNotFound)
+		}
+		return nil, err
+	}
+	return &card, nil
+}
+func (c *GiftCardRepository) GetGiftCards(page int, limit int) ([]models.GiftCard, int64, error) {
+	var total int64
+	if err := c.db.Model(&models.GiftCard{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var cards []models.GiftCard
+	err := c.db.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&cards).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return cards, total, nil
+}
+
+// GetPurchasedGiftCards returns the cards bought by the user, including those
+// whose payment has not completed yet.
+func (c *GiftCardRepository) GetPurchasedGiftCards(userID uint) ([]models.GiftCard, error) {
+	var cards []models.GiftCard
+	err := c.db.Where("user_id = ? AND source = ?", userID, models.GiftCardPurchased).Order("id DESC").Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// ActivateGiftCard sets the code of a purchased card that has none yet and
+// activates it. The code is kept as pending until MarkGiftCardDelivered. It
+// reports false when the card was already activated.
+func (c *GiftCardRepository) ActivateGiftCard(card *models.GiftCard, code string, codeHash string, codeHint string, expiresAt time.Time) (bool, error) {
+	result := c.db.Model(card).Clauses(clause.Returning{}).
+		Where("code_hash IS NULL").
+		Updates(map[string]interface{}{
+			"code_hash":    codeHash,
+			"code_hint":    codeHint,
+			"pending_code": code,
+			"expires_at":   expiresAt,
+			"is_active":    true,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetUndeliveredGiftCards returns the activated cards whose code has not been
+// emailed yet, oldest first.
+func (c *GiftCardRepository) GetUndeliveredGiftCards() ([]models.GiftCard, error) {
+	var cards []models.GiftCard
+	err := c.db.Where("pending_code IS NOT NULL").Order("id").Find(&cards).Error
+	if err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// MarkGiftCardDelivered forgets the code of a card once it has been emailed.
+func (c *GiftCardRepository) MarkGiftCardDelivered(card *models.GiftCard) error {
+	return c.db.Model(card).Update("pending_code", nil).Error
+}
+func (c *GiftCardRepository) SetGiftCardActive(cardID uint, active bool) error {
+	result := c.db.Model(&models.GiftCard{}).Where("id = ? AND code_hash IS NOT NULL", cardID).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.GiftCardNotFound)
+	}
+	return nil
+}
+func (c *GiftCardRepository) GetTransactions(cardID uint) ([]models.GiftCardTransaction, error) {
+	var transactions []models.GiftCardTransaction
+	err := c.db.Where("gift_card_id = ?", cardID).Order("id DESC").Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// Post changes the card balance by amount, positive for a load or refund and
+// negative for a redemption, and records it in the card's ledger.
+// Redemptions require a usable card, and like wallet debits the balance
+// update is conditional, so redeeming more than the balance fails with
+// InsufficientGiftCardBalance even under concurrency.
+func (c *GiftCardRepository) Post(cardID uint, transactionType string, amount int64, orderID *uint, reference string) (*models.GiftCardTransaction, error) {
+	var transaction models.GiftCardTransaction
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var card models.GiftCard
+		query := tx.Model(&card).Clauses(clause.Returning{}).Where("id = ? AND balance + ? >= 0", cardID, amount)
+		if amount < 0 {
+			query = query.Where("is_active AND expires_at > ?", time.Now())
+		}
+		result := query.UpdateColumns(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", amount),
+			"updated_at": gorm.Expr("NOW()"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			current, err := NewGiftCardRepository(tx).GetGiftCard(cardID)
+			if err != nil {
+				return err
+			}
+			if amount < 0 && !current.Usable(time.Now()) {
+				return errors.New(models.GiftCardNotUsable)
+			}
+			return errors.New(models.InsufficientGiftCardBalance)
+		}
+		transaction = models.GiftCardTransaction{
+			GiftCardID:   cardID,
+			Type:         transactionType,
+			Amount:       amount,
+			BalanceAfter: card.Balance,
+			OrderID:      orderID,
+			Reference:    reference,
+		}
+		return tx.Create(&transaction).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Transaction calls fn with a repository bound to a single transaction.
+func (c *GiftCardRepository) Transaction(fn func(giftCards *GiftCardRepository) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewGiftCardRepository(tx))
+	})
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IInventoryRepository interface {
+	GetVariantBySKU(sku string) (*models.ProductVariant, error)
+	ReserveStock(userID uint, items []models.ReservationItem, expiresAt time.Time, reference string) ([]models.StockReservation, error)
+	ReleaseReservations(reservationIDs []uint, status string) error
+	CommitReservations(reservationIDs []uint) error
+	GetExpiredReservationIDs(now time.Time) ([]uint, error)
+	AdjustStock(variantID uint, delta int, movementType string, reason string) (*models.ProductVariant, error)
+	GetLowStockVariants(unalertedOnly bool) ([]models.ProductVariant, error)
+	MarkLowStockAlerted(variantIDs []uint) error
+	GetMovements(variantID uint, page, limit int) ([]models.InventoryMovement, int64, error)
+}
+
+// InventoryRepository changes stock with conditional updates on the variant
+// row, so concurrent reservations can never take more than is available.
+// Create it on a transaction to make stock changes part of a larger unit of work.
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+func (c *InventoryRepository) GetVariantBySKU(sku string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := c.db.Where("sku = ?", sku).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.VariantNotFound)
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// ReserveStock reserves every item or none of them. Variants are updated in
+// ID order so concurrent multi item reservations cannot deadlock.
+func (c *InventoryRepository) ReserveStock(userID uint, items []models.ReservationItem, expiresAt time.Time, reference string) ([]models.StockReservation, error) {
+	sorted := append([]models.ReservationItem{}, items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].VariantID < sorted[j].VariantID })
+	var reservations []models.StockReservation
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range sorted {
+			var variant models.ProductVariant
+			result := tx.Model(&variant).Clauses(clause.Returning{}).
+				Where("id = ? AND deleted_at IS NULL AND stock - reserved >= ?", item.VariantID, item.Quantity).
+				UpdateColumn("reserved", gorm.Expr("reserved + ?", item.Quantity))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return stockError(tx, item.VariantID)
+			}
+			reservation := models.StockReservation{
+				VariantID: item.VariantID,
+				UserID:    userID,
+				Quantity:  item.Quantity,
+				Status:    models.ReservationActive,
+				ExpiresAt: expiresAt,
+				Reference: reference,
+			}
+			if err := tx.Create(&reservation).Error; err != nil {
+				return err
+			}
+			err := recordMovement(tx, &variant, models.MovementReserve, 0, item.Quantity, &reservation.ID, reference)
+			if err != nil {
+				return err
+			}
+			reservations = append(reservations, reservation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ReleaseReservations returns the stock of active reservations to the
+// available pool and marks them with status (released or expired).
+// Reservations that are no longer active are skipped.
+func (c *InventoryRepository) ReleaseReservations(reservationIDs []uint, status string) error {
+	movementType := models.MovementRelease
+	if status == models.ReservationExpired {
+		movementType = models.MovementExpire
+	}
+	return c.settleReservations(reservationIDs, status, false, func(tx *gorm.DB, reservation *models.StockReservation) error {
+		var variant models.ProductVariant
+		err := tx.Model(&variant).Clauses(clause.Returning{}).
+			Where("id = ?", reservation.VariantID).
+			UpdateColumn("reserved", gorm.Expr("reserved - ?", reservation.Quantity)).Error
+		if err != nil {
+			return err
+		}
+		return recordMovement(tx, &variant, movementType, 0, -reservation.Quantity, &reservation.ID, reservation.Reference)
+	})
+}
+
+// CommitReservations turns active reservations into sales, removing the
+// reserved units from on hand stock. It fails without committing any of them
+// unless all are still active, as the units of a released or expired
+// reservation may already be sold to someone else.
+func (c *InventoryRepository) CommitReservations(reservationIDs []uint) error {
+	return c.settleReservations(reservationIDs, models.ReservationCommitted, true, func(tx *gorm.DB, reservation *models.StockReservation) error {
+		var variant models.ProductVariant
+		err := tx.Model(&variant).Clauses(clause.Returning{}).
+			Where("id = ?", reservation.VariantID).
+			UpdateColumns(map[string]interface{}{
+				"stock":    gorm.Expr("stock - ?", reservation.Quantity),
+				"reserved": gorm.Expr("reserved - ?", reservation.Quantity),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return recordMovement(tx, &variant, models.MovementSale, -reservation.Quantity, -reservation.Quantity, &reservation.ID, reservation.Reference)
+	})
+}
+
+// settleReservations moves each still active reservation to status and calls
+// apply for it in the same transaction. The status update is conditional, so
+// a reservation is settled at most once even under concurrency. With
+// allActive set, reservations that are no longer active are an error instead
+// of being skipped.
+func (c *InventoryRepository) settleReservations(reservationIDs []uint, status string, allActive bool, apply func(tx *gorm.DB, reservation *models.StockReservation) error) error {
+	if len(reservationIDs) == 0 {
+		return nil
+	}
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", reservationIDs, models.ReservationActive).
+			Order("variant_id").
+			Find(&reservations).Error
+		if err != nil {
+			return err
+		}
+		if allActive && len(reservations) != len(reservationIDs) {
+			return errors.New(models.ReservationNotActive)
+		}
+		for i := range reservations {
+			err := tx.Model(&reservations[i]).Update("status", status).Error
+			if err != nil {
+				return err
+			}
+			if err := apply(tx, &reservations[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+func (c *InventoryRepository) GetExpiredReservationIDs(now time.Time) ([]uint, error) {
+	var ids []uint
+	err := c.db.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at < ?", models.ReservationActive, now).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AdjustStock changes on hand stock by delta. It fails when the result would
+// be lower than the currently reserved quantity. Raising available stock above
+// the low stock threshold re-arms the low stock alert.
+func (c *InventoryRepository) AdjustStock(variantID uint, delta int, movementType string, reason string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&variant).Clauses(clause.Returning{}).
+			Where("id = ? AND stock + ? >= reserved", variantID, delta).
+			UpdateColumns(map[string]interface{}{
+				"stock":             gorm.Expr("stock + ?", delta),
+				"low_stock_alerted": gorm.Expr("low_stock_alerted AND stock + ? - reserved <= low_stock_threshold", delta),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var count int64
+			if err := tx.Model(&models.ProductVariant{}).Where("id = ?", variantID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return errors.New(models.VariantNotFound)
+			}
+			return errors.New(models.InvalidStockAdjustment)
+		}
+		return recordMovement(tx, &variant, movementType, delta, 0, nil, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+func (c *InventoryRepository) GetLowStockVariants(unalertedOnly bool) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	query := c.db.Where("stock - reserved <= low_stock_threshold")
+	if unalertedOnly {
+		query = query.Where("NOT low_stock_alerted")
+	}
+	err := query.Order("stock - reserved, id").Find(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+func (c *InventoryRepository) MarkLowStockAlerted(variantIDs []uint) error {
+	if len(variantIDs) == 0 {
+		return nil
+	}
+	return c.db.Model(&models.ProductVariant{}).Where("id IN ?", variantIDs).UpdateColumn("low_stock_alerted", true).Error
+}
+func (c *InventoryRepository) GetMovements(variantID uint, page, limit int) ([]models.InventoryMovement, int64, error) {
+	var movements []models.InventoryMovement
+	var total int64
+	query := c.db.Model(&models.InventoryMovement{}).Where("variant_id = ?", variantID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&movements).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+// RecordInitialStock writes the ledger row for the stock a variant was created with.
+func RecordInitialStock(tx *gorm.DB, variant *models.ProductVariant) error {
+	if variant.Stock == 0 {
+		return nil
+	}
+	return recordMovement(tx, variant, models.MovementInitial, variant.Stock, 0, nil, "")
+}
+
+func recordMovement(tx *gorm.DB, variant *models.ProductVariant, movementType string, stockDelta, reservedDelta int, reservationID *uint, reason string) error {
+	return tx.Create(&models.InventoryMovement{
+		VariantID:     variant.ID,
+		Type:          movementType,
+		StockDelta:    stockDelta,
+		ReservedDelta: reservedDelta,
+		StockAfter:    variant.Stock,
+		ReservedAfter: variant.Reserved,
+		ReservationID: reservationID,
+		Reason:        reason,
+	}).Error
+}
+
+// stockError explains why a reservation of the variant failed.
+func stockError(tx *gorm.DB, variantID uint) error {
+	var variant models.ProductVariant
+	err := tx.Where("id = ?", variantID).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(models.VariantNotFound)
+		}
+		return err
+	}
+	return &models.StockError{SKU: variant.SKU}
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectMovement expects an inventory movement of the variant to be recorded.
+func expectMovement(mock sqlmock.Sqlmock, variantID uint, movementType string, stockDelta, reservedDelta int) {
+	mock.ExpectQuery(`INSERT INTO "inventory_movements"`).
+		WithArgs(sqlmock.AnyArg(), variantID, movementType, stockDelta, reservedDelta, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+func TestReserveStock(t *testing.T) {
+	expiresAt := time.Date(2024, 1, 31, 10, 15, 0, 0, time.UTC)
+	// requested out of order, reserved by variant ID so concurrent checkouts
+	// lock the variants in the same order
+	items := []models.ReservationItem{{VariantID: 6, Quantity: 1}, {VariantID: 5, Quantity: 2}}
+	expectReserve := func(mock sqlmock.Sqlmock, variantID uint, quantity int) {
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "product_variants" SET "reserved"=reserved + $1 WHERE (id = $2 AND deleted_at IS NULL AND stock - reserved >= $3)`)).
+			WithArgs(quantity, variantID, quantity).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "reserved"}).AddRow(variantID, 10, quantity))
+		mock.ExpectQuery(`INSERT INTO "stock_reservations"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(variantID + 10))
+		expectMovement(mock, variantID, models.MovementReserve, 0, quantity)
+	}
+
+	t.Run("reserved", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		expectReserve(mock, 5, 2)
+		expectReserve(mock, 6, 1)
+		mock.ExpectCommit()
+		reservations, err := NewInventoryRepository(db).ReserveStock(8, items, expiresAt, "ORD-20240131-48213975")
+		assert.NoError(t, err)
+		if assert.Len(t, reservations, 2) {
+			assert.Equal(t, uint(15), reservations[0].ID)
+			assert.Equal(t, models.ReservationActive, reservations[0].Status)
+			assert.Equal(t, expiresAt, reservations[1].ExpiresAt)
+		}
+	})
+
+	t.Run("out of stock", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		expectReserve(mock, 5, 2)
+		mock.ExpectQuery(`UPDATE "product_variants" SET "reserved"=reserved \+ \$1`).
+			WithArgs(1, uint(6), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT \* FROM "product_variants" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sku"}).AddRow(6, "SHIRT-L"))
+		mock.ExpectRollback()
+		_, err := NewInventoryRepository(db).ReserveStock(8, items, expiresAt, "ORD-20240131-48213975")
+		assert.Equal(t, &models.StockError{SKU: "SHIRT-L"}, err)
+	})
+
+	t.Run("deleted variant", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "product_variants"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT \* FROM "product_variants" WHERE id = \$1`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+		_, err := NewInventoryRepository(db).ReserveStock(8, items[1:], expiresAt, "ORD-20240131-48213975")
+		assert.EqualError(t, err, models.VariantNotFound)
+	})
+}
+
+// expectSettle expects the active ones of reservations 15 and 16 to be
+// locked, returning reservations.
+func expectSettle(mock sqlmock.Sqlmock, reservations *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "stock_reservations" WHERE (id IN ($1,$2) AND status = $3) AND "stock_reservations"."deleted_at" IS NULL ORDER BY variant_id FOR UPDATE`)).
+		WithArgs(uint(15), uint(16), models.ReservationActive).
+		WillReturnRows(reservations)
+}
+
+func TestCommitReservations(t *testing.T) {
+	t.Run("committed", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectSettle(mock, sqlmock.NewRows([]string{"id", "variant_id", "quantity", "status"}).
+			AddRow(15, 5, 2, models.ReservationActive).
+			AddRow(16, 6, 1, models.ReservationActive))
+		for _, reservation := range []struct {
+			id, variantID uint
+			quantity      int
+		}{{15, 5, 2}, {16, 6, 1}} {
+			mock.ExpectExec(`UPDATE "stock_reservations" SET "status"=\$1`).
+				WithArgs(models.ReservationCommitted, sqlmock.AnyArg(), reservation.id).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "product_variants" SET "reserved"=reserved - $1,"stock"=stock - $2 WHERE id = $3`)).
+				WithArgs(reservation.quantity, reservation.quantity, reservation.variantID).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "reserved"}).AddRow(reservation.variantID, 8, 0))
+			expectMovement(mock, reservation.variantID, models.MovementSale, -reservation.quantity, -reservation.quantity)
+		}
+		mock.ExpectCommit()
+		assert.NoError(t, NewInventoryRepository(db).CommitReservations([]uint{15, 16}))
+	})
+
+	t.Run("no longer active", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		// 16 was released when the order expired; its units may be sold
+		// already, so none of the order's reservations are committed
+		expectSettle(mock, sqlmock.NewRows([]string{"id", "variant_id", "quantity", "status"}).
+			AddRow(15, 5, 2, models.ReservationActive))
+		mock.ExpectRollback()
+		err := NewInventoryRepository(db).CommitReservations([]uint{15, 16})
+		assert.EqualError(t, err, models.ReservationNotActive)
+	})
+}
+
+func TestReleaseReservations(t *testing.T) {
+	tests := []struct {
+		status   string
+		movement string
+	}{
+		{models.ReservationReleased, models.MovementRelease},
+		{models.ReservationExpired, models.MovementExpire},
+	}
+	for _, test := range tests {
+		t.Run(test.status, func(t *testing.T) {
+			db, mock := mocks.NewMockDB(t)
+			expectSettle(mock, sqlmock.NewRows([]string{"id", "variant_id", "quantity", "status"}).
+				AddRow(15, 5, 2, models.ReservationActive).
+				AddRow(16, 6, 1, models.ReservationActive))
+			for _, reservation := range []struct {
+				id, variantID uint
+				quantity      int
+			}{{15, 5, 2}, {16, 6, 1}} {
+				mock.ExpectExec(`UPDATE "stock_reservations" SET "status"=\$1`).
+					WithArgs(test.status, sqlmock.AnyArg(), reservation.id).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "product_variants" SET "reserved"=reserved - $1 WHERE id = $2`)).
+					WithArgs(reservation.quantity, reservation.variantID).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "reserved"}).AddRow(reservation.variantID, 10, 0))
+				expectMovement(mock, reservation.variantID, test.movement, 0, -reservation.quantity)
+			}
+			mock.ExpectCommit()
+			assert.NoError(t, NewInventoryRepository(db).ReleaseReservations([]uint{15, 16}, test.status))
+		})
+	}
+
+	t.Run("nothing to release", func(t *testing.T) {
+		db, _ := mocks.NewMockDB(t)
+		assert.NoError(t, NewInventoryRepository(db).ReleaseReservations(nil, models.ReservationExpired))
+	})
+}
+
+func TestAdjustStock(t *testing.T) {
+	expectAdjust := func(mock sqlmock.Sqlmock, delta int, rows *sqlmock.Rows) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "product_variants" SET "low_stock_alerted"=low_stock_alerted AND stock + $1 - reserved <= low_stock_threshold,"stock"=stock + $2 WHERE (id = $3 AND stock + $4 >= reserved)`)).
+			WithArgs(delta, delta, uint(5), delta).
+			WillReturnRows(rows)
+	}
+
+	t.Run("adjusted", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectAdjust(mock, 10, sqlmock.NewRows([]string{"id", "stock", "reserved"}).AddRow(5, 12, 2))
+		expectMovement(mock, 5, models.MovementAdjustment, 10, 0)
+		mock.ExpectCommit()
+		variant, err := NewInventoryRepository(db).AdjustStock(5, 10, models.MovementAdjustment, "restock")
+		assert.NoError(t, err)
+		assert.Equal(t, 10, variant.Available())
+	})
+
+	t.Run("below the reserved quantity", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectAdjust(mock, -9, sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "product_variants" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+		_, err := NewInventoryRepository(db).AdjustStock(5, -9, models.MovementAdjustment, "damaged")
+		assert.EqualError(t, err, models.InvalidStockAdjustment)
+	})
+
+	t.Run("unknown variant", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectAdjust(mock, 1, sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "product_variants" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+		_, err := NewInventoryRepository(db).AdjustStock(5, 1, models.MovementAdjustment, "found")
+		assert.EqualError(t, err, models.VariantNotFound)
+	})
+}
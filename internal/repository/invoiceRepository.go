@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IInvoiceRepository interface {
+	GetOrderInvoice(orderID uint) (*models.Invoice, error)
+	IssueInvoice(order *models.Order, issuedAt time.Time) (*models.Invoice, error)
+	GetPendingInvoices() ([]models.Invoice, error)
+	MarkInvoiceStored(invoice *models.Invoice) error
+}
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+func (c *InvoiceRepository) GetOrderInvoice(orderID uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := c.db.Where("order_id = ?", orderID).First(&invoice).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.InvoiceNotFound)
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// IssueInvoice issues the invoice of an order that has none yet, with the
+// order row locked so it gets a single invoice, and returns it. Orders get
+// their invoice when they are paid or confirmed, see TransitionOrder; this
+// covers orders from before that.
+func (c *InvoiceRepository) IssueInvoice(order *models.Order, issuedAt time.Time) (*models.Invoice, error) {
+	var invoice *models.Invoice
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", order.ID).First(&models.Order{}).Error
+		if err != nil {
+			return err
+		}
+		invoice, err = issueInvoice(tx, order, issuedAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetPendingInvoices returns the invoices whose PDF has not been stored yet,
+// oldest first.
+func (c *InvoiceRepository) GetPendingInvoices() ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := c.db.Where("store_pending").Order("id").Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// MarkInvoiceStored records that the PDF of the invoice has been stored.
+func (c *InvoiceRepository) MarkInvoiceStored(invoice *models.Invoice) error {
+	return c.db.Model(invoice).Update("store_pending", false).Error
+}
+
+// issueInvoice takes the next number of the financial year of issuedAt and
+// records the invoice of the order, or returns the one it already has. The
+// caller holds the order row locked. Taking the number in the caller's
+// transaction gives it back on a rollback instead of leaving a gap, and the
+// PDF is stored afterwards so the sequence row is not held locked meanwhile.
+func issueInvoice(tx *gorm.DB, order *models.Order, issuedAt time.Time) (*models.Invoice, error) {
+	existing, err := NewInvoiceRepository(tx).GetOrderInvoice(order.ID)
+	if err == nil {
+		return existing, nil
+	}
+	if err.Error() != models.InvoiceNotFound {
+		return nil, err
+	}
+	sequence := models.InvoiceSequence{FinancialYear: models.FinancialYear(issuedAt), LastNumber: 1}
+	err = tx.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "financial_year"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("invoice_sequences.last_number + 1")}),
+		},
+		clause.Returning{},
+	).Create(&sequence).Error
+	if err != nil {
+		return nil, err
+	}
+	number := models.InvoiceNumber(sequence.FinancialYear, sequence.LastNumber)
+	key, err := models.InvoiceKey(sequence.FinancialYear, number)
+	if err != nil {
+		return nil, err
+	}
+	invoice := &models.Invoice{
+		OrderID:       order.ID,
+		UserID:        order.UserID,
+		Number:        number,
+		FinancialYear: sequence.FinancialYear,
+		Sequence:      sequence.LastNumber,
+		IssuedAt:      issuedAt,
+		Total:         order.Total,
+		Key:           key,
+		StorePending:  true,
+	}
+	if err := tx.Create(invoice).Error; err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestIssueInvoice(t *testing.T) {
+	order := &models.Order{Model: gorm.Model{ID: 3}, UserID: 8, Total: 11800}
+	issuedAt := time.Date(2025, time.March, 31, 19, 0, 0, 0, time.UTC)
+
+	t.Run("takes the next number", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM "invoices" WHERE order_id = \$1`).WithArgs(uint(3), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`INSERT INTO "invoice_sequences" .* ON CONFLICT \("financial_year"\) DO UPDATE SET "last_number"=invoice_sequences.last_number \+ 1 RETURNING`).
+			WithArgs("2025-26", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"financial_year", "last_number"}).AddRow("2025-26", 42))
+		// recorded before the PDF is stored, which happens after commit
+		mock.ExpectQuery(`INSERT INTO "invoices"`).
+			WithArgs(sqlmock.AnyArg(), uint(3), uint(8), "INV-2025-26-000042", "2025-26", 42, issuedAt, int64(11800), sqlmock.AnyArg(), true).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		var invoice *models.Invoice
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			invoice, err = issueInvoice(tx, order, issuedAt)
+			return err
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "INV-2025-26-000042", invoice.Number)
+		assert.True(t, invoice.StorePending)
+		assert.Regexp(t, `^invoices/2025-26/INV-2025-26-000042-[0-9A-Z]{16}\.pdf$`, invoice.Key)
+	})
+
+	t.Run("already issued", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "invoices" WHERE order_id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "number"}).AddRow(1, 3, "INV-2024-25-000007"))
+		invoice, err := issueInvoice(db, order, issuedAt)
+		assert.NoError(t, err)
+		assert.Equal(t, "INV-2024-25-000007", invoice.Number)
+	})
+}
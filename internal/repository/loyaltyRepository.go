@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ILoyaltyRepository interface {
+	GetRules() ([]models.LoyaltyRule, error)
+	SaveRule(rule *models.LoyaltyRule) error
+	DeleteRule(categoryID uint) error
+	GetAccount(userID uint) (*models.LoyaltyAccount, error)
+	GetTransactions(userID uint, page int, limit int) ([]models.LoyaltyTransaction, int64, error)
+	GetExpiringPoints(userID uint, before time.Time) (int64, error)
+	Credit(userID uint, transactionType string, points int64, orderID *uint, reference string, expiresAt time.Time) (*models.LoyaltyTransaction, error)
+	Debit(userID uint, transactionType string, points int64, orderID *uint, reference string) (*models.LoyaltyTransaction, error)
+	Reverse(userID uint, points int64, orderID uint, reference string) error
+	ExpirePoints(userID uint, now time.Time) error
+}
+type LoyaltyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyRepository(db *gorm.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+func (c *LoyaltyRepository) GetRules() ([]models.LoyaltyRule, error) {
+	var rules []models.LoyaltyRule
+	err := c.db.Order("category_id").Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SaveRule creates the rule of its category or replaces the existing one.
+func (c *LoyaltyRepository) SaveRule(rule *models.LoyaltyRule) error {
+	return c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rule).Error
+}
+func (c *LoyaltyRepository) DeleteRule(categoryID uint) error {
+	result := c.db.Where("category_id = ?", categoryID).Delete(&models.LoyaltyRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.LoyaltyRuleNotFound)
+	}
+	return nil
+}
+
+// GetAccount returns the user's points account, or an empty one if the user
+// never earned points.
+func (c *LoyaltyRepository) GetAccount(userID uint) (*models.LoyaltyAccount, error) {
+	var account models.LoyaltyAccount
+	err := c.db.Where("user_id = ?", userID).First(&account).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.LoyaltyAccount{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+func (c *LoyaltyRepository) GetTransactions(userID uint, page int, limit int) ([]models.LoyaltyTransaction, int64, error) {
+	query := c.db.Model(&models.LoyaltyTransaction{}).Where("user_id = ?", userID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var transactions []models.LoyaltyTransaction
+	err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+// GetExpiringPoints returns the user's points that expire before the given time.
+func (c *LoyaltyRepository) GetExpiringPoints(userID uint, before time.Time) (int64, error) {
+	var points int64
+	err := c.db.Model(&models.LoyaltyTransaction{}).
+		Where("user_id = ? AND remaining > 0 AND expires_at < ?", userID, before).
+		Select("COALESCE(SUM(remaining), 0)").
+		Scan(&points).Error
+	return points, err
+}
+
+// Credit adds points to the user's balance as a new lot expiring at expiresAt.
+func (c *LoyaltyRepository) Credit(userID uint, transactionType string, points int64, orderID *uint, reference string, expiresAt time.Time) (*models.LoyaltyTransaction, error) {
+	var transaction models.LoyaltyTransaction
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		balance, err := changeLoyaltyBalance(tx, userID, points)
+		if err != nil {
+			return err
+		}
+		transaction = models.LoyaltyTransaction{
+			UserID:       userID,
+			Type:         transactionType,
+			Points:       points,
+			BalanceAfter: balance,
+			Remaining:    points,
+			ExpiresAt:    &expiresAt,
+			OrderID:      orderID,
+			Reference:    reference,
+		}
+		return tx.Create(&transaction).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Debit takes points from the user's balance, using up the lots that expire
+// first. Lapsed lots are expired beforehand so they cannot be spent. Like
+// wallet debits the balance update is conditional, so spending more than the
+// balance fails with InsufficientLoyaltyPoints even under concurrency.
+func (c *LoyaltyRepository) Debit(userID uint, transactionType string, points int64, orderID *uint, reference string) (*models.LoyaltyTransaction, error) {
+	var transaction *models.LoyaltyTransaction
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := NewLoyaltyRepository(tx).ExpirePoints(userID, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		transaction, err = debitLoyaltyLots(tx, userID, transactionType, points, orderID, reference)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// Reverse takes back points earned on an order, using up the order's own lot
+// first. Points that were already spent are not clawed back beyond the
+// current balance.
+func (c *LoyaltyRepository) Reverse(userID uint, points int64, orderID uint, reference string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		account, err := NewLoyaltyRepository(tx).GetAccount(userID)
+		if err != nil {
+			return err
+		}
+		points = min(points, account.Balance)
+		if points <= 0 {
+			return nil
+		}
+		_, err = debitLoyaltyLots(tx, userID, models.LoyaltyReverse, points, &orderID, reference)
+		return err
+	})
+}
+
+// ExpirePoints expires the remaining points of the lots that lapsed before
+// now, of the user or of all users when userID is zero.
+func (c *LoyaltyRepository) ExpirePoints(userID uint, now time.Time) error {
+	query := c.db.Where("remaining > 0 AND expires_at <= ?", now)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var lots []models.LoyaltyTransaction
+	if err := query.Order("id").Find(&lots).Error; err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		err := c.db.Transaction(func(tx *gorm.DB) error {
+			// recheck the lot under lock, it may have been spent meanwhile
+			var current models.LoyaltyTransaction
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", lot.ID).First(&current).Error
+			if err != nil || current.Remaining == 0 {
+				return err
+			}
+			if err := tx.Model(&current).Update("remaining", 0).Error; err != nil {
+				return err
+			}
+			balance, err := changeLoyaltyBalance(tx, current.UserID, -current.Remaining)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&models.LoyaltyTransaction{
+				UserID:       current.UserID,
+				Type:         models.LoyaltyExpire,
+				Points:       -current.Remaining,
+				BalanceAfter: balance,
+				OrderID:      current.OrderID,
+				Reference:    current.Reference,
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changeLoyaltyBalance changes the user's balance by points and returns the
+// new balance, failing with InsufficientLoyaltyPoints if it would go negative.
+func changeLoyaltyBalance(tx *gorm.DB, userID uint, points int64) (int64, error) {
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoyaltyAccount{UserID: userID}).Error
+	if err != nil {
+		return 0, err
+	}
+	var account models.LoyaltyAccount
+	result := tx.Model(&account).Clauses(clause.Returning{}).
+		Where("user_id = ? AND balance + ? >= 0", userID, points).
+		UpdateColumns(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", points),
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, errors.New(models.InsufficientLoyaltyPoints)
+	}
+	return account.Balance, nil
+}
+
+// debitLoyaltyLots takes points from the balance and from the user's lots,
+// those of orderID first and then those expiring first.
+func debitLoyaltyLots(tx *gorm.DB, userID uint, transactionType string, points int64, orderID *uint, reference string) (*models.LoyaltyTransaction, error) {
+	balance, err := changeLoyaltyBalance(tx, userID, -points)
+	if err != nil {
+		return nil, err
+	}
+	var preferred uint
+	if orderID != nil && transactionType == models.LoyaltyReverse {
+		preferred = *orderID
+	}
+	var lots []models.LoyaltyTransaction
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND remaining > 0", userID).
+		Order(clause.Expr{SQL: "CASE WHEN order_id = ? THEN 0 ELSE 1 END, expires_at, id", Vars: []interface{}{preferred}}).
+		Find(&lots).Error
+	if err != nil {
+		return nil, err
+	}
+	left := points
+	for _, lot := range lots {
+		if left == 0 {
+			break
+		}
+		used := min(left, lot.Remaining)
+		if err := tx.Model(&lot).Update("remaining", lot.Remaining-used).Error; err != nil {
+			return nil, err
+		}
+		left -= used
+	}
+	transaction := &models.LoyaltyTransaction{
+		UserID:       userID,
+		Type:         transactionType,
+		Points:       -points,
+		BalanceAfter: balance,
+		OrderID:      orderID,
+		Reference:    reference,
+	}
+	if err := tx.Create(transaction).Error; err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
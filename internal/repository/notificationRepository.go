@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type INotificationRepository interface {
+	CreateNotification(notification *models.Notification) error
+	GetNotifications(userID uint, unreadOnly bool, page int, limit int) ([]models.Notification, int64, error)
+	MarkRead(userID uint, notificationID uint) error
+	MarkAllRead(userID uint) error
+	GetOptOuts(userID uint) ([]models.NotificationOptOut, error)
+	SetOptOuts(userID uint, optIn []models.NotificationOptOut, optOut []models.NotificationOptOut) error
+}
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+func (c *NotificationRepository) CreateNotification(notification *models.Notification) error {
+	return c.db.Create(notification).Error
+}
+func (c *NotificationRepository) GetNotifications(userID uint, unreadOnly bool, page int, limit int) ([]models.Notification, int64, error) {
+	query := c.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var notifications []models.Notification
+	err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+func (c *NotificationRepository) MarkRead(userID uint, notificationID uint) error {
+	result := c.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("read_at", gorm.Expr("COALESCE(read_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.NotificationNotFound)
+	}
+	return nil
+}
+func (c *NotificationRepository) MarkAllRead(userID uint) error {
+	return c.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+func (c *NotificationRepository) GetOptOuts(userID uint) ([]models.NotificationOptOut, error) {
+	var optOuts []models.NotificationOptOut
+	err := c.db.Where("user_id = ?", userID).Find(&optOuts).Error
+	if err != nil {
+		return nil, err
+	}
+	return optOuts, nil
+}
+
+// SetOptOuts removes the optIn rows and adds the optOut rows in one transaction.
+func (c *NotificationRepository) SetOptOuts(userID uint, optIn []models.NotificationOptOut, optOut []models.NotificationOptOut) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range optIn {
+			err := tx.Where("user_id = ? AND type = ? AND channel = ?", userID, row.Type, row.Channel).
+				Delete(&models.NotificationOptOut{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(optOut) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&optOut).Error
+	})
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IOrderRepository interface {
+	PlaceOrder(order *models.Order, cartID uint, redemptions []models.CouponRedemption) error
+	GetOrders(userID uint, status string, page int, limit int) ([]models.Order, int64, error)
+	GetOrder(userID uint, orderID uint) (*models.Order, error)
+	GetOrderByID(orderID uint) (*models.Order, error)
+	TransitionOrder(order *models.Order, status string, actor string, note string) error
+	GetExpiredOrders(now time.Time) ([]models.Order, error)
+	GetOrderHistory(orderID uint) ([]models.OrderHistory, error)
+}
+type OrderRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// PlaceOrder reserves stock for every item until order.ExpiresAt, stores the
+// order, redeems its coupons and empties the cart in a single transaction.
+// Nothing is written when any item cannot be reserved or a coupon is used up.
+func (c *OrderRepository) PlaceOrder(order *models.Order, cartID uint, redemptions []models.CouponRedemption) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		items := make([]models.ReservationItem, 0, len(order.Items))
+		for _, item := range order.Items {
+			items = append(items, models.ReservationItem{VariantID: item.VariantID, Quantity: item.Quantity})
+		}
+		reservations, err := NewInventoryRepository(tx).ReserveStock(order.UserID, items, order.ExpiresAt, order.OrderNumber)
+		if err != nil {
+			return err
+		}
+		byVariant := make(map[uint]uint, len(reservations))
+		for _, reservation := range reservations {
+			byVariant[reservation.VariantID] = reservation.ID
+		}
+		for i := range order.Items {
+			reservationID := byVariant[order.Items[i].VariantID]
+			order.Items[i].ReservationID = &reservationID
+		}
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if err := redeemCoupons(tx, order, redemptions); err != nil {
+			return err
+		}
+		if err := recordHistory(tx, order.ID, nil, "", order.Status, models.ActorUser, ""); err != nil {
+			return err
+		}
+		if err := tx.Where("cart_id = ?", cartID).Delete(&models.CartCoupon{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+	})
+}
+
+// GetOrders returns one page of the user's orders, newest first, optionally
+// filtered by status. A zero userID returns the orders of all users.
+func (c *OrderRepository) GetOrders(userID uint, status string, page int, limit int) ([]models.Order, int64, error) {
+	query := c.db.Model(&models.Order{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var orders []models.Order
+	err := query.Preload("Items").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+func (c *OrderRepository) GetOrder(userID uint, orderID uint) (*models.Order, error) {
+	return c.getOrder(c.db.Where("id = ? AND user_id = ?", orderID, userID))
+}
+func (c *OrderRepository) GetOrderByID(orderID uint) (*models.Order, error) {
+	return c.getOrder(c.db.Where("id = ?", orderID))
+}
+func (c *OrderRepository) getOrder(query *gorm.DB) (*models.Order, error) {
+	var order models.Order
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.OrderNotFound)
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// TransitionOrder moves the order to status and applies the inventory side
+// of the change in the same transaction: payment or confirmation turns the
+// reservations into sales and issues the invoice, cancelling releases them,
+// or puts the stock back when it was already sold, and gives back the coupon
+// uses. Cash on delivery payments are marked collected on delivery and failed
+// on cancellation, and delivery credits the loyalty points earned on the
+// order and the rewards of a pending referral of the user. The change is
+// recorded in the order history with actor and note. The update only succeeds
+// if the order is still in the status it was read with, so concurrent
+// transitions cannot both apply.
+func (c *OrderRepository) TransitionOrder(order *models.Order, status string, actor string, note string) error {
+	if !models.CanTransition(order.Status, status) {
+		return errors.New(models.InvalidOrderTransition)
+	}
+	now := time.Now()
+	var updated models.Order
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&updated).Clauses(clause.Returning{}).
+			Where("id = ? AND status = ?", order.ID, order.Status).
+			Updates(map[string]interface{}{"status": status, statusTimestamp(status): now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.InvalidOrderTransition)
+		}
+		if err := recordHistory(tx, order.ID, nil, order.Status, status, actor, note); err != nil {
+			return err
+		}
+		inventoryRepo := NewInventoryRepository(tx)
+		reservationIDs := orderReservationIDs(order)
+		switch {
+		case status == models.OrderPaid || status == models.OrderConfirmed:
+			if err := inventoryRepo.CommitReservations(reservationIDs); err != nil {
+				return err
+			}
+			_, err := issueInvoice(tx, order, now)
+			return err
+		case status == models.OrderDelivered:
+			if order.LoyaltyPoints > 0 {
+				_, err := NewLoyaltyRepository(tx).Credit(order.UserID, models.LoyaltyEarn, order.LoyaltyPoints, &order.ID, order.OrderNumber, now.Add(models.LoyaltyPointsValidity))
+				if err != nil {
+					return err
+				}
+			}
+			if err := rewardReferral(tx, order, now); err != nil {
+				return err
+			}
+			return settleCODPayment(tx, order.ID, map[string]interface{}{
+				"status":      models.PaymentSucceeded,
+				"captured_at": now,
+			})
+		case status == models.OrderCancelled && order.Status == models.OrderPending:
+			if err := releaseCoupons(tx, order.ID); err != nil {
+				return err
+			}
+			return inventoryRepo.ReleaseReservations(reservationIDs, models.ReservationReleased)
+		case status == models.OrderCancelled:
+			if err := releaseCoupons(tx, order.ID); err != nil {
+				return err
+			}
+			for _, item := range order.Items {
+				_, err := inventoryRepo.AdjustStock(item.VariantID, item.Quantity, models.MovementReturn, order.OrderNumber)
+				if err != nil {
+					return err
+				}
+			}
+			return settleCODPayment(tx, order.ID, map[string]interface{}{
+				"status":         models.PaymentFailed,
+				"failure_reason": "order cancelled",
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	items := order.Items
+	*order = updated
+	order.Items = items
+	return nil
+}
+
+// GetExpiredOrders returns pending orders whose payment window has passed.
+func (c *OrderRepository) GetExpiredOrders(now time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	err := c.db.Preload("Items").
+		Where("status = ? AND expires_at < ?", models.OrderPending, now).
+		Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetOrderHistory returns the status changes of the order and its return
+// requests, oldest first.
+func (c *OrderRepository) GetOrderHistory(orderID uint) ([]models.OrderHistory, error) {
+	var history []models.OrderHistory
+	err := c.db.Where("order_id = ?", orderID).Order("id").Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+func recordHistory(tx *gorm.DB, orderID uint, returnRequestID *uint, from string, to string, actor string, note string) error {
+	return tx.Create(&models.OrderHistory{
+		OrderID:         orderID,
+		ReturnRequestID: returnRequestID,
+		FromStatus:      from,
+		ToStatus:        to,
+		Actor:           actor,
+		Note:            note,
+	}).Error
+}
+
+// settleCODPayment updates the uncollected cash on delivery payment of the order, if any.
+func settleCODPayment(tx *gorm.DB, orderID uint, fields map[string]interface{}) error {
+	return tx.Model(&models.Payment{}).
+		Where("order_id = ? AND provider = ? AND status = ?", orderID, models.PaymentMethodCOD, models.PaymentCreated).
+		Updates(fields).Error
+}
+func orderReservationIDs(order *models.Order) []uint {
+	ids := make([]uint, 0, len(order.Items))
+	for _, item := range order.Items {
+		if item.ReservationID != nil {
+			ids = append(ids, *item.ReservationID)
+		}
+	}
+	return ids
+}
+
+// statusTimestamp returns the column recording when an order entered status.
+func statusTimestamp(status string) string {
+	switch status {
+	case models.OrderPaid:
+		return "paid_at"
+	case models.OrderConfirmed:
+		return "confirmed_at"
+	case models.OrderShipped:
+		return "shipped_at"
+	case models.OrderDelivered:
+		return "delivered_at"
+	case models.OrderCancelled:
+		return "cancelled_at"
+	}
+	return "returned_at"
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IPaymentRepository interface {
+	CreatePayment(payment *models.Payment) error
+	GetOpenPayment(orderID uint) (*models.Payment, error)
+	GetPaymentByRef(provider string, providerRef string) (*models.Payment, error)
+	GetOrderPayments(orderID uint) ([]models.Payment, error)
+	UpdatePayment(payment *models.Payment, fromStatus string, fields map[string]interface{}) (bool, error)
+	AddRefundedAmount(payment *models.Payment, amount int64) error
+	CreateRefund(refund *models.Refund) error
+	GetOrderRefunds(orderID uint) ([]models.Refund, error)
+	GetPendingRefunds() ([]models.Refund, error)
+	UpdateRefund(refund *models.Refund, fields map[string]interface{}) error
+	Transaction(fn func(payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error
+	ProcessEvent(event *models.PaymentEvent, apply func(payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error
+	GetCODPincodes() ([]models.CODPincode, error)
+	IsCODPincode(postalCode string) (bool, error)
+	AddCODPincodes(postalCodes []string) error
+	RemoveCODPincode(postalCode string) error
+}
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+func (c *PaymentRepository) CreatePayment(payment *models.Payment) error {
+	return c.db.Create(payment).Error
+}
+
+// GetOpenPayment returns the latest payment of the order that the customer
+// can still complete.
+func (c *PaymentRepository) GetOpenPayment(orderID uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := c.db.Where("order_id = ? AND status = ?", orderID, models.PaymentCreated).
+		Order("id DESC").First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.PaymentNotFound)
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+func (c *PaymentRepository) GetPaymentByRef(provider string, providerRef string) (*models.Payment, error) {
+	var payment models.Payment
+	err := c.db.Where("provider = ? AND provider_ref = ?", provider, providerRef).First(&payment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.PaymentNotFound)
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+func (c *PaymentRepository) GetOrderPayments(orderID uint) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := c.db.Where("order_id = ?", orderID).Order("id").Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// UpdatePayment applies fields only if the payment is still in fromStatus and
+// reports whether it did, so each status change happens exactly once.
+func (c *PaymentRepository) UpdatePayment(payment *models.Payment, fromStatus string, fields map[string]interface{}) (bool, error) {
+	result := c.db.Model(payment).Clauses(clause.Returning{}).
+		Where("status = ?", fromStatus).
+		Updates(fields)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AddRefundedAmount raises the refunded amount of a succeeded payment and
+// marks it refunded once nothing is left. It fails with RefundExceedsPaid
+// if more than the captured amount would be refunded.
+func (c *PaymentRepository) AddRefundedAmount(payment *models.Payment, amount int64) error {
+	result := c.db.Model(payment).Clauses(clause.Returning{}).
+		Where("status = ? AND refunded_amount + ? <= amount", models.PaymentSucceeded, amount).
+		Updates(map[string]interface{}{
+			"refunded_amount": gorm.Expr("refunded_amount + ?", amount),
+			"status":          gorm.Expr("CASE WHEN refunded_amount + ? >= amount THEN ? ELSE status END", amount, models.PaymentRefunded),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.RefundExceedsPaid)
+	}
+	return nil
+}
+func (c *PaymentRepository) CreateRefund(refund *models.Refund) error {
+	return c.db.Omit(clause.Associations).Create(refund).Error
+}
+func (c *PaymentRepository) GetOrderRefunds(orderID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := c.db.Where("order_id = ?", orderID).Order("id").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// GetPendingRefunds returns the gateway refunds not yet accepted by the
+// provider, oldest first, with their payments.
+func (c *PaymentRepository) GetPendingRefunds() ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := c.db.Preload("Payment").Where("status = ?", models.RefundPending).Order("id").Find(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// UpdateRefund updates a refund that is still pending, so a refund completed
+// concurrently is not changed again.
+func (c *PaymentRepository) UpdateRefund(refund *models.Refund, fields map[string]interface{}) error {
+	return c.db.Model(refund).Where("status = ?", models.RefundPending).Updates(fields).Error
+}
+
+// Transaction calls fn with repositories bound to a single transaction.
+func (c *PaymentRepository) Transaction(fn func(payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewPaymentRepository(tx), NewOrderRepository(tx), NewWalletRepository(tx), NewGiftCardRepository(tx), NewLoyaltyRepository(tx))
+	})
+}
+
+// ProcessEvent records the webhook event and calls apply in the same
+// transaction. Events that were already recorded are skipped, and a failing
+// apply rolls the record back so the provider's retry is processed again.
+func (c *PaymentRepository) ProcessEvent(event *models.PaymentEvent, apply func(payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error {
+	return c.Transaction(func(payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error {
+		result := payments.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return apply(payments, orders, wallets, giftCards, loyalty)
+	})
+}
+
+func (c *PaymentRepository) GetCODPincodes() ([]models.CODPincode, error) {
+	var pincodes []models.CODPincode
+	err := c.db.Order("postal_code").Find(&pincodes).Error
+	if err != nil {
+		return nil, err
+	}
+	return pincodes, nil
+}
+func (c *PaymentRepository) IsCODPincode(postalCode string) (bool, error) {
+	var count int64
+	err := c.db.Model(&models.CODPincode{}).Where("postal_code = ?", postalCode).Count(&count).Error
+	return count > 0, err
+}
+func (c *PaymentRepository) AddCODPincodes(postalCodes []string) error {
+	pincodes := make([]models.CODPincode, 0, len(postalCodes))
+	for _, postalCode := range postalCodes {
+		pincodes = append(pincodes, models.CODPincode{PostalCode: postalCode})
+	}
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&pincodes).Error
+}
+func (c *PaymentRepository) RemoveCODPincode(postalCode string) error {
+	return c.db.Where("postal_code = ?", postalCode).Delete(&models.CODPincode{}).Error
+}
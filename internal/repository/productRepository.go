@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+	"gorm.io/gorm"
+)
+
+type IProductRepository interface {
+	GetProducts(filter models.ProductFilter, categoryIDs []uint) ([]models.Product, int64, error)
+	GetProduct(productID uint, includeInactive bool) (*models.Product, error)
+	CreateProduct(product *models.Product) error
+	UpdateProduct(product *models.Product) error
+	DeleteProduct(productID uint) error
+	GetVariant(productID uint, variantID uint) (*models.ProductVariant, error)
+	GetVariantByID(variantID uint) (*models.ProductVariant, error)
+	GetProductsByIDs(productIDs []uint) (map[uint]models.Product, error)
+	GetCatalogProducts(productIDs []uint, includeInactive bool) (map[uint]models.Product, error)
+	GetProductIDs() ([]uint, error)
+	CreateVariant(variant *models.ProductVariant) error
+	UpdateVariant(variant *models.ProductVariant) error
+	DeleteVariant(productID uint, variantID uint) error
+}
+type ProductRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) *ProductRepository {
+	return &ProductRepository{db: db}
+}
+
+var productSortOrders = map[string]string{
+	models.SortNewest:    "products.created_at DESC, products.id DESC",
+	models.SortPriceAsc:  "min_price ASC NULLS LAST, products.id",
+	models.SortPriceDesc: "min_price DESC NULLS LAST, products.id",
+	models.SortName:      "products.name ASC, products.id",
+}
+
+// GetProducts returns one page of products matching the filter, with their
+// variants, and the total number of matches. A product matches when at least
+// one of its variants matches the price and attribute filters; price sorting
+// uses the cheapest matching variant.
+func (c *ProductRepository) GetProducts(filter models.ProductFilter, categoryIDs []uint) ([]models.Product, int64, error) {
+	base := func() *gorm.DB {
+		query := c.db.Model(&models.Product{}).
+			Joins("LEFT JOIN product_variants ON product_variants.product_id = products.id AND product_variants.deleted_at IS NULL")
+		if !filter.IncludeInactive {
+			query = query.Where("products.is_active AND product_variants.id IS NOT NULL")
+		}
+		if categoryIDs != nil {
+			query = query.Where("products.category_id IN ?", categoryIDs)
+		}
+		if filter.Brand != "" {
+			query = query.Where("LOWER(products.brand) = LOWER(?)", filter.Brand)
+		}
+		if filter.MinPrice > 0 {
+			query = query.Where("product_variants.price >= ?", filter.MinPrice)
+		}
+		if filter.MaxPrice > 0 {
+			query = query.Where("product_variants.price <= ?", filter.MaxPrice)
+		}
+		if filter.Size != "" {
+			query = query.Where("LOWER(product_variants.size) = LOWER(?)", filter.Size)
+		}
+		if filter.Colour != "" {
+			query = query.Where("LOWER(product_variants.colour) = LOWER(?)", filter.Colour)
+		}
+		return query
+	}
+	var total int64
+	err := base().Distinct("products.id").Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	order, ok := productSortOrders[filter.Sort]
+	if !ok {
+		order = productSortOrders[models.SortNewest]
+	}
+	var rows []struct {
+		ID       uint
+		MinPrice *int64
+	}
+	err = base().
+		Select("products.id, MIN(product_variants.price) AS min_price").
+		Group("products.id").
+		Order(order).
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []models.Product{}, total, nil
+	}
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var products []models.Product
+	err = c.db.Preload("Category").Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("price, id")
+	}).Preload("Variants.Prices").Where("id IN ?", ids).Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	// restore the sort order of the page query
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	sorted := make([]models.Product, 0, len(products))
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			sorted = append(sorted, product)
+		}
+	}
+	return sorted, total, nil
+}
+func (c *ProductRepository) GetProduct(productID uint, includeInactive bool) (*models.Product, error) {
+	var product models.Product
+	query := c.db.Preload("Category").Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("price, id")
+	}).Preload("Variants.Prices").Where("id = ?", productID)
+	if !includeInactive {
+		query = query.Where("is_active")
+	}
+	err := query.First(&product).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ProductNotFound)
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateProduct inserts the product together with its variants. A product
+// whose name gives no slug is given one from its ID once inserted.
+func (c *ProductRepository) CreateProduct(product *models.Product) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category").Create(product).Error; err != nil {
+			return err
+		}
+		if product.Slug == "" {
+			product.Slug = utils.IDSlug("product", product.ID)
+			if err := tx.Model(product).Update("slug", product.Slug).Error; err != nil {
+				return err
+			}
+		}
+		for i := range product.Variants {
+			if err := RecordInitialStock(tx, &product.Variants[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateProduct)
+	}
+	return err
+}
+
+// UpdateProduct updates the product's own fields; variants are managed separately.
+func (c *ProductRepository) UpdateProduct(product *models.Product) error {
+	if product.Slug == "" {
+		product.Slug = utils.IDSlug("product", product.ID)
+	}
+	err := c.db.Model(product).
+		Select("name", "slug", "description", "brand", "category_id", "is_active").
+		Updates(product).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateSlug)
+	}
+	return err
+}
+func (c *ProductRepository) DeleteProduct(productID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Product{}, productID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.ProductNotFound)
+		}
+		return tx.Where("product_id = ?", productID).Delete(&models.ProductVariant{}).Error
+	})
+}
+func (c *ProductRepository) GetVariant(productID uint, variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := c.db.Where("id = ? AND product_id = ?", variantID, productID).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.VariantNotFound)
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+func (c *ProductRepository) GetVariantByID(variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := c.db.Where("id = ?", variantID).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.VariantNotFound)
+		}
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// GetProductsByIDs returns the products with the given IDs, including soft deleted ones.
+func (c *ProductRepository) GetProductsByIDs(productIDs []uint) (map[uint]models.Product, error) {
+	var products []models.Product
+	err := c.db.Unscoped().Where("id IN ?", productIDs).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+// GetCatalogProducts returns the products with the given IDs with their
+// category and variants, like GetProduct. Deleted products are left out.
+func (c *ProductRepository) GetCatalogProducts(productIDs []uint, includeInactive bool) (map[uint]models.Product, error) {
+	var products []models.Product
+	query := c.db.Preload("Category").Preload("Variants", func(db *gorm.DB) *gorm.DB {
+		return db.Order("price, id")
+	}).Preload("Variants.Prices").Where("id IN ?", productIDs)
+	if !includeInactive {
+		query = query.Where("is_active")
+	}
+	if err := query.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+	return byID, nil
+}
+
+// GetProductIDs returns the IDs of all products that are not deleted.
+func (c *ProductRepository) GetProductIDs() ([]uint, error) {
+	var ids []uint
+	err := c.db.Model(&models.Product{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetCategoryProductIDs returns the IDs of the products in the category,
+// not counting its subcategories.
+func (c *ProductRepository) GetCategoryProductIDs(categoryID uint) ([]uint, error) {
+	var ids []uint
+	err := c.db.Model(&models.Product{}).Where("category_id = ?", categoryID).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+func (c *ProductRepository) CreateVariant(variant *models.ProductVariant) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(variant).Error; err != nil {
+			return err
+		}
+		return RecordInitialStock(tx, variant)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateSKU)
+	}
+	return err
+}
+
+// UpdateVariant updates the descriptive fields, price and weight, and
+// replaces the currency prices unless variant.Prices is nil. Stock is changed
+// through the inventory repository only.
+func (c *ProductRepository) UpdateVariant(variant *models.ProductVariant) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(variant).
+			Select("sku", "size", "colour", "price", "weight", "low_stock_threshold").
+			Updates(variant).Error
+		if err != nil || variant.Prices == nil {
+			return err
+		}
+		if err := tx.Where("variant_id = ?", variant.ID).Delete(&models.VariantPrice{}).Error; err != nil {
+			return err
+		}
+		if len(variant.Prices) == 0 {
+			return nil
+		}
+		for i := range variant.Prices {
+			variant.Prices[i].VariantID = variant.ID
+		}
+		return tx.Create(&variant.Prices).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateSKU)
+	}
+	return err
+}
+func (c *ProductRepository) DeleteVariant(productID uint, variantID uint) error {
+	result := c.db.Where("id = ? AND product_id = ?", variantID, productID).Delete(&models.ProductVariant{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.VariantNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRecommendationRepository interface {
+	RecordView(owner models.CartOwner, productID uint, at time.Time) error
+	GetRecentlyViewed(owner models.CartOwner, limit int) ([]uint, error)
+	DeleteGuestViewsBefore(before time.Time) error
+	GetRecommendations(productID uint, kind string, limit int) ([]uint, error)
+	RebuildBoughtTogether(since time.Time, minScore int, perProduct int) error
+	RebuildAlsoViewed(since time.Time, minScore int, perProduct int) error
+}
+type RecommendationRepository struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepository(db *gorm.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+// RecordView stores a view of the product by owner at the given time,
+// counting repeated views of the same product on one row.
+func (c *RecommendationRepository) RecordView(owner models.CartOwner, productID uint, at time.Time) error {
+	view := models.ProductView{ProductID: productID, ViewCount: 1, ViewedAt: at}
+	columns := []clause.Column{{Name: "user_id"}, {Name: "product_id"}}
+	if owner.UserID != 0 {
+		view.UserID = &owner.UserID
+	} else {
+		view.GuestToken = &owner.GuestToken
+		columns = []clause.Column{{Name: "guest_token"}, {Name: "product_id"}}
+	}
+	return c.db.Clauses(clause.OnConflict{
+		Columns: columns,
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"view_count": gorm.Expr("product_views.view_count + 1"),
+			"viewed_at":  at,
+		}),
+	}).Create(&view).Error
+}
+
+// GetRecentlyViewed returns the IDs of the products last viewed by owner,
+// most recent first.
+func (c *RecommendationRepository) GetRecentlyViewed(owner models.CartOwner, limit int) ([]uint, error) {
+	var ids []uint
+	err := c.db.Model(&models.ProductView{}).Scopes(ownerScope(owner)).
+		Order("viewed_at DESC, id DESC").
+		Limit(limit).
+		Pluck("product_id", &ids).Error
+	return ids, err
+}
+func (c *RecommendationRepository) DeleteGuestViewsBefore(before time.Time) error {
+	return c.db.Where("guest_token IS NOT NULL AND viewed_at < ?", before).Delete(&models.ProductView{}).Error
+}
+
+// GetRecommendations returns the IDs of the products recommended of kind for
+// the product, best first.
+func (c *RecommendationRepository) GetRecommendations(productID uint, kind string, limit int) ([]uint, error) {
+	var ids []uint
+	err := c.db.Model(&models.ProductRecommendation{}).
+		Where("product_id = ? AND kind = ?", productID, kind).
+		Order("score DESC, recommended_id").
+		Limit(limit).
+		Pluck("recommended_id", &ids).Error
+	return ids, err
+}
+
+// RebuildBoughtTogether recomputes the bought together recommendations from
+// the orders placed since the given time, scoring each pair of products by
+// the number of orders containing both. Unpaid and cancelled orders are not
+// counted.
+func (c *RecommendationRepository) RebuildBoughtTogether(since time.Time, minScore int, perProduct int) error {
+	pairs := `SELECT a.product_id, b.product_id AS recommended_id, COUNT(DISTINCT a.order_id) AS score
+	FROM order_items a
+	JOIN order_items b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+	JOIN orders ON orders.id = a.order_id
+	WHERE orders.deleted_at IS NULL AND orders.status NOT IN ? AND orders.created_at >= ?
+	GROUP BY a.product_id, b.product_id`
+	return c.replaceRecommendations(models.RecommendationBoughtTogether, pairs,
+		[]interface{}{[]string{models.OrderPending, models.OrderCancelled}, since}, minScore, perProduct)
+}
+
+// RebuildAlsoViewed recomputes the also viewed recommendations from the
+// views since the given time, scoring each pair of products by the number of
+// users and guests who viewed both.
+func (c *RecommendationRepository) RebuildAlsoViewed(since time.Time, minScore int, perProduct int) error {
+	pairs := `WITH views AS (
+		SELECT product_id, COALESCE('u' || user_id, 'g' || guest_token) AS viewer
+		FROM product_views WHERE viewed_at >= ?
+	)
+	SELECT a.product_id, b.product_id AS recommended_id, COUNT(*) AS score
+	FROM views a
+	JOIN views b ON b.viewer = a.viewer AND b.product_id <> a.product_id
+	GROUP BY a.product_id, b.product_id`
+	return c.replaceRecommendations(models.RecommendationAlsoViewed, pairs, []interface{}{since}, minScore, perProduct)
+}
+
+// replaceRecommendations swaps all recommendations of kind for the pairs
+// selected by pairsSQL, which yields product_id, recommended_id and score,
+// keeping the perProduct best pairs of each product that reach minScore.
+func (c *RecommendationRepository) replaceRecommendations(kind string, pairsSQL string, pairsArgs []interface{}, minScore int, perProduct int) error {
+	args := append([]interface{}{kind}, pairsArgs...)
+	args = append(args, minScore, perProduct)
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("kind = ?", kind).Delete(&models.ProductRecommendation{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO product_recommendations (product_id, kind, recommended_id, score, updated_at)
+SELECT product_id, ?, recommended_id, score, NOW() FROM (
+	SELECT product_id, recommended_id, score,
+		ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY score DESC, recommended_id) AS position
+	FROM (`+pairsSQL+`) pairs
+	WHERE score >= ?
+) ranked
+WHERE position <= ?`, args...).Error
+	})
+}
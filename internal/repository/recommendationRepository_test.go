@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordView(t *testing.T) {
+	at := time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		owner    models.CartOwner
+		conflict string
+	}{
+		{"user", models.CartOwner{UserID: 8}, `ON CONFLICT ("user_id","product_id")`},
+		{"guest", models.CartOwner{GuestToken: "guest-token"}, `ON CONFLICT ("guest_token","product_id")`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := mocks.NewMockDB(t)
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "product_views" ("user_id","guest_token","product_id","view_count","viewed_at") VALUES ($1,$2,$3,$4,$5) `+
+				test.conflict+` DO UPDATE SET "view_count"=product_views.view_count + 1,"viewed_at"=$6`)).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), uint(2), 1, at, at).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectCommit()
+			assert.NoError(t, NewRecommendationRepository(db).RecordView(test.owner, 2, at))
+		})
+	}
+}
+
+func TestRebuildRecommendations(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// expectReplace expects the recommendations of kind to be deleted and
+	// inserted again from pairs, failing the insert with err if set
+	expectReplace := func(mock sqlmock.Sqlmock, kind string, pairs string, args []driver.Value, err error) {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "product_recommendations" WHERE kind = $1`)).
+			WithArgs(kind).
+			WillReturnResult(sqlmock.NewResult(0, 12))
+		insert := mock.ExpectExec(`INSERT INTO product_recommendations .*` + pairs + `.*WHERE score >= \$\d+\s+\) ranked\s+WHERE position <= \$\d+`).
+			WithArgs(args...)
+		if err != nil {
+			insert.WillReturnError(err)
+			mock.ExpectRollback()
+			return
+		}
+		insert.WillReturnResult(sqlmock.NewResult(0, 10))
+		mock.ExpectCommit()
+	}
+
+	t.Run("bought together", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectReplace(mock, models.RecommendationBoughtTogether, `FROM order_items a`,
+			[]driver.Value{models.RecommendationBoughtTogether, models.OrderPending, models.OrderCancelled, since, 2, 10}, nil)
+		assert.NoError(t, NewRecommendationRepository(db).RebuildBoughtTogether(since, 2, 10))
+	})
+
+	t.Run("also viewed", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectReplace(mock, models.RecommendationAlsoViewed, `FROM product_views WHERE viewed_at >= \$2`,
+			[]driver.Value{models.RecommendationAlsoViewed, since, 2, 10}, nil)
+		assert.NoError(t, NewRecommendationRepository(db).RebuildAlsoViewed(since, 2, 10))
+	})
+
+	t.Run("failed rebuild keeps the old recommendations", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		expectReplace(mock, models.RecommendationAlsoViewed, `FROM views a`,
+			[]driver.Value{models.RecommendationAlsoViewed, since, 2, 10}, errors.New("statement timeout"))
+		assert.EqualError(t, NewRecommendationRepository(db).RebuildAlsoViewed(since, 2, 10), "statement timeout")
+	})
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReferralRepository interface {
+	GetReferrerByCode(code string) (*models.User, error)
+	IsPhoneRegistered(phone string) (bool, error)
+	HasReferralFrom(referrerID uint, column string, value string) (bool, error)
+	GetReferrals(referrerID uint) ([]models.Referral, error)
+	EnsureReferralCode(userID uint) (string, error)
+}
+type ReferralRepository struct {
+	db *gorm.DB
+}
+
+func NewReferralRepository(db *gorm.DB) *ReferralRepository {
+	return &ReferralRepository{db: db}
+}
+
+// GetReferrerByCode returns the active user with the referral code.
+func (c *ReferralRepository) GetReferrerByCode(code string) (*models.User, error) {
+	var user models.User
+	err := c.db.Where("referral_code = ? AND status = ?", code, models.StatusActive).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.InvalidReferralCode)
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// IsPhoneRegistered reports whether any account, including deleted ones not
+// yet purged, has the phone number.
+func (c *ReferralRepository) IsPhoneRegistered(phone string) (bool, error) {
+	var count int64
+	err := c.db.Unscoped().Model(&models.User{}).Where("phone = ? AND purged_at IS NULL", phone).Count(&count).Error
+	return count > 0, err
+}
+
+// HasReferralFrom reports whether a user referred by referrerID signed up
+// with the given value of column, signup_ip or signup_device.
+func (c *ReferralRepository) HasReferralFrom(referrerID uint, column string, value string) (bool, error) {
+	var count int64
+	err := c.db.Model(&models.Referral{}).
+		Joins("JOIN users ON users.id = referrals.user_id").
+		Where("referrals.referrer_id = ?", referrerID).
+		Where(clause.Eq{Column: clause.Column{Table: "users", Name: column}, Value: value}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetReferrals returns the referrals of the user with the referred users,
+// newest first.
+func (c *ReferralRepository) GetReferrals(referrerID uint) ([]models.Referral, error) {
+	var referrals []models.Referral
+	err := c.db.Preload("User").Where("referrer_id = ?", referrerID).Order("id DESC").Find(&referrals).Error
+	if err != nil {
+		return nil, err
+	}
+	return referrals, nil
+}
+
+// EnsureReferralCode returns the user's referral code, generating one for
+// users who signed up before referral codes existed.
+func (c *ReferralRepository) EnsureReferralCode(userID uint) (string, error) {
+	var user models.User
+	if err := c.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", err
+	}
+	if user.ReferralCode != nil {
+		return *user.ReferralCode, nil
+	}
+	code, err := models.GenerateReferralCode()
+	if err != nil {
+		return "", err
+	}
+	err = c.db.Model(&models.User{}).Where("id = ? AND referral_code IS NULL", userID).Update("referral_code", code).Error
+	if err != nil {
+		return "", err
+	}
+	// a concurrent request may have set a different code first
+	if err := c.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", err
+	}
+	return *user.ReferralCode, nil
+}
+
+// rewardReferral pays the rewards of the pending referral of the order's
+// user, if any, to the wallets of both users. It is called when an order is
+// delivered, so only the user's first delivered order earns them.
+func rewardReferral(tx *gorm.DB, order *models.Order, now time.Time) error {
+	var referral models.Referral
+	result := tx.Model(&referral).Clauses(clause.Returning{}).
+		Where("user_id = ? AND status = ?", order.UserID, models.ReferralPending).
+		Updates(map[string]interface{}{
+			"status":      models.ReferralRewarded,
+			"order_id":    order.ID,
+			"rewarded_at": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	wallets := NewWalletRepository(tx)
+	rewards := []struct {
+		userID uint
+		amount int64
+	}{
+		{referral.ReferrerID, referral.ReferrerReward},
+		{referral.UserID, referral.RefereeReward},
+	}
+	for _, reward := range rewards {
+		if reward.amount <= 0 {
+			continue
+		}
+		_, err := wallets.Post(reward.userID, models.WalletReferral, reward.amount, models.AccountReferrals, order.OrderNumber)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reverseReferral takes back the rewards of the referral earned by the order
+// once all of its items have been returned and refunded. Rewards already
+// spent are not clawed back beyond the current wallet balance.
+func reverseReferral(tx *gorm.DB, orderID uint, reference string, now time.Time) error {
+	var referral models.Referral
+	err := tx.Where("order_id = ? AND status = ?", orderID, models.ReferralRewarded).First(&referral).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	var ordered, returned int64
+	err = tx.Model(&models.OrderItem{}).Where("order_id = ?", orderID).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&ordered).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&models.ReturnItem{}).
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ? AND return_requests.status = ?", orderID, models.ReturnRefunded).
+		Select("COALESCE(SUM(return_items.quantity), 0)").Scan(&returned).Error
+	if err != nil {
+		return err
+	}
+	if returned < ordered {
+		return nil
+	}
+	result := tx.Model(&referral).Where("status = ?", models.ReferralRewarded).
+		Updates(map[string]interface{}{
+			"status":      models.ReferralReversed,
+			"reversed_at": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	wallets := NewWalletRepository(tx)
+	rewards := []struct {
+		userID uint
+		amount int64
+	}{
+		{referral.ReferrerID, referral.ReferrerReward},
+		{referral.UserID, referral.RefereeReward},
+	}
+	for _, reward := range rewards {
+		wallet, err := wallets.GetWallet(reward.userID)
+		if err != nil {
+			return err
+		}
+		amount := min(reward.amount, wallet.Balance)
+		if amount <= 0 {
+			continue
+		}
+		_, err = wallets.Post(reward.userID, models.WalletReferral, -amount, models.AccountReferrals, reference)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRewardReferral(t *testing.T) {
+	order := &models.Order{Model: gorm.Model{ID: 3}, UserID: 8, OrderNumber: "ORD-3"}
+	referralRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "referrer_id", "status", "referrer_reward", "referee_reward"}).
+			AddRow(1, 8, 7, models.ReferralRewarded, 5000, 2500)
+	}
+
+	t.Run("pays both users", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "referrals" SET .* WHERE \(user_id = \$\d+ AND status = \$\d+\)`).
+			WillReturnRows(referralRows())
+		expectWalletPost(mock, 7, 5000, models.AccountReferrals, 5000)
+		expectWalletPost(mock, 8, 2500, models.AccountReferrals, 2500)
+		mock.ExpectCommit()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return rewardReferral(tx, order, time.Now())
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("no pending referral", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "referrals"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return rewardReferral(tx, order, time.Now())
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestReverseReferral(t *testing.T) {
+	expectReferral := func(mock sqlmock.Sqlmock, ordered int, returned int) {
+		mock.ExpectQuery(`FROM "referrals" WHERE \(order_id = \$1 AND status = \$2\)`).
+			WithArgs(uint(3), models.ReferralRewarded, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "referrer_id", "status", "referrer_reward", "referee_reward"}).
+				AddRow(1, 8, 7, models.ReferralRewarded, 5000, 2500))
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(quantity\), 0\) FROM "order_items"`).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(ordered))
+		mock.ExpectQuery(`SELECT COALESCE\(SUM\(return_items.quantity\), 0\) FROM "return_items" JOIN return_requests`).
+			WithArgs(uint(3), models.ReturnRefunded).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(returned))
+	}
+
+	t.Run("partly returned", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		expectReferral(mock, 3, 2)
+		mock.ExpectCommit()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return reverseReferral(tx, 3, "return 5", time.Now())
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("returned in full", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		expectReferral(mock, 3, 3)
+		mock.ExpectExec(`UPDATE "referrals" SET .*"status"=\$\d+.* WHERE status = \$\d+`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// the referrer keeps what was already spent
+		mock.ExpectQuery(`FROM "wallets" WHERE user_id = \$1`).WithArgs(uint(7), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, 7, 1000))
+		expectWalletPost(mock, 7, -1000, models.AccountReferrals, 0)
+		mock.ExpectQuery(`FROM "wallets" WHERE user_id = \$1`).WithArgs(uint(8), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(2, 8, 9000))
+		expectWalletPost(mock, 8, -2500, models.AccountReferrals, 6500)
+		mock.ExpectCommit()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return reverseReferral(tx, 3, "return 5", time.Now())
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("not rewarded", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "referrals"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		assert.NoError(t, reverseReferral(db, 3, "return 5", time.Now()))
+	})
+}
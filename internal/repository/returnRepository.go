@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReturnRepository interface {
+	CreateReturn(request *models.ReturnRequest, order *models.Order) error
+	GetReturns(userID uint, status string, page int, limit int) ([]models.ReturnRequest, int64, error)
+	GetReturn(userID uint, returnID uint) (*models.ReturnRequest, error)
+	GetReturnByID(returnID uint) (*models.ReturnRequest, error)
+	GetReturnedQuantities(orderID uint, statuses []string) (map[uint]int, error)
+	TransitionReturn(request *models.ReturnRequest, status string, actor string, note string) error
+	Transaction(fn func(returns *ReturnRepository, payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error
+}
+type ReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+// CreateReturn stores the return request if none of its items is returned
+// more often than it was ordered, counting every request that was not
+// rejected. The order row is locked so concurrent requests are checked one
+// at a time.
+func (c *ReturnRepository) CreateReturn(request *models.ReturnRequest, order *models.Order) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", order.ID).First(&models.Order{}).Error
+		if err != nil {
+			return err
+		}
+		returned, err := NewReturnRepository(tx).GetReturnedQuantities(order.ID, []string{
+			models.ReturnRequested, models.ReturnApproved, models.ReturnPickedUp, models.ReturnRefunded,
+		})
+		if err != nil {
+			return err
+		}
+		ordered := make(map[uint]int, len(order.Items))
+		for _, item := range order.Items {
+			ordered[item.ID] = item.Quantity
+		}
+		for _, item := range request.Items {
+			if returned[item.OrderItemID]+item.Quantity > ordered[item.OrderItemID] {
+				return errors.New(models.ReturnQuantityExceeded)
+			}
+		}
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, order.ID, &request.ID, "", request.Status, models.ActorUser, request.Reason)
+	})
+}
+
+// GetReturns returns one page of the user's return requests, newest first,
+// optionally filtered by status. A zero userID returns the requests of all users.
+func (c *ReturnRepository) GetReturns(userID uint, status string, page int, limit int) ([]models.ReturnRequest, int64, error) {
+	query := c.db.Model(&models.ReturnRequest{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var requests []models.ReturnRequest
+	err := query.Preload("Items").Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&requests).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+func (c *ReturnRepository) GetReturn(userID uint, returnID uint) (*models.ReturnRequest, error) {
+	return c.getReturn(c.db.Where("id = ? AND user_id = ?", returnID, userID))
+}
+func (c *ReturnRepository) GetReturnByID(returnID uint) (*models.ReturnRequest, error) {
+	return c.getReturn(c.db.Where("id = ?", returnID))
+}
+func (c *ReturnRepository) getReturn(query *gorm.DB) (*models.ReturnRequest, error) {
+	var request models.ReturnRequest
+	err := query.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).First(&request).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ReturnNotFound)
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetReturnedQuantities sums the quantities of the order's items in return
+// requests with one of statuses, keyed by order item ID.
+func (c *ReturnRepository) GetReturnedQuantities(orderID uint, statuses []string) (map[uint]int, error) {
+	var rows []struct {
+		OrderItemID uint
+		Quantity    int
+	}
+	err := c.db.Model(&models.ReturnItem{}).
+		Select("return_items.order_item_id, SUM(return_items.quantity) AS quantity").
+		Joins("JOIN return_requests ON return_requests.id = return_items.return_request_id").
+		Where("return_requests.order_id = ? AND return_requests.status IN ? AND return_requests.deleted_at IS NULL", orderID, statuses).
+		Group("return_items.order_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderItemID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// TransitionReturn moves the return request to status and records the change
+// in the order history. Picking up the items puts them back in stock and
+// refunding them takes back the loyalty points they earned, and the referral
+// rewards of the order once it has been returned in full. Like order
+// transitions the update is conditional on the status it was read with.
+func (c *ReturnRepository) TransitionReturn(request *models.ReturnRequest, status string, actor string, note string) error {
+	if !models.CanTransitionReturn(request.Status, status) {
+		return errors.New(models.InvalidReturnTransition)
+	}
+	now := time.Now()
+	fields := map[string]interface{}{"status": status, returnTimestamp(status): now}
+	if actor == models.ActorAdmin && note != "" {
+		fields["admin_note"] = note
+	}
+	var updated models.ReturnRequest
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&updated).Clauses(clause.Returning{}).
+			Where("id = ? AND status = ?", request.ID, request.Status).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.InvalidReturnTransition)
+		}
+		if err := recordHistory(tx, request.OrderID, &request.ID, request.Status, status, actor, note); err != nil {
+			return err
+		}
+		if status == models.ReturnRefunded {
+			if err := reverseLoyaltyPoints(tx, request); err != nil {
+				return err
+			}
+			return reverseReferral(tx, request.OrderID, fmt.Sprintf("return %d", request.ID), now)
+		}
+		if status != models.ReturnPickedUp {
+			return nil
+		}
+		inventoryRepo := NewInventoryRepository(tx)
+		for _, item := range request.Items {
+			_, err := inventoryRepo.AdjustStock(item.VariantID, item.Quantity, models.MovementReturn, fmt.Sprintf("return %d", request.ID))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	items := request.Items
+	*request = updated
+	request.Items = items
+	return nil
+}
+
+// reverseLoyaltyPoints takes back the points earned on the returned items, in
+// proportion to the quantity returned.
+func reverseLoyaltyPoints(tx *gorm.DB, request *models.ReturnRequest) error {
+	order, err := NewOrderRepository(tx).GetOrderByID(request.OrderID)
+	if err != nil {
+		return err
+	}
+	items := make(map[uint]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+	var points int64
+	for _, returned := range request.Items {
+		item := items[returned.OrderItemID]
+		if item.Quantity > 0 {
+			points += item.LoyaltyPoints * int64(returned.Quantity) / int64(item.Quantity)
+		}
+	}
+	if points == 0 {
+		return nil
+	}
+	return NewLoyaltyRepository(tx).Reverse(order.UserID, points, order.ID, fmt.Sprintf("return %d", request.ID))
+}
+
+// Transaction calls fn with repositories bound to a single transaction.
+func (c *ReturnRepository) Transaction(fn func(returns *ReturnRepository, payments *PaymentRepository, orders *OrderRepository, wallets *WalletRepository, giftCards *GiftCardRepository, loyalty *LoyaltyRepository) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		return fn(NewReturnRepository(tx), NewPaymentRepository(tx), NewOrderRepository(tx), NewWalletRepository(tx), NewGiftCardRepository(tx), NewLoyaltyRepository(tx))
+	})
+}
+
+// returnTimestamp returns the column recording when a return request entered status.
+func returnTimestamp(status string) string {
+	switch status {
+	case models.ReturnApproved:
+		return "approved_at"
+	case models.ReturnRejected:
+		return "rejected_at"
+	case models.ReturnPickedUp:
+		return "picked_up_at"
+	}
+	return "refunded_at"
+}
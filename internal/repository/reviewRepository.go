@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IReviewRepository interface {
+	GetReviews(filter models.ReviewFilter) ([]models.Review, int64, error)
+	GetReview(reviewID uint) (*models.Review, error)
+	GetUserReview(userID uint, productID uint) (*models.Review, error)
+	HasDeliveredProduct(userID uint, productID uint) (bool, error)
+	CreateReview(review *models.Review) error
+	UpdateReview(review *models.Review) error
+	DeleteReview(reviewID uint) (*models.Review, error)
+	SetReviewStatus(reviewID uint, status string, note string) (*models.Review, error)
+	AddReviewImage(reviewID uint, image models.ReviewImage) error
+	RemoveReviewImage(reviewID uint, index int) (*models.ReviewImage, error)
+	AddVote(reviewID uint, userID uint) error
+	RemoveVote(reviewID uint, userID uint) error
+}
+
+// ReviewRepository keeps the rating aggregates on the product in step with
+// its published reviews: every change to a review that affects them adjusts
+// the product in the same transaction.
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+func (c *ReviewRepository) GetReviews(filter models.ReviewFilter) ([]models.Review, int64, error) {
+	query := c.db.Model(&models.Review{})
+	if filter.ProductID != 0 {
+		query = query.Where("product_id = ?", filter.ProductID)
+	}
+	if filter.Rating != 0 {
+		query = query.Where("rating = ?", filter.Rating)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	switch filter.Sort {
+	case models.ReviewSortHelpful:
+		query = query.Order("helpful_count DESC")
+	case models.ReviewSortHighest:
+		query = query.Order("rating DESC")
+	case models.ReviewSortLowest:
+		query = query.Order("rating")
+	}
+	var reviews []models.Review
+	err := query.Preload("User").Order("id DESC").
+		Offset((filter.Page - 1) * filter.Limit).Limit(filter.Limit).
+		Find(&reviews).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+func (c *ReviewRepository) GetReview(reviewID uint) (*models.Review, error) {
+	return getReview(c.db.Where("id = ?", reviewID))
+}
+func (c *ReviewRepository) GetUserReview(userID uint, productID uint) (*models.Review, error) {
+	return getReview(c.db.Where("user_id = ? AND product_id = ?", userID, productID))
+}
+func getReview(query *gorm.DB) (*models.Review, error) {
+	var review models.Review
+	err := query.First(&review).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ReviewNotFound)
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+// HasDeliveredProduct reports whether the user has a delivered order
+// containing the product.
+func (c *ReviewRepository) HasDeliveredProduct(userID uint, productID uint) (bool, error) {
+	var count int64
+	err := c.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, models.OrderDelivered, productID).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
+func (c *ReviewRepository) CreateReview(review *models.Review) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if review.Status != models.ReviewPublished {
+			return nil
+		}
+		return adjustRating(tx, review.ProductID, review.Rating, 1)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.ReviewAlreadyExists)
+	}
+	return err
+}
+
+// UpdateReview saves the rating and text of the review. A changed rating of
+// a published review moves it between histogram buckets.
+func (c *ReviewRepository) UpdateReview(review *models.Review) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockReview(tx, review.ID)
+		if err != nil {
+			return err
+		}
+		err = tx.Model(review).Clauses(clause.Returning{}).
+			Select("rating", "title", "body", "updated_at").
+			Updates(review).Error
+		if err != nil {
+			return err
+		}
+		if current.Status != models.ReviewPublished || current.Rating == review.Rating {
+			return nil
+		}
+		if err := adjustRating(tx, current.ProductID, current.Rating, -1); err != nil {
+			return err
+		}
+		return adjustRating(tx, current.ProductID, review.Rating, 1)
+	})
+}
+
+// DeleteReview removes the review with its votes and returns it, so the
+// caller can clean up its images.
+func (c *ReviewRepository) DeleteReview(reviewID uint) (*models.Review, error) {
+	var review *models.Review
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		review, err = lockReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(review).Error; err != nil {
+			return err
+		}
+		if review.Status != models.ReviewPublished {
+			return nil
+		}
+		return adjustRating(tx, review.ProductID, review.Rating, -1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// SetReviewStatus publishes or hides the review, adding it to or removing it
+// from the product's aggregates.
+func (c *ReviewRepository) SetReviewStatus(reviewID uint, status string, note string) (*models.Review, error) {
+	var review *models.Review
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		review, err = lockReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+		previous := review.Status
+		err = tx.Model(review).Clauses(clause.Returning{}).
+			Updates(map[string]interface{}{"status": status, "moderation_note": note}).Error
+		if err != nil || previous == status {
+			return err
+		}
+		delta := 1
+		if status != models.ReviewPublished {
+			delta = -1
+		}
+		return adjustRating(tx, review.ProductID, review.Rating, delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// AddReviewImage appends the image unless the review already has
+// MaxReviewImages.
+func (c *ReviewRepository) AddReviewImage(reviewID uint, image models.ReviewImage) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		review, err := lockReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+		if len(review.Images) >= models.MaxReviewImages {
+			return errors.New(models.ReviewImageLimit)
+		}
+		images := append(review.Images, image)
+		return tx.Model(review).Select("images").Updates(&models.Review{Images: images}).Error
+	})
+}
+
+// RemoveReviewImage removes the image at index and returns it.
+func (c *ReviewRepository) RemoveReviewImage(reviewID uint, index int) (*models.ReviewImage, error) {
+	var removed models.ReviewImage
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		review, err := lockReview(tx, reviewID)
+		if err != nil {
+			return err
+		}
+		if index < 0 || index >= len(review.Images) {
+			return errors.New(models.ReviewImageNotFound)
+		}
+		removed = review.Images[index]
+		images := append(review.Images[:index:index], review.Images[index+1:]...)
+		return tx.Model(review).Select("images").Updates(&models.Review{Images: images}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &removed, nil
+}
+
+// AddVote marks the review helpful for the user. Voting twice counts once.
+func (c *ReviewRepository) AddVote(reviewID uint, userID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ReviewVote{ReviewID: reviewID, UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Review{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+}
+func (c *ReviewRepository) RemoveVote(reviewID uint, userID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&models.ReviewVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Review{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+	})
+}
+func lockReview(tx *gorm.DB, reviewID uint) (*models.Review, error) {
+	return getReview(tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", reviewID))
+}
+
+// adjustRating adds delta reviews with rating to the product's aggregates
+// and recomputes the average from the updated sum and count.
+func adjustRating(tx *gorm.DB, productID uint, rating int, delta int) error {
+	bucket := fmt.Sprintf("rating_stars%d", rating)
+	return tx.Unscoped().Model(&models.Product{}).Where("id = ?", productID).
+		UpdateColumns(map[string]interface{}{
+			"rating_count": gorm.Expr("rating_count + ?", delta),
+			"rating_sum":   gorm.Expr("rating_sum + ?", rating*delta),
+			bucket:         gorm.Expr(bucket+" + ?", delta),
+			"rating_average": gorm.Expr("CASE WHEN rating_count + ? > 0 THEN ROUND((rating_sum + ?)::numeric / (rating_count + ?), 2) ELSE 0 END",
+				delta, rating*delta, delta),
+		}).Error
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// expectAdjustRating expects the aggregates of product 2 to change by delta
+// reviews with rating.
+func expectAdjustRating(mock sqlmock.Sqlmock, rating int, delta int) {
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "products" SET `+
+		`"rating_average"=CASE WHEN rating_count + $1 > 0 THEN ROUND((rating_sum + $2)::numeric / (rating_count + $3), 2) ELSE 0 END,`+
+		`"rating_count"=rating_count + $4,`+
+		`"rating_stars`+string(rune('0'+rating))+`"=rating_stars`+string(rune('0'+rating))+` + $5,`+
+		`"rating_sum"=rating_sum + $6 WHERE id = $7`)).
+		WithArgs(delta, rating*delta, delta, delta, delta, rating*delta, uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestAdjustRating(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectBegin()
+	expectAdjustRating(mock, 4, -1)
+	mock.ExpectCommit()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return adjustRating(tx, 2, 4, -1)
+	})
+	assert.NoError(t, err)
+}
+
+func TestCreateReview(t *testing.T) {
+	t.Run("published review counts in the aggregates", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "reviews"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		expectAdjustRating(mock, 5, 1)
+		mock.ExpectCommit()
+		err := NewReviewRepository(db).CreateReview(&models.Review{ProductID: 2, UserID: 8, Rating: 5, Status: models.ReviewPublished})
+		assert.NoError(t, err)
+	})
+
+	t.Run("hidden review does not", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "reviews"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		err := NewReviewRepository(db).CreateReview(&models.Review{ProductID: 2, UserID: 8, Rating: 5, Status: models.ReviewHidden})
+		assert.NoError(t, err)
+	})
+
+	t.Run("second review of the product", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "reviews"`).WillReturnError(gorm.ErrDuplicatedKey)
+		mock.ExpectRollback()
+		err := NewReviewRepository(db).CreateReview(&models.Review{ProductID: 2, UserID: 8, Rating: 5, Status: models.ReviewPublished})
+		assert.EqualError(t, err, models.ReviewAlreadyExists)
+	})
+}
+
+func TestHasDeliveredProduct(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "order_items" JOIN orders ON orders.id = order_items.order_id `+
+		`WHERE orders.user_id = $1 AND orders.status = $2 AND order_items.product_id = $3`)).
+		WithArgs(uint(8), models.OrderDelivered, uint(2), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	delivered, err := NewReviewRepository(db).HasDeliveredProduct(8, 2)
+	assert.NoError(t, err)
+	assert.True(t, delivered)
+}
+
+func TestRemoveHelpfulVotes(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET "helpful_count"=helpful_count - 1 ` +
+		`WHERE id IN (SELECT "review_id" FROM "review_votes" WHERE user_id = $1)`)).
+		WithArgs(uint(8)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return removeHelpfulVotes(tx, 8)
+	})
+	assert.NoError(t, err)
+}
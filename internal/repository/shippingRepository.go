@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IShippingRepository interface {
+	GetShippingMethods(activeOnly bool) ([]models.ShippingMethod, error)
+	GetShippingMethod(methodID uint) (*models.ShippingMethod, error)
+	SaveShippingMethod(method *models.ShippingMethod) error
+	DeleteShippingMethod(methodID uint) error
+	CreateShipment(shipment *models.Shipment) error
+	GetShipment(shipmentID uint) (*models.Shipment, error)
+	GetShipmentByTracking(carrier string, trackingNumber string) (*models.Shipment, error)
+	GetOrderShipments(orderID uint) ([]models.Shipment, error)
+	UpdateShipment(shipment *models.Shipment, fields map[string]interface{}) error
+	ProcessEvent(event *models.ShipmentEvent, apply func(shipments *ShippingRepository, orders *OrderRepository) error) error
+}
+type ShippingRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) *ShippingRepository {
+	return &ShippingRepository{db: db}
+}
+func (c *ShippingRepository) GetShippingMethods(activeOnly bool) ([]models.ShippingMethod, error) {
+	query := c.db.Order("id")
+	if activeOnly {
+		query = query.Where("is_active")
+	}
+	var methods []models.ShippingMethod
+	if err := query.Find(&methods).Error; err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+func (c *ShippingRepository) GetShippingMethod(methodID uint) (*models.ShippingMethod, error) {
+	var method models.ShippingMethod
+	err := c.db.Where("id = ?", methodID).First(&method).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ShippingMethodNotFound)
+		}
+		return nil, err
+	}
+	return &method, nil
+}
+func (c *ShippingRepository) SaveShippingMethod(method *models.ShippingMethod) error {
+	err := c.db.Save(method).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateShippingMethod)
+	}
+	return err
+}
+func (c *ShippingRepository) DeleteShippingMethod(methodID uint) error {
+	result := c.db.Delete(&models.ShippingMethod{}, methodID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.ShippingMethodNotFound)
+	}
+	return nil
+}
+func (c *ShippingRepository) CreateShipment(shipment *models.Shipment) error {
+	return c.db.Create(shipment).Error
+}
+func (c *ShippingRepository) GetShipment(shipmentID uint) (*models.Shipment, error) {
+	return c.getShipment(c.db.Where("id = ?", shipmentID))
+}
+func (c *ShippingRepository) GetShipmentByTracking(carrier string, trackingNumber string) (*models.Shipment, error) {
+	return c.getShipment(c.db.Where("carrier = ? AND tracking_number = ?", carrier, trackingNumber))
+}
+func (c *ShippingRepository) getShipment(query *gorm.DB) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := query.First(&shipment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.ShipmentNotFound)
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// GetOrderShipments returns the shipments of the order with their tracking
+// events, oldest first.
+func (c *ShippingRepository) GetOrderShipments(orderID uint) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := c.db.Where("order_id = ?", orderID).
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("occurred_at, id") }).
+		Order("id").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+func (c *ShippingRepository) UpdateShipment(shipment *models.Shipment, fields map[string]interface{}) error {
+	return c.db.Model(shipment).Clauses(clause.Returning{}).Updates(fields).Error
+}
+
+// ProcessEvent records the tracking event and calls apply in the same
+// transaction. Events that were already recorded are skipped, and a failing
+// apply rolls the record back so the carrier's retry is processed again.
+func (c *ShippingRepository) ProcessEvent(event *models.ShipmentEvent, apply func(shipments *ShippingRepository, orders *OrderRepository) error) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return apply(NewShippingRepository(tx), NewOrderRepository(tx))
+	})
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+)
+
+type ITaxRepository interface {
+	GetTaxClasses() ([]models.TaxClass, error)
+	GetTaxClass(taxClassID uint) (*models.TaxClass, error)
+	SaveTaxClass(taxClass *models.TaxClass) error
+	DeleteTaxClass(taxClassID uint) error
+}
+type TaxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+func (c *TaxRepository) GetTaxClasses() ([]models.TaxClass, error) {
+	var taxClasses []models.TaxClass
+	err := c.db.Order("name").Find(&taxClasses).Error
+	if err != nil {
+		return nil, err
+	}
+	return taxClasses, nil
+}
+func (c *TaxRepository) GetTaxClass(taxClassID uint) (*models.TaxClass, error) {
+	var taxClass models.TaxClass
+	err := c.db.Where("id = ?", taxClassID).First(&taxClass).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New(models.TaxClassNotFound)
+		}
+		return nil, err
+	}
+	return &taxClass, nil
+}
+
+// SaveTaxClass creates or updates the tax class. Making it the default
+// unsets the previous default in the same transaction.
+func (c *TaxRepository) SaveTaxClass(taxClass *models.TaxClass) error {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if taxClass.IsDefault {
+			err := tx.Model(&models.TaxClass{}).Where("is_default AND id <> ?", taxClass.ID).Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Save(taxClass).Error
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New(models.DuplicateTaxClass)
+	}
+	return err
+}
+
+// DeleteTaxClass refuses to delete a class that categories still use.
+func (c *TaxRepository) DeleteTaxClass(taxClassID uint) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		var categories int64
+		err := tx.Model(&models.Category{}).Where("tax_class_id = ?", taxClassID).Count(&categories).Error
+		if err != nil {
+			return err
+		}
+		if categories > 0 {
+			return errors.New(models.TaxClassInUse)
+		}
+		result := tx.Delete(&models.TaxClass{}, taxClassID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.TaxClassNotFound)
+		}
+		return nil
+	})
+}
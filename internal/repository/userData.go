@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+)
+
+// userDataTable describes a table holding rows owned by a user through a
+// user_id column. Every such table must be listed here so it is included in
+// data exports and handled when a deleted account is purged.
+type userDataTable struct {
+	name string
+	rows func() interface{}
+	// further columns whose rows are exported as the user's, e.g. the
+	// referrals the user made
+	alsoExportBy []string
+	// hard delete the rows on purge; tables that must be retained for
+	// accounting only keep a reference to the anonymized user row
+	purge bool
+	// associations included in the export, e.g. the items of an order
+	preload []string
+	// called before the rows are purged, to keep counters on other rows in
+	// step
+	beforePurge func(tx *gorm.DB, userID uint) error
+}
+
+var userDataTables = []userDataTable{
+	{name: "magic_links", rows: func() interface{} { return &[]models.MagicLink{} }, purge: true},
+	{name: "addresses", rows: func() interface{} { return &[]models.Address{} }, purge: true},
+	{name: "carts", rows: func() interface{} { return &[]models.Cart{} }, purge: true, preload: []string{"Items"}},
+	{name: "wishlist_items", rows: func() interface{} { return &[]models.WishlistItem{} }, purge: true},
+	{name: "product_views", rows: func() interface{} { return &[]models.ProductView{} }, purge: true},
+	{name: "notifications", rows: func() interface{} { return &[]models.Notification{} }, purge: true},
+	{name: "notification_opt_outs", rows: func() interface{} { return &[]models.NotificationOptOut{} }, purge: true},
+	// reviews stay published under the anonymized user and keep the product ratings intact
+	{name: "reviews", rows: func() interface{} { return &[]models.Review{} }, purge: false},
+	{name: "review_votes", rows: func() interface{} { return &[]models.ReviewVote{} }, purge: true, beforePurge: removeHelpfulVotes},
+	// kept like the orders they were made for; the inventory movements and
+	// order items refer to them
+	{name: "stock_reservations", rows: func() interface{} { return &[]models.StockReservation{} }, purge: false},
+	{name: "orders", rows: func() interface{} { return &[]models.Order{} }, purge: false, preload: []string{"Items"}},
+	{name: "return_requests", rows: func() interface{} { return &[]models.ReturnRequest{} }, purge: false, preload: []string{"Items"}},
+	{name: "shipments", rows: func() interface{} { return &[]models.Shipment{} }, purge: false, preload: []string{"Events"}},
+	{name: "invoices", rows: func() interface{} { return &[]models.Invoice{} }, purge: false},
+	{name: "refunds", rows: func() interface{} { return &[]models.Refund{} }, purge: false},
+	{name: "payments", rows: func() interface{} { return &[]models.Payment{} }, purge: false},
+	{name: "wallets", rows: func() interface{} { return &[]models.Wallet{} }, purge: false},
+	{name: "wallet_transactions", rows: func() interface{} { return &[]models.WalletTransaction{} }, purge: false},
+	{name: "gift_cards", rows: func() interface{} { return &[]models.GiftCard{} }, purge: false},
+	{name: "loyalty_accounts", rows: func() interface{} { return &[]models.LoyaltyAccount{} }, purge: false},
+	{name: "loyalty_transactions", rows: func() interface{} { return &[]models.LoyaltyTransaction{} }, purge: false},
+	// kept so the rewards paid stay accounted for
+	{name: "referrals", rows: func() interface{} { return &[]models.Referral{} }, purge: false, alsoExportBy: []string{"referrer_id"}},
+	{name: "coupon_redemptions", rows: func() interface{} { return &[]models.CouponRedemption{} }, purge: false},
+}
+
+// ExportUserData collects everything stored about the user, keyed by table name.
+func (c *UserRepository) ExportUserData(userID uint) (map[string]interface{}, error) {
+	var user models.User
+	err := c.db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{"user": user}
+	for _, table := range userDataTables {
+		rows := table.rows()
+		query := c.db.Where("user_id = ?", userID)
+		for _, column := range table.alsoExportBy {
+			query = query.Or(column+" = ?", userID)
+		}
+		for _, association := range table.preload {
+			query = query.Preload(association)
+		}
+		err := query.Find(rows).Error
+		if err != nil {
+			return nil, err
+		}
+		data[table.name] = rows
+	}
+	return data, nil
+}
+
+// ScheduleAccountDeletion soft deletes the user. The row stays in the
+// database until PurgeUser anonymizes it after the grace period.
+func (c *UserRepository) ScheduleAccountDeletion(user *models.User) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(user).Update("status", models.StatusDeleted).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+// GetUsersDueForPurge returns soft deleted users whose grace period ended before the given time.
+func (c *UserRepository) GetUsersDueForPurge(before time.Time) ([]models.User, error) {
+	var users []models.User
+	err := c.db.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND purged_at IS NULL", before).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// PurgeUser deletes purgeable user data and replaces personal fields on the
+// user row with placeholders, keeping the row so retained records still resolve.
+func (c *UserRepository) PurgeUser(user *models.User) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range userDataTables {
+			if !table.purge {
+				continue
+			}
+			if table.beforePurge != nil {
+				if err := table.beforePurge(tx, user.ID); err != nil {
+					return err
+				}
+			}
+			err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(table.rows()).Error
+			if err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		return tx.Unscoped().Model(user).Updates(map[string]interface{}{
+			"first_name":    "Deleted",
+			"last_name":     "User",
+			"email":         fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"password":      "",
+			"phone":         "0000000000",
+			"avatar_key":    "",
+			"avatar":        nil,
+			"signup_ip":     "",
+			"signup_device": "",
+			"purged_at":     now,
+		}).Error
+	})
+}
+
+// removeHelpfulVotes takes the user's votes off the helpful counts of the
+// reviews they voted for.
+func removeHelpfulVotes(tx *gorm.DB, userID uint) error {
+	votes := tx.Model(&models.ReviewVote{}).Select("review_id").Where("user_id = ?", userID)
+	return tx.Unscoped().Model(&models.Review{}).Where("id IN (?)", votes).
+		UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// the tables holding rows with a user_id, listed explicitly so a table missing
+// from userDataTables fails the tests
+var (
+	exportedTables = []string{
+		"magic_links", "addresses", "carts", "wishlist_items", "product_views", "notifications",
+		"notification_opt_outs", "reviews", "review_votes", "stock_reservations", "orders",
+		"return_requests", "shipments", "invoices", "refunds", "payments", "wallets",
+		"wallet_transactions", "gift_cards", "loyalty_accounts", "loyalty_transactions",
+		"referrals", "coupon_redemptions",
+	}
+	purgedTables = []string{
+		"magic_links", "addresses", "carts", "wishlist_items", "product_views", "notifications",
+		"notification_opt_outs", "review_votes",
+	}
+)
+
+func TestExportUserData(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectQuery(`FROM "users" WHERE id = \$1`).
+		WithArgs(uint(8), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(8, "asha@example.com"))
+	for _, table := range exportedTables {
+		if table == "referrals" {
+			// the user's own referral and those the user made
+			mock.ExpectQuery(regexp.QuoteMeta(`FROM "referrals" WHERE (user_id = $1 OR referrer_id = $2)`)).
+				WithArgs(uint(8), uint(8)).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "referrer_id"}).AddRow(3, 12, 8))
+			continue
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM "` + table + `" WHERE user_id = $1`)).
+			WithArgs(uint(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	data, err := NewUserRepository(db).ExportUserData(8)
+	assert.NoError(t, err)
+	assert.Len(t, data, len(exportedTables)+1)
+	assert.Equal(t, "asha@example.com", data["user"].(models.User).Email)
+	assert.Len(t, *data["referrals"].(*[]models.Referral), 1)
+}
+
+func TestScheduleAccountDeletion(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "status"=\$1`).
+		WithArgs(models.StatusDeleted, sqlmock.AnyArg(), uint(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "users" SET "deleted_at"=\$1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, NewUserRepository(db).ScheduleAccountDeletion(&models.User{Model: gorm.Model{ID: 8}}))
+}
+
+func TestGetUsersDueForPurge(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	before := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE deleted_at IS NOT NULL AND deleted_at < $1 AND purged_at IS NULL`)).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8).AddRow(9))
+	users, err := NewUserRepository(db).GetUsersDueForPurge(before)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+}
+
+func TestPurgeUser(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectBegin()
+	for _, table := range purgedTables {
+		if table == "review_votes" {
+			mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET "helpful_count"=helpful_count - 1`)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "` + table + `" WHERE user_id = $1`)).
+			WithArgs(uint(8)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(`UPDATE "users" SET .*"email"=\$\d+.*"purged_at"=\$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	user := &models.User{Model: gorm.Model{ID: 8}, Email: "asha@example.com"}
+	assert.NoError(t, NewUserRepository(db).PurgeUser(user))
+	assert.Equal(t, "deleted-8@deleted.invalid", user.Email)
+	assert.Equal(t, "Deleted", user.FirstName)
+}
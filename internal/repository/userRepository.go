@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/Ansalps/UserEcommerceClean/internal/models"
 	"gorm.io/gorm"
@@ -14,6 +15,11 @@ type IUserRepository interface {
 	GetUserById(userID uint) (*models.User, error)
 	GetProfile(userId string) (*models.User, error)
 	UpdateProfile(user *models.User) error
+	PatchProfile(userID uint, version uint, update *models.User, fields []string) error
+	UpdateAvatar(userID uint, key string, urls map[string]string) error
+	CreateMagicLink(link *models.MagicLink) error
+	CountMagicLinksSince(userID uint, since time.Time) (int64, error)
+	ConsumeMagicLink(userID uint, jti string) error
 }
 type UserRepository struct {
 	db *gorm.DB
@@ -22,12 +28,20 @@ type UserRepository struct {
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
-func (c *UserRepository) UserSignUp(user models.User) error {
-	err := c.db.Create(&user).Error
-	if err != nil {
-		return err
-	}
-	return nil
+
+// UserSignUp creates the user and, when the user was referred, the referral
+// in one transaction.
+func (c *UserRepository) UserSignUp(user *models.User, referral *models.Referral) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if referral == nil {
+			return nil
+		}
+		referral.UserID = user.ID
+		return tx.Create(referral).Error
+	})
 }
 
 // func (c *UserRepository) GetUser(field string, value interface{}) {
@@ -72,10 +86,78 @@ func (c *UserRepository) GetProfile(userId string) (*models.User, error) {
 	return &user, nil
 }
 func (c *UserRepository) UpdateProfile(user *models.User) error {
-
-	err := c.db.Save(user).Error
+	err := c.db.Model(user).Updates(map[string]interface{}{
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"phone":      user.Phone,
+		"currency":   user.Currency,
+		"version":    gorm.Expr("version + 1"),
+	}).Error
 	if err != nil {
 		return errors.New("error in update of databse query")
 	}
 	return nil
 }
+
+// PatchProfile updates only the given columns, and only if the stored version
+// still equals version, so concurrent edits cannot overwrite each other.
+func (c *UserRepository) PatchProfile(userID uint, version uint, update *models.User, fields []string) error {
+	values := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	for _, field := range fields {
+		switch field {
+		case "first_name":
+			values[field] = update.FirstName
+		case "last_name":
+			values[field] = update.LastName
+		case "phone":
+			values[field] = update.Phone
+		case "currency":
+			values[field] = update.Currency
+		}
+	}
+	result := c.db.Model(&models.User{}).Where("id = ? AND version = ?", userID, version).Updates(values)
+	if result.Error != nil {
+		return errors.New("error in update of databse query")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.VersionConflict)
+	}
+	return nil
+}
+
+func (c *UserRepository) UpdateAvatar(userID uint, key string, urls map[string]string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(&models.User{AvatarKey: key, Avatar: urls}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("version", gorm.Expr("version + 1")).Error
+	})
+}
+func (c *UserRepository) CreateMagicLink(link *models.MagicLink) error {
+	return c.db.Create(link).Error
+}
+func (c *UserRepository) CountMagicLinksSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := c.db.Model(&models.MagicLink{}).Where("user_id = ? AND created_at > ?", userID, since).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ConsumeMagicLink marks the link as used in a single conditional update so a
+// link can never be exchanged twice, even by concurrent requests.
+func (c *UserRepository) ConsumeMagicLink(userID uint, jti string) error {
+	now := time.Now()
+	result := c.db.Model(&models.MagicLink{}).
+		Where("jti = ? AND user_id = ? AND used_at IS NULL AND expires_at > ?", jti, userID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.InvalidMagicLink)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWalletRepository interface {
+	GetWallet(userID uint) (*models.Wallet, error)
+	GetTransactions(userID uint, page int, limit int) ([]models.WalletTransaction, int64, error)
+	Post(userID uint, transactionType string, amount int64, counterAccount string, reference string) (*models.WalletTransaction, error)
+	Audit() ([]models.WalletAuditIssue, error)
+}
+type WalletRepository struct {
+	db *gorm.DB
+}
+
+func NewWalletRepository(db *gorm.DB) *WalletRepository {
+	return &WalletRepository{db: db}
+}
+
+// GetWallet returns the user's wallet, or an empty one if the user never had
+// a balance.
+func (c *WalletRepository) GetWallet(userID uint) (*models.Wallet, error) {
+	var wallet models.Wallet
+	err := c.db.Where("user_id = ?", userID).First(&wallet).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.Wallet{UserID: userID}, nil
+		}
+		return nil, err
+	}
+	return &wallet, nil
+}
+func (c *WalletRepository) GetTransactions(userID uint, page int, limit int) ([]models.WalletTransaction, int64, error) {
+	query := c.db.Model(&models.WalletTransaction{}).Where("user_id = ?", userID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var transactions []models.WalletTransaction
+	err := query.Order("id DESC").Offset((page - 1) * limit).Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+// Post changes the wallet balance by amount, positive for a credit and
+// negative for a debit, and books it against counterAccount as a balanced
+// pair of ledger entries. The balance update is conditional, so a debit
+// larger than the balance fails with InsufficientWalletBalance even under
+// concurrency.
+func (c *WalletRepository) Post(userID uint, transactionType string, amount int64, counterAccount string, reference string) (*models.WalletTransaction, error) {
+	var transaction models.WalletTransaction
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.Wallet{UserID: userID}).Error
+		if err != nil {
+			return err
+		}
+		var wallet models.Wallet
+		result := tx.Model(&wallet).Clauses(clause.Returning{}).
+			Where("user_id = ? AND balance + ? >= 0", userID, amount).
+			UpdateColumns(map[string]interface{}{
+				"balance":    gorm.Expr("balance + ?", amount),
+				"updated_at": gorm.Expr("NOW()"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New(models.InsufficientWalletBalance)
+		}
+		transaction = models.WalletTransaction{
+			UserID:       userID,
+			Type:         transactionType,
+			Amount:       amount,
+			BalanceAfter: wallet.Balance,
+			Reference:    reference,
+			Entries: []models.LedgerEntry{
+				{Account: models.WalletAccount(userID), Amount: amount},
+				{Account: counterAccount, Amount: -amount},
+			},
+		}
+		return tx.Create(&transaction).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Audit returns wallets whose balance differs from the sum of their ledger
+// entries and transactions whose entries do not sum to zero.
+func (c *WalletRepository) Audit() ([]models.WalletAuditIssue, error) {
+	var issues []models.WalletAuditIssue
+	err := c.db.Raw(`
+		SELECT w.user_id, 'balance does not match ledger' AS problem,
+			COALESCE(e.total, 0) AS expected, w.balance AS actual
+		FROM wallets w
+		LEFT JOIN (
+			SELECT account, SUM(amount) AS total FROM ledger_entries GROUP BY account
+		) e ON e.account = 'wallet:' || w.user_id
+		WHERE w.balance <> COALESCE(e.total, 0)`).
+		Scan(&issues).Error
+	if err != nil {
+		return nil, err
+	}
+	var unbalanced []models.WalletAuditIssue
+	err = c.db.Raw(`
+		SELECT transaction_id, 'ledger entries do not balance' AS problem,
+			0 AS expected, SUM(amount) AS actual
+		FROM ledger_entries
+		GROUP BY transaction_id
+		HAVING SUM(amount) <> 0`).
+		Scan(&unbalanced).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, unbalanced...), nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// expectWalletPost expects the statements of a successful Post of amount to
+// the user's wallet inside an open transaction, leaving balanceAfter.
+func expectWalletPost(mock sqlmock.Sqlmock, userID uint, amount int64, counterAccount string, balanceAfter int64) {
+	mock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "wallets" .* ON CONFLICT DO NOTHING`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "wallets" SET "balance"=balance + $1,"updated_at"=NOW() WHERE user_id = $2 AND balance + $3 >= 0`)).
+		WithArgs(amount, userID, amount).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "balance"}).AddRow(1, userID, balanceAfter))
+	mock.ExpectQuery(`INSERT INTO "wallet_transactions"`).
+		WithArgs(sqlmock.AnyArg(), userID, sqlmock.AnyArg(), amount, balanceAfter, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`INSERT INTO "ledger_entries"`).
+		WithArgs(sqlmock.AnyArg(), uint(1), models.WalletAccount(userID), amount, sqlmock.AnyArg(), uint(1), counterAccount, -amount).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+}
+
+func TestPost(t *testing.T) {
+	t.Run("credit", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		expectWalletPost(mock, 8, 5000, models.AccountGateway, 7000)
+		mock.ExpectCommit()
+		var transaction *models.WalletTransaction
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			transaction, err = NewWalletRepository(tx).Post(8, models.WalletTopUp, 5000, models.AccountGateway, "wallet-topup-3")
+			return err
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7000), transaction.BalanceAfter)
+		assert.Equal(t, []int64{5000, -5000}, []int64{transaction.Entries[0].Amount, transaction.Entries[1].Amount})
+	})
+
+	t.Run("debit above the balance", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "wallets" .* ON CONFLICT DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`WHERE user_id = $2 AND balance + $3 >= 0`)).
+			WithArgs(int64(-5000), uint(8), int64(-5000)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
+		_, err := NewWalletRepository(db).Post(8, models.WalletPayment, -5000, models.AccountSales, "ORD-20240131-48213975")
+		assert.EqualError(t, err, models.InsufficientWalletBalance)
+	})
+}
+
+func TestAudit(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	mock.ExpectQuery(`WHERE w.balance <> COALESCE\(e.total, 0\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "problem", "expected", "actual"}).
+			AddRow(8, "balance does not match ledger", 2000, 2500))
+	mock.ExpectQuery(`HAVING SUM\(amount\) <> 0`).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "problem", "expected", "actual"}).
+			AddRow(3, "ledger entries do not balance", 0, 100))
+	issues, err := NewWalletRepository(db).Audit()
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 2) {
+		assert.Equal(t, uint(8), issues[0].UserID)
+		assert.Equal(t, int64(500), issues[0].Actual-issues[0].Expected)
+		assert.Equal(t, uint(3), issues[1].TransactionID)
+		assert.Equal(t, int64(100), issues[1].Actual)
+	}
+}
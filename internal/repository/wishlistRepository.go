@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IWishlistRepository interface {
+	GetWishlist(userID uint) ([]models.WishlistItem, error)
+	CountItems(userID uint) (int64, error)
+	AddItem(item *models.WishlistItem) error
+	RemoveItem(userID uint, variantID uint) error
+	CheckItemsInBatches(batchSize int, check func(items []models.WishlistItem) error) error
+	UpdateCheckState(item *models.WishlistItem) error
+}
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// GetWishlist returns the user's items with their variants, including soft
+// deleted variants so the client can show them as unavailable.
+func (c *WishlistRepository) GetWishlist(userID uint) ([]models.WishlistItem, error) {
+	var items []models.WishlistItem
+	err := c.db.Where("user_id = ?", userID).
+		Preload("Variant", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("id DESC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+func (c *WishlistRepository) CountItems(userID uint) (int64, error) {
+	var count int64
+	err := c.db.Model(&models.WishlistItem{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// AddItem saves the item. Adding a variant that is already saved resets its
+// saved price, so the user is notified relative to the latest price seen.
+func (c *WishlistRepository) AddItem(item *models.WishlistItem) error {
+	var existing models.WishlistItem
+	err := c.db.Where("user_id = ? AND variant_id = ?", item.UserID, item.VariantID).First(&existing).Error
+	if err == nil {
+		item.ID = existing.ID
+		item.CreatedAt = existing.CreatedAt
+		return c.db.Save(item).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	err = c.db.Create(item).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// saved concurrently by another request
+		return nil
+	}
+	return err
+}
+func (c *WishlistRepository) RemoveItem(userID uint, variantID uint) error {
+	result := c.db.Where("user_id = ? AND variant_id = ?", userID, variantID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(models.WishlistItemNotFound)
+	}
+	return nil
+}
+
+// CheckItemsInBatches calls check with the items of active users whose
+// variants are still on sale, batchSize items at a time. A failing batch does
+// not stop the rest; the errors are returned together at the end.
+func (c *WishlistRepository) CheckItemsInBatches(batchSize int, check func(items []models.WishlistItem) error) error {
+	var items []models.WishlistItem
+	var errs []error
+	err := c.db.
+		Joins("JOIN product_variants ON product_variants.id = wishlist_items.variant_id AND product_variants.deleted_at IS NULL").
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL AND products.is_active").
+		Joins("JOIN users ON users.id = wishlist_items.user_id AND users.deleted_at IS NULL AND users.status = ?", models.StatusActive).
+		Preload("Variant").
+		FindInBatches(&items, batchSize, func(tx *gorm.DB, batch int) error {
+			if err := check(items); err != nil {
+				errs = append(errs, err)
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// UpdateCheckState stores the price and stock state seen by the checker.
+func (c *WishlistRepository) UpdateCheckState(item *models.WishlistItem) error {
+	return c.db.Model(item).Omit(clause.Associations).Updates(map[string]interface{}{
+		"notified_price": item.NotifiedPrice,
+		"in_stock":       item.InStock,
+	}).Error
+}
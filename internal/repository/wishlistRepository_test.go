@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckItemsInBatches(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	for _, id := range []uint{1, 2} {
+		mock.ExpectQuery(`SELECT "wishlist_items"\."id".* FROM "wishlist_items" JOIN product_variants`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "variant_id"}).AddRow(id, 4, 5))
+		mock.ExpectQuery(`SELECT \* FROM "product_variants" WHERE "product_variants"\."id" = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "product_id"}).AddRow(5, 2))
+	}
+	mock.ExpectQuery(`FROM "wishlist_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	failed := errors.New("notification failed")
+	var checked []uint
+	err := NewWishlistRepository(db).CheckItemsInBatches(1, func(items []models.WishlistItem) error {
+		checked = append(checked, items[0].ID)
+		if items[0].ID == 1 {
+			return failed
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, []uint{1, 2}, checked, "a failing batch does not stop the rest")
+}
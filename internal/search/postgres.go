@@ -0,0 +1,214 @@
+package search
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"gorm.io/gorm"
+)
+
+// Postgres text search configuration used for stemming and stop words.
+const textSearchConfig = "'english'"
+
+// similarityThreshold is the minimum trigram word similarity for a product
+// to match a query that does not match its words exactly, e.g. "tshrit"
+// against "t-shirt".
+const similarityThreshold = 0.4
+
+// maxBrandFacets caps the brand facet to the most common brands.
+const maxBrandFacets = 20
+
+var (
+	tsQuery = "websearch_to_tsquery(" + textSearchConfig + ", @q)"
+	// the <% operator uses the trigram index, unlike word_similarity itself
+	matchSQL = "(document @@ " + tsQuery + " OR @q <% search_text)"
+	scoreSQL = "ts_rank_cd(document, " + tsQuery + ") + word_similarity(@q, search_text)"
+)
+
+var searchSortOrders = map[string]string{
+	models.SortRelevance: "score DESC, product_id DESC",
+	models.SortNewest:    "product_id DESC",
+	models.SortPriceAsc:  "min_price ASC, product_id",
+	models.SortPriceDesc: "min_price DESC, product_id",
+	models.SortName:      "name ASC, product_id",
+}
+
+// PostgresEngine searches the search_documents table with Postgres full-text
+// search, ranked by ts_rank_cd, and falls back to pg_trgm similarity so that
+// misspelt queries still find products. Both have GIN indexes.
+type PostgresEngine struct {
+	db *gorm.DB
+}
+
+func NewPostgresEngine(db *gorm.DB) *PostgresEngine {
+	return &PostgresEngine{db: db}
+}
+
+// Index upserts the document. Name is weighted highest, then brand and
+// category, variant attributes and finally the description.
+func (e *PostgresEngine) Index(doc Document) error {
+	return e.db.Exec(`INSERT INTO search_documents
+	(product_id, category_id, brand, min_price, max_price, is_active, name, description, search_text, document, updated_at)
+VALUES (@id, @category_id, @brand, @min_price, @max_price, @is_active, @name, @description, @search_text,
+	setweight(to_tsvector(`+textSearchConfig+`, @name), 'A') ||
+	setweight(to_tsvector(`+textSearchConfig+`, @brand || ' ' || @category), 'B') ||
+	setweight(to_tsvector(`+textSearchConfig+`, @attributes), 'C') ||
+	setweight(to_tsvector(`+textSearchConfig+`, @description), 'D'),
+	NOW())
+ON CONFLICT (product_id) DO UPDATE SET
+	category_id = EXCLUDED.category_id, brand = EXCLUDED.brand,
+	min_price = EXCLUDED.min_price, max_price = EXCLUDED.max_price,
+	is_active = EXCLUDED.is_active, name = EXCLUDED.name, description = EXCLUDED.description,
+	search_text = EXCLUDED.search_text, document = EXCLUDED.document, updated_at = EXCLUDED.updated_at`,
+		map[string]interface{}{
+			"id":          doc.ProductID,
+			"category_id": doc.CategoryID,
+			"category":    doc.Category,
+			"brand":       doc.Brand,
+			"min_price":   doc.MinPrice,
+			"max_price":   doc.MaxPrice,
+			"is_active":   doc.IsActive,
+			"name":        doc.Name,
+			"description": doc.Description,
+			"attributes":  strings.Join(doc.Attributes, " "),
+			"search_text": strings.Join([]string{doc.Name, doc.Brand, doc.Category}, " "),
+		}).Error
+}
+func (e *PostgresEngine) Remove(productID uint) error {
+	return e.db.Delete(&models.SearchDocument{}, productID).Error
+}
+
+// Search runs the query, the total count and the three facet counts in one
+// read transaction.
+func (e *PostgresEngine) Search(query Query) (*Result, error) {
+	result := &Result{Hits: []Hit{}}
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		threshold := strconv.FormatFloat(similarityThreshold, 'f', -1, 64)
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", threshold).Error; err != nil {
+			return err
+		}
+		if err := e.filtered(tx, query, "").Count(&result.Total).Error; err != nil {
+			return err
+		}
+		if err := e.hits(tx, query, result); err != nil {
+			return err
+		}
+		return e.facets(tx, query, &result.Facets)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Facet names for filtered.
+const (
+	facetCategory = "category"
+	facetBrand    = "brand"
+	facetPrice    = "price"
+)
+
+// filtered selects the active documents matching the query, leaving out the
+// filter of the facet named by except.
+func (e *PostgresEngine) filtered(tx *gorm.DB, query Query, except string) *gorm.DB {
+	db := tx.Model(&models.SearchDocument{}).Where("is_active")
+	if query.Text != "" {
+		db = db.Where(matchSQL, sql.Named("q", query.Text))
+	}
+	if except != facetCategory && query.CategoryIDs != nil {
+		db = db.Where("category_id IN ?", query.CategoryIDs)
+	}
+	if except != facetBrand && query.Brand != "" {
+		db = db.Where("LOWER(brand) = LOWER(?)", query.Brand)
+	}
+	if except != facetPrice {
+		if query.MinPrice > 0 {
+			db = db.Where("max_price >= ?", query.MinPrice)
+		}
+		if query.MaxPrice > 0 {
+			db = db.Where("min_price <= ?", query.MaxPrice)
+		}
+	}
+	return db
+}
+func (e *PostgresEngine) hits(tx *gorm.DB, query Query, result *Result) error {
+	order, ok := searchSortOrders[query.Sort]
+	if !ok {
+		order = searchSortOrders[models.SortRelevance]
+	}
+	db := e.filtered(tx, query, "")
+	if query.Text != "" {
+		nameOptions := fmt.Sprintf("HighlightAll=true, StartSel=%s, StopSel=%s", HighlightStart, HighlightStop)
+		descriptionOptions := fmt.Sprintf("MaxFragments=2, MaxWords=20, MinWords=8, StartSel=%s, StopSel=%s", HighlightStart, HighlightStop)
+		db = db.Select("product_id, "+scoreSQL+" AS score, "+
+			"ts_headline("+textSearchConfig+", name, "+tsQuery+", @name_options) AS name_highlight, "+
+			"ts_headline("+textSearchConfig+", description, "+tsQuery+", @description_options) AS description_highlight",
+			sql.Named("q", query.Text), sql.Named("name_options", nameOptions), sql.Named("description_options", descriptionOptions))
+	} else {
+		db = db.Select("product_id, 0 AS score, name AS name_highlight, '' AS description_highlight")
+	}
+	return db.Order(order).
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Scan(&result.Hits).Error
+}
+func (e *PostgresEngine) facets(tx *gorm.DB, query Query, facets *Facets) error {
+	err := e.filtered(tx, query, facetCategory).
+		Select("category_id, COUNT(*) AS count").
+		Group("category_id").
+		Order("count DESC, category_id").
+		Scan(&facets.Categories).Error
+	if err != nil {
+		return err
+	}
+	err = e.filtered(tx, query, facetBrand).
+		Where("brand <> ''").
+		Select("brand, COUNT(*) AS count").
+		Group("brand").
+		Order("count DESC, brand").
+		Limit(maxBrandFacets).
+		Scan(&facets.Brands).Error
+	if err != nil {
+		return err
+	}
+	var buckets []struct {
+		Bucket int
+		Count  int64
+	}
+	err = e.filtered(tx, query, facetPrice).
+		Select(priceBucketSQL() + " AS bucket, COUNT(*) AS count").
+		Group("bucket").
+		Scan(&buckets).Error
+	if err != nil {
+		return err
+	}
+	facets.PriceRanges = make([]PriceRangeCount, len(PriceRanges))
+	for i, priceRange := range PriceRanges {
+		facets.PriceRanges[i].PriceRange = priceRange
+	}
+	for _, bucket := range buckets {
+		if bucket.Bucket >= 0 && bucket.Bucket < len(PriceRanges) {
+			facets.PriceRanges[bucket.Bucket].Count = bucket.Count
+		}
+	}
+	return nil
+}
+
+// priceBucketSQL returns a CASE expression numbering the PriceRanges bucket
+// of a product by its cheapest variant.
+func priceBucketSQL() string {
+	var b strings.Builder
+	b.WriteString("CASE")
+	for i, priceRange := range PriceRanges {
+		if priceRange.Max == 0 {
+			fmt.Fprintf(&b, " WHEN min_price >= %d THEN %d", priceRange.Min, i)
+		} else {
+			fmt.Fprintf(&b, " WHEN min_price >= %d AND min_price < %d THEN %d", priceRange.Min, priceRange.Max, i)
+		}
+	}
+	b.WriteString(" ELSE -1 END")
+	return b.String()
+}
@@ -0,0 +1,104 @@
+// Package search finds products by free text with faceted counts. Engine
+// abstracts the backend so that an external search service can replace the
+// Postgres implementation.
+package search
+
+// Highlighted matches in the returned fields are wrapped in these markers.
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Document is the searchable content of one product.
+type Document struct {
+	ProductID   uint
+	CategoryID  uint
+	Category    string
+	Name        string
+	Brand       string
+	Description string
+	// SKUs, sizes and colours of the variants
+	Attributes []string
+	MinPrice   int64
+	MaxPrice   int64
+	IsActive   bool
+}
+
+// Query selects and orders search results. Zero values mean no filtering on
+// that field; an empty Text matches every active product.
+type Query struct {
+	Text string
+	// nil means all categories
+	CategoryIDs []uint
+	Brand       string
+	// a product matches when any of its variants is priced within the range
+	MinPrice int64
+	MaxPrice int64
+	// one of the models sort orders; relevance by default
+	Sort  string
+	Page  int
+	Limit int
+}
+
+// Hit is one matching product with its highlighted name and description
+// snippets.
+type Hit struct {
+	ProductID            uint
+	Score                float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// PriceRange is a price facet bucket; a zero Max means no upper bound.
+type PriceRange struct {
+	Min int64
+	Max int64
+}
+
+// PriceRanges are the buckets of the price facet, in paise.
+var PriceRanges = []PriceRange{
+	{Min: 0, Max: 50000},
+	{Min: 50000, Max: 100000},
+	{Min: 100000, Max: 250000},
+	{Min: 250000, Max: 500000},
+	{Min: 500000},
+}
+
+type CategoryCount struct {
+	CategoryID uint
+	// filled in by the caller; engines only know the ID
+	Name  string
+	Count int64
+}
+type BrandCount struct {
+	Brand string
+	Count int64
+}
+type PriceRangeCount struct {
+	PriceRange
+	Count int64
+}
+
+// Facets count the matches by category, brand and price range. Each facet
+// ignores its own filter, so that it shows the alternatives to the current
+// selection.
+type Facets struct {
+	Categories  []CategoryCount
+	Brands      []BrandCount
+	PriceRanges []PriceRangeCount
+}
+
+// Result is one page of hits, the total number of matches and the facets.
+type Result struct {
+	Hits   []Hit
+	Total  int64
+	Facets Facets
+}
+
+// Engine indexes products and searches them.
+type Engine interface {
+	// Index adds the document or replaces the one of the same product.
+	Index(doc Document) error
+	Remove(productID uint) error
+	Search(query Query) (*Result, error)
+}
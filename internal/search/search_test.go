@@ -0,0 +1,26 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceRangesAreContiguous(t *testing.T) {
+	assert.Equal(t, int64(0), PriceRanges[0].Min)
+	for i := 1; i < len(PriceRanges); i++ {
+		assert.Equal(t, PriceRanges[i-1].Max, PriceRanges[i].Min)
+	}
+	assert.Equal(t, int64(0), PriceRanges[len(PriceRanges)-1].Max, "the last range is open ended")
+}
+
+func TestPriceBucketSQL(t *testing.T) {
+	expected := "CASE" +
+		" WHEN min_price >= 0 AND min_price < 50000 THEN 0" +
+		" WHEN min_price >= 50000 AND min_price < 100000 THEN 1" +
+		" WHEN min_price >= 100000 AND min_price < 250000 THEN 2" +
+		" WHEN min_price >= 250000 AND min_price < 500000 THEN 3" +
+		" WHEN min_price >= 500000 THEN 4" +
+		" ELSE -1 END"
+	assert.Equal(t, expected, priceBucketSQL())
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+type IAddressService interface {
+	GetAddresses(userID uint) ([]models.Address, error)
+	GetAddress(userID uint, addressID uint) (*models.Address, error)
+	CreateAddress(userID uint, request dto.AddressRequest) (*models.Address, error)
+	UpdateAddress(userID uint, addressID uint, request dto.AddressRequest) (*models.Address, error)
+	DeleteAddress(userID uint, addressID uint) error
+}
+type AddressService struct {
+	addressRepo *repository.AddressRepository
+}
+
+func NewAddressService(addressRepo *repository.AddressRepository) *AddressService {
+	return &AddressService{addressRepo: addressRepo}
+}
+func (c *AddressService) GetAddresses(userID uint) ([]models.Address, error) {
+	return c.addressRepo.GetAddresses(userID)
+}
+func (c *AddressService) GetAddress(userID uint, addressID uint) (*models.Address, error) {
+	return c.addressRepo.GetAddress(userID, addressID)
+}
+func (c *AddressService) CreateAddress(userID uint, request dto.AddressRequest) (*models.Address, error) {
+	if !utils.ValidatePostalCode(request.Country, request.PostalCode) {
+		return nil, errors.New(models.InvalidPostalCode)
+	}
+	address := models.Address{UserID: userID}
+	request.ToAddress(&address)
+	err := c.addressRepo.CreateAddress(&address)
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+func (c *AddressService) UpdateAddress(userID uint, addressID uint, request dto.AddressRequest) (*models.Address, error) {
+	if !utils.ValidatePostalCode(request.Country, request.PostalCode) {
+		return nil, errors.New(models.InvalidPostalCode)
+	}
+	address, err := c.addressRepo.GetAddress(userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+	request.ToAddress(address)
+	err = c.addressRepo.UpdateAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+func (c *AddressService) DeleteAddress(userID uint, addressID uint) error {
+	return c.addressRepo.DeleteAddress(userID, addressID)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IAdminService interface {
+	AdminLogin(request dto.LoginRequest) (*models.Admin, error)
+}
+type AdminService struct {
+	adminRepo *repository.AdminRepository
+}
+
+func NewAdminService(adminRepo *repository.AdminRepository) *AdminService {
+	return &AdminService{adminRepo: adminRepo}
+}
+func (c *AdminService) AdminLogin(request dto.LoginRequest) (*models.Admin, error) {
+	admin, err := c.adminRepo.GetAdminByEmail(request.Email)
+	if err != nil {
+		return nil, errors.New(models.InvalidInput)
+	}
+	if admin.Password != request.Password {
+		return nil, errors.New(models.InvalidInput)
+	}
+	return admin, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+// guest carts untouched for this long are deleted
+const guestCartLifetime = 30 * 24 * time.Hour
+
+type ICartService interface {
+	GetCart(owner models.CartOwner) (*models.Cart, []models.CartLine, error)
+	AcknowledgePrices(owner models.CartOwner) error
+	AddItem(owner models.CartOwner, request dto.CartItemRequest) error
+	UpdateItem(owner models.CartOwner, variantID uint, quantity int) error
+	RemoveItem(owner models.CartOwner, variantID uint) error
+	ClearCart(owner models.CartOwner) error
+	MergeGuestCart(guestToken string, userID uint) error
+}
+type CartService struct {
+	cartRepo    *repository.CartRepository
+	productRepo *repository.ProductRepository
+}
+
+func NewCartService(cartRepo *repository.CartRepository, productRepo *repository.ProductRepository) *CartService {
+	return &CartService{cartRepo: cartRepo, productRepo: productRepo}
+}
+
+// GetCart returns the cart with every line revalidated against current
+// prices and stock. Lines that cannot be bought as they are get Available
+// false and a warning; price changes are reported until the shopper
+// acknowledges them with AcknowledgePrices. The cart is not changed.
+func (c *CartService) GetCart(owner models.CartOwner) (*models.Cart, []models.CartLine, error) {
+	cart, err := c.cartRepo.GetCart(owner)
+	if err != nil {
+		return nil, nil, err
+	}
+	productIDs := make([]uint, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		productIDs = append(productIDs, item.Variant.ProductID)
+	}
+	products, err := c.productRepo.GetProductsByIDs(productIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := make([]models.CartLine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		variant := item.Variant
+		product, found := products[variant.ProductID]
+		line := models.CartLine{Item: item, Product: product, Available: true}
+		switch {
+		case variant.ID == 0 || variant.DeletedAt.Valid || !found || product.DeletedAt.Valid || !product.IsActive:
+			line.Available = false
+			line.Warnings = append(line.Warnings, models.ProductUnavailable)
+		case variant.Available() == 0:
+			line.Available = false
+			line.Warnings = append(line.Warnings, "out of stock")
+		case variant.Available() < item.Quantity:
+			line.Available = false
+			line.Warnings = append(line.Warnings, fmt.Sprintf("only %d left in stock", variant.Available()))
+		}
+		if line.Available && variant.Price != item.SeenPrice {
+			line.Warnings = append(line.Warnings, fmt.Sprintf("price changed from %s to %s", currency.FormatAmount(item.SeenPrice, currency.Base), currency.FormatAmount(variant.Price, currency.Base)))
+		}
+		lines = append(lines, line)
+	}
+	return cart, lines, nil
+}
+
+// AcknowledgePrices accepts the current prices of the available lines of the
+// cart, clearing their price change warnings so the cart can be checked out.
+func (c *CartService) AcknowledgePrices(owner models.CartOwner) error {
+	_, lines, err := c.GetCart(owner)
+	if err != nil {
+		return err
+	}
+	var repriced []models.CartItem
+	for _, line := range lines {
+		item := line.Item
+		if line.Available && item.Variant.Price != item.SeenPrice {
+			item.SeenPrice = item.Variant.Price
+			repriced = append(repriced, item)
+		}
+	}
+	if len(repriced) == 0 {
+		return nil
+	}
+	return c.cartRepo.UpdateSeenPrices(repriced)
+}
+func (c *CartService) AddItem(owner models.CartOwner, request dto.CartItemRequest) error {
+	cart, err := c.cartRepo.GetOrCreateCart(owner)
+	if err != nil {
+		return err
+	}
+	quantity := request.Quantity
+	for _, item := range cart.Items {
+		if item.VariantID == request.VariantID {
+			quantity += item.Quantity
+		}
+	}
+	return c.saveItem(cart.ID, request.VariantID, quantity)
+}
+func (c *CartService) UpdateItem(owner models.CartOwner, variantID uint, quantity int) error {
+	cart, err := c.cartRepo.GetCart(owner)
+	if err != nil {
+		return err
+	}
+	for _, item := range cart.Items {
+		if item.VariantID == variantID {
+			return c.saveItem(cart.ID, variantID, quantity)
+		}
+	}
+	return errors.New(models.CartItemNotFound)
+}
+
+// saveItem sets the quantity of a variant in the cart after checking that the
+// product is on sale and the quantity is within the limit and in stock.
+func (c *CartService) saveItem(cartID uint, variantID uint, quantity int) error {
+	if quantity > models.MaxCartItemQuantity {
+		return errors.New(models.QuantityLimitExceeded)
+	}
+	variant, err := c.productRepo.GetVariantByID(variantID)
+	if err != nil {
+		return err
+	}
+	if _, err := c.productRepo.GetProduct(variant.ProductID, false); err != nil {
+		if err.Error() == models.ProductNotFound {
+			return errors.New(models.ProductUnavailable)
+		}
+		return err
+	}
+	if variant.Available() < quantity {
+		return &models.StockError{SKU: variant.SKU}
+	}
+	return c.cartRepo.SaveItem(&models.CartItem{
+		CartID:    cartID,
+		VariantID: variantID,
+		Quantity:  quantity,
+		SeenPrice: variant.Price,
+	})
+}
+func (c *CartService) RemoveItem(owner models.CartOwner, variantID uint) error {
+	cart, err := c.cartRepo.GetCart(owner)
+	if err != nil {
+		return err
+	}
+	if cart.ID == 0 {
+		return errors.New(models.CartItemNotFound)
+	}
+	return c.cartRepo.RemoveItem(cart.ID, variantID)
+}
+func (c *CartService) ClearCart(owner models.CartOwner) error {
+	cart, err := c.cartRepo.GetCart(owner)
+	if err != nil || cart.ID == 0 {
+		return err
+	}
+	return c.cartRepo.ClearCart(cart.ID)
+}
+func (c *CartService) MergeGuestCart(guestToken string, userID uint) error {
+	return c.cartRepo.MergeGuestCart(guestToken, userID)
+}
+
+// DeleteAbandonedGuestCarts is run periodically to remove stale guest carts.
+func (c *CartService) DeleteAbandonedGuestCarts() error {
+	return c.cartRepo.DeleteGuestCartsBefore(time.Now().Add(-guestCartLifetime))
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectRepricedCart expects the cart of user 8 to be loaded with two lines:
+// variant 5 now priced 1200 after being added at 1000, and variant 6 unchanged.
+func expectRepricedCart(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(`FROM "carts" WHERE user_id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(4, 8))
+	mock.ExpectQuery(`FROM "cart_items"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "cart_id", "variant_id", "quantity", "seen_price"}).
+			AddRow(20, 4, 5, 1, 1000).
+			AddRow(21, 4, 6, 1, 500))
+	mock.ExpectQuery(`FROM "product_variants"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "sku", "price", "stock"}).
+			AddRow(5, 2, "SHIRT-M", 1200, 10).
+			AddRow(6, 2, "SHIRT-L", 500, 10))
+	mock.ExpectQuery(`FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "is_active"}).AddRow(2, "Shirt", true))
+}
+
+func TestGetCart(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	expectRepricedCart(mock)
+	service := NewCartService(repository.NewCartRepository(db), repository.NewProductRepository(db))
+	_, lines, err := service.GetCart(models.CartOwner{UserID: 8})
+	assert.NoError(t, err)
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, []string{"price changed from 10.00 to 12.00"}, lines[0].Warnings)
+		assert.Empty(t, lines[1].Warnings)
+	}
+	// reading the cart does not acknowledge the new price
+	assert.Equal(t, int64(1000), lines[0].Item.SeenPrice)
+}
+
+func TestAcknowledgePrices(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	expectRepricedCart(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "cart_items" SET "seen_price"=\$1 WHERE id = \$2`).
+		WithArgs(int64(1200), uint(20)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	service := NewCartService(repository.NewCartRepository(db), repository.NewProductRepository(db))
+	assert.NoError(t, service.AcknowledgePrices(models.CartOwner{UserID: 8}))
+}
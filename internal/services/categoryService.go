@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+type ICategoryService interface {
+	GetCategories() ([]models.Category, error)
+	CreateCategory(request dto.CategoryRequest) (*models.Category, error)
+	UpdateCategory(categoryID uint, request dto.CategoryRequest) (*models.Category, error)
+	DeleteCategory(categoryID uint) error
+}
+type CategoryService struct {
+	categoryRepo  *repository.CategoryRepository
+	taxRepo       *repository.TaxRepository
+	productRepo   *repository.ProductRepository
+	searchService ISearchService
+}
+
+func NewCategoryService(categoryRepo *repository.CategoryRepository, taxRepo *repository.TaxRepository, productRepo *repository.ProductRepository, searchService ISearchService) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo, taxRepo: taxRepo, productRepo: productRepo, searchService: searchService}
+}
+func (c *CategoryService) GetCategories() ([]models.Category, error) {
+	return c.categoryRepo.GetCategories()
+}
+func (c *CategoryService) CreateCategory(request dto.CategoryRequest) (*models.Category, error) {
+	if request.ParentID != nil {
+		if _, err := c.categoryRepo.GetCategory(*request.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkTaxClass(request); err != nil {
+		return nil, err
+	}
+	category := models.Category{
+		Name:        request.Name,
+		Slug:        categorySlug(request),
+		Description: request.Description,
+		ParentID:    request.ParentID,
+		TaxClassID:  request.TaxClassID,
+	}
+	err := c.categoryRepo.CreateCategory(&category)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory saves the category and, when it was renamed or moved,
+// updates the search index entries of its products, which carry the
+// category name.
+func (c *CategoryService) UpdateCategory(categoryID uint, request dto.CategoryRequest) (*models.Category, error) {
+	category, err := c.categoryRepo.GetCategory(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if request.ParentID != nil {
+		// moving a category below itself or one of its descendants would create a cycle
+		descendants, err := c.categoryRepo.GetDescendantIDs(categoryID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range descendants {
+			if id == *request.ParentID {
+				return nil, errors.New(models.InvalidParentCategory)
+			}
+		}
+		if _, err := c.categoryRepo.GetCategory(*request.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkTaxClass(request); err != nil {
+		return nil, err
+	}
+	moved := (category.ParentID == nil) != (request.ParentID == nil) ||
+		(category.ParentID != nil && *category.ParentID != *request.ParentID)
+	reindex := moved || category.Name != request.Name
+	category.Name = request.Name
+	category.Slug = categorySlug(request)
+	category.Description = request.Description
+	category.ParentID = request.ParentID
+	category.TaxClassID = request.TaxClassID
+	err = c.categoryRepo.UpdateCategory(category)
+	if err != nil {
+		return nil, err
+	}
+	if reindex {
+		c.indexProducts(category.ID)
+	}
+	return category, nil
+}
+
+// indexProducts updates the search index entries of the products in the
+// category. The change is saved already, so a failure is only logged; the
+// periodic reindex repairs the entries.
+func (c *CategoryService) indexProducts(categoryID uint) {
+	ids, err := c.productRepo.GetCategoryProductIDs(categoryID)
+	if err != nil {
+		fmt.Println("failed to index products of category", categoryID, err)
+		return
+	}
+	for _, id := range ids {
+		if err := c.searchService.IndexProduct(id); err != nil {
+			fmt.Println("failed to index product", id, err)
+		}
+	}
+}
+func (c *CategoryService) DeleteCategory(categoryID uint) error {
+	return c.categoryRepo.DeleteCategory(categoryID)
+}
+func (c *CategoryService) checkTaxClass(request dto.CategoryRequest) error {
+	if request.TaxClassID == nil {
+		return nil
+	}
+	_, err := c.taxRepo.GetTaxClass(*request.TaxClassID)
+	return err
+}
+func categorySlug(request dto.CategoryRequest) string {
+	if request.Slug != "" {
+		return utils.Slugify(request.Slug)
+	}
+	return utils.Slugify(request.Name)
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCategory(t *testing.T) {
+	parentID := uint(5)
+	tests := []struct {
+		name    string
+		request dto.CategoryRequest
+		reindex bool
+	}{
+		{"description changed", dto.CategoryRequest{Name: "Shirts", Description: "Formal and casual"}, false},
+		{"renamed", dto.CategoryRequest{Name: "Shirts & Tops"}, true},
+		{"moved", dto.CategoryRequest{Name: "Shirts", ParentID: &parentID}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := mocks.NewMockDB(t)
+			search := NewMockISearchService(gomock.NewController(t))
+			mock.ExpectQuery(`FROM "categories" WHERE id = \$1`).
+				WithArgs(uint(3), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug"}).AddRow(3, "Shirts", "shirts"))
+			if test.request.ParentID != nil {
+				mock.ExpectQuery(`WITH RECURSIVE tree`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectQuery(`FROM "categories" WHERE id = \$1`).
+					WithArgs(parentID, sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(5, "Men"))
+			}
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE "categories"`).WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			if test.reindex {
+				mock.ExpectQuery(`SELECT "id" FROM "products" WHERE category_id = \$1`).
+					WithArgs(uint(3)).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(4))
+				// a failing entry does not hold back the others
+				search.EXPECT().IndexProduct(uint(2)).Return(errors.New("index unavailable"))
+				search.EXPECT().IndexProduct(uint(4)).Return(nil)
+			}
+			service := NewCategoryService(repository.NewCategoryRepository(db), nil, repository.NewProductRepository(db), search)
+			category, err := service.UpdateCategory(3, test.request)
+			assert.NoError(t, err)
+			assert.Equal(t, test.request.Name, category.Name)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/promotion"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type ICouponService interface {
+	GetCoupons(page int, limit int) ([]models.Coupon, int64, error)
+	GetCoupon(couponID uint) (*models.Coupon, error)
+	CreateCoupon(request dto.CouponRequest) (*models.Coupon, error)
+	UpdateCoupon(couponID uint, request dto.CouponRequest) (*models.Coupon, error)
+	DeleteCoupon(couponID uint) error
+	ApplyCoupon(userID uint, code string) error
+	RemoveCoupon(userID uint, code string) error
+	PriceCart(userID uint, cart *models.Cart, lines []models.CartLine) (*promotion.CartPricing, error)
+}
+type CouponService struct {
+	couponRepo   *repository.CouponRepository
+	categoryRepo *repository.CategoryRepository
+	cartService  ICartService
+}
+
+func NewCouponService(couponRepo *repository.CouponRepository, categoryRepo *repository.CategoryRepository, cartService ICartService) *CouponService {
+	return &CouponService{couponRepo: couponRepo, categoryRepo: categoryRepo, cartService: cartService}
+}
+func (c *CouponService) GetCoupons(page int, limit int) ([]models.Coupon, int64, error) {
+	return c.couponRepo.GetCoupons(page, limit)
+}
+func (c *CouponService) GetCoupon(couponID uint) (*models.Coupon, error) {
+	return c.couponRepo.GetCoupon(couponID)
+}
+func (c *CouponService) CreateCoupon(request dto.CouponRequest) (*models.Coupon, error) {
+	coupon := request.ToCoupon()
+	if err := validateCoupon(&coupon); err != nil {
+		return nil, err
+	}
+	if err := c.couponRepo.CreateCoupon(&coupon); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+func (c *CouponService) UpdateCoupon(couponID uint, request dto.CouponRequest) (*models.Coupon, error) {
+	existing, err := c.couponRepo.GetCoupon(couponID)
+	if err != nil {
+		return nil, err
+	}
+	coupon := request.ToCoupon()
+	coupon.ID = existing.ID
+	coupon.CreatedAt = existing.CreatedAt
+	if err := validateCoupon(&coupon); err != nil {
+		return nil, err
+	}
+	if err := c.couponRepo.UpdateCoupon(&coupon); err != nil {
+		return nil, err
+	}
+	return c.couponRepo.GetCoupon(couponID)
+}
+func (c *CouponService) DeleteCoupon(couponID uint) error {
+	return c.couponRepo.DeleteCoupon(couponID)
+}
+
+// validateCoupon checks that the value fields make sense for the coupon type.
+func validateCoupon(coupon *models.Coupon) error {
+	valid := true
+	switch coupon.Type {
+	case models.CouponPercentage:
+		valid = coupon.Value >= 1 && coupon.Value <= 100
+	case models.CouponFlat:
+		valid = coupon.Value > 0
+	case models.CouponBuyXGetY:
+		valid = coupon.BuyQuantity >= 1 && coupon.GetQuantity >= 1
+	}
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt) {
+		valid = false
+	}
+	if !valid {
+		return errors.New(models.InvalidCoupon)
+	}
+	return nil
+}
+
+// ApplyCoupon adds the coupon to the user's cart if it can be used on the
+// cart now and combined with the coupons already applied.
+func (c *CouponService) ApplyCoupon(userID uint, code string) error {
+	cart, lines, err := c.cartService.GetCart(models.CartOwner{UserID: userID})
+	if err != nil {
+		return err
+	}
+	if cart.ID == 0 || len(lines) == 0 {
+		return errors.New(models.CartEmpty)
+	}
+	coupon, err := c.couponRepo.GetCouponByCode(normalizeCouponCode(code))
+	if err != nil {
+		return err
+	}
+	applied, err := c.couponRepo.GetCartCoupons(cart.ID)
+	if err != nil {
+		return err
+	}
+	if err := promotion.CheckStacking(append(applied, *coupon)); err != nil {
+		return err
+	}
+	if err := c.checkCoupon(userID, coupon, lines); err != nil {
+		return err
+	}
+	return c.couponRepo.AddCartCoupon(cart.ID, coupon.ID)
+}
+func (c *CouponService) RemoveCoupon(userID uint, code string) error {
+	cart, _, err := c.cartService.GetCart(models.CartOwner{UserID: userID})
+	if err != nil {
+		return err
+	}
+	coupon, err := c.couponRepo.GetCouponByCode(normalizeCouponCode(code))
+	if err != nil {
+		return err
+	}
+	return c.couponRepo.RemoveCartCoupon(cart.ID, coupon.ID)
+}
+
+// PriceCart evaluates the coupons applied to the cart against its available
+// lines. Coupons that can no longer be used, e.g. because they expired or the
+// cart fell below the minimum value, give no discount and report why.
+func (c *CouponService) PriceCart(userID uint, cart *models.Cart, lines []models.CartLine) (*promotion.CartPricing, error) {
+	pricing := &promotion.CartPricing{Result: promotion.Apply(nil, nil)}
+	if cart.ID == 0 {
+		return pricing, nil
+	}
+	coupons, err := c.couponRepo.GetCartCoupons(cart.ID)
+	if err != nil || len(coupons) == 0 {
+		return pricing, err
+	}
+	var usable []models.Coupon
+	for i := range coupons {
+		if err := c.checkCoupon(userID, &coupons[i], lines); err != nil {
+			if !isCouponProblem(err) {
+				return nil, err
+			}
+			pricing.Coupons = append(pricing.Coupons, promotion.AppliedCoupon{Coupon: coupons[i], Problem: err.Error()})
+			continue
+		}
+		usable = append(usable, coupons[i])
+	}
+	pricing.Result = promotion.Apply(usable, promotionLines(lines))
+	for _, coupon := range usable {
+		pricing.Coupons = append(pricing.Coupons, promotion.AppliedCoupon{Coupon: coupon, Discount: pricing.Result.CouponDiscounts[coupon.ID]})
+	}
+	return pricing, nil
+}
+
+// checkCoupon checks the coupon against the cart and the user's redemptions.
+// It expands the coupon's categories to include their subcategories.
+func (c *CouponService) checkCoupon(userID uint, coupon *models.Coupon, lines []models.CartLine) error {
+	var categoryIDs []uint
+	for _, categoryID := range coupon.CategoryIDs {
+		ids, err := c.categoryRepo.GetDescendantIDs(categoryID)
+		if err != nil {
+			return err
+		}
+		categoryIDs = append(categoryIDs, ids...)
+	}
+	coupon.CategoryIDs = categoryIDs
+	if err := promotion.Check(coupon, promotionLines(lines), time.Now()); err != nil {
+		return err
+	}
+	if coupon.PerUserLimit > 0 {
+		count, err := c.couponRepo.CountUserRedemptions(coupon.ID, userID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(coupon.PerUserLimit) {
+			return errors.New(models.CouponUsageExceeded)
+		}
+	}
+	return nil
+}
+
+// promotionLines converts the available cart lines for the promotion engine.
+func promotionLines(lines []models.CartLine) []promotion.Line {
+	var result []promotion.Line
+	for _, line := range lines {
+		if !line.Available {
+			continue
+		}
+		result = append(result, promotion.Line{
+			Key:        line.Item.VariantID,
+			ProductID:  line.Product.ID,
+			CategoryID: line.Product.CategoryID,
+			UnitPrice:  line.Item.Variant.Price,
+			Quantity:   line.Item.Quantity,
+		})
+	}
+	return result
+}
+
+// isCouponProblem reports whether err explains why a coupon cannot be used,
+// as opposed to a failure to find out.
+func isCouponProblem(err error) bool {
+	switch err.Error() {
+	case models.CouponNotActive, models.CouponUsageExceeded, models.CouponMinOrderValue, models.CouponNotApplicable:
+		return true
+	}
+	return false
+}
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
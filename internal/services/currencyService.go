@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type ICurrencyService interface {
+	GetRates() (map[string]int64, error)
+	SaveRate(code string, request dto.ExchangeRateRequest) (*models.ExchangeRate, error)
+	DeleteRate(code string) error
+	CheckSupported(code string) error
+	GetDisplay(userID uint, code string) (currency.Display, error)
+}
+type CurrencyService struct {
+	currencyRepo *repository.CurrencyRepository
+	userRepo     *repository.UserRepository
+	// rates from the rates file, overridden by the ones set through the API
+	fileRates map[string]int64
+}
+
+func NewCurrencyService(currencyRepo *repository.CurrencyRepository, userRepo *repository.UserRepository, fileRates map[string]int64) *CurrencyService {
+	return &CurrencyService{currencyRepo: currencyRepo, userRepo: userRepo, fileRates: fileRates}
+}
+
+// GetRates returns the exchange rates of the currencies other than the base
+// currency that prices can be displayed in, keyed by currency code.
+func (c *CurrencyService) GetRates() (map[string]int64, error) {
+	stored, err := c.currencyRepo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[string]int64, len(c.fileRates)+len(stored))
+	for code, rate := range c.fileRates {
+		rates[code] = rate
+	}
+	for _, rate := range stored {
+		rates[rate.Currency] = rate.Rate
+	}
+	return rates, nil
+}
+
+// SaveRate sets the rate of the currency, replacing the one from the rates
+// file if any.
+func (c *CurrencyService) SaveRate(code string, request dto.ExchangeRateRequest) (*models.ExchangeRate, error) {
+	code = strings.ToUpper(code)
+	if !currency.Known(code) || code == currency.Base {
+		return nil, errors.New(models.UnsupportedCurrency)
+	}
+	value, err := currency.ParseRate(request.Rate)
+	if err != nil {
+		return nil, err
+	}
+	rate := models.ExchangeRate{Currency: code, Rate: value}
+	if err := c.currencyRepo.SaveRate(&rate); err != nil {
+		return nil, err
+	}
+	return c.currencyRepo.GetRate(code)
+}
+
+// DeleteRate removes the rate set for the currency. A rate for it in the
+// rates file applies again.
+func (c *CurrencyService) DeleteRate(code string) error {
+	return c.currencyRepo.DeleteRate(strings.ToUpper(code))
+}
+
+// CheckSupported returns UnsupportedCurrency unless prices can be displayed
+// in the currency. The empty code stands for the base currency.
+func (c *CurrencyService) CheckSupported(code string) error {
+	if code == "" || code == currency.Base {
+		return nil
+	}
+	rates, err := c.GetRates()
+	if err != nil {
+		return err
+	}
+	if _, ok := rates[code]; !ok {
+		return errors.New(models.UnsupportedCurrency)
+	}
+	return nil
+}
+
+// GetDisplay returns how to display prices in the requested currency code, or
+// without one in the currency the user chose in their profile. A zero userID
+// is a guest. A profile currency that is no longer supported falls back to
+// the base currency, a requested one is an error.
+func (c *CurrencyService) GetDisplay(userID uint, code string) (currency.Display, error) {
+	code = strings.ToUpper(code)
+	requested := code != ""
+	if !requested && userID != 0 {
+		// a token may outlive its account; show such users the base currency
+		if user, err := c.userRepo.GetUserById(userID); err == nil {
+			code = user.Currency
+		}
+	}
+	if code == "" || code == currency.Base {
+		return currency.BaseDisplay, nil
+	}
+	rates, err := c.GetRates()
+	if err != nil {
+		return currency.Display{}, err
+	}
+	rate, ok := rates[code]
+	if !ok {
+		if requested {
+			return currency.Display{}, errors.New(models.UnsupportedCurrency)
+		}
+		return currency.BaseDisplay, nil
+	}
+	return currency.Display{Currency: code, Rate: rate}, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IGiftCardService interface {
+	IssueGiftCard(request dto.GiftCardIssueRequest) (*models.GiftCard, string, error)
+	GetBalance(code string) (*models.GiftCard, error)
+	GetPurchasedGiftCards(userID uint) ([]models.GiftCard, error)
+	GetGiftCards(page int, limit int) ([]models.GiftCard, int64, error)
+	GetGiftCard(cardID uint) (*models.GiftCard, []models.GiftCardTransaction, error)
+	SetGiftCardActive(cardID uint, active bool) (*models.GiftCard, error)
+}
+type GiftCardService struct {
+	giftCardRepo *repository.GiftCardRepository
+	mailer       notification.Mailer
+}
+
+func NewGiftCardService(giftCardRepo *repository.GiftCardRepository, mailer notification.Mailer) *GiftCardService {
+	return &GiftCardService{giftCardRepo: giftCardRepo, mailer: mailer}
+}
+
+// IssueGiftCard creates an active gift card loaded with the requested amount,
+// e.g. as a goodwill gesture, and returns it with its code. The code is only
+// returned here and emailed to the recipient, if one is given.
+func (c *GiftCardService) IssueGiftCard(request dto.GiftCardIssueRequest) (*models.GiftCard, string, error) {
+	if request.Amount < models.MinGiftCardAmount || request.Amount > models.MaxGiftCardAmount {
+		return nil, "", errors.New(models.InvalidGiftCardAmount)
+	}
+	expiresAt := time.Now().Add(models.GiftCardValidity)
+	if request.ExpiresAt != nil {
+		if !request.ExpiresAt.After(time.Now()) {
+			return nil, "", errors.New(models.InvalidGiftCardExpiry)
+		}
+		expiresAt = *request.ExpiresAt
+	}
+	code, err := models.GenerateGiftCardCode()
+	if err != nil {
+		return nil, "", err
+	}
+	hash := models.HashGiftCardCode(code)
+	card := &models.GiftCard{
+		CodeHash:       &hash,
+		CodeHint:       models.GiftCardCodeHint(code),
+		Source:         models.GiftCardIssued,
+		Amount:         request.Amount,
+		Currency:       models.DefaultCurrency,
+		ExpiresAt:      &expiresAt,
+		IsActive:       true,
+		RecipientName:  request.RecipientName,
+		RecipientEmail: request.RecipientEmail,
+		Message:        request.Message,
+	}
+	err = c.giftCardRepo.Transaction(func(giftCards *repository.GiftCardRepository) error {
+		if err := giftCards.CreateGiftCard(card); err != nil {
+			return err
+		}
+		transaction, err := giftCards.Post(card.ID, models.GiftCardLoad, card.Amount, nil, "issued")
+		if err != nil {
+			return err
+		}
+		card.Balance = transaction.BalanceAfter
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if card.RecipientEmail != "" {
+		if err := sendGiftCard(c.mailer, card, code); err != nil {
+			// the admin still has the code from the response
+			fmt.Println("failed to email gift card", card.ID, err)
+		}
+	}
+	return card, code, nil
+}
+
+// GetBalance looks a card up by its code. Cards that were never activated are
+// reported as not found.
+func (c *GiftCardService) GetBalance(code string) (*models.GiftCard, error) {
+	return c.giftCardRepo.GetGiftCardByCode(models.HashGiftCardCode(code))
+}
+func (c *GiftCardService) GetPurchasedGiftCards(userID uint) ([]models.GiftCard, error) {
+	return c.giftCardRepo.GetPurchasedGiftCards(userID)
+}
+func (c *GiftCardService) GetGiftCards(page int, limit int) ([]models.GiftCard, int64, error) {
+	return c.giftCardRepo.GetGiftCards(page, limit)
+}
+func (c *GiftCardService) GetGiftCard(cardID uint) (*models.GiftCard, []models.GiftCardTransaction, error) {
+	card, err := c.giftCardRepo.GetGiftCard(cardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := c.giftCardRepo.GetTransactions(cardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return card, transactions, nil
+}
+
+// SetGiftCardActive enables or disables an activated card. Disabled cards
+// cannot be redeemed and refunds of their payments go to the wallet.
+func (c *GiftCardService) SetGiftCardActive(cardID uint, active bool) (*models.GiftCard, error) {
+	if err := c.giftCardRepo.SetGiftCardActive(cardID, active); err != nil {
+		return nil, err
+	}
+	return c.giftCardRepo.GetGiftCard(cardID)
+}
+
+// sendGiftCard emails the code of the card to its recipient.
+func sendGiftCard(mailer notification.Mailer, card *models.GiftCard, code string) error {
+	var body strings.Builder
+	if card.RecipientName != "" {
+		fmt.Fprintf(&body, "Hi %s,\n\n", card.RecipientName)
+	}
+	fmt.Fprintf(&body, "You have received a gift card worth %s %s.\n\n", card.Currency, currency.FormatAmount(card.Amount, card.Currency))
+	if card.Message != "" {
+		fmt.Fprintf(&body, "%s\n\n", card.Message)
+	}
+	fmt.Fprintf(&body, "Code: %s\n", code)
+	if card.ExpiresAt != nil {
+		fmt.Fprintf(&body, "Valid until: %s\n", card.ExpiresAt.Format("2 January 2006"))
+	}
+	body.WriteString("\nEnter the code at checkout to pay with it.\n")
+	return mailer.Send(card.RecipientEmail, "You have received a gift card", body.String())
+}
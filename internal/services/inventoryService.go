@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+// ReservationTTL is how long reserved stock is held before it is released
+// back to the available pool.
+const ReservationTTL = 15 * time.Minute
+
+type IInventoryService interface {
+	ReserveStock(userID uint, items []models.ReservationItem, reference string) ([]models.StockReservation, error)
+	ReleaseReservations(reservationIDs []uint) error
+	CommitReservations(reservationIDs []uint) error
+	GetInventory(sku string) (*models.ProductVariant, error)
+	AdjustStock(sku string, request dto.StockAdjustmentRequest) (*models.ProductVariant, error)
+	GetLowStock() ([]models.ProductVariant, error)
+	GetMovements(sku string, page, limit int) ([]models.InventoryMovement, int64, error)
+}
+type InventoryService struct {
+	inventoryRepo *repository.InventoryRepository
+	mailer        notification.Mailer
+}
+
+func NewInventoryService(inventoryRepo *repository.InventoryRepository, mailer notification.Mailer) *InventoryService {
+	return &InventoryService{inventoryRepo: inventoryRepo, mailer: mailer}
+}
+func (c *InventoryService) ReserveStock(userID uint, items []models.ReservationItem, reference string) ([]models.StockReservation, error) {
+	return c.inventoryRepo.ReserveStock(userID, items, time.Now().Add(ReservationTTL), reference)
+}
+func (c *InventoryService) ReleaseReservations(reservationIDs []uint) error {
+	return c.inventoryRepo.ReleaseReservations(reservationIDs, models.ReservationReleased)
+}
+func (c *InventoryService) CommitReservations(reservationIDs []uint) error {
+	return c.inventoryRepo.CommitReservations(reservationIDs)
+}
+func (c *InventoryService) GetInventory(sku string) (*models.ProductVariant, error) {
+	return c.inventoryRepo.GetVariantBySKU(sku)
+}
+func (c *InventoryService) AdjustStock(sku string, request dto.StockAdjustmentRequest) (*models.ProductVariant, error) {
+	variant, err := c.inventoryRepo.GetVariantBySKU(sku)
+	if err != nil {
+		return nil, err
+	}
+	return c.inventoryRepo.AdjustStock(variant.ID, request.Delta, models.MovementAdjustment, request.Reason)
+}
+func (c *InventoryService) GetLowStock() ([]models.ProductVariant, error) {
+	return c.inventoryRepo.GetLowStockVariants(false)
+}
+func (c *InventoryService) GetMovements(sku string, page, limit int) ([]models.InventoryMovement, int64, error) {
+	variant, err := c.inventoryRepo.GetVariantBySKU(sku)
+	if err != nil {
+		return nil, 0, err
+	}
+	return c.inventoryRepo.GetMovements(variant.ID, page, limit)
+}
+
+// ReleaseExpiredReservations is run periodically to free stock held by
+// checkouts that were never paid.
+func (c *InventoryService) ReleaseExpiredReservations() error {
+	ids, err := c.inventoryRepo.GetExpiredReservationIDs(time.Now())
+	if err != nil {
+		return err
+	}
+	return c.inventoryRepo.ReleaseReservations(ids, models.ReservationExpired)
+}
+
+// SendLowStockAlerts emails INVENTORY_ALERT_EMAIL about variants that dropped
+// to their low stock threshold since the last alert. Without an address
+// nothing is sent and the variants stay unalerted until one is set.
+func (c *InventoryService) SendLowStockAlerts() error {
+	to := os.Getenv("INVENTORY_ALERT_EMAIL")
+	if to == "" {
+		fmt.Println("INVENTORY_ALERT_EMAIL is not set, skipping low stock alerts")
+		return nil
+	}
+	variants, err := c.inventoryRepo.GetLowStockVariants(true)
+	if err != nil || len(variants) == 0 {
+		return err
+	}
+	var body strings.Builder
+	body.WriteString("The following SKUs are low on stock:\n\n")
+	ids := make([]uint, 0, len(variants))
+	for _, variant := range variants {
+		fmt.Fprintf(&body, "%s: %d available (%d on hand, %d reserved)\n", variant.SKU, variant.Available(), variant.Stock, variant.Reserved)
+		ids = append(ids, variant.ID)
+	}
+	err = c.mailer.Send(to, "Low stock alert", body.String())
+	if err != nil {
+		return err
+	}
+	return c.inventoryRepo.MarkLowStockAlerted(ids)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendLowStockAlerts(t *testing.T) {
+	lowStock := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "sku", "stock", "reserved"}).
+			AddRow(5, "SHIRT-M", 3, 1).
+			AddRow(6, "SHIRT-L", 1, 0)
+	}
+	body := "The following SKUs are low on stock:\n\n" +
+		"SHIRT-M: 2 available (3 on hand, 1 reserved)\n" +
+		"SHIRT-L: 1 available (1 on hand, 0 reserved)\n"
+
+	t.Run("no alert address", func(t *testing.T) {
+		t.Setenv("INVENTORY_ALERT_EMAIL", "")
+		db, _ := mocks.NewMockDB(t)
+		mailer := mocks.NewMockMailer(gomock.NewController(t))
+		assert.NoError(t, NewInventoryService(repository.NewInventoryRepository(db), mailer).SendLowStockAlerts())
+	})
+
+	t.Run("sent and marked", func(t *testing.T) {
+		t.Setenv("INVENTORY_ALERT_EMAIL", "stock@example.com")
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`WHERE stock - reserved <= low_stock_threshold AND NOT low_stock_alerted`).WillReturnRows(lowStock())
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "product_variants" SET "low_stock_alerted"=\$1 WHERE id IN \(\$2,\$3\)`).
+			WithArgs(true, uint(5), uint(6)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+		mailer := mocks.NewMockMailer(gomock.NewController(t))
+		mailer.EXPECT().Send("stock@example.com", "Low stock alert", body).Return(nil)
+		assert.NoError(t, NewInventoryService(repository.NewInventoryRepository(db), mailer).SendLowStockAlerts())
+	})
+
+	t.Run("not marked when sending fails", func(t *testing.T) {
+		t.Setenv("INVENTORY_ALERT_EMAIL", "stock@example.com")
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`NOT low_stock_alerted`).WillReturnRows(lowStock())
+		mailer := mocks.NewMockMailer(gomock.NewController(t))
+		mailer.EXPECT().Send("stock@example.com", "Low stock alert", body).Return(errors.New("smtp unavailable"))
+		err := NewInventoryService(repository.NewInventoryRepository(db), mailer).SendLowStockAlerts()
+		assert.EqualError(t, err, "smtp unavailable")
+	})
+}
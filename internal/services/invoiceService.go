@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/invoice"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+)
+
+type IInvoiceService interface {
+	GetInvoice(userID uint, orderID uint) (*models.Invoice, []byte, error)
+	StoreInvoices() error
+}
+type InvoiceService struct {
+	invoiceRepo *repository.InvoiceRepository
+	orderRepo   *repository.OrderRepository
+	storage     storage.BlobStorage
+	seller      invoice.Party
+}
+
+func NewInvoiceService(invoiceRepo *repository.InvoiceRepository, orderRepo *repository.OrderRepository, storage storage.BlobStorage, seller invoice.Party) *InvoiceService {
+	return &InvoiceService{invoiceRepo: invoiceRepo, orderRepo: orderRepo, storage: storage, seller: seller}
+}
+
+// GetInvoice returns the invoice of the user's order and its PDF. Orders get
+// their invoice when they are paid or confirmed; orders from before that get
+// it on first request, and orders cancelled before that never do. A PDF not
+// stored yet is stored now.
+func (c *InvoiceService) GetInvoice(userID uint, orderID uint) (*models.Invoice, []byte, error) {
+	order, err := c.orderRepo.GetOrder(userID, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	issued, err := c.invoiceRepo.GetOrderInvoice(order.ID)
+	if err != nil {
+		if err.Error() != models.InvoiceNotFound {
+			return nil, nil, err
+		}
+		if order.Status == models.OrderPending || order.Status == models.OrderCancelled {
+			return nil, nil, errors.New(models.InvoiceNotAvailable)
+		}
+		issued, err = c.invoiceRepo.IssueInvoice(order, time.Now())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if issued.StorePending {
+		if err := c.storeInvoice(order, issued); err != nil {
+			return nil, nil, err
+		}
+	}
+	file, err := c.storage.Get(issued.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return issued, data, nil
+}
+
+// StoreInvoices renders and stores the PDFs of the invoices issued since it
+// last ran.
+func (c *InvoiceService) StoreInvoices() error {
+	invoices, err := c.invoiceRepo.GetPendingInvoices()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range invoices {
+		order, err := c.orderRepo.GetOrderByID(invoices[i].OrderID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, c.storeInvoice(order, &invoices[i]))
+	}
+	return errors.Join(errs...)
+}
+
+// storeInvoice renders the invoice and saves it under its key. The document
+// only depends on the order and the invoice, so storing it again after a
+// failure or a concurrent store writes the same file.
+func (c *InvoiceService) storeInvoice(order *models.Order, issued *models.Invoice) error {
+	if err := c.storage.Put(issued.Key, invoice.Render(c.invoiceDocument(order, issued)), "application/pdf"); err != nil {
+		return err
+	}
+	return c.invoiceRepo.MarkInvoiceStored(issued)
+}
+
+// invoiceDocument lays out the order as printed on its invoice.
+func (c *InvoiceService) invoiceDocument(order *models.Order, issued *models.Invoice) invoice.Document {
+	doc := invoice.Document{
+		Number:      issued.Number,
+		Date:        issued.IssuedAt.In(models.InvoiceLocation),
+		OrderNumber: order.OrderNumber,
+		Currency:    models.DefaultCurrency,
+		Seller:      c.seller,
+		BillTo:      invoiceParty(order.BillingAddress),
+		ShipTo:      invoiceParty(order.ShippingAddress),
+		Notes: []string{
+			"Place of supply: " + order.ShippingAddress.State,
+			"This is a computer generated invoice and does not require a signature.",
+		},
+	}
+	taxes := map[string]*invoice.TaxLine{}
+	var labels []string
+	addTax := func(label string, taxable int64, amount int64) {
+		if amount == 0 {
+			return
+		}
+		if taxes[label] == nil {
+			taxes[label] = &invoice.TaxLine{Label: label}
+			labels = append(labels, label)
+		}
+		taxes[label].Taxable += taxable
+		taxes[label].Amount += amount
+	}
+	for _, item := range order.Items {
+		var details []string
+		for _, detail := range []string{item.SKU, item.Size, item.Colour} {
+			if detail != "" {
+				details = append(details, detail)
+			}
+		}
+		line := invoice.Line{
+			Description: item.ProductName,
+			Detail:      strings.Join(details, " / "),
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			Discount:    item.Discount,
+			Taxable:     item.Total - item.Tax,
+			Tax:         item.Tax,
+			Total:       item.Total,
+		}
+		addTax("CGST "+taxRateLabel(item.TaxRate, 2), line.Taxable, item.CGST)
+		addTax("SGST "+taxRateLabel(item.TaxRate, 2), line.Taxable, item.SGST)
+		addTax("IGST "+taxRateLabel(item.TaxRate, 1), line.Taxable, item.IGST)
+		doc.Lines = append(doc.Lines, line)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		doc.Taxes = append(doc.Taxes, *taxes[label])
+	}
+	taxLabel := "Tax"
+	if order.TaxInclusive {
+		// already part of the prices, so not added to the total again
+		taxLabel = "Tax (included)"
+	}
+	doc.Totals = append(doc.Totals, invoice.Amount{Label: "Subtotal", Amount: order.Subtotal})
+	if order.DiscountTotal > 0 {
+		doc.Totals = append(doc.Totals, invoice.Amount{Label: "Discount", Amount: -order.DiscountTotal})
+	}
+	doc.Totals = append(doc.Totals,
+		invoice.Amount{Label: taxLabel, Amount: order.TaxTotal},
+		invoice.Amount{Label: "Shipping", Amount: order.ShippingTotal},
+		invoice.Amount{Label: "Total", Amount: order.Total, Bold: true},
+	)
+	if len(order.CouponCodes) > 0 {
+		doc.Notes = append([]string{"Coupons applied: " + strings.Join(order.CouponCodes, ", ")}, doc.Notes...)
+	}
+	return doc
+}
+
+// taxRateLabel formats a rate in basis points divided by parts, e.g. 1800
+// in two parts as "9%".
+func taxRateLabel(rate int, parts int) string {
+	return strconv.FormatFloat(float64(rate)/float64(100*parts), 'f', -1, 64) + "%"
+}
+func invoiceParty(address models.OrderAddress) invoice.Party {
+	party := invoice.Party{Name: address.Name, Lines: []string{address.Line1}}
+	if address.Line2 != "" {
+		party.Lines = append(party.Lines, address.Line2)
+	}
+	party.Lines = append(party.Lines,
+		fmt.Sprintf("%s, %s %s", address.City, address.State, address.PostalCode),
+		address.Country,
+		"Phone: "+address.Phone,
+	)
+	return party
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type ILoyaltyService interface {
+	GetLoyalty(userID uint, page int, limit int) (*models.LoyaltyAccount, int64, []models.LoyaltyTransaction, int64, error)
+	GetRules() ([]models.LoyaltyRule, error)
+	SaveRule(request dto.LoyaltyRuleRequest) (*models.LoyaltyRule, error)
+	DeleteRule(categoryID uint) error
+	ApplyEarning(order *models.Order, productCategories map[uint]uint) error
+	ExpirePoints() error
+}
+type LoyaltyService struct {
+	loyaltyRepo  *repository.LoyaltyRepository
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewLoyaltyService(loyaltyRepo *repository.LoyaltyRepository, categoryRepo *repository.CategoryRepository) *LoyaltyService {
+	return &LoyaltyService{loyaltyRepo: loyaltyRepo, categoryRepo: categoryRepo}
+}
+
+// GetLoyalty returns the user's points balance, the points expiring within
+// LoyaltyExpiryNotice and one page of the points ledger. Lapsed points are
+// expired first so the balance is current.
+func (c *LoyaltyService) GetLoyalty(userID uint, page int, limit int) (*models.LoyaltyAccount, int64, []models.LoyaltyTransaction, int64, error) {
+	now := time.Now()
+	if err := c.loyaltyRepo.ExpirePoints(userID, now); err != nil {
+		return nil, 0, nil, 0, err
+	}
+	account, err := c.loyaltyRepo.GetAccount(userID)
+	if err != nil {
+		return nil, 0, nil, 0, err
+	}
+	expiring, err := c.loyaltyRepo.GetExpiringPoints(userID, now.Add(models.LoyaltyExpiryNotice))
+	if err != nil {
+		return nil, 0, nil, 0, err
+	}
+	transactions, total, err := c.loyaltyRepo.GetTransactions(userID, page, limit)
+	if err != nil {
+		return nil, 0, nil, 0, err
+	}
+	return account, expiring, transactions, total, nil
+}
+func (c *LoyaltyService) GetRules() ([]models.LoyaltyRule, error) {
+	return c.loyaltyRepo.GetRules()
+}
+
+// SaveRule sets the rate of the category, or the default rate when no
+// category is given.
+func (c *LoyaltyService) SaveRule(request dto.LoyaltyRuleRequest) (*models.LoyaltyRule, error) {
+	if request.CategoryID != 0 {
+		if _, err := c.categoryRepo.GetCategory(request.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	rule := &models.LoyaltyRule{CategoryID: request.CategoryID, Rate: *request.Rate}
+	if err := c.loyaltyRepo.SaveRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+func (c *LoyaltyService) DeleteRule(categoryID uint) error {
+	return c.loyaltyRepo.DeleteRule(categoryID)
+}
+
+// ApplyEarning sets the points the order items earn on delivery, by the rule
+// of each product's category, on the item value after discounts and before
+// tax. productCategories maps the products to their categories.
+func (c *LoyaltyService) ApplyEarning(order *models.Order, productCategories map[uint]uint) error {
+	categories, err := c.categoryRepo.GetCategories()
+	if err != nil {
+		return err
+	}
+	rules, err := c.loyaltyRepo.GetRules()
+	if err != nil {
+		return err
+	}
+	order.LoyaltyPoints = 0
+	for i := range order.Items {
+		item := &order.Items[i]
+		rate := resolveLoyaltyRate(productCategories[item.ProductID], categories, rules)
+		item.LoyaltyPoints = models.LoyaltyPoints(item.UnitPrice*int64(item.Quantity)-item.Discount, rate)
+		order.LoyaltyPoints += item.LoyaltyPoints
+	}
+	return nil
+}
+
+// ExpirePoints is run periodically to expire points that reached the end of
+// their validity.
+func (c *LoyaltyService) ExpirePoints() error {
+	return c.loyaltyRepo.ExpirePoints(0, time.Now())
+}
+
+// resolveLoyaltyRate returns the rate of the category or of its nearest
+// ancestor that has a rule, falling back to the default rule.
+func resolveLoyaltyRate(categoryID uint, categories []models.Category, rules []models.LoyaltyRule) int64 {
+	byID := make(map[uint]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	rates := make(map[uint]int64, len(rules))
+	for _, rule := range rules {
+		rates[rule.CategoryID] = rule.Rate
+	}
+	// the depth limit guards against a cycle in bad data
+	for depth := 0; depth < len(categories); depth++ {
+		category, ok := byID[categoryID]
+		if !ok {
+			break
+		}
+		if rate, ok := rates[category.ID]; ok {
+			return rate
+		}
+		if category.ParentID == nil {
+			break
+		}
+		categoryID = *category.ParentID
+	}
+	return rates[0]
+}
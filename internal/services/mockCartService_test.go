@@ -0,0 +1,136 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/cartService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockICartService is a mock of ICartService interface.
+type MockICartService struct {
+	ctrl     *gomock.Controller
+	recorder *MockICartServiceMockRecorder
+}
+
+// MockICartServiceMockRecorder is the mock recorder for MockICartService.
+type MockICartServiceMockRecorder struct {
+	mock *MockICartService
+}
+
+// NewMockICartService creates a new mock instance.
+func NewMockICartService(ctrl *gomock.Controller) *MockICartService {
+	mock := &MockICartService{ctrl: ctrl}
+	mock.recorder = &MockICartServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICartService) EXPECT() *MockICartServiceMockRecorder {
+	return m.recorder
+}
+
+// AcknowledgePrices mocks base method.
+func (m *MockICartService) AcknowledgePrices(owner models.CartOwner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcknowledgePrices", owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcknowledgePrices indicates an expected call of AcknowledgePrices.
+func (mr *MockICartServiceMockRecorder) AcknowledgePrices(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgePrices", reflect.TypeOf((*MockICartService)(nil).AcknowledgePrices), owner)
+}
+
+// AddItem mocks base method.
+func (m *MockICartService) AddItem(owner models.CartOwner, request dto.CartItemRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddItem", owner, request)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddItem indicates an expected call of AddItem.
+func (mr *MockICartServiceMockRecorder) AddItem(owner, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddItem", reflect.TypeOf((*MockICartService)(nil).AddItem), owner, request)
+}
+
+// ClearCart mocks base method.
+func (m *MockICartService) ClearCart(owner models.CartOwner) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearCart", owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearCart indicates an expected call of ClearCart.
+func (mr *MockICartServiceMockRecorder) ClearCart(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearCart", reflect.TypeOf((*MockICartService)(nil).ClearCart), owner)
+}
+
+// GetCart mocks base method.
+func (m *MockICartService) GetCart(owner models.CartOwner) (*models.Cart, []models.CartLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCart", owner)
+	ret0, _ := ret[0].(*models.Cart)
+	ret1, _ := ret[1].([]models.CartLine)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCart indicates an expected call of GetCart.
+func (mr *MockICartServiceMockRecorder) GetCart(owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCart", reflect.TypeOf((*MockICartService)(nil).GetCart), owner)
+}
+
+// MergeGuestCart mocks base method.
+func (m *MockICartService) MergeGuestCart(guestToken string, userID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeGuestCart", guestToken, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeGuestCart indicates an expected call of MergeGuestCart.
+func (mr *MockICartServiceMockRecorder) MergeGuestCart(guestToken, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeGuestCart", reflect.TypeOf((*MockICartService)(nil).MergeGuestCart), guestToken, userID)
+}
+
+// RemoveItem mocks base method.
+func (m *MockICartService) RemoveItem(owner models.CartOwner, variantID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveItem", owner, variantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveItem indicates an expected call of RemoveItem.
+func (mr *MockICartServiceMockRecorder) RemoveItem(owner, variantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveItem", reflect.TypeOf((*MockICartService)(nil).RemoveItem), owner, variantID)
+}
+
+// UpdateItem mocks base method.
+func (m *MockICartService) UpdateItem(owner models.CartOwner, variantID uint, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", owner, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateItem indicates an expected call of UpdateItem.
+func (mr *MockICartServiceMockRecorder) UpdateItem(owner, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockICartService)(nil).UpdateItem), owner, variantID, quantity)
+}
@@ -0,0 +1,155 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/couponService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	promotion "github.com/Ansalps/UserEcommerceClean/internal/promotion"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockICouponService is a mock of ICouponService interface.
+type MockICouponService struct {
+	ctrl     *gomock.Controller
+	recorder *MockICouponServiceMockRecorder
+}
+
+// MockICouponServiceMockRecorder is the mock recorder for MockICouponService.
+type MockICouponServiceMockRecorder struct {
+	mock *MockICouponService
+}
+
+// NewMockICouponService creates a new mock instance.
+func NewMockICouponService(ctrl *gomock.Controller) *MockICouponService {
+	mock := &MockICouponService{ctrl: ctrl}
+	mock.recorder = &MockICouponServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockICouponService) EXPECT() *MockICouponServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyCoupon mocks base method.
+func (m *MockICouponService) ApplyCoupon(userID uint, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyCoupon", userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyCoupon indicates an expected call of ApplyCoupon.
+func (mr *MockICouponServiceMockRecorder) ApplyCoupon(userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyCoupon", reflect.TypeOf((*MockICouponService)(nil).ApplyCoupon), userID, code)
+}
+
+// CreateCoupon mocks base method.
+func (m *MockICouponService) CreateCoupon(request dto.CouponRequest) (*models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCoupon", request)
+	ret0, _ := ret[0].(*models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCoupon indicates an expected call of CreateCoupon.
+func (mr *MockICouponServiceMockRecorder) CreateCoupon(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCoupon", reflect.TypeOf((*MockICouponService)(nil).CreateCoupon), request)
+}
+
+// DeleteCoupon mocks base method.
+func (m *MockICouponService) DeleteCoupon(couponID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCoupon", couponID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCoupon indicates an expected call of DeleteCoupon.
+func (mr *MockICouponServiceMockRecorder) DeleteCoupon(couponID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCoupon", reflect.TypeOf((*MockICouponService)(nil).DeleteCoupon), couponID)
+}
+
+// GetCoupon mocks base method.
+func (m *MockICouponService) GetCoupon(couponID uint) (*models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupon", couponID)
+	ret0, _ := ret[0].(*models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCoupon indicates an expected call of GetCoupon.
+func (mr *MockICouponServiceMockRecorder) GetCoupon(couponID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupon", reflect.TypeOf((*MockICouponService)(nil).GetCoupon), couponID)
+}
+
+// GetCoupons mocks base method.
+func (m *MockICouponService) GetCoupons(page, limit int) ([]models.Coupon, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCoupons", page, limit)
+	ret0, _ := ret[0].([]models.Coupon)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCoupons indicates an expected call of GetCoupons.
+func (mr *MockICouponServiceMockRecorder) GetCoupons(page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCoupons", reflect.TypeOf((*MockICouponService)(nil).GetCoupons), page, limit)
+}
+
+// PriceCart mocks base method.
+func (m *MockICouponService) PriceCart(userID uint, cart *models.Cart, lines []models.CartLine) (*promotion.CartPricing, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PriceCart", userID, cart, lines)
+	ret0, _ := ret[0].(*promotion.CartPricing)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PriceCart indicates an expected call of PriceCart.
+func (mr *MockICouponServiceMockRecorder) PriceCart(userID, cart, lines interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PriceCart", reflect.TypeOf((*MockICouponService)(nil).PriceCart), userID, cart, lines)
+}
+
+// RemoveCoupon mocks base method.
+func (m *MockICouponService) RemoveCoupon(userID uint, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCoupon", userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCoupon indicates an expected call of RemoveCoupon.
+func (mr *MockICouponServiceMockRecorder) RemoveCoupon(userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCoupon", reflect.TypeOf((*MockICouponService)(nil).RemoveCoupon), userID, code)
+}
+
+// UpdateCoupon mocks base method.
+func (m *MockICouponService) UpdateCoupon(couponID uint, request dto.CouponRequest) (*models.Coupon, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCoupon", couponID, request)
+	ret0, _ := ret[0].(*models.Coupon)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCoupon indicates an expected call of UpdateCoupon.
+func (mr *MockICouponServiceMockRecorder) UpdateCoupon(couponID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCoupon", reflect.TypeOf((*MockICouponService)(nil).UpdateCoupon), couponID, request)
+}
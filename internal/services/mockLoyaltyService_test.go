@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/loyaltyService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockILoyaltyService is a mock of ILoyaltyService interface.
+type MockILoyaltyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockILoyaltyServiceMockRecorder
+}
+
+// MockILoyaltyServiceMockRecorder is the mock recorder for MockILoyaltyService.
+type MockILoyaltyServiceMockRecorder struct {
+	mock *MockILoyaltyService
+}
+
+// NewMockILoyaltyService creates a new mock instance.
+func NewMockILoyaltyService(ctrl *gomock.Controller) *MockILoyaltyService {
+	mock := &MockILoyaltyService{ctrl: ctrl}
+	mock.recorder = &MockILoyaltyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockILoyaltyService) EXPECT() *MockILoyaltyServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyEarning mocks base method.
+func (m *MockILoyaltyService) ApplyEarning(order *models.Order, productCategories map[uint]uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyEarning", order, productCategories)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyEarning indicates an expected call of ApplyEarning.
+func (mr *MockILoyaltyServiceMockRecorder) ApplyEarning(order, productCategories interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyEarning", reflect.TypeOf((*MockILoyaltyService)(nil).ApplyEarning), order, productCategories)
+}
+
+// DeleteRule mocks base method.
+func (m *MockILoyaltyService) DeleteRule(categoryID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRule", categoryID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRule indicates an expected call of DeleteRule.
+func (mr *MockILoyaltyServiceMockRecorder) DeleteRule(categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRule", reflect.TypeOf((*MockILoyaltyService)(nil).DeleteRule), categoryID)
+}
+
+// ExpirePoints mocks base method.
+func (m *MockILoyaltyService) ExpirePoints() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpirePoints")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpirePoints indicates an expected call of ExpirePoints.
+func (mr *MockILoyaltyServiceMockRecorder) ExpirePoints() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpirePoints", reflect.TypeOf((*MockILoyaltyService)(nil).ExpirePoints))
+}
+
+// GetLoyalty mocks base method.
+func (m *MockILoyaltyService) GetLoyalty(userID uint, page, limit int) (*models.LoyaltyAccount, int64, []models.LoyaltyTransaction, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoyalty", userID, page, limit)
+	ret0, _ := ret[0].(*models.LoyaltyAccount)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].([]models.LoyaltyTransaction)
+	ret3, _ := ret[3].(int64)
+	ret4, _ := ret[4].(error)
+	return ret0, ret1, ret2, ret3, ret4
+}
+
+// GetLoyalty indicates an expected call of GetLoyalty.
+func (mr *MockILoyaltyServiceMockRecorder) GetLoyalty(userID, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoyalty", reflect.TypeOf((*MockILoyaltyService)(nil).GetLoyalty), userID, page, limit)
+}
+
+// GetRules mocks base method.
+func (m *MockILoyaltyService) GetRules() ([]models.LoyaltyRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules")
+	ret0, _ := ret[0].([]models.LoyaltyRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules.
+func (mr *MockILoyaltyServiceMockRecorder) GetRules() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockILoyaltyService)(nil).GetRules))
+}
+
+// SaveRule mocks base method.
+func (m *MockILoyaltyService) SaveRule(request dto.LoyaltyRuleRequest) (*models.LoyaltyRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRule", request)
+	ret0, _ := ret[0].(*models.LoyaltyRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveRule indicates an expected call of SaveRule.
+func (mr *MockILoyaltyServiceMockRecorder) SaveRule(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRule", reflect.TypeOf((*MockILoyaltyService)(nil).SaveRule), request)
+}
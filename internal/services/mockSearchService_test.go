@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/searchService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	search "github.com/Ansalps/UserEcommerceClean/internal/search"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockISearchService is a mock of ISearchService interface.
+type MockISearchService struct {
+	ctrl     *gomock.Controller
+	recorder *MockISearchServiceMockRecorder
+}
+
+// MockISearchServiceMockRecorder is the mock recorder for MockISearchService.
+type MockISearchServiceMockRecorder struct {
+	mock *MockISearchService
+}
+
+// NewMockISearchService creates a new mock instance.
+func NewMockISearchService(ctrl *gomock.Controller) *MockISearchService {
+	mock := &MockISearchService{ctrl: ctrl}
+	mock.recorder = &MockISearchServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockISearchService) EXPECT() *MockISearchServiceMockRecorder {
+	return m.recorder
+}
+
+// IndexProduct mocks base method.
+func (m *MockISearchService) IndexProduct(productID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexProduct", productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexProduct indicates an expected call of IndexProduct.
+func (mr *MockISearchServiceMockRecorder) IndexProduct(productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexProduct", reflect.TypeOf((*MockISearchService)(nil).IndexProduct), productID)
+}
+
+// Reindex mocks base method.
+func (m *MockISearchService) Reindex() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reindex")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reindex indicates an expected call of Reindex.
+func (mr *MockISearchServiceMockRecorder) Reindex() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reindex", reflect.TypeOf((*MockISearchService)(nil).Reindex))
+}
+
+// Search mocks base method.
+func (m *MockISearchService) Search(query search.Query, categoryID uint) (*search.Result, map[uint]models.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", query, categoryID)
+	ret0, _ := ret[0].(*search.Result)
+	ret1, _ := ret[1].(map[uint]models.Product)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockISearchServiceMockRecorder) Search(query, categoryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockISearchService)(nil).Search), query, categoryID)
+}
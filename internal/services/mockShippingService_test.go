@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/shippingService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	http "net/http"
+	reflect "reflect"
+
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIShippingService is a mock of IShippingService interface.
+type MockIShippingService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIShippingServiceMockRecorder
+}
+
+// MockIShippingServiceMockRecorder is the mock recorder for MockIShippingService.
+type MockIShippingServiceMockRecorder struct {
+	mock *MockIShippingService
+}
+
+// NewMockIShippingService creates a new mock instance.
+func NewMockIShippingService(ctrl *gomock.Controller) *MockIShippingService {
+	mock := &MockIShippingService{ctrl: ctrl}
+	mock.recorder = &MockIShippingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIShippingService) EXPECT() *MockIShippingServiceMockRecorder {
+	return m.recorder
+}
+
+// AdminGetOrderShipments mocks base method.
+func (m *MockIShippingService) AdminGetOrderShipments(orderID uint) ([]models.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdminGetOrderShipments", orderID)
+	ret0, _ := ret[0].([]models.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdminGetOrderShipments indicates an expected call of AdminGetOrderShipments.
+func (mr *MockIShippingServiceMockRecorder) AdminGetOrderShipments(orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdminGetOrderShipments", reflect.TypeOf((*MockIShippingService)(nil).AdminGetOrderShipments), orderID)
+}
+
+// CreateShipment mocks base method.
+func (m *MockIShippingService) CreateShipment(orderID uint) (*models.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShipment", orderID)
+	ret0, _ := ret[0].(*models.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShipment indicates an expected call of CreateShipment.
+func (mr *MockIShippingServiceMockRecorder) CreateShipment(orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShipment", reflect.TypeOf((*MockIShippingService)(nil).CreateShipment), orderID)
+}
+
+// CreateShippingMethod mocks base method.
+func (m *MockIShippingService) CreateShippingMethod(request dto.ShippingMethodRequest) (*models.ShippingMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShippingMethod", request)
+	ret0, _ := ret[0].(*models.ShippingMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShippingMethod indicates an expected call of CreateShippingMethod.
+func (mr *MockIShippingServiceMockRecorder) CreateShippingMethod(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShippingMethod", reflect.TypeOf((*MockIShippingService)(nil).CreateShippingMethod), request)
+}
+
+// DeleteShippingMethod mocks base method.
+func (m *MockIShippingService) DeleteShippingMethod(methodID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShippingMethod", methodID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShippingMethod indicates an expected call of DeleteShippingMethod.
+func (mr *MockIShippingServiceMockRecorder) DeleteShippingMethod(methodID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShippingMethod", reflect.TypeOf((*MockIShippingService)(nil).DeleteShippingMethod), methodID)
+}
+
+// GetOrderShipments mocks base method.
+func (m *MockIShippingService) GetOrderShipments(userID, orderID uint) ([]models.Shipment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderShipments", userID, orderID)
+	ret0, _ := ret[0].([]models.Shipment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderShipments indicates an expected call of GetOrderShipments.
+func (mr *MockIShippingServiceMockRecorder) GetOrderShipments(userID, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderShipments", reflect.TypeOf((*MockIShippingService)(nil).GetOrderShipments), userID, orderID)
+}
+
+// GetQuotes mocks base method.
+func (m *MockIShippingService) GetQuotes(userID, addressID uint) ([]models.ShippingQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotes", userID, addressID)
+	ret0, _ := ret[0].([]models.ShippingQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotes indicates an expected call of GetQuotes.
+func (mr *MockIShippingServiceMockRecorder) GetQuotes(userID, addressID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotes", reflect.TypeOf((*MockIShippingService)(nil).GetQuotes), userID, addressID)
+}
+
+// GetShipmentLabel mocks base method.
+func (m *MockIShippingService) GetShipmentLabel(shipmentID uint) (*models.Shipment, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShipmentLabel", shipmentID)
+	ret0, _ := ret[0].(*models.Shipment)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetShipmentLabel indicates an expected call of GetShipmentLabel.
+func (mr *MockIShippingServiceMockRecorder) GetShipmentLabel(shipmentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShipmentLabel", reflect.TypeOf((*MockIShippingService)(nil).GetShipmentLabel), shipmentID)
+}
+
+// GetShippingMethods mocks base method.
+func (m *MockIShippingService) GetShippingMethods() ([]models.ShippingMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShippingMethods")
+	ret0, _ := ret[0].([]models.ShippingMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShippingMethods indicates an expected call of GetShippingMethods.
+func (mr *MockIShippingServiceMockRecorder) GetShippingMethods() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShippingMethods", reflect.TypeOf((*MockIShippingService)(nil).GetShippingMethods))
+}
+
+// HandleWebhook mocks base method.
+func (m *MockIShippingService) HandleWebhook(payload []byte, header http.Header) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleWebhook", payload, header)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleWebhook indicates an expected call of HandleWebhook.
+func (mr *MockIShippingServiceMockRecorder) HandleWebhook(payload, header interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleWebhook", reflect.TypeOf((*MockIShippingService)(nil).HandleWebhook), payload, header)
+}
+
+// QuoteShipping mocks base method.
+func (m *MockIShippingService) QuoteShipping(address models.OrderAddress, weight int, value int64) ([]models.ShippingQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteShipping", address, weight, value)
+	ret0, _ := ret[0].([]models.ShippingQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteShipping indicates an expected call of QuoteShipping.
+func (mr *MockIShippingServiceMockRecorder) QuoteShipping(address, weight, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteShipping", reflect.TypeOf((*MockIShippingService)(nil).QuoteShipping), address, weight, value)
+}
+
+// SimulateTracking mocks base method.
+func (m *MockIShippingService) SimulateTracking(trackingNumber, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateTracking", trackingNumber, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SimulateTracking indicates an expected call of SimulateTracking.
+func (mr *MockIShippingServiceMockRecorder) SimulateTracking(trackingNumber, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateTracking", reflect.TypeOf((*MockIShippingService)(nil).SimulateTracking), trackingNumber, status)
+}
+
+// UpdateShippingMethod mocks base method.
+func (m *MockIShippingService) UpdateShippingMethod(methodID uint, request dto.ShippingMethodRequest) (*models.ShippingMethod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateShippingMethod", methodID, request)
+	ret0, _ := ret[0].(*models.ShippingMethod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateShippingMethod indicates an expected call of UpdateShippingMethod.
+func (mr *MockIShippingServiceMockRecorder) UpdateShippingMethod(methodID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateShippingMethod", reflect.TypeOf((*MockIShippingService)(nil).UpdateShippingMethod), methodID, request)
+}
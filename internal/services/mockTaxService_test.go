@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/services/taxService.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	dto "github.com/Ansalps/UserEcommerceClean/internal/dto"
+	models "github.com/Ansalps/UserEcommerceClean/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockITaxService is a mock of ITaxService interface.
+type MockITaxService struct {
+	ctrl     *gomock.Controller
+	recorder *MockITaxServiceMockRecorder
+}
+
+// MockITaxServiceMockRecorder is the mock recorder for MockITaxService.
+type MockITaxServiceMockRecorder struct {
+	mock *MockITaxService
+}
+
+// NewMockITaxService creates a new mock instance.
+func NewMockITaxService(ctrl *gomock.Controller) *MockITaxService {
+	mock := &MockITaxService{ctrl: ctrl}
+	mock.recorder = &MockITaxServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockITaxService) EXPECT() *MockITaxServiceMockRecorder {
+	return m.recorder
+}
+
+// ApplyTax mocks base method.
+func (m *MockITaxService) ApplyTax(order *models.Order, productCategories map[uint]uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyTax", order, productCategories)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyTax indicates an expected call of ApplyTax.
+func (mr *MockITaxServiceMockRecorder) ApplyTax(order, productCategories interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyTax", reflect.TypeOf((*MockITaxService)(nil).ApplyTax), order, productCategories)
+}
+
+// CreateTaxClass mocks base method.
+func (m *MockITaxService) CreateTaxClass(request dto.TaxClassRequest) (*models.TaxClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTaxClass", request)
+	ret0, _ := ret[0].(*models.TaxClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTaxClass indicates an expected call of CreateTaxClass.
+func (mr *MockITaxServiceMockRecorder) CreateTaxClass(request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTaxClass", reflect.TypeOf((*MockITaxService)(nil).CreateTaxClass), request)
+}
+
+// DeleteTaxClass mocks base method.
+func (m *MockITaxService) DeleteTaxClass(taxClassID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaxClass", taxClassID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaxClass indicates an expected call of DeleteTaxClass.
+func (mr *MockITaxServiceMockRecorder) DeleteTaxClass(taxClassID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaxClass", reflect.TypeOf((*MockITaxService)(nil).DeleteTaxClass), taxClassID)
+}
+
+// GetTaxClasses mocks base method.
+func (m *MockITaxService) GetTaxClasses() ([]models.TaxClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaxClasses")
+	ret0, _ := ret[0].([]models.TaxClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaxClasses indicates an expected call of GetTaxClasses.
+func (mr *MockITaxServiceMockRecorder) GetTaxClasses() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaxClasses", reflect.TypeOf((*MockITaxService)(nil).GetTaxClasses))
+}
+
+// UpdateTaxClass mocks base method.
+func (m *MockITaxService) UpdateTaxClass(taxClassID uint, request dto.TaxClassRequest) (*models.TaxClass, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTaxClass", taxClassID, request)
+	ret0, _ := ret[0].(*models.TaxClass)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTaxClass indicates an expected call of UpdateTaxClass.
+func (mr *MockITaxServiceMockRecorder) UpdateTaxClass(taxClassID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaxClass", reflect.TypeOf((*MockITaxService)(nil).UpdateTaxClass), taxClassID, request)
+}
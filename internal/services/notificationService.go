@@ -0,0 +1,118 @@
+package services
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+// NotificationPreferences maps a notification type to the channels it is
+// enabled on, e.g. {"price_drop": {"email": false, "in_app": true}}.
+type NotificationPreferences map[string]map[string]bool
+
+type INotificationService interface {
+	Notify(userID uint, notificationType string, title string, body string) error
+	GetNotifications(userID uint, unreadOnly bool, page int, limit int) ([]models.Notification, int64, error)
+	MarkRead(userID uint, notificationID uint) error
+	MarkAllRead(userID uint) error
+	GetPreferences(userID uint) (NotificationPreferences, error)
+	UpdatePreferences(userID uint, preferences NotificationPreferences) (NotificationPreferences, error)
+}
+type NotificationService struct {
+	notificationRepo *repository.NotificationRepository
+	userRepo         *repository.UserRepository
+	mailer           notification.Mailer
+}
+
+func NewNotificationService(notificationRepo *repository.NotificationRepository, userRepo *repository.UserRepository, mailer notification.Mailer) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo, userRepo: userRepo, mailer: mailer}
+}
+
+// Notify delivers a notification on every channel the user has not opted out
+// of for its type. Blocked and deleted users are skipped.
+func (c *NotificationService) Notify(userID uint, notificationType string, title string, body string) error {
+	preferences, err := c.GetPreferences(userID)
+	if err != nil {
+		return err
+	}
+	channels := preferences[notificationType]
+	if channels[models.ChannelInApp] {
+		err := c.notificationRepo.CreateNotification(&models.Notification{
+			UserID: userID,
+			Type:   notificationType,
+			Title:  title,
+			Body:   body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if channels[models.ChannelEmail] {
+		user, err := c.userRepo.GetUserById(userID)
+		if err != nil {
+			return err
+		}
+		if CheckUserStatus(user) != nil {
+			return nil
+		}
+		return c.mailer.Send(user.Email, title, body)
+	}
+	return nil
+}
+func (c *NotificationService) GetNotifications(userID uint, unreadOnly bool, page int, limit int) ([]models.Notification, int64, error) {
+	return c.notificationRepo.GetNotifications(userID, unreadOnly, page, limit)
+}
+func (c *NotificationService) MarkRead(userID uint, notificationID uint) error {
+	return c.notificationRepo.MarkRead(userID, notificationID)
+}
+func (c *NotificationService) MarkAllRead(userID uint) error {
+	return c.notificationRepo.MarkAllRead(userID)
+}
+func (c *NotificationService) GetPreferences(userID uint) (NotificationPreferences, error) {
+	optOuts, err := c.notificationRepo.GetOptOuts(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences := NotificationPreferences{}
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = map[string]bool{}
+		for _, channel := range models.NotificationChannels {
+			preferences[notificationType][channel] = true
+		}
+	}
+	for _, optOut := range optOuts {
+		if channels, ok := preferences[optOut.Type]; ok {
+			channels[optOut.Channel] = false
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences applies the supplied settings; types and channels that
+// are left out keep their current setting.
+func (c *NotificationService) UpdatePreferences(userID uint, preferences NotificationPreferences) (NotificationPreferences, error) {
+	var optIn, optOut []models.NotificationOptOut
+	for notificationType, channels := range preferences {
+		if !slices.Contains(models.NotificationTypes, notificationType) {
+			return nil, errors.New(models.InvalidNotificationPreference)
+		}
+		for channel, enabled := range channels {
+			if !slices.Contains(models.NotificationChannels, channel) {
+				return nil, errors.New(models.InvalidNotificationPreference)
+			}
+			row := models.NotificationOptOut{UserID: userID, Type: notificationType, Channel: channel}
+			if enabled {
+				optIn = append(optIn, row)
+			} else {
+				optOut = append(optOut, row)
+			}
+		}
+	}
+	if err := c.notificationRepo.SetOptOuts(userID, optIn, optOut); err != nil {
+		return nil, err
+	}
+	return c.GetPreferences(userID)
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IOrderService interface {
+	Checkout(userID uint, request dto.CheckoutRequest) (*models.Order, error)
+	GetOrders(userID uint, status string, page int, limit int) ([]models.Order, int64, error)
+	GetOrder(userID uint, orderID uint) (*models.Order, error)
+	AdminGetOrders(status string, page int, limit int) ([]models.Order, int64, error)
+	AdminGetOrder(orderID uint) (*models.Order, error)
+	UpdateOrderStatus(orderID uint, status string) (*models.Order, error)
+	CancelOrder(userID uint, orderID uint, request dto.CancelOrderRequest) (*models.Order, error)
+	GetOrderHistory(userID uint, orderID uint) ([]models.OrderHistory, []models.Refund, error)
+	AdminGetOrderHistory(orderID uint) ([]models.OrderHistory, []models.Refund, error)
+}
+type OrderService struct {
+	orderRepo       *repository.OrderRepository
+	addressRepo     *repository.AddressRepository
+	cartService     ICartService
+	couponService   ICouponService
+	refundService   IRefundService
+	taxService      ITaxService
+	shippingService IShippingService
+	loyaltyService  ILoyaltyService
+}
+
+func NewOrderService(orderRepo *repository.OrderRepository, addressRepo *repository.AddressRepository, cartService ICartService, couponService ICouponService, refundService IRefundService, taxService ITaxService, shippingService IShippingService, loyaltyService ILoyaltyService) *OrderService {
+	return &OrderService{orderRepo: orderRepo, addressRepo: addressRepo, cartService: cartService, couponService: couponService, refundService: refundService, taxService: taxService, shippingService: shippingService, loyaltyService: loyaltyService}
+}
+
+// Checkout turns the user's cart into a pending order. The cart is
+// revalidated first; if any line is unavailable or changed price the order is
+// not placed, so the user always confirms the prices they pay. The same
+// holds for applied coupons that can no longer be used. Shipping is charged
+// by the requested method, or the cheapest one delivering to the address.
+// The loyalty points the order earns on delivery are fixed here too.
+func (c *OrderService) Checkout(userID uint, request dto.CheckoutRequest) (*models.Order, error) {
+	cart, lines, err := c.cartService.GetCart(models.CartOwner{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New(models.CartEmpty)
+	}
+	for _, line := range lines {
+		if !line.Available || len(line.Warnings) > 0 {
+			return nil, errors.New(models.CartNeedsReview)
+		}
+	}
+	pricing, err := c.couponService.PriceCart(userID, cart, lines)
+	if err != nil {
+		return nil, err
+	}
+	for _, coupon := range pricing.Coupons {
+		if coupon.Problem != "" {
+			return nil, errors.New(coupon.Problem)
+		}
+	}
+	shipping, err := c.addressRepo.GetAddress(userID, request.ShippingAddressID)
+	if err != nil {
+		return nil, err
+	}
+	billing := shipping
+	if request.BillingAddressID != 0 && request.BillingAddressID != request.ShippingAddressID {
+		billing, err = c.addressRepo.GetAddress(userID, request.BillingAddressID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	orderNumber, err := generateOrderNumber()
+	if err != nil {
+		return nil, err
+	}
+	order := &models.Order{
+		OrderNumber:     orderNumber,
+		UserID:          userID,
+		Status:          models.OrderPending,
+		ShippingAddress: models.NewOrderAddress(shipping),
+		BillingAddress:  models.NewOrderAddress(billing),
+		ExpiresAt:       time.Now().Add(ReservationTTL),
+	}
+	productCategories := make(map[uint]uint, len(lines))
+	for _, line := range lines {
+		productCategories[line.Product.ID] = line.Product.CategoryID
+		variant := line.Item.Variant
+		item := models.OrderItem{
+			ProductID:   variant.ProductID,
+			VariantID:   variant.ID,
+			ProductName: line.Product.Name,
+			SKU:         variant.SKU,
+			Size:        variant.Size,
+			Colour:      variant.Colour,
+			UnitPrice:   variant.Price,
+			Quantity:    line.Item.Quantity,
+			Discount:    pricing.Result.LineDiscounts[variant.ID],
+		}
+		order.Items = append(order.Items, item)
+	}
+	var redemptions []models.CouponRedemption
+	for _, coupon := range pricing.Coupons {
+		order.CouponCodes = append(order.CouponCodes, coupon.Coupon.Code)
+		redemptions = append(redemptions, models.CouponRedemption{
+			CouponID: coupon.Coupon.ID,
+			Code:     coupon.Coupon.Code,
+			Discount: coupon.Discount,
+		})
+	}
+	if err := c.taxService.ApplyTax(order, productCategories); err != nil {
+		return nil, err
+	}
+	if err := c.loyaltyService.ApplyEarning(order, productCategories); err != nil {
+		return nil, err
+	}
+	if err := c.applyShipping(order, request.ShippingMethodID, lines, pricing.Result.Discount, pricing.Result.FreeShipping); err != nil {
+		return nil, err
+	}
+	calculateOrderTotals(order)
+	if err := c.orderRepo.PlaceOrder(order, cart.ID, redemptions); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// applyShipping sets the shipping method and charge of the order. Orders
+// ship free when no shipping methods are configured.
+func (c *OrderService) applyShipping(order *models.Order, methodID uint, lines []models.CartLine, discount int64, free bool) error {
+	order.Weight = cartWeight(lines)
+	quotes, err := c.shippingService.QuoteShipping(order.ShippingAddress, order.Weight, cartValue(lines)-discount)
+	if err != nil || len(quotes) == 0 {
+		return err
+	}
+	quote := &quotes[0]
+	if methodID != 0 {
+		quote = nil
+		for i := range quotes {
+			if quotes[i].Method.ID == methodID {
+				quote = &quotes[i]
+				break
+			}
+		}
+		if quote == nil {
+			return errors.New(models.ShippingNotAvailable)
+		}
+	}
+	order.ShippingMethodID = &quote.Method.ID
+	order.ShippingMethod = quote.Method.Name
+	order.ShippingTotal = quote.Amount
+	if free {
+		order.ShippingTotal = 0
+	}
+	return nil
+}
+
+// calculateOrderTotals sums the item amounts into the order totals.
+func calculateOrderTotals(order *models.Order) {
+	order.Subtotal, order.DiscountTotal, order.TaxTotal = 0, 0, 0
+	for _, item := range order.Items {
+		order.Subtotal += item.UnitPrice * int64(item.Quantity)
+		order.DiscountTotal += item.Discount
+		order.TaxTotal += item.Tax
+	}
+	order.Total = order.Subtotal - order.DiscountTotal + order.ShippingTotal
+	if !order.TaxInclusive {
+		order.Total += order.TaxTotal
+	}
+}
+
+// generateOrderNumber returns a date prefixed number with a random suffix,
+// e.g. ORD-20240131-48213975. Uniqueness is enforced by the database.
+func generateOrderNumber() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ORD-%s-%08d", time.Now().Format("20060102"), n.Int64()), nil
+}
+func (c *OrderService) GetOrders(userID uint, status string, page int, limit int) ([]models.Order, int64, error) {
+	return c.orderRepo.GetOrders(userID, status, page, limit)
+}
+func (c *OrderService) GetOrder(userID uint, orderID uint) (*models.Order, error) {
+	return c.orderRepo.GetOrder(userID, orderID)
+}
+func (c *OrderService) AdminGetOrders(status string, page int, limit int) ([]models.Order, int64, error) {
+	return c.orderRepo.GetOrders(0, status, page, limit)
+}
+func (c *OrderService) AdminGetOrder(orderID uint) (*models.Order, error) {
+	return c.orderRepo.GetOrderByID(orderID)
+}
+
+// UpdateOrderStatus moves the order to status on behalf of an admin.
+// Cancelling refunds what was paid to the original payment method. A pending
+// order can only be marked paid or confirmed until it expires, as its stock
+// reservations are released then.
+func (c *OrderService) UpdateOrderStatus(orderID uint, status string) (*models.Order, error) {
+	order, err := c.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderPending && (status == models.OrderPaid || status == models.OrderConfirmed) && !orderPayable(order) {
+		return nil, errors.New(models.OrderNotPayable)
+	}
+	if status == models.OrderCancelled {
+		err = c.refundService.CancelOrder(order, models.ActorAdmin, "", models.RefundToOriginal)
+	} else {
+		err = c.orderRepo.TransitionOrder(order, status, models.ActorAdmin, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelOrder cancels the user's order if it has not shipped yet and refunds
+// what was paid to the requested method.
+func (c *OrderService) CancelOrder(userID uint, orderID uint, request dto.CancelOrderRequest) (*models.Order, error) {
+	order, err := c.orderRepo.GetOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanTransition(order.Status, models.OrderCancelled) {
+		return nil, errors.New(models.OrderNotCancellable)
+	}
+	method := request.RefundMethod
+	if method == "" {
+		method = models.RefundToOriginal
+	}
+	err = c.refundService.CancelOrder(order, models.ActorUser, request.Reason, method)
+	if err != nil {
+		if err.Error() == models.InvalidOrderTransition {
+			// shipped in the meantime
+			return nil, errors.New(models.OrderNotCancellable)
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// GetOrderHistory returns the status changes and refunds of the user's order.
+func (c *OrderService) GetOrderHistory(userID uint, orderID uint) ([]models.OrderHistory, []models.Refund, error) {
+	if _, err := c.orderRepo.GetOrder(userID, orderID); err != nil {
+		return nil, nil, err
+	}
+	return c.AdminGetOrderHistory(orderID)
+}
+func (c *OrderService) AdminGetOrderHistory(orderID uint) ([]models.OrderHistory, []models.Refund, error) {
+	history, err := c.orderRepo.GetOrderHistory(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	refunds, err := c.refundService.GetRefunds(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, refunds, nil
+}
+
+// CancelExpiredOrders is run periodically to cancel orders that were not paid
+// within ReservationTTL and release their stock.
+func (c *OrderService) CancelExpiredOrders() error {
+	orders, err := c.orderRepo.GetExpiredOrders(time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		err := c.orderRepo.TransitionOrder(&orders[i], models.OrderCancelled, models.ActorSystem, "payment window expired")
+		if err != nil && err.Error() != models.InvalidOrderTransition {
+			return err
+		}
+	}
+	return nil
+}
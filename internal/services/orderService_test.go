@@ -0,0 +1,185 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/promotion"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type checkoutMocks struct {
+	db       sqlmock.Sqlmock
+	cart     *MockICartService
+	coupon   *MockICouponService
+	tax      *MockITaxService
+	shipping *MockIShippingService
+	loyalty  *MockILoyaltyService
+}
+
+func newCheckoutService(t *testing.T) (*OrderService, checkoutMocks) {
+	ctrl := gomock.NewController(t)
+	db, mock := mocks.NewMockDB(t)
+	m := checkoutMocks{
+		db:       mock,
+		cart:     NewMockICartService(ctrl),
+		coupon:   NewMockICouponService(ctrl),
+		tax:      NewMockITaxService(ctrl),
+		shipping: NewMockIShippingService(ctrl),
+		loyalty:  NewMockILoyaltyService(ctrl),
+	}
+	service := NewOrderService(repository.NewOrderRepository(db), repository.NewAddressRepository(db), m.cart, m.coupon, nil, m.tax, m.shipping, m.loyalty)
+	return service, m
+}
+
+func TestCheckout(t *testing.T) {
+	cart := &models.Cart{ID: 4}
+	line := func(variantID uint, price int64, quantity int) models.CartLine {
+		variant := models.ProductVariant{Model: gorm.Model{ID: variantID}, ProductID: 2, SKU: "SHIRT", Price: price, Stock: 10}
+		return models.CartLine{
+			Item:      models.CartItem{VariantID: variantID, Quantity: quantity, SeenPrice: price, Variant: variant},
+			Product:   models.Product{Model: gorm.Model{ID: 2}, Name: "Shirt", CategoryID: 3, IsActive: true},
+			Available: true,
+		}
+	}
+	lines := []models.CartLine{line(5, 1000, 2), line(6, 500, 1)}
+	request := dto.CheckoutRequest{ShippingAddressID: 9}
+	// prices the cart with 200 off the first line
+	expectPricing := func(m checkoutMocks) {
+		m.coupon.EXPECT().PriceCart(uint(8), cart, lines).Return(&promotion.CartPricing{
+			Result: promotion.Result{Discount: 200, LineDiscounts: map[uint]int64{5: 200}},
+		}, nil)
+	}
+	// loads the address and charges 100 tax per line and 4000 shipping
+	expectPricedOrder := func(m checkoutMocks) {
+		m.db.ExpectQuery(`FROM "addresses"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "state", "country"}).AddRow(9, 8, "Asha", "Kerala", "IN"))
+		m.tax.EXPECT().ApplyTax(gomock.Any(), map[uint]uint{2: 3}).DoAndReturn(func(order *models.Order, _ map[uint]uint) error {
+			for i := range order.Items {
+				order.Items[i].Tax = 100
+			}
+			return nil
+		})
+		m.loyalty.EXPECT().ApplyEarning(gomock.Any(), map[uint]uint{2: 3}).Return(nil)
+		m.shipping.EXPECT().QuoteShipping(gomock.Any(), gomock.Any(), int64(2300)).
+			Return([]models.ShippingQuote{{Method: models.ShippingMethod{Model: gorm.Model{ID: 1}, Name: "Standard"}, Amount: 4000}}, nil)
+	}
+
+	t.Run("empty cart", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, nil, nil)
+		_, err := service.Checkout(8, request)
+		assert.EqualError(t, err, models.CartEmpty)
+	})
+
+	t.Run("cart needs review", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		changed := line(5, 1200, 2)
+		changed.Warnings = []string{"price changed from ₹10.00 to ₹12.00"}
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, []models.CartLine{changed}, nil)
+		_, err := service.Checkout(8, request)
+		assert.EqualError(t, err, models.CartNeedsReview)
+	})
+
+	t.Run("unavailable line", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		gone := line(5, 1000, 2)
+		gone.Available = false
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, []models.CartLine{gone}, nil)
+		_, err := service.Checkout(8, request)
+		assert.EqualError(t, err, models.CartNeedsReview)
+	})
+
+	t.Run("coupon no longer applies", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, lines, nil)
+		m.coupon.EXPECT().PriceCart(uint(8), cart, lines).Return(&promotion.CartPricing{
+			Coupons: []promotion.AppliedCoupon{{Coupon: models.Coupon{Code: "SAVE10"}, Problem: models.CouponNotActive}},
+		}, nil)
+		_, err := service.Checkout(8, request)
+		assert.EqualError(t, err, models.CouponNotActive)
+	})
+
+	t.Run("shipping method not available", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, lines, nil)
+		expectPricing(m)
+		expectPricedOrder(m)
+		_, err := service.Checkout(8, dto.CheckoutRequest{ShippingAddressID: 9, ShippingMethodID: 2})
+		assert.EqualError(t, err, models.ShippingNotAvailable)
+	})
+
+	t.Run("stock cannot be reserved", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, lines, nil)
+		expectPricing(m)
+		expectPricedOrder(m)
+		m.db.ExpectBegin()
+		m.db.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		m.db.ExpectQuery(`UPDATE "product_variants" SET "reserved"=reserved \+ \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		m.db.ExpectQuery(`FROM "product_variants"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sku"}).AddRow(5, "SHIRT-M"))
+		m.db.ExpectExec(`ROLLBACK TO SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		m.db.ExpectRollback()
+		_, err := service.Checkout(8, request)
+		assert.Equal(t, &models.StockError{SKU: "SHIRT-M"}, err)
+	})
+
+	t.Run("places the order with its totals", func(t *testing.T) {
+		service, m := newCheckoutService(t)
+		m.cart.EXPECT().GetCart(models.CartOwner{UserID: 8}).Return(cart, lines, nil)
+		expectPricing(m)
+		expectPricedOrder(m)
+		m.db.ExpectBegin()
+		m.db.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		for _, variantID := range []int{5, 6} {
+			m.db.ExpectQuery(`UPDATE "product_variants" SET "reserved"=reserved \+ \$1`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "reserved"}).AddRow(variantID, 10, 2))
+			m.db.ExpectQuery(`INSERT INTO "stock_reservations"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(variantID + 10))
+			m.db.ExpectQuery(`INSERT INTO "inventory_movements"`).
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		}
+		m.db.ExpectQuery(`INSERT INTO "orders"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(30))
+		m.db.ExpectQuery(`INSERT INTO "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40).AddRow(41))
+		m.db.ExpectQuery(`INSERT INTO "order_histories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		m.db.ExpectExec(`DELETE FROM "cart_coupons" WHERE cart_id = \$1`).WithArgs(uint(4)).WillReturnResult(sqlmock.NewResult(0, 0))
+		m.db.ExpectExec(`DELETE FROM "cart_items" WHERE cart_id = \$1`).WithArgs(uint(4)).WillReturnResult(sqlmock.NewResult(0, 2))
+		m.db.ExpectCommit()
+		order, err := service.Checkout(8, request)
+		assert.NoError(t, err)
+		assert.Equal(t, models.OrderPending, order.Status)
+		assert.Equal(t, "Standard", order.ShippingMethod)
+		assert.Equal(t, int64(2500), order.Subtotal)
+		assert.Equal(t, int64(200), order.DiscountTotal)
+		assert.Equal(t, int64(200), order.TaxTotal)
+		assert.Equal(t, int64(4000), order.ShippingTotal)
+		assert.Equal(t, int64(2500-200+200+4000), order.Total)
+		assert.Equal(t, uint(15), *order.Items[0].ReservationID)
+		assert.Equal(t, uint(16), *order.Items[1].ReservationID)
+	})
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	// the order's reservations were released when it expired, so its stock
+	// may already be sold to someone else
+	for _, status := range []string{models.OrderPaid, models.OrderConfirmed} {
+		t.Run("expired order to "+status, func(t *testing.T) {
+			service, m := newCheckoutService(t)
+			m.db.ExpectQuery(`FROM "orders" WHERE id = \$1`).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status", "expires_at"}).
+					AddRow(30, 8, models.OrderPending, time.Now().Add(-time.Minute)))
+			m.db.ExpectQuery(`FROM "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}))
+			_, err := service.UpdateOrderStatus(30, status)
+			assert.EqualError(t, err, models.OrderNotPayable)
+		})
+	}
+}
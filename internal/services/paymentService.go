@@ -0,0 +1,666 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
+	"github.com/Ansalps/UserEcommerceClean/internal/payment"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+const (
+	// wallet top up limits in minor units
+	minTopUpAmount = 100
+	maxTopUpAmount = 100000_00
+	// default cash on delivery order value limits, overridden by
+	// COD_MIN_ORDER_AMOUNT and COD_MAX_ORDER_AMOUNT
+	defaultCODMinAmount = 0
+	defaultCODMaxAmount = 50000_00
+)
+
+type IPaymentService interface {
+	CreatePayment(userID uint, orderID uint, request dto.PaymentRequest) (*models.Payment, error)
+	TopUpWallet(userID uint, amount int64) (*models.Payment, error)
+	PurchaseGiftCard(userID uint, request dto.GiftCardPurchaseRequest) (*models.Payment, *models.GiftCard, error)
+	HandleWebhook(payload []byte, header http.Header) error
+	DeliverGiftCards() error
+	SimulatePayment(intentID string, succeed bool) error
+	GetCODPincodes() ([]models.CODPincode, error)
+	AddCODPincodes(postalCodes []string) error
+	RemoveCODPincode(postalCode string) error
+}
+type PaymentService struct {
+	paymentRepo  *repository.PaymentRepository
+	orderRepo    *repository.OrderRepository
+	walletRepo   *repository.WalletRepository
+	giftCardRepo *repository.GiftCardRepository
+	loyaltyRepo  *repository.LoyaltyRepository
+	provider     payment.Provider
+	mailer       notification.Mailer
+}
+
+func NewPaymentService(paymentRepo *repository.PaymentRepository, orderRepo *repository.OrderRepository, walletRepo *repository.WalletRepository, giftCardRepo *repository.GiftCardRepository, loyaltyRepo *repository.LoyaltyRepository, provider payment.Provider, mailer notification.Mailer) *PaymentService {
+	return &PaymentService{paymentRepo: paymentRepo, orderRepo: orderRepo, walletRepo: walletRepo, giftCardRepo: giftCardRepo, loyaltyRepo: loyaltyRepo, provider: provider, mailer: mailer}
+}
+
+// CreatePayment pays a pending order with the requested method:
+//   - gateway starts a payment with the provider, optionally covering
+//     WalletAmount from the wallet, GiftCardAmount from the gift card with
+//     GiftCardCode and LoyaltyPoints from the points balance. Calling it
+//     again while the payment is not completed returns the same payment.
+//   - wallet pays the whole order from the wallet at once.
+//   - gift_card pays the whole order from the gift card with GiftCardCode.
+//   - cod confirms the order for cash on delivery.
+func (c *PaymentService) CreatePayment(userID uint, orderID uint, request dto.PaymentRequest) (*models.Payment, error) {
+	order, err := c.orderRepo.GetOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderPending || time.Now().After(order.ExpiresAt) {
+		return nil, errors.New(models.OrderNotPayable)
+	}
+	switch request.Method {
+	case models.PaymentMethodWallet:
+		return c.payWithWallet(order)
+	case models.PaymentMethodCOD:
+		return c.payOnDelivery(order)
+	case models.PaymentMethodGiftCard:
+		card, err := c.usableGiftCard(request.GiftCardCode, order.Total)
+		if err != nil {
+			return nil, err
+		}
+		return c.payWithGiftCard(order, card)
+	}
+	loyaltyAmount := request.LoyaltyPoints * models.LoyaltyPointValue
+	if request.WalletAmount < 0 || request.GiftCardAmount < 0 || loyaltyAmount < 0 ||
+		request.WalletAmount+request.GiftCardAmount+loyaltyAmount >= order.Total {
+		return nil, errors.New(models.InvalidWalletAmount)
+	}
+	if request.LoyaltyPoints > 0 {
+		account, err := c.loyaltyRepo.GetAccount(userID)
+		if err != nil {
+			return nil, err
+		}
+		if account.Balance < request.LoyaltyPoints {
+			return nil, errors.New(models.InsufficientLoyaltyPoints)
+		}
+	}
+	var giftCardID *uint
+	if request.GiftCardAmount > 0 {
+		// checked again when the payment succeeds, like the wallet balance
+		card, err := c.usableGiftCard(request.GiftCardCode, request.GiftCardAmount)
+		if err != nil {
+			return nil, err
+		}
+		giftCardID = &card.ID
+	}
+	if request.WalletAmount > 0 {
+		// checked again when the payment succeeds, as the balance may change
+		wallet, err := c.walletRepo.GetWallet(userID)
+		if err != nil {
+			return nil, err
+		}
+		if wallet.Balance < request.WalletAmount {
+			return nil, errors.New(models.InsufficientWalletBalance)
+		}
+	}
+	amount := order.Total - request.WalletAmount - request.GiftCardAmount - loyaltyAmount
+	existing, err := c.paymentRepo.GetOpenPayment(order.ID)
+	if err == nil && existing.Provider == c.provider.Name() && existing.ClientSecret != "" && existing.Amount == amount && existing.WalletAmount == request.WalletAmount &&
+		existing.GiftCardAmount == request.GiftCardAmount && sameID(existing.GiftCardID, giftCardID) && existing.LoyaltyPoints == request.LoyaltyPoints {
+		return existing, nil
+	}
+	if err != nil && err.Error() != models.PaymentNotFound {
+		return nil, err
+	}
+	return c.createGatewayPayment(&models.Payment{
+		Purpose:        models.PaymentForOrder,
+		OrderID:        order.ID,
+		UserID:         userID,
+		Amount:         amount,
+		WalletAmount:   request.WalletAmount,
+		GiftCardID:     giftCardID,
+		GiftCardAmount: request.GiftCardAmount,
+		LoyaltyPoints:  request.LoyaltyPoints,
+	}, order.OrderNumber)
+}
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// usableGiftCard returns the gift card with the code if it can be redeemed
+// for amount.
+func (c *PaymentService) usableGiftCard(code string, amount int64) (*models.GiftCard, error) {
+	if code == "" {
+		return nil, errors.New(models.GiftCardCodeRequired)
+	}
+	card, err := c.giftCardRepo.GetGiftCardByCode(models.HashGiftCardCode(code))
+	if err != nil {
+		return nil, err
+	}
+	if !card.Usable(time.Now()) {
+		return nil, errors.New(models.GiftCardNotUsable)
+	}
+	if card.Balance < amount {
+		return nil, errors.New(models.InsufficientGiftCardBalance)
+	}
+	return card, nil
+}
+
+// PurchaseGiftCard starts a gateway payment for a gift card. The card is
+// created inactive and without a code; settle activates it and emails the
+// code to the recipient once the payment succeeds.
+func (c *PaymentService) PurchaseGiftCard(userID uint, request dto.GiftCardPurchaseRequest) (*models.Payment, *models.GiftCard, error) {
+	if request.Amount < models.MinGiftCardAmount || request.Amount > models.MaxGiftCardAmount {
+		return nil, nil, errors.New(models.InvalidGiftCardAmount)
+	}
+	p := &models.Payment{
+		Purpose: models.PaymentForGiftCard,
+		UserID:  userID,
+		Amount:  request.Amount,
+	}
+	if err := c.newGatewayPayment(p); err != nil {
+		return nil, nil, err
+	}
+	card := &models.GiftCard{
+		Source:         models.GiftCardPurchased,
+		Amount:         request.Amount,
+		Currency:       models.DefaultCurrency,
+		UserID:         &userID,
+		RecipientName:  request.RecipientName,
+		RecipientEmail: request.RecipientEmail,
+		Message:        request.Message,
+	}
+	err := c.paymentRepo.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		if err := payments.CreatePayment(p); err != nil {
+			return err
+		}
+		card.PaymentID = &p.ID
+		return giftCards.CreateGiftCard(card)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	// each purchase is its own payment, so it gets its own reference
+	if err := c.startGatewayPayment(p, fmt.Sprintf("gift-card-%d", p.ID)); err != nil {
+		return nil, nil, err
+	}
+	return p, card, nil
+}
+
+// TopUpWallet starts a gateway payment that credits the wallet when it succeeds.
+func (c *PaymentService) TopUpWallet(userID uint, amount int64) (*models.Payment, error) {
+	if amount < minTopUpAmount || amount > maxTopUpAmount {
+		return nil, errors.New(models.InvalidTopUpAmount)
+	}
+	p := &models.Payment{
+		Purpose: models.PaymentForWalletTopUp,
+		UserID:  userID,
+		Amount:  amount,
+	}
+	if err := c.newGatewayPayment(p); err != nil {
+		return nil, err
+	}
+	if err := c.paymentRepo.CreatePayment(p); err != nil {
+		return nil, err
+	}
+	// each top up is its own payment, so it gets its own reference
+	if err := c.startGatewayPayment(p, fmt.Sprintf("wallet-topup-%d", p.ID)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+func (c *PaymentService) createGatewayPayment(p *models.Payment, reference string) (*models.Payment, error) {
+	if err := c.newGatewayPayment(p); err != nil {
+		return nil, err
+	}
+	if err := c.paymentRepo.CreatePayment(p); err != nil {
+		return nil, err
+	}
+	if err := c.startGatewayPayment(p, reference); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// newGatewayPayment prepares p to be stored before its provider intent
+// exists. The placeholder ProviderRef keeps the provider reference unique
+// until startGatewayPayment replaces it.
+func (c *PaymentService) newGatewayPayment(p *models.Payment) error {
+	placeholder, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return err
+	}
+	p.Provider = c.provider.Name()
+	p.ProviderRef = "pending-" + placeholder
+	p.Currency = models.DefaultCurrency
+	p.Status = models.PaymentCreated
+	return nil
+}
+
+// startGatewayPayment creates the provider intent for the stored payment p
+// and records it on p. The intent is keyed by the payment ID, so a retried
+// request reuses it while every new payment gets its own. If the provider
+// fails, p is marked failed.
+func (c *PaymentService) startGatewayPayment(p *models.Payment, reference string) error {
+	intent, err := c.provider.CreateIntent(p.Amount, p.Currency, reference, fmt.Sprintf("payment-%d", p.ID))
+	if err != nil {
+		if _, updateErr := c.paymentRepo.UpdatePayment(p, models.PaymentCreated, map[string]interface{}{
+			"status":         models.PaymentFailed,
+			"failure_reason": err.Error(),
+		}); updateErr != nil {
+			fmt.Println("failed to mark payment", p.ID, "failed:", updateErr)
+		}
+		return err
+	}
+	_, err = c.paymentRepo.UpdatePayment(p, models.PaymentCreated, map[string]interface{}{
+		"provider_ref":  intent.ID,
+		"client_secret": intent.ClientSecret,
+	})
+	return err
+}
+
+// payWithWallet debits the order total from the wallet and marks the order
+// paid in one transaction.
+func (c *PaymentService) payWithWallet(order *models.Order) (*models.Payment, error) {
+	var p *models.Payment
+	err := c.paymentRepo.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		var err error
+		p, err = debitWallet(payments, wallets, order, order.Total)
+		if err != nil {
+			return err
+		}
+		return orders.TransitionOrder(order, models.OrderPaid, models.ActorUser, "paid from wallet")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// debitWallet takes amount of the order total from the user's wallet and
+// records it as a succeeded wallet payment.
+func debitWallet(payments *repository.PaymentRepository, wallets *repository.WalletRepository, order *models.Order, amount int64) (*models.Payment, error) {
+	transaction, err := wallets.Post(order.UserID, models.WalletPayment, -amount, models.AccountSales, order.OrderNumber)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p := &models.Payment{
+		Purpose:     models.PaymentForOrder,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Provider:    models.PaymentMethodWallet,
+		ProviderRef: strconv.FormatUint(uint64(transaction.ID), 10),
+		Amount:      amount,
+		Currency:    models.DefaultCurrency,
+		Status:      models.PaymentSucceeded,
+		CapturedAt:  &now,
+	}
+	if err := payments.CreatePayment(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// payWithGiftCard redeems the order total from the gift card and marks the
+// order paid in one transaction.
+func (c *PaymentService) payWithGiftCard(order *models.Order, card *models.GiftCard) (*models.Payment, error) {
+	var p *models.Payment
+	err := c.paymentRepo.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		var err error
+		p, err = redeemGiftCard(payments, giftCards, order, card.ID, order.Total)
+		if err != nil {
+			return err
+		}
+		return orders.TransitionOrder(order, models.OrderPaid, models.ActorUser, "paid with gift card")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// redeemGiftCard takes amount of the order total from the gift card and
+// records it as a succeeded gift card payment.
+func redeemGiftCard(payments *repository.PaymentRepository, giftCards *repository.GiftCardRepository, order *models.Order, cardID uint, amount int64) (*models.Payment, error) {
+	transaction, err := giftCards.Post(cardID, models.GiftCardRedeem, -amount, &order.ID, order.OrderNumber)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p := &models.Payment{
+		Purpose:     models.PaymentForOrder,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Provider:    models.PaymentMethodGiftCard,
+		ProviderRef: strconv.FormatUint(uint64(transaction.ID), 10),
+		Amount:      amount,
+		Currency:    models.DefaultCurrency,
+		Status:      models.PaymentSucceeded,
+		CapturedAt:  &now,
+		GiftCardID:  &cardID,
+	}
+	if err := payments.CreatePayment(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// redeemLoyaltyPoints takes points from the user's balance towards the order
+// total and records them as a succeeded loyalty payment.
+func redeemLoyaltyPoints(payments *repository.PaymentRepository, loyalty *repository.LoyaltyRepository, order *models.Order, points int64) (*models.Payment, error) {
+	transaction, err := loyalty.Debit(order.UserID, models.LoyaltyRedeem, points, &order.ID, order.OrderNumber)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	p := &models.Payment{
+		Purpose:       models.PaymentForOrder,
+		OrderID:       order.ID,
+		UserID:        order.UserID,
+		Provider:      models.PaymentMethodLoyalty,
+		ProviderRef:   strconv.FormatUint(uint64(transaction.ID), 10),
+		Amount:        points * models.LoyaltyPointValue,
+		Currency:      models.DefaultCurrency,
+		Status:        models.PaymentSucceeded,
+		CapturedAt:    &now,
+		LoyaltyPoints: points,
+	}
+	if err := payments.CreatePayment(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// payOnDelivery confirms the order for cash on delivery if its value is
+// within the COD limits and the shipping pincode is serviceable. The payment
+// is marked collected when the order is delivered.
+func (c *PaymentService) payOnDelivery(order *models.Order) (*models.Payment, error) {
+	minAmount, maxAmount := codOrderLimits()
+	if order.Total < minAmount || order.Total > maxAmount {
+		return nil, errors.New(models.CODNotAvailable)
+	}
+	eligible, err := c.paymentRepo.IsCODPincode(order.ShippingAddress.PostalCode)
+	if err != nil {
+		return nil, err
+	}
+	if !eligible {
+		return nil, errors.New(models.CODNotAvailable)
+	}
+	p := &models.Payment{
+		Purpose:     models.PaymentForOrder,
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Provider:    models.PaymentMethodCOD,
+		ProviderRef: order.OrderNumber,
+		Amount:      order.Total,
+		Currency:    models.DefaultCurrency,
+		Status:      models.PaymentCreated,
+	}
+	err = c.paymentRepo.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		if err := payments.CreatePayment(p); err != nil {
+			return err
+		}
+		return orders.TransitionOrder(order, models.OrderConfirmed, models.ActorUser, "cash on delivery")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+func codOrderLimits() (int64, int64) {
+	minAmount, err := strconv.ParseInt(os.Getenv("COD_MIN_ORDER_AMOUNT"), 10, 64)
+	if err != nil {
+		minAmount = defaultCODMinAmount
+	}
+	maxAmount, err := strconv.ParseInt(os.Getenv("COD_MAX_ORDER_AMOUNT"), 10, 64)
+	if err != nil {
+		maxAmount = defaultCODMaxAmount
+	}
+	return minAmount, maxAmount
+}
+
+// HandleWebhook verifies and applies a provider webhook. Each event is
+// applied at most once; redelivered events succeed without changing anything.
+func (c *PaymentService) HandleWebhook(payload []byte, header http.Header) error {
+	event, err := c.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return errors.New(models.InvalidWebhookSignature)
+		}
+		return err
+	}
+	record := &models.PaymentEvent{
+		Provider: c.provider.Name(),
+		EventID:  event.ID,
+		Type:     event.Type,
+		IntentID: event.IntentID,
+	}
+	var p *models.Payment
+	err = c.paymentRepo.ProcessEvent(record, func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		var err error
+		p, err = payments.GetPaymentByRef(c.provider.Name(), event.IntentID)
+		if err != nil {
+			if err.Error() == models.PaymentNotFound {
+				// not created by us, e.g. a payment made on the dashboard
+				return nil
+			}
+			return err
+		}
+		switch event.Type {
+		case payment.EventPaymentAuthorized:
+			return c.capture(payments, orders, wallets, giftCards, loyalty, p)
+		case payment.EventPaymentSucceeded:
+			return c.settle(payments, orders, wallets, giftCards, loyalty, p)
+		case payment.EventPaymentFailed:
+			_, err := payments.UpdatePayment(p, models.PaymentCreated, map[string]interface{}{
+				"status":         models.PaymentFailed,
+				"failure_reason": event.Reason,
+			})
+			return err
+		case payment.EventRefundSucceeded:
+			fields := map[string]interface{}{"refunded_amount": event.Amount}
+			if event.Amount >= p.Amount {
+				fields["status"] = models.PaymentRefunded
+			}
+			_, err := payments.UpdatePayment(p, p.Status, fields)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if p != nil && p.Purpose == models.PaymentForGiftCard && p.Status == models.PaymentSucceeded {
+		// the code is emailed once the activation is committed
+		if card, err := c.giftCardRepo.GetGiftCardByPayment(p.ID); err == nil {
+			c.deliverGiftCard(card)
+		}
+	}
+	return nil
+}
+
+// capture collects an authorized payment if its order can still be paid.
+// The provider call happens inside the event transaction, so a failed capture
+// leaves the event unrecorded and the provider's retry tries again.
+func (c *PaymentService) capture(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository, p *models.Payment) error {
+	if p.Purpose == models.PaymentForOrder {
+		order, err := orders.GetOrderByID(p.OrderID)
+		if err != nil {
+			return err
+		}
+		if !orderPayable(order) {
+			// the authorization is left to lapse
+			_, err := payments.UpdatePayment(p, models.PaymentCreated, map[string]interface{}{
+				"status":         models.PaymentFailed,
+				"failure_reason": models.OrderNotPayable,
+			})
+			return err
+		}
+	}
+	updated, err := payments.UpdatePayment(p, models.PaymentCreated, map[string]interface{}{"status": models.PaymentAuthorized})
+	if err != nil || !updated {
+		return err
+	}
+	if err := c.provider.Capture(p.ProviderRef, p.Amount); err != nil {
+		return err
+	}
+	return c.settle(payments, orders, wallets, giftCards, loyalty, p)
+}
+
+// settle marks a captured payment as succeeded and applies it: a top up is
+// credited to the wallet, a gift card purchase activates the card, and an
+// order payment debits its wallet, gift card and loyalty point shares and
+// marks the order paid. Money received for an order that can no longer be
+// paid, because it expired, was already paid or one of those shares is no
+// longer covered, is refunded.
+func (c *PaymentService) settle(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository, p *models.Payment) error {
+	fields := map[string]interface{}{"status": models.PaymentSucceeded, "captured_at": time.Now()}
+	updated, err := payments.UpdatePayment(p, models.PaymentAuthorized, fields)
+	if err == nil && !updated {
+		// providers that capture automatically skip the authorized state
+		updated, err = payments.UpdatePayment(p, models.PaymentCreated, fields)
+	}
+	if err != nil || !updated {
+		return err
+	}
+	if p.Purpose == models.PaymentForWalletTopUp {
+		_, err := wallets.Post(p.UserID, models.WalletTopUp, p.Amount, models.AccountGateway, p.ProviderRef)
+		return err
+	}
+	if p.Purpose == models.PaymentForGiftCard {
+		return c.activateGiftCard(giftCards, p)
+	}
+	order, err := orders.GetOrderByID(p.OrderID)
+	if err != nil {
+		return err
+	}
+	if orderPayable(order) {
+		err := payments.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+			if p.GiftCardAmount > 0 && p.GiftCardID != nil {
+				if _, err := redeemGiftCard(payments, giftCards, order, *p.GiftCardID, p.GiftCardAmount); err != nil {
+					return err
+				}
+			}
+			if p.WalletAmount > 0 {
+				if _, err := debitWallet(payments, wallets, order, p.WalletAmount); err != nil {
+					return err
+				}
+			}
+			if p.LoyaltyPoints > 0 {
+				if _, err := redeemLoyaltyPoints(payments, loyalty, order, p.LoyaltyPoints); err != nil {
+					return err
+				}
+			}
+			return orders.TransitionOrder(order, models.OrderPaid, models.ActorSystem, "payment "+p.ProviderRef)
+		})
+		if err == nil || !isShareUncovered(err) {
+			return err
+		}
+	}
+	if _, err := c.provider.Refund(p.ProviderRef, p.Amount, fmt.Sprintf("payment-%d-refund", p.ID)); err != nil {
+		return err
+	}
+	_, err = payments.UpdatePayment(p, models.PaymentSucceeded, map[string]interface{}{
+		"status":          models.PaymentRefunded,
+		"refunded_amount": p.Amount,
+		"failure_reason":  models.OrderNotPayable,
+	})
+	return err
+}
+
+// isShareUncovered reports whether err means the wallet, gift card or loyalty
+// point share of a payment can no longer be taken.
+func isShareUncovered(err error) bool {
+	switch err.Error() {
+	case models.InsufficientWalletBalance, models.InsufficientGiftCardBalance, models.GiftCardNotUsable, models.InsufficientLoyaltyPoints:
+		return true
+	}
+	return false
+}
+
+// activateGiftCard generates the code of a purchased gift card and loads its
+// balance. The code is kept on the card as pending and emailed to the
+// recipient after the event transaction commits.
+func (c *PaymentService) activateGiftCard(giftCards *repository.GiftCardRepository, p *models.Payment) error {
+	card, err := giftCards.GetGiftCardByPayment(p.ID)
+	if err != nil {
+		return err
+	}
+	code, err := models.GenerateGiftCardCode()
+	if err != nil {
+		return err
+	}
+	activated, err := giftCards.ActivateGiftCard(card, code, models.HashGiftCardCode(code), models.GiftCardCodeHint(code), time.Now().Add(models.GiftCardValidity))
+	if err != nil || !activated {
+		return err
+	}
+	_, err = giftCards.Post(card.ID, models.GiftCardLoad, card.Amount, nil, p.ProviderRef)
+	return err
+}
+
+// DeliverGiftCards emails the codes of activated gift cards whose email
+// failed before.
+func (c *PaymentService) DeliverGiftCards() error {
+	cards, err := c.giftCardRepo.GetUndeliveredGiftCards()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range cards {
+		errs = append(errs, c.deliverGiftCard(&cards[i]))
+	}
+	return errors.Join(errs...)
+}
+
+// deliverGiftCard emails the pending code of card and then forgets it. A
+// failed email leaves the code pending for DeliverGiftCards.
+func (c *PaymentService) deliverGiftCard(card *models.GiftCard) error {
+	if card.PendingCode == nil {
+		return nil
+	}
+	if err := sendGiftCard(c.mailer, card, *card.PendingCode); err != nil {
+		fmt.Println("failed to email gift card", card.ID, ":", err)
+		return err
+	}
+	return c.giftCardRepo.MarkGiftCardDelivered(card)
+}
+func orderPayable(order *models.Order) bool {
+	return order.Status == models.OrderPending && !time.Now().After(order.ExpiresAt)
+}
+
+// SimulatePayment completes a payment on the fake gateway and feeds the
+// resulting webhook through HandleWebhook. It is only available in
+// development, when the fake gateway is configured.
+func (c *PaymentService) SimulatePayment(intentID string, succeed bool) error {
+	fake, ok := c.provider.(*payment.FakeProvider)
+	if !ok {
+		return errors.New(models.PaymentNotFound)
+	}
+	payload, header, err := fake.Complete(intentID, succeed)
+	if err != nil {
+		return errors.New(models.PaymentNotFound)
+	}
+	return c.HandleWebhook(payload, header)
+}
+func (c *PaymentService) GetCODPincodes() ([]models.CODPincode, error) {
+	return c.paymentRepo.GetCODPincodes()
+}
+func (c *PaymentService) AddCODPincodes(postalCodes []string) error {
+	return c.paymentRepo.AddCODPincodes(postalCodes)
+}
+func (c *PaymentService) RemoveCODPincode(postalCode string) error {
+	return c.paymentRepo.RemoveCODPincode(postalCode)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/payment"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newPaymentService(db *gorm.DB, provider payment.Provider) *PaymentService {
+	return NewPaymentService(repository.NewPaymentRepository(db), repository.NewOrderRepository(db), repository.NewWalletRepository(db),
+		repository.NewGiftCardRepository(db), repository.NewLoyaltyRepository(db), provider, nil)
+}
+
+func TestCODOrderLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		min, max string
+		wantMin  int64
+		wantMax  int64
+	}{
+		{"defaults", "", "", defaultCODMinAmount, defaultCODMaxAmount},
+		{"configured", "50000", "2000000", 50000, 2000000},
+		{"invalid falls back", "abc", "-", defaultCODMinAmount, defaultCODMaxAmount},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("COD_MIN_ORDER_AMOUNT", test.min)
+			t.Setenv("COD_MAX_ORDER_AMOUNT", test.max)
+			minAmount, maxAmount := codOrderLimits()
+			assert.Equal(t, test.wantMin, minAmount)
+			assert.Equal(t, test.wantMax, maxAmount)
+		})
+	}
+}
+
+func TestPayOnDelivery(t *testing.T) {
+	t.Setenv("COD_MIN_ORDER_AMOUNT", "10000")
+	t.Setenv("COD_MAX_ORDER_AMOUNT", "500000")
+	order := func(total int64) *models.Order {
+		return &models.Order{Model: gorm.Model{ID: 30}, UserID: 8, Total: total, ShippingAddress: models.OrderAddress{PostalCode: "682001"}}
+	}
+
+	t.Run("below the minimum", func(t *testing.T) {
+		db, _ := mocks.NewMockDB(t)
+		_, err := newPaymentService(db, nil).payOnDelivery(order(9999))
+		assert.EqualError(t, err, models.CODNotAvailable)
+	})
+
+	t.Run("above the maximum", func(t *testing.T) {
+		db, _ := mocks.NewMockDB(t)
+		_, err := newPaymentService(db, nil).payOnDelivery(order(500001))
+		assert.EqualError(t, err, models.CODNotAvailable)
+	})
+
+	t.Run("pincode not serviceable", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "cod_pincodes" WHERE postal_code = \$1`).
+			WithArgs("682001").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		_, err := newPaymentService(db, nil).payOnDelivery(order(25000))
+		assert.EqualError(t, err, models.CODNotAvailable)
+	})
+}
+
+func TestSettle(t *testing.T) {
+	// a captured gateway intent of 5000 covering an order together with 3000
+	// from the wallet
+	capturedPayment := func(t *testing.T, provider *payment.FakeProvider) *models.Payment {
+		intent, err := provider.CreateIntent(5000, models.DefaultCurrency, "ORD-20240131-48213975", "payment-12")
+		assert.NoError(t, err)
+		_, _, err = provider.Complete(intent.ID, true)
+		assert.NoError(t, err)
+		assert.NoError(t, provider.Capture(intent.ID, 5000))
+		return &models.Payment{
+			Model:        gorm.Model{ID: 12},
+			Purpose:      models.PaymentForOrder,
+			OrderID:      30,
+			UserID:       8,
+			Provider:     provider.Name(),
+			ProviderRef:  intent.ID,
+			Amount:       5000,
+			WalletAmount: 3000,
+			Status:       models.PaymentAuthorized,
+		}
+	}
+	expectSucceeded := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "payments" SET .* WHERE status = \$\d+ AND "payments"."deleted_at" IS NULL AND "id" = \$\d+`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(12, models.PaymentSucceeded))
+		mock.ExpectCommit()
+	}
+	expectOrder := func(mock sqlmock.Sqlmock, status string, expiresAt time.Time) {
+		mock.ExpectQuery(`FROM "orders" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "order_number", "status", "expires_at"}).
+				AddRow(30, 8, "ORD-20240131-48213975", status, expiresAt))
+		mock.ExpectQuery(`FROM "order_items"`).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id"}))
+	}
+	expectRefunded := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE "payments" SET "failure_reason"=\$1,"refunded_amount"=\$2,"status"=\$3`).
+			WithArgs(models.OrderNotPayable, int64(5000), models.PaymentRefunded, sqlmock.AnyArg(), models.PaymentSucceeded, uint(12)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(12, models.PaymentRefunded))
+		mock.ExpectCommit()
+	}
+
+	t.Run("wallet share no longer covered", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		provider := payment.NewFakeProvider("secret")
+		p := capturedPayment(t, provider)
+		expectSucceeded(mock)
+		expectOrder(mock, models.OrderPending, time.Now().Add(time.Hour))
+		mock.ExpectBegin()
+		mock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO "wallets" .* ON CONFLICT DO NOTHING`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`UPDATE "wallets" SET "balance"=balance \+ \$1`).
+			WithArgs(int64(-3000), uint(8), int64(-3000)).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(`ROLLBACK TO SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+		expectRefunded(mock)
+		service := newPaymentService(db, provider)
+		err := service.settle(service.paymentRepo, service.orderRepo, service.walletRepo, service.giftCardRepo, service.loyaltyRepo, p)
+		assert.NoError(t, err)
+		// the gateway share was refunded under the payment's refund key
+		_, err = provider.Refund(p.ProviderRef, 1, "payment-12-refund")
+		assert.NoError(t, err)
+		_, err = provider.Refund(p.ProviderRef, 1, "another-refund")
+		assert.Error(t, err)
+	})
+
+	t.Run("order expired", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		provider := payment.NewFakeProvider("secret")
+		p := capturedPayment(t, provider)
+		expectSucceeded(mock)
+		expectOrder(mock, models.OrderPending, time.Now().Add(-time.Minute))
+		expectRefunded(mock)
+		service := newPaymentService(db, provider)
+		err := service.settle(service.paymentRepo, service.orderRepo, service.walletRepo, service.giftCardRepo, service.loyaltyRepo, p)
+		assert.NoError(t, err)
+	})
+
+	t.Run("already settled", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		provider := payment.NewFakeProvider("secret")
+		p := capturedPayment(t, provider)
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectQuery(`UPDATE "payments"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			mock.ExpectCommit()
+		}
+		service := newPaymentService(db, provider)
+		err := service.settle(service.paymentRepo, service.orderRepo, service.walletRepo, service.giftCardRepo, service.loyaltyRepo, p)
+		assert.NoError(t, err)
+	})
+}
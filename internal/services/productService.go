@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+type IProductService interface {
+	GetProducts(filter models.ProductFilter) ([]models.Product, int64, error)
+	GetProduct(productID uint, includeInactive bool) (*models.Product, error)
+	CreateProduct(request dto.ProductRequest) (*models.Product, error)
+	UpdateProduct(productID uint, request dto.ProductRequest) (*models.Product, error)
+	DeleteProduct(productID uint) error
+	CreateVariant(productID uint, request dto.VariantRequest) (*models.ProductVariant, error)
+	UpdateVariant(productID uint, variantID uint, request dto.VariantRequest) (*models.ProductVariant, error)
+	DeleteVariant(productID uint, variantID uint) error
+}
+type ProductService struct {
+	productRepo   *repository.ProductRepository
+	categoryRepo  *repository.CategoryRepository
+	searchService ISearchService
+}
+
+func NewProductService(productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository, searchService ISearchService) *ProductService {
+	return &ProductService{productRepo: productRepo, categoryRepo: categoryRepo, searchService: searchService}
+}
+
+// GetProducts lists products; filtering by a category includes all of its subcategories.
+func (c *ProductService) GetProducts(filter models.ProductFilter) ([]models.Product, int64, error) {
+	var categoryIDs []uint
+	if filter.CategoryID != 0 {
+		ids, err := c.categoryRepo.GetDescendantIDs(filter.CategoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(ids) == 0 {
+			return []models.Product{}, 0, nil
+		}
+		categoryIDs = ids
+	}
+	return c.productRepo.GetProducts(filter, categoryIDs)
+}
+func (c *ProductService) GetProduct(productID uint, includeInactive bool) (*models.Product, error) {
+	return c.productRepo.GetProduct(productID, includeInactive)
+}
+func (c *ProductService) CreateProduct(request dto.ProductRequest) (*models.Product, error) {
+	if _, err := c.categoryRepo.GetCategory(request.CategoryID); err != nil {
+		return nil, err
+	}
+	for _, variant := range request.Variants {
+		if variant.UnsupportedPrice() != "" {
+			return nil, errors.New(models.UnsupportedCurrency)
+		}
+	}
+	var product models.Product
+	request.ToProduct(&product)
+	product.Slug = productSlug(request)
+	err := c.productRepo.CreateProduct(&product)
+	if err != nil {
+		return nil, err
+	}
+	c.indexProduct(product.ID)
+	return c.productRepo.GetProduct(product.ID, true)
+}
+func (c *ProductService) UpdateProduct(productID uint, request dto.ProductRequest) (*models.Product, error) {
+	product, err := c.productRepo.GetProduct(productID, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.categoryRepo.GetCategory(request.CategoryID); err != nil {
+		return nil, err
+	}
+	request.ToProduct(product)
+	product.Slug = productSlug(request)
+	err = c.productRepo.UpdateProduct(product)
+	if err != nil {
+		return nil, err
+	}
+	c.indexProduct(productID)
+	return c.productRepo.GetProduct(productID, true)
+}
+func (c *ProductService) DeleteProduct(productID uint) error {
+	if err := c.productRepo.DeleteProduct(productID); err != nil {
+		return err
+	}
+	c.indexProduct(productID)
+	return nil
+}
+func (c *ProductService) CreateVariant(productID uint, request dto.VariantRequest) (*models.ProductVariant, error) {
+	if _, err := c.productRepo.GetProduct(productID, true); err != nil {
+		return nil, err
+	}
+	if request.UnsupportedPrice() != "" {
+		return nil, errors.New(models.UnsupportedCurrency)
+	}
+	variant := models.ProductVariant{ProductID: productID}
+	request.ToVariant(&variant)
+	err := c.productRepo.CreateVariant(&variant)
+	if err != nil {
+		return nil, err
+	}
+	c.indexProduct(productID)
+	return &variant, nil
+}
+func (c *ProductService) UpdateVariant(productID uint, variantID uint, request dto.VariantRequest) (*models.ProductVariant, error) {
+	if request.UnsupportedPrice() != "" {
+		return nil, errors.New(models.UnsupportedCurrency)
+	}
+	variant, err := c.productRepo.GetVariant(productID, variantID)
+	if err != nil {
+		return nil, err
+	}
+	request.ToVariant(variant)
+	err = c.productRepo.UpdateVariant(variant)
+	if err != nil {
+		return nil, err
+	}
+	c.indexProduct(productID)
+	return variant, nil
+}
+func (c *ProductService) DeleteVariant(productID uint, variantID uint) error {
+	if err := c.productRepo.DeleteVariant(productID, variantID); err != nil {
+		return err
+	}
+	c.indexProduct(productID)
+	return nil
+}
+
+// indexProduct updates the search index after a catalog change. The change
+// is saved already, so a failure is only logged; the periodic reindex
+// repairs the entry.
+func (c *ProductService) indexProduct(productID uint) {
+	if err := c.searchService.IndexProduct(productID); err != nil {
+		fmt.Println("failed to index product", productID, err)
+	}
+}
+func productSlug(request dto.ProductRequest) string {
+	if request.Slug != "" {
+		return utils.Slugify(request.Slug)
+	}
+	return utils.Slugify(request.Name)
+}
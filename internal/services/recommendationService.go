@@ -0,0 +1,112 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+// Recommendation tuning: the history each kind is computed from, the number
+// of shared orders or viewers a pair needs and how many are kept per product.
+const (
+	boughtTogetherWindow   = 180 * 24 * time.Hour
+	alsoViewedWindow       = 90 * 24 * time.Hour
+	minRecommendationScore = 2
+	recommendationsKept    = 10
+)
+
+type IRecommendationService interface {
+	RecordView(owner models.CartOwner, productID uint) error
+	GetRecentlyViewed(owner models.CartOwner, limit int) ([]models.Product, error)
+	GetRecommendations(productID uint, limit int) (map[string][]models.Product, error)
+	ComputeRecommendations() error
+	DeleteOldGuestViews() error
+}
+type RecommendationService struct {
+	recommendationRepo *repository.RecommendationRepository
+	productRepo        *repository.ProductRepository
+}
+
+func NewRecommendationService(recommendationRepo *repository.RecommendationRepository, productRepo *repository.ProductRepository) *RecommendationService {
+	return &RecommendationService{recommendationRepo: recommendationRepo, productRepo: productRepo}
+}
+
+// RecordView records that owner viewed the product. Guests without a token
+// are not tracked.
+func (c *RecommendationService) RecordView(owner models.CartOwner, productID uint) error {
+	if owner.UserID == 0 && owner.GuestToken == "" {
+		return nil
+	}
+	return c.recommendationRepo.RecordView(owner, productID, time.Now())
+}
+
+// GetRecentlyViewed returns up to limit products last viewed by owner, most
+// recent first. Products that are no longer listed are left out.
+func (c *RecommendationService) GetRecentlyViewed(owner models.CartOwner, limit int) ([]models.Product, error) {
+	if owner.UserID == 0 && owner.GuestToken == "" {
+		return []models.Product{}, nil
+	}
+	ids, err := c.recommendationRepo.GetRecentlyViewed(owner, limit)
+	if err != nil {
+		return nil, err
+	}
+	return c.listedProducts(ids)
+}
+
+// GetRecommendations returns up to limit products of each recommendation
+// kind for the product.
+func (c *RecommendationService) GetRecommendations(productID uint, limit int) (map[string][]models.Product, error) {
+	if _, err := c.productRepo.GetProduct(productID, false); err != nil {
+		return nil, err
+	}
+	recommendations := make(map[string][]models.Product, 2)
+	for _, kind := range []string{models.RecommendationBoughtTogether, models.RecommendationAlsoViewed} {
+		// fetch extra to make up for products that are no longer listed
+		ids, err := c.recommendationRepo.GetRecommendations(productID, kind, recommendationsKept)
+		if err != nil {
+			return nil, err
+		}
+		products, err := c.listedProducts(ids)
+		if err != nil {
+			return nil, err
+		}
+		recommendations[kind] = products[:min(limit, len(products))]
+	}
+	return recommendations, nil
+}
+
+// listedProducts loads the active products with the given IDs in that order.
+func (c *RecommendationService) listedProducts(ids []uint) ([]models.Product, error) {
+	products := make([]models.Product, 0, len(ids))
+	if len(ids) == 0 {
+		return products, nil
+	}
+	byID, err := c.productRepo.GetCatalogProducts(ids, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if product, ok := byID[id]; ok && len(product.Variants) > 0 {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+// ComputeRecommendations is run periodically to recompute the
+// recommendations from recent orders and views.
+func (c *RecommendationService) ComputeRecommendations() error {
+	now := time.Now()
+	return errors.Join(
+		c.recommendationRepo.RebuildBoughtTogether(now.Add(-boughtTogetherWindow), minRecommendationScore, recommendationsKept),
+		c.recommendationRepo.RebuildAlsoViewed(now.Add(-alsoViewedWindow), minRecommendationScore, recommendationsKept),
+	)
+}
+
+// DeleteOldGuestViews is run periodically to remove guest views that are too
+// old to be used for recommendations.
+func (c *RecommendationService) DeleteOldGuestViews() error {
+	return c.recommendationRepo.DeleteGuestViewsBefore(time.Now().Add(-alsoViewedWindow))
+}
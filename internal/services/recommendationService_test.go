@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordView(t *testing.T) {
+	t.Run("guest without a token", func(t *testing.T) {
+		db, _ := mocks.NewMockDB(t)
+		service := NewRecommendationService(repository.NewRecommendationRepository(db), repository.NewProductRepository(db))
+		assert.NoError(t, service.RecordView(models.CartOwner{}, 2))
+	})
+
+	t.Run("user", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "product_views"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		service := NewRecommendationService(repository.NewRecommendationRepository(db), repository.NewProductRepository(db))
+		assert.NoError(t, service.RecordView(models.CartOwner{UserID: 8}, 2))
+	})
+}
+
+func TestComputeRecommendations(t *testing.T) {
+	db, mock := mocks.NewMockDB(t)
+	// a failing bought together rebuild does not hold back the also viewed one
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "product_recommendations"`).
+		WithArgs(models.RecommendationBoughtTogether).
+		WillReturnError(errors.New("lock timeout"))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "product_recommendations"`).
+		WithArgs(models.RecommendationAlsoViewed).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO product_recommendations`).
+		WithArgs(models.RecommendationAlsoViewed, sqlmock.AnyArg(), minRecommendationScore, recommendationsKept).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+	service := NewRecommendationService(repository.NewRecommendationRepository(db), repository.NewProductRepository(db))
+	assert.EqualError(t, service.ComputeRecommendations(), "lock timeout")
+}
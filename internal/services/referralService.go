@@ -0,0 +1,113 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+// default referral rewards in minor units, overridden by
+// REFERRAL_REFERRER_REWARD and REFERRAL_REFEREE_REWARD
+const (
+	defaultReferrerReward = 100_00
+	defaultRefereeReward  = 100_00
+)
+
+type IReferralService interface {
+	NewReferral(code string, referee *models.User) (*models.Referral, error)
+	GetReferrals(userID uint) (string, []models.Referral, error)
+}
+type ReferralService struct {
+	referralRepo *repository.ReferralRepository
+}
+
+func NewReferralService(referralRepo *repository.ReferralRepository) *ReferralService {
+	return &ReferralService{referralRepo: referralRepo}
+}
+
+// NewReferral returns the referral of a user signing up with the referral
+// code, to be stored with the user. Referrals that look like someone
+// referring themselves, by phone number, IP address or device, are rejected
+// rather than failing the signup, so they are visible but never rewarded.
+func (c *ReferralService) NewReferral(code string, referee *models.User) (*models.Referral, error) {
+	referrer, err := c.referralRepo.GetReferrerByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	referrerReward, refereeReward := referralRewards()
+	referral := &models.Referral{
+		ReferrerID:     referrer.ID,
+		Status:         models.ReferralPending,
+		ReferrerReward: referrerReward,
+		RefereeReward:  refereeReward,
+	}
+	reason, err := c.fraudCheck(referrer, referee)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		referral.Status = models.ReferralRejected
+		referral.RejectReason = reason
+	}
+	return referral, nil
+}
+
+// fraudCheck returns why the referral should be rejected, or an empty string.
+func (c *ReferralService) fraudCheck(referrer *models.User, referee *models.User) (string, error) {
+	if referee.Phone == referrer.Phone {
+		return models.ReferralSamePhone, nil
+	}
+	registered, err := c.referralRepo.IsPhoneRegistered(referee.Phone)
+	if err != nil || registered {
+		return models.ReferralPhoneReused, err
+	}
+	checks := []struct {
+		column   string
+		value    string
+		referrer string
+		reason   string
+	}{
+		{"signup_ip", referee.SignupIP, referrer.SignupIP, models.ReferralSameIP},
+		{"signup_device", referee.SignupDevice, referrer.SignupDevice, models.ReferralSameDevice},
+	}
+	for _, check := range checks {
+		if check.value == "" {
+			continue
+		}
+		if check.value == check.referrer {
+			return check.reason, nil
+		}
+		found, err := c.referralRepo.HasReferralFrom(referrer.ID, check.column, check.value)
+		if err != nil || found {
+			return check.reason, err
+		}
+	}
+	return "", nil
+}
+
+// GetReferrals returns the user's referral code and the users they referred.
+func (c *ReferralService) GetReferrals(userID uint) (string, []models.Referral, error) {
+	code, err := c.referralRepo.EnsureReferralCode(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	referrals, err := c.referralRepo.GetReferrals(userID)
+	if err != nil {
+		return "", nil, err
+	}
+	return code, referrals, nil
+}
+func referralRewards() (int64, int64) {
+	referrer, err := strconv.ParseInt(os.Getenv("REFERRAL_REFERRER_REWARD"), 10, 64)
+	if err != nil {
+		referrer = defaultReferrerReward
+	}
+	referee, err := strconv.ParseInt(os.Getenv("REFERRAL_REFEREE_REWARD"), 10, 64)
+	if err != nil {
+		referee = defaultRefereeReward
+	}
+	return referrer, referee
+}
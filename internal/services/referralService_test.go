@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFraudCheck(t *testing.T) {
+	referrer := &models.User{Model: gorm.Model{ID: 7}, Phone: "9000000001", SignupIP: "192.0.2.1", SignupDevice: "device-1"}
+	tests := []struct {
+		name    string
+		referee models.User
+		// rows of the phone, IP and device checks that are run, in order
+		counts []int
+		want   string
+	}{
+		{"clean", models.User{Phone: "9000000002", SignupIP: "192.0.2.2", SignupDevice: "device-2"}, []int{0, 0, 0}, ""},
+		{"same phone", models.User{Phone: "9000000001"}, nil, models.ReferralSamePhone},
+		{"phone reused", models.User{Phone: "9000000002"}, []int{1}, models.ReferralPhoneReused},
+		{"same ip as referrer", models.User{Phone: "9000000002", SignupIP: "192.0.2.1"}, []int{0}, models.ReferralSameIP},
+		{"ip of an earlier referral", models.User{Phone: "9000000002", SignupIP: "192.0.2.3"}, []int{0, 1}, models.ReferralSameIP},
+		{"same device as referrer", models.User{Phone: "9000000002", SignupIP: "192.0.2.2", SignupDevice: "device-1"}, []int{0, 0}, models.ReferralSameDevice},
+		{"device of an earlier referral", models.User{Phone: "9000000002", SignupDevice: "device-3"}, []int{0, 1}, models.ReferralSameDevice},
+		{"no ip or device", models.User{Phone: "9000000002"}, []int{0}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock := mocks.NewMockDB(t)
+			for i, count := range test.counts {
+				query := `JOIN users ON users.id = referrals.user_id`
+				if i == 0 {
+					query = `FROM "users" WHERE phone = \$1 AND purged_at IS NULL`
+				}
+				mock.ExpectQuery(query).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+			}
+			service := NewReferralService(repository.NewReferralRepository(db))
+			reason, err := service.fraudCheck(referrer, &test.referee)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, reason)
+		})
+	}
+}
+
+func TestNewReferral(t *testing.T) {
+	t.Setenv("REFERRAL_REFERRER_REWARD", "5000")
+	t.Setenv("REFERRAL_REFEREE_REWARD", "2500")
+	referrerRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "phone", "signup_ip"}).AddRow(7, "9000000001", "192.0.2.1")
+	}
+
+	t.Run("unknown code", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`referral_code = \$1 AND status = \$2`).
+			WithArgs("NOPE1234", models.StatusActive, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		_, err := NewReferralService(repository.NewReferralRepository(db)).NewReferral(" nope1234 ", &models.User{Phone: "9000000002"})
+		assert.EqualError(t, err, models.InvalidReferralCode)
+	})
+
+	t.Run("pending with the configured rewards", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`referral_code = \$1`).WithArgs("K7QX3MNP", models.StatusActive, sqlmock.AnyArg()).WillReturnRows(referrerRows())
+		mock.ExpectQuery(`phone = \$1`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		referral, err := NewReferralService(repository.NewReferralRepository(db)).NewReferral("k7qx3mnp", &models.User{Phone: "9000000002"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.Referral{ReferrerID: 7, Status: models.ReferralPending, ReferrerReward: 5000, RefereeReward: 2500}, *referral)
+	})
+
+	t.Run("rejected by the fraud checks", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`referral_code = \$1`).WillReturnRows(referrerRows())
+		mock.ExpectQuery(`phone = \$1`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		referral, err := NewReferralService(repository.NewReferralRepository(db)).NewReferral("K7QX3MNP", &models.User{Phone: "9000000002", SignupIP: "192.0.2.1"})
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReferralRejected, referral.Status)
+		assert.Equal(t, models.ReferralSameIP, referral.RejectReason)
+	})
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/payment"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IRefundService interface {
+	CancelOrder(order *models.Order, actor string, note string, method string) error
+	RefundReturn(request *models.ReturnRequest, note string) error
+	GetRefunds(orderID uint) ([]models.Refund, error)
+	RetryPendingRefunds() error
+}
+type RefundService struct {
+	paymentRepo *repository.PaymentRepository
+	returnRepo  *repository.ReturnRepository
+	provider    payment.Provider
+}
+
+func NewRefundService(paymentRepo *repository.PaymentRepository, returnRepo *repository.ReturnRepository, provider payment.Provider) *RefundService {
+	return &RefundService{paymentRepo: paymentRepo, returnRepo: returnRepo, provider: provider}
+}
+
+// CancelOrder cancels the order and refunds everything paid for it to
+// method. Stock, coupons and cash on delivery payments are settled by the
+// order transition in the same transaction.
+func (c *RefundService) CancelOrder(order *models.Order, actor string, note string, method string) error {
+	var pending []models.Refund
+	err := c.paymentRepo.Transaction(func(payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		if err := orders.TransitionOrder(order, models.OrderCancelled, actor, note); err != nil {
+			return err
+		}
+		paid, err := payments.GetOrderPayments(order.ID)
+		if err != nil {
+			return err
+		}
+		var amount int64
+		for _, p := range paid {
+			if p.Status == models.PaymentSucceeded {
+				amount += p.Amount - p.RefundedAmount
+			}
+		}
+		pending, err = c.refund(payments, wallets, giftCards, loyalty, order, paid, amount, method, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	c.submitRefunds(pending)
+	return nil
+}
+
+// RefundReturn refunds a picked up return request and marks it refunded.
+// Once every item of the order has been refunded the order becomes returned.
+func (c *RefundService) RefundReturn(request *models.ReturnRequest, note string) error {
+	var pending []models.Refund
+	err := c.returnRepo.Transaction(func(returns *repository.ReturnRepository, payments *repository.PaymentRepository, orders *repository.OrderRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository) error {
+		if err := returns.TransitionReturn(request, models.ReturnRefunded, models.ActorAdmin, note); err != nil {
+			return err
+		}
+		order, err := orders.GetOrderByID(request.OrderID)
+		if err != nil {
+			return err
+		}
+		paid, err := payments.GetOrderPayments(order.ID)
+		if err != nil {
+			return err
+		}
+		pending, err = c.refund(payments, wallets, giftCards, loyalty, order, paid, request.RefundAmount, request.RefundMethod, &request.ID)
+		if err != nil {
+			return err
+		}
+		returned, err := returns.GetReturnedQuantities(order.ID, []string{models.ReturnRefunded})
+		if err != nil {
+			return err
+		}
+		for _, item := range order.Items {
+			if returned[item.ID] < item.Quantity {
+				return nil
+			}
+		}
+		return orders.TransitionOrder(order, models.OrderReturned, models.ActorSystem, "all items returned")
+	})
+	if err != nil {
+		return err
+	}
+	c.submitRefunds(pending)
+	return nil
+}
+
+func (c *RefundService) GetRefunds(orderID uint) ([]models.Refund, error) {
+	return c.paymentRepo.GetOrderRefunds(orderID)
+}
+
+// RetryPendingRefunds sends the gateway refunds that are still pending,
+// because the provider failed or the process stopped after they were
+// recorded, to the provider again.
+func (c *RefundService) RetryPendingRefunds() error {
+	pending, err := c.paymentRepo.GetPendingRefunds()
+	if err != nil {
+		return err
+	}
+	return c.submitRefunds(pending)
+}
+
+// refund pays amount back from the order's succeeded payments, oldest first.
+// Gateway payments are refunded through the provider and gift card payments
+// to their card when method is original and the card is still usable;
+// loyalty point payments always give the points back with a new expiry, see
+// restoredPoints. Everything else, including cash collected on delivery, is
+// credited to the wallet. Gateway refunds are only recorded as pending and
+// returned; the caller sends them with submitRefunds once the transaction
+// has committed.
+func (c *RefundService) refund(payments *repository.PaymentRepository, wallets *repository.WalletRepository, giftCards *repository.GiftCardRepository, loyalty *repository.LoyaltyRepository, order *models.Order, paid []models.Payment, amount int64, method string, returnRequestID *uint) ([]models.Refund, error) {
+	var pending []models.Refund
+	remaining := amount
+	for i := range paid {
+		p := &paid[i]
+		if remaining == 0 {
+			break
+		}
+		part := min(remaining, p.Amount-p.RefundedAmount)
+		if p.Status != models.PaymentSucceeded || part <= 0 {
+			continue
+		}
+		refundedBefore := p.RefundedAmount
+		if err := payments.AddRefundedAmount(p, part); err != nil {
+			return nil, err
+		}
+		refund := &models.Refund{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			ReturnRequestID: returnRequestID,
+			PaymentID:       p.ID,
+			Method:          models.PaymentMethodWallet,
+			Amount:          part,
+			Status:          models.RefundSucceeded,
+		}
+		if p.Provider == models.PaymentMethodLoyalty {
+			refund.Method = models.PaymentMethodLoyalty
+			if points := restoredPoints(refundedBefore, part); points > 0 {
+				transaction, err := loyalty.Credit(order.UserID, models.LoyaltyRestore, points, &order.ID, order.OrderNumber, time.Now().Add(models.LoyaltyPointsValidity))
+				if err != nil {
+					return nil, err
+				}
+				refund.Reference = strconv.FormatUint(uint64(transaction.ID), 10)
+			}
+		} else if method == models.RefundToOriginal && p.Provider == c.provider.Name() {
+			refund.Method = models.PaymentMethodGateway
+			refund.Status = models.RefundPending
+			refund.Payment = p
+		} else if method == models.RefundToOriginal && p.Provider == models.PaymentMethodGiftCard && c.giftCardUsable(giftCards, p.GiftCardID) {
+			transaction, err := giftCards.Post(*p.GiftCardID, models.GiftCardRefund, part, &order.ID, order.OrderNumber)
+			if err != nil {
+				return nil, err
+			}
+			refund.Method = models.PaymentMethodGiftCard
+			refund.Reference = strconv.FormatUint(uint64(transaction.ID), 10)
+		} else {
+			transaction, err := wallets.Post(order.UserID, models.WalletRefund, part, models.AccountRefunds, order.OrderNumber)
+			if err != nil {
+				return nil, err
+			}
+			refund.Reference = strconv.FormatUint(uint64(transaction.ID), 10)
+		}
+		if err := payments.CreateRefund(refund); err != nil {
+			return nil, err
+		}
+		if refund.Status == models.RefundPending {
+			pending = append(pending, *refund)
+		}
+		remaining -= part
+	}
+	if remaining > 0 {
+		return nil, errors.New(models.RefundExceedsPaid)
+	}
+	return pending, nil
+}
+
+// restoredPoints returns the points given back for refunding part of a
+// loyalty point payment of which refundedBefore was already refunded. The
+// refunded total is rounded up to whole points once per payment, in the
+// customer's favour, so partial refunds never add up to more points than
+// were redeemed.
+func restoredPoints(refundedBefore int64, part int64) int64 {
+	ceil := func(amount int64) int64 {
+		return (amount + models.LoyaltyPointValue - 1) / models.LoyaltyPointValue
+	}
+	return ceil(refundedBefore+part) - ceil(refundedBefore)
+}
+
+// submitRefunds sends pending gateway refunds to the provider. The refund ID
+// is the idempotency key, so a retry never refunds twice. A refund the
+// provider fails stays pending, with the error, for RetryPendingRefunds.
+func (c *RefundService) submitRefunds(refunds []models.Refund) error {
+	var errs []error
+	for i := range refunds {
+		refund := &refunds[i]
+		refundID, err := c.provider.Refund(refund.Payment.ProviderRef, refund.Amount, fmt.Sprintf("refund-%d", refund.ID))
+		fields := map[string]interface{}{"status": models.RefundSucceeded, "reference": refundID, "failure_reason": ""}
+		if err != nil {
+			fmt.Println("refund", refund.ID, "left pending:", err)
+			errs = append(errs, err)
+			fields = map[string]interface{}{"failure_reason": err.Error()}
+		}
+		if err := c.paymentRepo.UpdateRefund(refund, fields); err != nil {
+			fmt.Println("failed to update refund", refund.ID, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// giftCardUsable reports whether the gift card can take a refund. Refunds to
+// an expired or disabled card go to the wallet instead, where they can still
+// be spent.
+func (c *RefundService) giftCardUsable(giftCards *repository.GiftCardRepository, cardID *uint) bool {
+	if cardID == nil {
+		return false
+	}
+	card, err := giftCards.GetGiftCard(*cardID)
+	return err == nil && card.Usable(time.Now())
+}
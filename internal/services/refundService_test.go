@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoredPoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		refundedBefore int64
+		part           int64
+		want           int64
+	}{
+		{"whole points", 0, 500, 5},
+		{"rounded up", 0, 450, 5},
+		{"rest of a rounded up refund", 450, 50, 0},
+		{"rest of a payment refunded in halves", 250, 250, 2},
+		{"tiny refunds", 0, 1, 1},
+		{"tiny refund after a rounded up one", 1, 1, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, restoredPoints(test.refundedBefore, test.part))
+		})
+	}
+
+	// refunding a 10 point payment in three parts gives back 10 points
+	var total, refunded int64
+	for _, part := range []int64{333, 333, 334} {
+		total += restoredPoints(refunded, part)
+		refunded += part
+	}
+	assert.Equal(t, int64(10), total)
+}
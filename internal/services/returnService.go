@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IReturnService interface {
+	CreateReturn(userID uint, orderID uint, request dto.ReturnRequest) (*models.ReturnRequest, error)
+	GetReturns(userID uint, status string, page int, limit int) ([]models.ReturnRequest, int64, error)
+	GetReturn(userID uint, returnID uint) (*models.ReturnRequest, error)
+	AdminGetReturns(status string, page int, limit int) ([]models.ReturnRequest, int64, error)
+	AdminGetReturn(returnID uint) (*models.ReturnRequest, error)
+	UpdateReturnStatus(returnID uint, request dto.ReturnStatusRequest) (*models.ReturnRequest, error)
+}
+type ReturnService struct {
+	returnRepo    *repository.ReturnRepository
+	orderRepo     *repository.OrderRepository
+	refundService IRefundService
+}
+
+func NewReturnService(returnRepo *repository.ReturnRepository, orderRepo *repository.OrderRepository, refundService IRefundService) *ReturnService {
+	return &ReturnService{returnRepo: returnRepo, orderRepo: orderRepo, refundService: refundService}
+}
+
+// CreateReturn requests the return of items of a delivered order within
+// ReturnWindow. The refund amount is the share of each item's paid total for
+// the returned quantity.
+func (c *ReturnService) CreateReturn(userID uint, orderID uint, request dto.ReturnRequest) (*models.ReturnRequest, error) {
+	order, err := c.orderRepo.GetOrder(userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderDelivered || order.DeliveredAt == nil {
+		return nil, errors.New(models.ReturnNotAllowed)
+	}
+	if time.Now().After(order.DeliveredAt.Add(models.ReturnWindow)) {
+		return nil, errors.New(models.ReturnWindowExpired)
+	}
+	items := make(map[uint]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+	returnRequest := &models.ReturnRequest{
+		OrderID:      order.ID,
+		UserID:       userID,
+		Status:       models.ReturnRequested,
+		Reason:       request.Reason,
+		Comment:      request.Comment,
+		RefundMethod: request.RefundMethod,
+	}
+	if returnRequest.RefundMethod == "" {
+		returnRequest.RefundMethod = models.RefundToOriginal
+	}
+	seen := make(map[uint]bool, len(request.Items))
+	for _, requested := range request.Items {
+		item, ok := items[requested.OrderItemID]
+		if !ok || seen[item.ID] || requested.Quantity > item.Quantity {
+			return nil, errors.New(models.InvalidReturnItems)
+		}
+		seen[item.ID] = true
+		amount := item.Total * int64(requested.Quantity) / int64(item.Quantity)
+		returnRequest.Items = append(returnRequest.Items, models.ReturnItem{
+			OrderItemID: item.ID,
+			VariantID:   item.VariantID,
+			Quantity:    requested.Quantity,
+			Amount:      amount,
+		})
+		returnRequest.RefundAmount += amount
+	}
+	if err := c.returnRepo.CreateReturn(returnRequest, order); err != nil {
+		return nil, err
+	}
+	return returnRequest, nil
+}
+func (c *ReturnService) GetReturns(userID uint, status string, page int, limit int) ([]models.ReturnRequest, int64, error) {
+	return c.returnRepo.GetReturns(userID, status, page, limit)
+}
+func (c *ReturnService) GetReturn(userID uint, returnID uint) (*models.ReturnRequest, error) {
+	return c.returnRepo.GetReturn(userID, returnID)
+}
+func (c *ReturnService) AdminGetReturns(status string, page int, limit int) ([]models.ReturnRequest, int64, error) {
+	return c.returnRepo.GetReturns(0, status, page, limit)
+}
+func (c *ReturnService) AdminGetReturn(returnID uint) (*models.ReturnRequest, error) {
+	return c.returnRepo.GetReturnByID(returnID)
+}
+
+// UpdateReturnStatus moves a return request along approval, pickup and
+// refund. Refunding pays the refund amount to the requested method.
+func (c *ReturnService) UpdateReturnStatus(returnID uint, request dto.ReturnStatusRequest) (*models.ReturnRequest, error) {
+	returnRequest, err := c.returnRepo.GetReturnByID(returnID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status == models.ReturnRefunded {
+		err = c.refundService.RefundReturn(returnRequest, request.Note)
+	} else {
+		err = c.returnRepo.TransitionReturn(returnRequest, request.Status, models.ActorAdmin, request.Note)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return returnRequest, nil
+}
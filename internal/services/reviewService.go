@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+type IReviewService interface {
+	GetProductReviews(filter models.ReviewFilter) (*models.Product, []models.Review, int64, error)
+	GetUserReview(userID uint, productID uint) (*models.Review, error)
+	CreateReview(userID uint, productID uint, request dto.ReviewRequest) (*models.Review, error)
+	UpdateReview(userID uint, productID uint, request dto.ReviewRequest) (*models.Review, error)
+	DeleteReview(userID uint, productID uint) error
+	AddReviewImage(userID uint, productID uint, image []byte) (*models.Review, error)
+	RemoveReviewImage(userID uint, productID uint, index int) (*models.Review, error)
+	VoteHelpful(userID uint, reviewID uint) error
+	RemoveVote(userID uint, reviewID uint) error
+	AdminGetReviews(filter models.ReviewFilter) ([]models.Review, int64, error)
+	ModerateReview(reviewID uint, request dto.ReviewModerationRequest) (*models.Review, error)
+	AdminDeleteReview(reviewID uint) error
+}
+type ReviewService struct {
+	reviewRepo  *repository.ReviewRepository
+	productRepo *repository.ProductRepository
+	storage     storage.BlobStorage
+}
+
+func NewReviewService(reviewRepo *repository.ReviewRepository, productRepo *repository.ProductRepository, storage storage.BlobStorage) *ReviewService {
+	return &ReviewService{reviewRepo: reviewRepo, productRepo: productRepo, storage: storage}
+}
+
+// GetProductReviews returns the product with its rating aggregates and one
+// page of its published reviews.
+func (c *ReviewService) GetProductReviews(filter models.ReviewFilter) (*models.Product, []models.Review, int64, error) {
+	product, err := c.productRepo.GetProduct(filter.ProductID, false)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	filter.Status = models.ReviewPublished
+	reviews, total, err := c.reviewRepo.GetReviews(filter)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return product, reviews, total, nil
+}
+func (c *ReviewService) GetUserReview(userID uint, productID uint) (*models.Review, error) {
+	return c.reviewRepo.GetUserReview(userID, productID)
+}
+
+// CreateReview adds the user's review of a product they have received.
+func (c *ReviewService) CreateReview(userID uint, productID uint, request dto.ReviewRequest) (*models.Review, error) {
+	if _, err := c.productRepo.GetProduct(productID, false); err != nil {
+		return nil, err
+	}
+	verified, err := c.reviewRepo.HasDeliveredProduct(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, errors.New(models.NotVerifiedBuyer)
+	}
+	review := &models.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    request.Rating,
+		Title:     request.Title,
+		Body:      request.Body,
+		Status:    models.ReviewPublished,
+	}
+	if err := c.reviewRepo.CreateReview(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+func (c *ReviewService) UpdateReview(userID uint, productID uint, request dto.ReviewRequest) (*models.Review, error) {
+	review, err := c.reviewRepo.GetUserReview(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	review.Rating = request.Rating
+	review.Title = request.Title
+	review.Body = request.Body
+	if err := c.reviewRepo.UpdateReview(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+func (c *ReviewService) DeleteReview(userID uint, productID uint) error {
+	review, err := c.reviewRepo.GetUserReview(userID, productID)
+	if err != nil {
+		return err
+	}
+	return c.deleteReview(review.ID)
+}
+
+// AddReviewImage stores a re-encoded copy of the image, without its
+// metadata, and attaches it to the user's review.
+func (c *ReviewService) AddReviewImage(userID uint, productID uint, image []byte) (*models.Review, error) {
+	review, err := c.reviewRepo.GetUserReview(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(review.Images) >= models.MaxReviewImages {
+		return nil, errors.New(models.ReviewImageLimit)
+	}
+	data, contentType, ext, err := utils.ProcessReviewImage(image, models.ReviewImageMaxSide)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := utils.GenerateRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("reviews/%d/%s.%s", review.ID, suffix, ext)
+	if err := c.storage.Put(key, data, contentType); err != nil {
+		return nil, err
+	}
+	err = c.reviewRepo.AddReviewImage(review.ID, models.ReviewImage{Key: key, URL: c.storage.URL(key)})
+	if err != nil {
+		c.deleteImage(key)
+		return nil, err
+	}
+	return c.reviewRepo.GetReview(review.ID)
+}
+func (c *ReviewService) RemoveReviewImage(userID uint, productID uint, index int) (*models.Review, error) {
+	review, err := c.reviewRepo.GetUserReview(userID, productID)
+	if err != nil {
+		return nil, err
+	}
+	image, err := c.reviewRepo.RemoveReviewImage(review.ID, index)
+	if err != nil {
+		return nil, err
+	}
+	c.deleteImage(image.Key)
+	return c.reviewRepo.GetReview(review.ID)
+}
+
+// VoteHelpful marks a published review of another user as helpful.
+func (c *ReviewService) VoteHelpful(userID uint, reviewID uint) error {
+	review, err := c.reviewRepo.GetReview(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.Status != models.ReviewPublished {
+		return errors.New(models.ReviewNotFound)
+	}
+	if review.UserID == userID {
+		return errors.New(models.CannotVoteOwnReview)
+	}
+	return c.reviewRepo.AddVote(reviewID, userID)
+}
+func (c *ReviewService) RemoveVote(userID uint, reviewID uint) error {
+	return c.reviewRepo.RemoveVote(reviewID, userID)
+}
+func (c *ReviewService) AdminGetReviews(filter models.ReviewFilter) ([]models.Review, int64, error) {
+	return c.reviewRepo.GetReviews(filter)
+}
+func (c *ReviewService) ModerateReview(reviewID uint, request dto.ReviewModerationRequest) (*models.Review, error) {
+	return c.reviewRepo.SetReviewStatus(reviewID, request.Status, request.Note)
+}
+func (c *ReviewService) AdminDeleteReview(reviewID uint) error {
+	return c.deleteReview(reviewID)
+}
+func (c *ReviewService) deleteReview(reviewID uint) error {
+	review, err := c.reviewRepo.DeleteReview(reviewID)
+	if err != nil {
+		return err
+	}
+	for _, image := range review.Images {
+		c.deleteImage(image.Key)
+	}
+	return nil
+}
+
+// deleteImage removes a stored review image. Failures are only logged since
+// a leftover file must not fail the request.
+func (c *ReviewService) deleteImage(key string) {
+	if err := c.storage.Delete(key); err != nil {
+		fmt.Println("failed to delete review image", key, err)
+	}
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReview(t *testing.T) {
+	request := dto.ReviewRequest{Rating: 4, Title: "Good fit", Body: "Fits as expected."}
+
+	t.Run("product not found", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "products"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		service := NewReviewService(repository.NewReviewRepository(db), repository.NewProductRepository(db), nil)
+		_, err := service.CreateReview(8, 2, request)
+		assert.EqualError(t, err, models.ProductNotFound)
+	})
+
+	t.Run("not a verified buyer", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectQuery(`FROM "products"`).WillReturnRows(sqlmock.NewRows([]string{"id", "category_id"}).AddRow(2, 3))
+		mock.ExpectQuery(`FROM "categories"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectQuery(`FROM "product_variants"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`JOIN orders ON orders.id = order_items.order_id`).
+			WithArgs(uint(8), models.OrderDelivered, uint(2), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		service := NewReviewService(repository.NewReviewRepository(db), repository.NewProductRepository(db), nil)
+		_, err := service.CreateReview(8, 2, request)
+		assert.EqualError(t, err, models.NotVerifiedBuyer)
+	})
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/search"
+)
+
+// reindexBatchSize is the number of products loaded at a time by Reindex.
+const reindexBatchSize = 100
+
+type ISearchService interface {
+	Search(query search.Query, categoryID uint) (*search.Result, map[uint]models.Product, error)
+	IndexProduct(productID uint) error
+	Reindex() error
+}
+type SearchService struct {
+	engine       search.Engine
+	productRepo  *repository.ProductRepository
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewSearchService(engine search.Engine, productRepo *repository.ProductRepository, categoryRepo *repository.CategoryRepository) *SearchService {
+	return &SearchService{engine: engine, productRepo: productRepo, categoryRepo: categoryRepo}
+}
+
+// Search runs the query within the category and its subcategories, if
+// categoryID is set, and returns the result with the products of its hits.
+// Hits whose product was removed since it was indexed are dropped.
+func (c *SearchService) Search(query search.Query, categoryID uint) (*search.Result, map[uint]models.Product, error) {
+	if categoryID != 0 {
+		ids, err := c.categoryRepo.GetDescendantIDs(categoryID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(ids) == 0 {
+			return &search.Result{Hits: []search.Hit{}}, map[uint]models.Product{}, nil
+		}
+		query.CategoryIDs = ids
+	}
+	result, err := c.engine.Search(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	productIDs := make([]uint, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		productIDs = append(productIDs, hit.ProductID)
+	}
+	products, err := c.productRepo.GetCatalogProducts(productIDs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	hits := result.Hits[:0]
+	for _, hit := range result.Hits {
+		if _, ok := products[hit.ProductID]; ok {
+			hits = append(hits, hit)
+		}
+	}
+	result.Hits = hits
+	categories, err := c.categoryRepo.GetCategories()
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	for i := range result.Facets.Categories {
+		result.Facets.Categories[i].Name = names[result.Facets.Categories[i].CategoryID]
+	}
+	return result, products, nil
+}
+
+// IndexProduct brings the index entry of the product up to date, removing it
+// when the product was deleted.
+func (c *SearchService) IndexProduct(productID uint) error {
+	product, err := c.productRepo.GetProduct(productID, true)
+	if err != nil {
+		if err.Error() == models.ProductNotFound {
+			return c.engine.Remove(productID)
+		}
+		return err
+	}
+	return c.engine.Index(searchDocument(product))
+}
+
+// Reindex rebuilds the index entries of all products. It runs periodically
+// to pick up changes that are not indexed as they happen and to repair
+// failed updates.
+func (c *SearchService) Reindex() error {
+	ids, err := c.productRepo.GetProductIDs()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for start := 0; start < len(ids); start += reindexBatchSize {
+		batch := ids[start:min(start+reindexBatchSize, len(ids))]
+		products, err := c.productRepo.GetCatalogProducts(batch, true)
+		if err != nil {
+			return err
+		}
+		for _, id := range batch {
+			product, ok := products[id]
+			if !ok {
+				// deleted meanwhile
+				continue
+			}
+			if err := c.engine.Index(searchDocument(&product)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// searchDocument returns the searchable content of the product. Products
+// without variants are not listed in the catalog and so are not searchable
+// either.
+func searchDocument(product *models.Product) search.Document {
+	doc := search.Document{
+		ProductID:   product.ID,
+		CategoryID:  product.CategoryID,
+		Category:    product.Category.Name,
+		Name:        product.Name,
+		Brand:       product.Brand,
+		Description: product.Description,
+		IsActive:    product.IsActive && len(product.Variants) > 0,
+	}
+	for i, variant := range product.Variants {
+		doc.Attributes = append(doc.Attributes, variant.SKU)
+		for _, attribute := range []string{variant.Size, variant.Colour} {
+			if attribute != "" {
+				doc.Attributes = append(doc.Attributes, attribute)
+			}
+		}
+		if i == 0 || variant.Price < doc.MinPrice {
+			doc.MinPrice = variant.Price
+		}
+		doc.MaxPrice = max(doc.MaxPrice, variant.Price)
+	}
+	return doc
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/shipping"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+type IShippingService interface {
+	GetShippingMethods() ([]models.ShippingMethod, error)
+	CreateShippingMethod(request dto.ShippingMethodRequest) (*models.ShippingMethod, error)
+	UpdateShippingMethod(methodID uint, request dto.ShippingMethodRequest) (*models.ShippingMethod, error)
+	DeleteShippingMethod(methodID uint) error
+	GetQuotes(userID uint, addressID uint) ([]models.ShippingQuote, error)
+	QuoteShipping(address models.OrderAddress, weight int, value int64) ([]models.ShippingQuote, error)
+	CreateShipment(orderID uint) (*models.Shipment, error)
+	GetShipmentLabel(shipmentID uint) (*models.Shipment, []byte, error)
+	GetOrderShipments(userID uint, orderID uint) ([]models.Shipment, error)
+	AdminGetOrderShipments(orderID uint) ([]models.Shipment, error)
+	HandleWebhook(payload []byte, header http.Header) error
+	SimulateTracking(trackingNumber string, status string) error
+}
+type ShippingService struct {
+	shippingRepo        *repository.ShippingRepository
+	orderRepo           *repository.OrderRepository
+	addressRepo         *repository.AddressRepository
+	cartService         ICartService
+	couponService       ICouponService
+	notificationService INotificationService
+	provider            shipping.Provider
+	storage             storage.BlobStorage
+	origin              string
+}
+
+func NewShippingService(shippingRepo *repository.ShippingRepository, orderRepo *repository.OrderRepository, addressRepo *repository.AddressRepository, cartService ICartService, couponService ICouponService, notificationService INotificationService, provider shipping.Provider, storage storage.BlobStorage, origin string) *ShippingService {
+	return &ShippingService{
+		shippingRepo:        shippingRepo,
+		orderRepo:           orderRepo,
+		addressRepo:         addressRepo,
+		cartService:         cartService,
+		couponService:       couponService,
+		notificationService: notificationService,
+		provider:            provider,
+		storage:             storage,
+		origin:              origin,
+	}
+}
+func (c *ShippingService) GetShippingMethods() ([]models.ShippingMethod, error) {
+	return c.shippingRepo.GetShippingMethods(false)
+}
+func (c *ShippingService) CreateShippingMethod(request dto.ShippingMethodRequest) (*models.ShippingMethod, error) {
+	method := &models.ShippingMethod{}
+	request.ToShippingMethod(method)
+	if err := c.shippingRepo.SaveShippingMethod(method); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+func (c *ShippingService) UpdateShippingMethod(methodID uint, request dto.ShippingMethodRequest) (*models.ShippingMethod, error) {
+	method, err := c.shippingRepo.GetShippingMethod(methodID)
+	if err != nil {
+		return nil, err
+	}
+	request.ToShippingMethod(method)
+	if err := c.shippingRepo.SaveShippingMethod(method); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
+func (c *ShippingService) DeleteShippingMethod(methodID uint) error {
+	return c.shippingRepo.DeleteShippingMethod(methodID)
+}
+
+// GetQuotes prices the user's cart with every shipping method available for
+// the address. A free shipping coupon on the cart makes every method free.
+func (c *ShippingService) GetQuotes(userID uint, addressID uint) ([]models.ShippingQuote, error) {
+	address, err := c.addressRepo.GetAddress(userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+	cart, lines, err := c.cartService.GetCart(models.CartOwner{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	pricing, err := c.couponService.PriceCart(userID, cart, lines)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := c.QuoteShipping(models.NewOrderAddress(address), cartWeight(lines), cartValue(lines)-pricing.Result.Discount)
+	if err != nil {
+		return nil, err
+	}
+	if pricing.Result.FreeShipping {
+		for i := range quotes {
+			quotes[i].Amount = 0
+		}
+	}
+	return quotes, nil
+}
+
+// QuoteShipping prices a parcel of weight grams for an order of value with
+// every active method that delivers to the address, cheapest first. It
+// returns no quotes and no error when no methods are configured, and
+// ShippingNotAvailable when none of them delivers there.
+func (c *ShippingService) QuoteShipping(address models.OrderAddress, weight int, value int64) ([]models.ShippingQuote, error) {
+	methods, err := c.shippingRepo.GetShippingMethods(true)
+	if err != nil {
+		return nil, err
+	}
+	var quotes []models.ShippingQuote
+	for _, method := range methods {
+		rule := method.MatchRule(address.State, address.PostalCode, weight, value)
+		if rule == nil {
+			continue
+		}
+		quote := models.ShippingQuote{Method: method, Amount: rule.Charge(weight), EstimatedDays: method.EstimatedDays}
+		if method.CarrierRates {
+			carrierQuote, err := c.provider.Quote(c.origin, shippingAddress(address), shipping.Parcel{Weight: weight, Value: value})
+			if err != nil {
+				// the carrier does not serve the address
+				continue
+			}
+			quote.Amount, quote.EstimatedDays = carrierQuote.Amount, carrierQuote.EstimatedDays
+		}
+		quotes = append(quotes, quote)
+	}
+	if len(quotes) == 0 && len(methods) > 0 {
+		return nil, errors.New(models.ShippingNotAvailable)
+	}
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].Amount < quotes[j].Amount })
+	return quotes, nil
+}
+
+// CreateShipment books the parcel of a paid or confirmed order with the
+// carrier and stores its label. An order has one shipment at a time; a new
+// one can only be booked after the previous one came back.
+func (c *ShippingService) CreateShipment(orderID uint) (*models.Shipment, error) {
+	order, err := c.orderRepo.GetOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderPaid && order.Status != models.OrderConfirmed {
+		return nil, errors.New(models.OrderNotShippable)
+	}
+	shipments, err := c.shippingRepo.GetOrderShipments(order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, shipment := range shipments {
+		if shipment.Status != shipping.StatusReturnedToOrigin {
+			return nil, errors.New(models.OrderNotShippable)
+		}
+	}
+	parcel := shipping.Parcel{Weight: order.Weight, Value: order.Total}
+	label, err := c.provider.CreateLabel(order.OrderNumber, c.origin, shippingAddress(order.ShippingAddress), parcel)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := utils.GenerateRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	ext := "pdf"
+	if !strings.HasSuffix(label.ContentType, "/pdf") {
+		ext = "txt"
+	}
+	key := fmt.Sprintf("shipments/%d/%s-%s.%s", order.ID, label.TrackingNumber, suffix, ext)
+	if err := c.storage.Put(key, label.Data, label.ContentType); err != nil {
+		return nil, err
+	}
+	shipment := &models.Shipment{
+		OrderID:        order.ID,
+		UserID:         order.UserID,
+		Carrier:        c.provider.Name(),
+		TrackingNumber: label.TrackingNumber,
+		Status:         shipping.StatusLabelCreated,
+		Weight:         order.Weight,
+		LabelKey:       key,
+		LabelType:      label.ContentType,
+	}
+	if err := c.shippingRepo.CreateShipment(shipment); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+func (c *ShippingService) GetShipmentLabel(shipmentID uint) (*models.Shipment, []byte, error) {
+	shipment, err := c.shippingRepo.GetShipment(shipmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := c.storage.Get(shipment.LabelKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return shipment, data, nil
+}
+func (c *ShippingService) GetOrderShipments(userID uint, orderID uint) ([]models.Shipment, error) {
+	if _, err := c.orderRepo.GetOrder(userID, orderID); err != nil {
+		return nil, err
+	}
+	return c.shippingRepo.GetOrderShipments(orderID)
+}
+func (c *ShippingService) AdminGetOrderShipments(orderID uint) ([]models.Shipment, error) {
+	if _, err := c.orderRepo.GetOrderByID(orderID); err != nil {
+		return nil, err
+	}
+	return c.shippingRepo.GetOrderShipments(orderID)
+}
+
+// shipmentProgress orders the shipment statuses; updates never move a
+// shipment back, so late deliveries of older events only add to its history.
+var shipmentProgress = map[string]int{
+	shipping.StatusLabelCreated:     0,
+	shipping.StatusInTransit:        1,
+	shipping.StatusOutForDelivery:   2,
+	shipping.StatusDelivered:        3,
+	shipping.StatusReturnedToOrigin: 3,
+}
+
+// HandleWebhook verifies and applies a carrier tracking update. The first
+// movement marks the order shipped and delivery marks it delivered, and the
+// user is notified of every status change. Each event is applied at most
+// once; events for unknown parcels are ignored.
+func (c *ShippingService) HandleWebhook(payload []byte, header http.Header) error {
+	event, err := c.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		if errors.Is(err, shipping.ErrInvalidSignature) {
+			return errors.New(models.InvalidShipmentWebhook)
+		}
+		return err
+	}
+	shipment, err := c.shippingRepo.GetShipmentByTracking(c.provider.Name(), event.TrackingNumber)
+	if err != nil {
+		if err.Error() == models.ShipmentNotFound {
+			return nil
+		}
+		return err
+	}
+	if _, ok := shipmentProgress[event.Status]; !ok {
+		return nil
+	}
+	record := &models.ShipmentEvent{
+		ShipmentID:  shipment.ID,
+		Carrier:     c.provider.Name(),
+		EventID:     event.ID,
+		Status:      event.Status,
+		Location:    event.Location,
+		Description: event.Description,
+		OccurredAt:  event.OccurredAt,
+	}
+	var order *models.Order
+	err = c.shippingRepo.ProcessEvent(record, func(shipments *repository.ShippingRepository, orders *repository.OrderRepository) error {
+		shipment, err := shipments.GetShipment(shipment.ID)
+		if err != nil {
+			return err
+		}
+		if shipmentProgress[event.Status] <= shipmentProgress[shipment.Status] {
+			return nil
+		}
+		fields := map[string]interface{}{"status": event.Status}
+		if shipment.ShippedAt == nil && event.Status != shipping.StatusReturnedToOrigin {
+			fields["shipped_at"] = event.OccurredAt
+		}
+		if event.Status == shipping.StatusDelivered {
+			fields["delivered_at"] = event.OccurredAt
+		}
+		if err := shipments.UpdateShipment(shipment, fields); err != nil {
+			return err
+		}
+		order, err = orders.GetOrderByID(shipment.OrderID)
+		if err != nil {
+			return err
+		}
+		return advanceOrder(orders, order, event.Status)
+	})
+	if err != nil || order == nil {
+		return err
+	}
+	title, body := shipmentNotification(order, event)
+	if err := c.notificationService.Notify(order.UserID, models.NotificationShipment, title, body); err != nil {
+		// the update is applied; a failed notification must not make the carrier retry
+		fmt.Println("failed to notify user", order.UserID, "of shipment", shipment.ID, err)
+	}
+	return nil
+}
+
+// advanceOrder moves the order along with its shipment: a parcel on the move
+// means shipped, a delivered one delivered.
+func advanceOrder(orders *repository.OrderRepository, order *models.Order, status string) error {
+	note := "carrier update: " + status
+	if status == shipping.StatusReturnedToOrigin {
+		return nil
+	}
+	if order.Status == models.OrderPaid || order.Status == models.OrderConfirmed {
+		if err := orders.TransitionOrder(order, models.OrderShipped, models.ActorSystem, note); err != nil {
+			return err
+		}
+	}
+	if status == shipping.StatusDelivered && order.Status == models.OrderShipped {
+		return orders.TransitionOrder(order, models.OrderDelivered, models.ActorSystem, note)
+	}
+	return nil
+}
+func shipmentNotification(order *models.Order, event *shipping.Event) (string, string) {
+	switch event.Status {
+	case shipping.StatusInTransit:
+		return "Your order has shipped", fmt.Sprintf("Order %s is on its way.", order.OrderNumber)
+	case shipping.StatusOutForDelivery:
+		return "Out for delivery", fmt.Sprintf("Order %s will be delivered today.", order.OrderNumber)
+	case shipping.StatusDelivered:
+		return "Delivered", fmt.Sprintf("Order %s has been delivered.", order.OrderNumber)
+	}
+	return "Delivery failed", fmt.Sprintf("Order %s could not be delivered and is being returned to us.", order.OrderNumber)
+}
+
+// SimulateTracking moves a parcel of the fake carrier and processes the
+// webhook it sends.
+func (c *ShippingService) SimulateTracking(trackingNumber string, status string) error {
+	fake, ok := c.provider.(*shipping.FakeCarrier)
+	if !ok {
+		return errors.New(models.ShipmentNotFound)
+	}
+	payload, header, err := fake.Advance(trackingNumber, status)
+	if err != nil {
+		return err
+	}
+	return c.HandleWebhook(payload, header)
+}
+func shippingAddress(address models.OrderAddress) shipping.Address {
+	return shipping.Address{
+		Name:       address.Name,
+		Phone:      address.Phone,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		State:      address.State,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
+}
+
+// cartWeight returns the shipping weight of the cart in grams.
+func cartWeight(lines []models.CartLine) int {
+	weight := 0
+	for _, line := range lines {
+		weight += line.Item.Variant.Weight * line.Item.Quantity
+	}
+	return weight
+}
+func cartValue(lines []models.CartLine) int64 {
+	var value int64
+	for _, line := range lines {
+		value += line.Item.Variant.Price * int64(line.Item.Quantity)
+	}
+	return value
+}
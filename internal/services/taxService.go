@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/tax"
+)
+
+type ITaxService interface {
+	GetTaxClasses() ([]models.TaxClass, error)
+	CreateTaxClass(request dto.TaxClassRequest) (*models.TaxClass, error)
+	UpdateTaxClass(taxClassID uint, request dto.TaxClassRequest) (*models.TaxClass, error)
+	DeleteTaxClass(taxClassID uint) error
+	ApplyTax(order *models.Order, productCategories map[uint]uint) error
+}
+type TaxService struct {
+	taxRepo      *repository.TaxRepository
+	categoryRepo *repository.CategoryRepository
+	config       tax.Config
+}
+
+func NewTaxService(taxRepo *repository.TaxRepository, categoryRepo *repository.CategoryRepository, config tax.Config) *TaxService {
+	return &TaxService{taxRepo: taxRepo, categoryRepo: categoryRepo, config: config}
+}
+func (c *TaxService) GetTaxClasses() ([]models.TaxClass, error) {
+	return c.taxRepo.GetTaxClasses()
+}
+func (c *TaxService) CreateTaxClass(request dto.TaxClassRequest) (*models.TaxClass, error) {
+	taxClass := &models.TaxClass{}
+	if err := c.saveTaxClass(taxClass, request); err != nil {
+		return nil, err
+	}
+	return taxClass, nil
+}
+func (c *TaxService) UpdateTaxClass(taxClassID uint, request dto.TaxClassRequest) (*models.TaxClass, error) {
+	taxClass, err := c.taxRepo.GetTaxClass(taxClassID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.saveTaxClass(taxClass, request); err != nil {
+		return nil, err
+	}
+	return taxClass, nil
+}
+func (c *TaxService) saveTaxClass(taxClass *models.TaxClass, request dto.TaxClassRequest) error {
+	slabs := request.ToTaxSlabs()
+	if slabs[0].MinUnitValue != 0 {
+		return errors.New(models.InvalidTaxSlabs)
+	}
+	for i := 1; i < len(slabs); i++ {
+		if slabs[i].MinUnitValue == slabs[i-1].MinUnitValue {
+			return errors.New(models.InvalidTaxSlabs)
+		}
+	}
+	taxClass.Name = request.Name
+	taxClass.Description = request.Description
+	taxClass.Slabs = slabs
+	taxClass.IsDefault = request.IsDefault
+	return c.taxRepo.SaveTaxClass(taxClass)
+}
+func (c *TaxService) DeleteTaxClass(taxClassID uint) error {
+	return c.taxRepo.DeleteTaxClass(taxClassID)
+}
+
+// ApplyTax calculates the tax of the order items, whose product categories
+// are given by productCategories, for delivery to the shipping address. It
+// sets the tax fields and totals of the items and marks the order tax
+// inclusive in that pricing mode.
+func (c *TaxService) ApplyTax(order *models.Order, productCategories map[uint]uint) error {
+	categories, err := c.categoryRepo.GetCategories()
+	if err != nil {
+		return err
+	}
+	taxClasses, err := c.taxRepo.GetTaxClasses()
+	if err != nil {
+		return err
+	}
+	lines := make([]tax.Line, 0, len(order.Items))
+	for i, item := range order.Items {
+		taxClass := resolveTaxClass(productCategories[item.ProductID], categories, taxClasses)
+		line := tax.Line{Key: uint(i), Amount: item.UnitPrice*int64(item.Quantity) - item.Discount, Quantity: item.Quantity}
+		if taxClass != nil {
+			line.Slabs = taxClass.Slabs
+		}
+		lines = append(lines, line)
+	}
+	result := tax.Calculate(c.config, order.ShippingAddress.State, lines)
+	order.TaxInclusive = c.config.Mode == tax.Inclusive
+	for i := range order.Items {
+		item := &order.Items[i]
+		lineTax := result.Lines[uint(i)]
+		item.TaxRate = lineTax.Rate
+		item.CGST, item.SGST, item.IGST = lineTax.CGST, lineTax.SGST, lineTax.IGST
+		item.Tax = lineTax.Tax()
+		item.Total = lines[i].Amount
+		if !order.TaxInclusive {
+			item.Total += item.Tax
+		}
+	}
+	return nil
+}
+
+// resolveTaxClass returns the class of the category or of its nearest
+// ancestor that has one, falling back to the default class.
+func resolveTaxClass(categoryID uint, categories []models.Category, taxClasses []models.TaxClass) *models.TaxClass {
+	byID := make(map[uint]*models.Category, len(categories))
+	for i := range categories {
+		byID[categories[i].ID] = &categories[i]
+	}
+	classes := make(map[uint]*models.TaxClass, len(taxClasses))
+	var fallback *models.TaxClass
+	for i := range taxClasses {
+		classes[taxClasses[i].ID] = &taxClasses[i]
+		if taxClasses[i].IsDefault {
+			fallback = &taxClasses[i]
+		}
+	}
+	// the depth limit guards against a cycle in bad data
+	for depth := 0; depth < len(categories); depth++ {
+		category, ok := byID[categoryID]
+		if !ok {
+			break
+		}
+		if category.TaxClassID != nil {
+			if taxClass, ok := classes[*category.TaxClassID]; ok {
+				return taxClass
+			}
+		}
+		if category.ParentID == nil {
+			break
+		}
+		categoryID = *category.ParentID
+	}
+	return fallback
+}
@@ -2,44 +2,99 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
 
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
 	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/notification"
 	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+	"github.com/Ansalps/UserEcommerceClean/internal/utils"
+)
+
+const (
+	magicLinkExpiry = 15 * time.Minute
+	// maximum number of magic links a single user can request per magicLinkExpiry
+	magicLinkLimit = 3
+	// how long a deleted account can still be recovered by support before it is anonymized
+	accountDeletionGracePeriod = 30 * 24 * time.Hour
 )
 
 type IUserService interface {
-	UserSignUp(user *models.User) error
-	UserLogin(user *models.UserLogin) (*models.User, error)
-	ComparePassword(providedUser models.UserLogin, user models.User) bool
+	UserSignUp(user *models.User, referralCode string) error
+	UserLogin(user *dto.LoginRequest) (*models.User, error)
+	ComparePassword(providedUser dto.LoginRequest, user models.User) bool
 	GetProfile(userID string) (*models.User, error)
-	UpdateProfile(userID uint, user models.UserUpdate) error
+	UpdateProfile(userID uint, user dto.UpdateProfileRequest) error
+	PatchProfile(userID uint, version uint, user dto.UpdateProfileRequest, fields []string) (*models.User, error)
+	RequestMagicLink(email string) error
+	MagicLinkLogin(token string) (*models.User, error)
+	RefreshToken(refreshToken string) (*models.User, error)
+	DeleteAccount(userID uint, password string) error
+	ExportUserData(userID uint) (map[string]interface{}, error)
+	UpdateAvatar(userID uint, image []byte) (*models.User, error)
 }
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo        *repository.UserRepository
+	referralService IReferralService
+	currencyService ICurrencyService
+	mailer          notification.Mailer
+	storage         storage.BlobStorage
+}
+
+func NewUserService(userRepo *repository.UserRepository, referralService IReferralService, currencyService ICurrencyService, mailer notification.Mailer, storage storage.BlobStorage) *UserService {
+	return &UserService{userRepo: userRepo, referralService: referralService, currencyService: currencyService, mailer: mailer, storage: storage}
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+// CheckUserStatus returns an error when the user is not allowed to log in.
+func CheckUserStatus(user *models.User) error {
+	switch user.Status {
+	case models.StatusBlocked:
+		return errors.New(models.UserBlocked)
+	case models.StatusDeleted:
+		return errors.New(models.UserDeleted)
+	}
+	return nil
 }
-func (c *UserService) UserSignUp(user *models.User) error {
+
+// UserSignUp creates the user with a referral code of their own. A user
+// signing up with someone else's referralCode is recorded as their referral.
+func (c *UserService) UserSignUp(user *models.User, referralCode string) error {
 	existingUser, _ := c.userRepo.GetUserByEmail(user.Email)
 	if existingUser != nil {
 		return errors.New(models.UserAlreadyExists)
 	}
-	err := c.userRepo.UserSignUp(*user)
+	var referral *models.Referral
+	if referralCode != "" {
+		var err error
+		referral, err = c.referralService.NewReferral(referralCode, user)
+		if err != nil {
+			return err
+		}
+	}
+	code, err := models.GenerateReferralCode()
+	if err != nil {
+		return err
+	}
+	user.ReferralCode = &code
+	err = c.userRepo.UserSignUp(user, referral)
 	if err != nil {
 		return err
 	}
 	return nil
 }
-func (c *UserService) UserLogin(user *models.UserLogin) (*models.User, error) {
+func (c *UserService) UserLogin(user *dto.LoginRequest) (*models.User, error) {
 	User, err := c.userRepo.GetUserByEmail(user.Email)
 	if err != nil {
 		return nil, err
 	}
 	return User, nil
 }
-func (c *UserService) ComparePassword(providedUser models.UserLogin, user models.User) bool {
+func (c *UserService) ComparePassword(providedUser dto.LoginRequest, user models.User) bool {
 	//c.userRepo.ComparePassword(providedUser.Password, user.Password)
 	check := false
 	if providedUser.Password == user.Password {
@@ -54,7 +109,10 @@ func (c *UserService) GetProfile(userID string) (*models.User, error) {
 	}
 	return user, nil
 }
-func (c *UserService) UpdateProfile(userID uint, user models.UserUpdate) error {
+func (c *UserService) UpdateProfile(userID uint, user dto.UpdateProfileRequest) error {
+	if err := c.currencyService.CheckSupported(user.Currency); err != nil {
+		return err
+	}
 	User, err := c.userRepo.GetUserById(userID)
 	if err != nil {
 		return err
@@ -62,9 +120,183 @@ func (c *UserService) UpdateProfile(userID uint, user models.UserUpdate) error {
 	User.FirstName = user.FirstName
 	User.LastName = user.LastName
 	User.Phone = user.Phone
+	User.Currency = user.Currency
 	err = c.userRepo.UpdateProfile(User)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+func (c *UserService) PatchProfile(userID uint, version uint, user dto.UpdateProfileRequest, fields []string) (*models.User, error) {
+	if slices.Contains(fields, "currency") {
+		if err := c.currencyService.CheckSupported(user.Currency); err != nil {
+			return nil, err
+		}
+	}
+	patched := models.User{FirstName: user.FirstName, LastName: user.LastName, Phone: user.Phone, Currency: user.Currency}
+	err := c.userRepo.PatchProfile(userID, version, &patched, fields)
+	if err != nil {
+		return nil, err
+	}
+	return c.userRepo.GetUserById(userID)
+}
+
+// RequestMagicLink emails a single-use login link to the user. Unknown emails,
+// inactive users and users over the request limit are silently ignored so the
+// response never reveals whether an account exists.
+func (c *UserService) RequestMagicLink(email string) error {
+	user, err := c.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+	if CheckUserStatus(user) != nil {
+		return nil
+	}
+	count, err := c.userRepo.CountMagicLinksSince(user.ID, time.Now().Add(-magicLinkExpiry))
+	if err != nil {
+		return err
+	}
+	if count >= magicLinkLimit {
+		fmt.Println("magic link limit reached for user", user.ID)
+		return nil
+	}
+	jti, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return err
+	}
+	token, err := utils.GenerateMagicLinkToken(user.ID, jti, magicLinkExpiry)
+	if err != nil {
+		return err
+	}
+	err = c.userRepo.CreateMagicLink(&models.MagicLink{
+		UserID:    user.ID,
+		JTI:       jti,
+		ExpiresAt: time.Now().Add(magicLinkExpiry),
+	})
+	if err != nil {
+		return err
+	}
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5000"
+	}
+	link := fmt.Sprintf("%s/login/magic?token=%s", baseURL, url.QueryEscape(token))
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to log in. It expires in %d minutes and can only be used once.\n\n%s\n", user.FirstName, int(magicLinkExpiry.Minutes()), link)
+	// a failed send is not reported either, as only registered emails get here
+	if err := c.mailer.Send(user.Email, "Your login link", body); err != nil {
+		fmt.Println("failed to send magic link to user", user.ID, err)
+	}
+	return nil
+}
+func (c *UserService) MagicLinkLogin(token string) (*models.User, error) {
+	userID, jti, err := utils.ParseMagicLinkToken(token)
+	if err != nil {
+		return nil, errors.New(models.InvalidMagicLink)
+	}
+	err = c.userRepo.ConsumeMagicLink(userID, jti)
+	if err != nil {
+		return nil, err
+	}
+	user, err := c.userRepo.GetUserById(userID)
+	if err != nil {
+		return nil, errors.New(models.InvalidMagicLink)
+	}
+	if err := CheckUserStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RefreshToken returns the user a refresh token was issued for, so a new
+// access token can be issued, as long as the user may still log in.
+func (c *UserService) RefreshToken(refreshToken string) (*models.User, error) {
+	userID, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, errors.New(models.InvalidRefreshToken)
+	}
+	user, err := c.userRepo.GetUserById(userID)
+	if err != nil {
+		return nil, errors.New(models.InvalidRefreshToken)
+	}
+	if err := CheckUserStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+func (c *UserService) DeleteAccount(userID uint, password string) error {
+	user, err := c.userRepo.GetUserById(userID)
+	if err != nil {
+		return err
+	}
+	if !c.ComparePassword(dto.LoginRequest{Email: user.Email, Password: password}, *user) {
+		return errors.New(models.IncorrectPassword)
+	}
+	return c.userRepo.ScheduleAccountDeletion(user)
+}
+func (c *UserService) ExportUserData(userID uint) (map[string]interface{}, error) {
+	return c.userRepo.ExportUserData(userID)
+}
+
+// PurgeDeletedAccounts anonymizes accounts whose deletion grace period has ended.
+func (c *UserService) PurgeDeletedAccounts() error {
+	users, err := c.userRepo.GetUsersDueForPurge(time.Now().Add(-accountDeletionGracePeriod))
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		c.deleteAvatar(users[i].AvatarKey)
+		if err := c.userRepo.PurgeUser(&users[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateAvatar stores thumbnails of the uploaded image and replaces the
+// user's previous avatar.
+func (c *UserService) UpdateAvatar(userID uint, image []byte) (*models.User, error) {
+	user, err := c.userRepo.GetUserById(userID)
+	if err != nil {
+		return nil, err
+	}
+	thumbnails, contentType, ext, err := utils.ProcessAvatar(image)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := utils.GenerateRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("avatars/%d/%s", userID, suffix)
+	urls := make(map[string]string, len(thumbnails))
+	for name, data := range thumbnails {
+		objectKey := fmt.Sprintf("%s/%s.%s", key, name, ext)
+		if err := c.storage.Put(objectKey, data, contentType); err != nil {
+			c.deleteAvatar(key)
+			return nil, err
+		}
+		urls[name] = c.storage.URL(objectKey)
+	}
+	if err := c.userRepo.UpdateAvatar(userID, key, urls); err != nil {
+		c.deleteAvatar(key)
+		return nil, err
+	}
+	c.deleteAvatar(user.AvatarKey)
+	return c.userRepo.GetUserById(userID)
+}
+
+// deleteAvatar removes all thumbnails stored under key. Failures are only
+// logged since a leftover file must not fail the request.
+func (c *UserService) deleteAvatar(key string) {
+	if key == "" {
+		return
+	}
+	for name := range utils.AvatarSizes {
+		for _, ext := range []string{"jpg", "png"} {
+			if err := c.storage.Delete(fmt.Sprintf("%s/%s.%s", key, name, ext)); err != nil {
+				fmt.Println("failed to delete avatar", key, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/Ansalps/UserEcommerceClean/internal/storage"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRequestMagicLink(t *testing.T) {
+	// the response must not tell registered emails from unknown ones
+	t.Run("unknown email", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "users" WHERE email=\$1`).WillReturnError(gorm.ErrRecordNotFound)
+		err := NewUserService(repository.NewUserRepository(db), nil, nil, mocks.NewMockMailer(gomock.NewController(t)), nil).RequestMagicLink("nobody@example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("mail not sent", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "users" WHERE email=\$1`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "status"}).AddRow(8, "asha@example.com", models.StatusActive))
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "magic_links"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "magic_links"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		mailer := mocks.NewMockMailer(gomock.NewController(t))
+		mailer.EXPECT().Send("asha@example.com", "Your login link", gomock.Any()).Return(errors.New("connection refused"))
+		err := NewUserService(repository.NewUserRepository(db), nil, nil, mailer, nil).RequestMagicLink("asha@example.com")
+		assert.NoError(t, err)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	userRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "email", "password"}).AddRow(8, "asha@example.com", "secret123")
+	}
+
+	t.Run("wrong password", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "users" WHERE id=\$1`).WillReturnRows(userRows())
+		err := NewUserService(repository.NewUserRepository(db), nil, nil, nil, nil).DeleteAccount(8, "wrong")
+		assert.EqualError(t, err, models.IncorrectPassword)
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`FROM "users" WHERE id=\$1`).WillReturnRows(userRows())
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "users" SET "status"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "users" SET "deleted_at"=\$1`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		err := NewUserService(repository.NewUserRepository(db), nil, nil, nil, nil).DeleteAccount(8, "secret123")
+		assert.NoError(t, err)
+	})
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	// expectPurge expects the purge of one user, failing it with err if set
+	expectPurge := func(mock sqlmock.Sqlmock, err error) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "magic_links"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		if err != nil {
+			mock.ExpectExec(`DELETE FROM "addresses"`).WillReturnError(err)
+			mock.ExpectRollback()
+			return
+		}
+		for _, table := range []string{"addresses", "carts", "wishlist_items", "product_views", "notifications", "notification_opt_outs"} {
+			mock.ExpectExec(`DELETE FROM "` + table + `"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec(`UPDATE "reviews" SET "helpful_count"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`DELETE FROM "review_votes"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`UPDATE "users" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+
+	t.Run("purges users and their avatars", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		root := t.TempDir()
+		avatar := filepath.Join(root, "avatars", "8-abc", "small.jpg")
+		assert.NoError(t, os.MkdirAll(filepath.Dir(avatar), 0o755))
+		assert.NoError(t, os.WriteFile(avatar, []byte("jpeg"), 0o644))
+		mock.ExpectQuery(`deleted_at IS NOT NULL AND deleted_at < \$1 AND purged_at IS NULL`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "avatar_key"}).AddRow(8, "avatars/8-abc").AddRow(9, ""))
+		expectPurge(mock, nil)
+		expectPurge(mock, nil)
+		service := NewUserService(repository.NewUserRepository(db), nil, nil, nil, storage.NewLocalStorage(root, ""))
+		assert.NoError(t, service.PurgeDeletedAccounts())
+		assert.NoFileExists(t, avatar)
+	})
+
+	t.Run("stops at a failing purge", func(t *testing.T) {
+		db, mock := mocks.NewMockDB(t)
+		mock.ExpectQuery(`purged_at IS NULL`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8).AddRow(9))
+		expectPurge(mock, errors.New("connection reset"))
+		service := NewUserService(repository.NewUserRepository(db), nil, nil, nil, nil)
+		assert.EqualError(t, service.PurgeDeletedAccounts(), "connection reset")
+	})
+}
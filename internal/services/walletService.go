@@ -0,0 +1,34 @@
+package services
+
+import (
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+type IWalletService interface {
+	GetWallet(userID uint, page int, limit int) (*models.Wallet, []models.WalletTransaction, int64, error)
+	Audit() ([]models.WalletAuditIssue, error)
+}
+type WalletService struct {
+	walletRepo *repository.WalletRepository
+}
+
+func NewWalletService(walletRepo *repository.WalletRepository) *WalletService {
+	return &WalletService{walletRepo: walletRepo}
+}
+
+// GetWallet returns the balance and one page of transactions, newest first.
+func (c *WalletService) GetWallet(userID uint, page int, limit int) (*models.Wallet, []models.WalletTransaction, int64, error) {
+	wallet, err := c.walletRepo.GetWallet(userID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	transactions, total, err := c.walletRepo.GetTransactions(userID, page, limit)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return wallet, transactions, total, nil
+}
+func (c *WalletService) Audit() ([]models.WalletAuditIssue, error) {
+	return c.walletRepo.Audit()
+}
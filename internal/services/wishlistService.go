@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/currency"
+	"github.com/Ansalps/UserEcommerceClean/internal/dto"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+)
+
+const wishlistCheckBatchSize = 500
+
+type IWishlistService interface {
+	GetWishlist(userID uint) ([]models.WishlistItem, map[uint]models.Product, error)
+	AddItem(userID uint, request dto.WishlistRequest) error
+	RemoveItem(userID uint, variantID uint) error
+}
+type WishlistService struct {
+	wishlistRepo        *repository.WishlistRepository
+	productRepo         *repository.ProductRepository
+	notificationService INotificationService
+}
+
+func NewWishlistService(wishlistRepo *repository.WishlistRepository, productRepo *repository.ProductRepository, notificationService INotificationService) *WishlistService {
+	return &WishlistService{wishlistRepo: wishlistRepo, productRepo: productRepo, notificationService: notificationService}
+}
+
+// GetWishlist returns the user's items and their products keyed by product ID.
+func (c *WishlistService) GetWishlist(userID uint) ([]models.WishlistItem, map[uint]models.Product, error) {
+	items, err := c.wishlistRepo.GetWishlist(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	products, err := c.productRepo.GetProductsByIDs(wishlistProductIDs(items))
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, products, nil
+}
+func (c *WishlistService) AddItem(userID uint, request dto.WishlistRequest) error {
+	variant, err := c.productRepo.GetVariantByID(request.VariantID)
+	if err != nil {
+		return err
+	}
+	if _, err := c.productRepo.GetProduct(variant.ProductID, false); err != nil {
+		if err.Error() == models.ProductNotFound {
+			return errors.New(models.ProductUnavailable)
+		}
+		return err
+	}
+	items, err := c.wishlistRepo.GetWishlist(userID)
+	if err != nil {
+		return err
+	}
+	saved := false
+	for _, item := range items {
+		if item.VariantID == variant.ID {
+			saved = true
+		}
+	}
+	if !saved && len(items) >= models.MaxWishlistItems {
+		return errors.New(models.WishlistLimitReached)
+	}
+	return c.wishlistRepo.AddItem(&models.WishlistItem{
+		UserID:     userID,
+		VariantID:  variant.ID,
+		SavedPrice: variant.Price,
+		InStock:    variant.Available() > 0,
+	})
+}
+func (c *WishlistService) RemoveItem(userID uint, variantID uint) error {
+	return c.wishlistRepo.RemoveItem(userID, variantID)
+}
+
+// CheckWishlists is run periodically. It notifies users when a saved variant
+// is back in stock or its price dropped below the saved price. A price drop is
+// reported once per new lowest price, and only while the variant is in stock.
+func (c *WishlistService) CheckWishlists() error {
+	return c.wishlistRepo.CheckItemsInBatches(wishlistCheckBatchSize, func(items []models.WishlistItem) error {
+		products, err := c.productRepo.GetProductsByIDs(wishlistProductIDs(items))
+		if err != nil {
+			return err
+		}
+		// keep going so one failing user does not hold back the rest
+		var errs []error
+		for i := range items {
+			errs = append(errs, c.checkItem(&items[i], products))
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// checkItem stores what was seen for one item and then sends the
+// notifications due. The state is stored first, so a failing channel such as
+// the mailer cannot make the next check notify the user again.
+func (c *WishlistService) checkItem(item *models.WishlistItem, products map[uint]models.Product) error {
+	variant := item.Variant
+	name := fmt.Sprintf("%s (%s)", products[variant.ProductID].Name, variant.SKU)
+	inStock := variant.Available() > 0
+	changed := item.InStock != inStock
+	var notifications []models.Notification
+	if inStock && !item.InStock {
+		notifications = append(notifications, models.Notification{
+			Type:  models.NotificationBackInStock,
+			Title: "Back in stock: " + name,
+			Body:  fmt.Sprintf("%s from your wishlist is back in stock at %s.", name, currency.FormatAmount(variant.Price, currency.Base)),
+		})
+	}
+	lowest := item.SavedPrice
+	if item.NotifiedPrice != 0 && item.NotifiedPrice < lowest {
+		lowest = item.NotifiedPrice
+	}
+	if inStock && variant.Price < lowest {
+		notifications = append(notifications, models.Notification{
+			Type:  models.NotificationPriceDrop,
+			Title: "Price drop: " + name,
+			Body:  fmt.Sprintf("%s from your wishlist is now %s, down from %s.", name, currency.FormatAmount(variant.Price, currency.Base), currency.FormatAmount(item.SavedPrice, currency.Base)),
+		})
+		item.NotifiedPrice = variant.Price
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	item.InStock = inStock
+	if err := c.wishlistRepo.UpdateCheckState(item); err != nil {
+		return err
+	}
+	var errs []error
+	for _, n := range notifications {
+		errs = append(errs, c.notificationService.Notify(item.UserID, n.Type, n.Title, n.Body))
+	}
+	return errors.Join(errs...)
+}
+func wishlistProductIDs(items []models.WishlistItem) []uint {
+	productIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.Variant.ProductID)
+	}
+	return productIDs
+}
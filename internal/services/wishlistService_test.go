@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/mocks"
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/Ansalps/UserEcommerceClean/internal/repository"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCheckWishlistItem(t *testing.T) {
+	products := map[uint]models.Product{2: {Name: "Shirt"}}
+	variant := func(price int64, stock int) models.ProductVariant {
+		return models.ProductVariant{Model: gorm.Model{ID: 5}, ProductID: 2, SKU: "SHIRT-M", Price: price, Stock: stock}
+	}
+	tests := []struct {
+		name      string
+		item      models.WishlistItem
+		notify    []string
+		notifyErr error
+		// stored state, nil if nothing is stored
+		stored *models.WishlistItem
+	}{
+		{
+			name:   "unchanged",
+			item:   models.WishlistItem{InStock: true, SavedPrice: 1000, Variant: variant(1000, 3)},
+			notify: nil,
+		},
+		{
+			name:   "back in stock",
+			item:   models.WishlistItem{InStock: false, SavedPrice: 1000, Variant: variant(1000, 3)},
+			notify: []string{models.NotificationBackInStock},
+			stored: &models.WishlistItem{InStock: true},
+		},
+		{
+			name:   "back in stock and cheaper",
+			item:   models.WishlistItem{InStock: false, SavedPrice: 1000, Variant: variant(800, 3)},
+			notify: []string{models.NotificationBackInStock, models.NotificationPriceDrop},
+			stored: &models.WishlistItem{InStock: true, NotifiedPrice: 800},
+		},
+		{
+			name:   "price drop already notified",
+			item:   models.WishlistItem{InStock: true, SavedPrice: 1000, NotifiedPrice: 800, Variant: variant(800, 3)},
+			notify: nil,
+		},
+		{
+			name:   "price drop while out of stock",
+			item:   models.WishlistItem{InStock: true, SavedPrice: 1000, Variant: variant(800, 0)},
+			notify: nil,
+			stored: &models.WishlistItem{InStock: false},
+		},
+		{
+			name:      "failing notification is not repeated",
+			item:      models.WishlistItem{InStock: true, SavedPrice: 1000, Variant: variant(900, 3)},
+			notify:    []string{models.NotificationPriceDrop},
+			notifyErr: errors.New("smtp: connection refused"),
+			stored:    &models.WishlistItem{InStock: true, NotifiedPrice: 900},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			db, mock := mocks.NewMockDB(t)
+			notifications := NewMockINotificationService(ctrl)
+			service := NewWishlistService(repository.NewWishlistRepository(db), repository.NewProductRepository(db), notifications)
+
+			item := test.item
+			item.ID = 9
+			item.UserID = 4
+			if test.stored != nil {
+				mock.ExpectBegin()
+				mock.ExpectExec(`UPDATE "wishlist_items" SET "in_stock"=\$1,"notified_price"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+					WithArgs(test.stored.InStock, test.stored.NotifiedPrice, sqlmock.AnyArg(), uint(9)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			}
+			for _, notificationType := range test.notify {
+				notifications.EXPECT().Notify(uint(4), notificationType, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(uint, string, string, string) error {
+						assert.NoError(t, mock.ExpectationsWereMet(), "state stored before notifying")
+						return test.notifyErr
+					})
+			}
+			err := service.checkItem(&item, products)
+			if test.notifyErr != nil {
+				assert.ErrorIs(t, err, test.notifyErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package shipping
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const fakeSignatureHeader = "X-Fake-Signature"
+
+// FakeCarrier is an in-memory carrier for tests and local development.
+// Prices depend only on the weight, tracking numbers are sequential, and
+// parcels move along with Advance, which returns the signed webhook the
+// carrier would have sent.
+type FakeCarrier struct {
+	webhookSecret string
+	mu            sync.Mutex
+	parcels       map[string]string
+	sequence      int
+	now           func() time.Time
+}
+
+func NewFakeCarrier(webhookSecret string) *FakeCarrier {
+	return &FakeCarrier{webhookSecret: webhookSecret, parcels: map[string]string{}, now: time.Now}
+}
+
+func (f *FakeCarrier) Name() string {
+	return "fake"
+}
+
+// Quote charges 40.00 for the first 500 g and 20.00 for every further 500 g
+// started, delivering in three days.
+func (f *FakeCarrier) Quote(origin string, to Address, parcel Parcel) (*Quote, error) {
+	if to.PostalCode == "" {
+		return nil, errors.New("fake: destination pincode is required")
+	}
+	extra := max((parcel.Weight-1)/500, 0)
+	return &Quote{Amount: 4000 + int64(extra)*2000, EstimatedDays: 3}, nil
+}
+func (f *FakeCarrier) CreateLabel(reference string, origin string, to Address, parcel Parcel) (*Label, error) {
+	if to.PostalCode == "" {
+		return nil, errors.New("fake: destination pincode is required")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sequence++
+	tracking := fmt.Sprintf("FAKE%08d", f.sequence)
+	f.parcels[tracking] = StatusLabelCreated
+	label := fmt.Sprintf("FAKE CARRIER\nTracking: %s\nReference: %s\nFrom: %s\nTo: %s, %s, %s %s\nWeight: %d g\n",
+		tracking, reference, origin, to.Name, to.Line1, to.City, to.PostalCode, parcel.Weight)
+	return &Label{TrackingNumber: tracking, Data: []byte(label), ContentType: "text/plain"}, nil
+}
+
+// Advance moves the parcel to status and returns the webhook payload and
+// headers reporting it.
+func (f *FakeCarrier) Advance(trackingNumber string, status string) ([]byte, http.Header, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current, ok := f.parcels[trackingNumber]
+	if !ok {
+		return nil, nil, errors.New("fake: unknown tracking number")
+	}
+	if current == StatusDelivered || current == StatusReturnedToOrigin {
+		return nil, nil, errors.New("fake: parcel has already arrived")
+	}
+	switch status {
+	case StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusReturnedToOrigin:
+	default:
+		return nil, nil, errors.New("fake: invalid status")
+	}
+	f.parcels[trackingNumber] = status
+	f.sequence++
+	event := Event{
+		ID:             fmt.Sprintf("fake_sevt_%06d", f.sequence),
+		TrackingNumber: trackingNumber,
+		Status:         status,
+		Location:       "Fake hub",
+		OccurredAt:     f.now().UTC().Truncate(time.Second),
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := http.Header{}
+	header.Set(fakeSignatureHeader, f.sign(payload))
+	return payload, header, nil
+}
+
+// VerifyWebhook expects the hex HMAC-SHA256 of the payload in X-Fake-Signature.
+func (f *FakeCarrier) VerifyWebhook(payload []byte, header http.Header) (*Event, error) {
+	if !hmac.Equal([]byte(header.Get(fakeSignatureHeader)), []byte(f.sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+func (f *FakeCarrier) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(f.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
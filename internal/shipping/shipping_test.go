@@ -0,0 +1,72 @@
+package shipping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeCarrierQuote(t *testing.T) {
+	carrier := NewFakeCarrier("secret")
+	to := Address{PostalCode: "682001"}
+	tests := []struct {
+		weight int
+		amount int64
+	}{
+		{0, 4000},
+		{500, 4000},
+		{501, 6000},
+		{1500, 8000},
+	}
+	for _, test := range tests {
+		quote, err := carrier.Quote("560001", to, Parcel{Weight: test.weight})
+		assert.NoError(t, err)
+		assert.Equal(t, test.amount, quote.Amount, "%d g", test.weight)
+	}
+	_, err := carrier.Quote("560001", Address{}, Parcel{Weight: 100})
+	assert.Error(t, err)
+}
+
+func TestFakeCarrierTracking(t *testing.T) {
+	now := time.Date(2024, time.May, 2, 10, 0, 0, 0, time.UTC)
+	carrier := NewFakeCarrier("secret")
+	carrier.now = func() time.Time { return now }
+	label, err := carrier.CreateLabel("ORD-1", "560001", Address{Name: "Asha", PostalCode: "682001"}, Parcel{Weight: 700})
+	assert.NoError(t, err)
+	assert.Equal(t, "FAKE00000001", label.TrackingNumber)
+	assert.Contains(t, string(label.Data), "ORD-1")
+
+	payload, header, err := carrier.Advance(label.TrackingNumber, StatusInTransit)
+	assert.NoError(t, err)
+	event, err := carrier.VerifyWebhook(payload, header)
+	assert.NoError(t, err)
+	assert.Equal(t, Event{ID: "fake_sevt_000002", TrackingNumber: label.TrackingNumber, Status: StatusInTransit, Location: "Fake hub", OccurredAt: now}, *event)
+
+	_, err = carrier.VerifyWebhook(append(payload, ' '), header)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	_, _, err = carrier.Advance(label.TrackingNumber, StatusDelivered)
+	assert.NoError(t, err)
+	_, _, err = carrier.Advance(label.TrackingNumber, StatusInTransit)
+	assert.Error(t, err, "delivered parcels do not move")
+	_, _, err = carrier.Advance("FAKE99999999", StatusInTransit)
+	assert.Error(t, err)
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	t.Setenv("SHIPPING_PROVIDER", "")
+	t.Setenv("SHIPPING_WEBHOOK_SECRET", "secret")
+	_, err := NewProviderFromEnv()
+	assert.Error(t, err, "no provider")
+
+	t.Setenv("SHIPPING_PROVIDER", "fake")
+	t.Setenv("SHIPPING_WEBHOOK_SECRET", "")
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err, "no webhook secret")
+
+	t.Setenv("SHIPPING_WEBHOOK_SECRET", "secret")
+	provider, err := NewProviderFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "fake", provider.Name())
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// BlobStorage stores binary objects under slash separated keys and exposes
+// them at public URLs.
+type BlobStorage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+	URL(key string) string
+}
+
+// NewStorageFromEnv returns an S3 compatible storage when S3_BUCKET is set and
+// a LocalStorage under UPLOAD_DIR (default "uploads") otherwise.
+func NewStorageFromEnv() BlobStorage {
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    bucket,
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	}
+	dir := os.Getenv("UPLOAD_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+	baseURL := os.Getenv("APP_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5000"
+	}
+	return NewLocalStorage(dir, strings.TrimSuffix(baseURL, "/")+LocalMediaPath)
+}
@@ -0,0 +1,181 @@
+// Package tax calculates GST on order lines. It has no database access: the
+// caller resolves the tax class of every line.
+package tax
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+// Pricing modes.
+const (
+	// prices include tax, which is worked out of them
+	Inclusive = "inclusive"
+	// tax is charged on top of prices
+	Exclusive = "exclusive"
+)
+
+// Rounding rules, applied to every tax component of every line.
+const (
+	RoundHalfUp   = "half_up"
+	RoundHalfEven = "half_even"
+	RoundUp       = "up"
+	RoundDown     = "down"
+)
+
+// Config holds the store wide tax settings.
+type Config struct {
+	Mode     string
+	Rounding string
+	// state the seller is registered in; supplies to other states are inter-state
+	SellerState string
+}
+
+// ConfigFromEnv reads TAX_PRICING_MODE (default inclusive), TAX_ROUNDING
+// (default half_up) and SELLER_STATE.
+func ConfigFromEnv() Config {
+	config := Config{Mode: Inclusive, Rounding: RoundHalfUp, SellerState: os.Getenv("SELLER_STATE")}
+	if os.Getenv("TAX_PRICING_MODE") == Exclusive {
+		config.Mode = Exclusive
+	}
+	switch rounding := os.Getenv("TAX_ROUNDING"); rounding {
+	case RoundHalfEven, RoundUp, RoundDown:
+		config.Rounding = rounding
+	}
+	return config
+}
+
+// Line is one order line. Key identifies it in the result. Amount is the
+// price of all units after discounts, in minor units, and includes tax in
+// inclusive mode. A line without slabs is not taxed.
+type Line struct {
+	Key      uint
+	Amount   int64
+	Quantity int
+	Slabs    []models.TaxSlab
+}
+
+// LineTax is the tax of one line. Intra-state supplies pay CGST and SGST,
+// each at half the rate; inter-state supplies pay IGST at the full rate.
+type LineTax struct {
+	Rate    int
+	Taxable int64
+	CGST    int64
+	SGST    int64
+	IGST    int64
+}
+
+func (t LineTax) Tax() int64 {
+	return t.CGST + t.SGST + t.IGST
+}
+
+// Result is the tax of all lines. Lines is keyed by Line.Key.
+type Result struct {
+	Lines      map[uint]LineTax
+	InterState bool
+	Taxable    int64
+	CGST       int64
+	SGST       int64
+	IGST       int64
+}
+
+func (r Result) Tax() int64 {
+	return r.CGST + r.SGST + r.IGST
+}
+
+// InterState reports whether goods shipped to state are an inter-state
+// supply. A missing state is treated as the seller's own.
+func InterState(sellerState, state string) bool {
+	sellerState, state = strings.TrimSpace(sellerState), strings.TrimSpace(state)
+	return sellerState != "" && state != "" && !strings.EqualFold(sellerState, state)
+}
+
+// Calculate returns the tax of the lines shipped to state.
+func Calculate(config Config, state string, lines []Line) Result {
+	result := Result{Lines: make(map[uint]LineTax, len(lines)), InterState: InterState(config.SellerState, state)}
+	for _, line := range lines {
+		lineTax := calculateLine(config, result.InterState, line)
+		result.Lines[line.Key] = lineTax
+		result.Taxable += lineTax.Taxable
+		result.CGST += lineTax.CGST
+		result.SGST += lineTax.SGST
+		result.IGST += lineTax.IGST
+	}
+	return result
+}
+func calculateLine(config Config, interState bool, line Line) LineTax {
+	amount := max(line.Amount, 0)
+	quantity := int64(max(line.Quantity, 1))
+	if config.Mode == Exclusive {
+		lineTax := LineTax{Rate: Rate(line.Slabs, amount/quantity), Taxable: amount}
+		rate := int64(lineTax.Rate)
+		if interState {
+			lineTax.IGST = round(amount*rate, models.MaxTaxRate, config.Rounding)
+		} else {
+			lineTax.CGST = round(amount*rate, 2*models.MaxTaxRate, config.Rounding)
+			lineTax.SGST = lineTax.CGST
+		}
+		return lineTax
+	}
+	// the customer pays exactly the price, so the tax is what is left of it
+	// after the rounded taxable value, split with the odd paisa going to CGST
+	rate := inclusiveRate(line.Slabs, amount/quantity)
+	lineTax := LineTax{Rate: rate}
+	lineTax.Taxable = round(amount*models.MaxTaxRate, int64(models.MaxTaxRate+rate), config.Rounding)
+	tax := amount - lineTax.Taxable
+	if interState {
+		lineTax.IGST = tax
+	} else {
+		lineTax.CGST = tax - tax/2
+		lineTax.SGST = tax / 2
+	}
+	return lineTax
+}
+
+// Rate returns the rate of the slab a unit of taxable value falls in.
+func Rate(slabs []models.TaxSlab, unitValue int64) int {
+	rate := 0
+	for _, slab := range slabs {
+		if unitValue >= slab.MinUnitValue {
+			rate = slab.Rate
+		}
+	}
+	return rate
+}
+
+// inclusiveRate returns the rate of the highest slab whose threshold the
+// unit price is above once that slab's tax is taken out of it.
+func inclusiveRate(slabs []models.TaxSlab, unitPrice int64) int {
+	for i := len(slabs) - 1; i >= 0; i-- {
+		taxable := unitPrice * models.MaxTaxRate / int64(models.MaxTaxRate+slabs[i].Rate)
+		if taxable >= slabs[i].MinUnitValue {
+			return slabs[i].Rate
+		}
+	}
+	return 0
+}
+
+// round divides the non-negative numerator by denominator using rule.
+func round(numerator, denominator int64, rule string) int64 {
+	quotient, remainder := numerator/denominator, numerator%denominator
+	if remainder == 0 {
+		return quotient
+	}
+	switch rule {
+	case RoundDown:
+		return quotient
+	case RoundUp:
+		return quotient + 1
+	case RoundHalfEven:
+		if 2*remainder > denominator || (2*remainder == denominator && quotient%2 == 1) {
+			return quotient + 1
+		}
+		return quotient
+	}
+	if 2*remainder >= denominator {
+		return quotient + 1
+	}
+	return quotient
+}
@@ -0,0 +1,132 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	standard = []models.TaxSlab{{MinUnitValue: 0, Rate: 1800}}
+	// 5% below 1000 a piece, 12% from 1000
+	apparel = []models.TaxSlab{{MinUnitValue: 0, Rate: 500}, {MinUnitValue: 100000, Rate: 1200}}
+)
+
+func TestCalculateLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		rounding string
+		state    string
+		line     Line
+		want     LineTax
+	}{
+		{"exclusive intra-state", Exclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 99999, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 99999, CGST: 9000, SGST: 9000}},
+		{"exclusive inter-state", Exclusive, RoundHalfUp, "Tamil Nadu",
+			Line{Amount: 99999, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 99999, IGST: 18000}},
+		{"exclusive round down", Exclusive, RoundDown, "Tamil Nadu",
+			Line{Amount: 99999, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 99999, IGST: 17999}},
+		// 25 * 18% = 4.5
+		{"exclusive half up", Exclusive, RoundHalfUp, "Tamil Nadu",
+			Line{Amount: 25, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 25, IGST: 5}},
+		{"exclusive half even", Exclusive, RoundHalfEven, "Tamil Nadu",
+			Line{Amount: 25, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 25, IGST: 4}},
+		{"exclusive round up", Exclusive, RoundUp, "Tamil Nadu",
+			Line{Amount: 24, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 24, IGST: 5}},
+		{"exclusive lower slab by unit value", Exclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 199800, Quantity: 2, Slabs: apparel},
+			LineTax{Rate: 500, Taxable: 199800, CGST: 4995, SGST: 4995}},
+		{"exclusive upper slab", Exclusive, RoundHalfUp, "Tamil Nadu",
+			Line{Amount: 100000, Quantity: 1, Slabs: apparel},
+			LineTax{Rate: 1200, Taxable: 100000, IGST: 12000}},
+		{"inclusive intra-state", Inclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 118000, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 100000, CGST: 9000, SGST: 9000}},
+		// taxable 847.46 rounds to 847, leaving 153 of tax; the odd paisa goes to CGST
+		{"inclusive odd split", Inclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 1000, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 847, CGST: 77, SGST: 76}},
+		{"inclusive inter-state", Inclusive, RoundHalfUp, "Tamil Nadu",
+			Line{Amount: 1000, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 847, IGST: 153}},
+		{"inclusive round up", Inclusive, RoundUp, "Tamil Nadu",
+			Line{Amount: 1000, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800, Taxable: 848, IGST: 152}},
+		// 1049.99 is 937.49 before 12% tax, below the threshold, so 5% applies
+		{"inclusive lower slab", Inclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 104999, Quantity: 1, Slabs: apparel},
+			LineTax{Rate: 500, Taxable: 99999, CGST: 2500, SGST: 2500}},
+		{"inclusive upper slab", Inclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 224000, Quantity: 2, Slabs: apparel},
+			LineTax{Rate: 1200, Taxable: 200000, CGST: 12000, SGST: 12000}},
+		{"untaxed", Inclusive, RoundHalfUp, "Kerala",
+			Line{Amount: 5000, Quantity: 1},
+			LineTax{Taxable: 5000}},
+		{"negative amount", Exclusive, RoundHalfUp, "Kerala",
+			Line{Amount: -100, Quantity: 1, Slabs: standard},
+			LineTax{Rate: 1800}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := Config{Mode: test.mode, Rounding: test.rounding, SellerState: "Kerala"}
+			result := Calculate(config, test.state, []Line{test.line})
+			assert.Equal(t, test.want, result.Lines[0])
+		})
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	config := Config{Mode: Exclusive, Rounding: RoundHalfUp, SellerState: "Kerala"}
+	result := Calculate(config, "kerala", []Line{
+		{Key: 1, Amount: 10000, Quantity: 1, Slabs: standard},
+		{Key: 2, Amount: 50000, Quantity: 5, Slabs: apparel},
+	})
+
+	assert.False(t, result.InterState)
+	assert.Equal(t, int64(60000), result.Taxable)
+	assert.Equal(t, int64(900+1250), result.CGST)
+	assert.Equal(t, int64(900+1250), result.SGST)
+	assert.Equal(t, int64(0), result.IGST)
+	assert.Equal(t, int64(4300), result.Tax())
+	assert.Equal(t, int64(1800), result.Lines[1].Tax())
+}
+
+func TestInterState(t *testing.T) {
+	tests := []struct {
+		seller, state string
+		interState    bool
+	}{
+		{"Kerala", "Kerala", false},
+		{"Kerala", " kerala ", false},
+		{"Kerala", "Tamil Nadu", true},
+		{"", "Tamil Nadu", false},
+		{"Kerala", "", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.interState, InterState(test.seller, test.state), "%q -> %q", test.seller, test.state)
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		unitValue int64
+		rate      int
+	}{
+		{0, 500},
+		{99999, 500},
+		{100000, 1200},
+		{500000, 1200},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.rate, Rate(apparel, test.unitValue), "%d", test.unitValue)
+	}
+	assert.Equal(t, 0, Rate(nil, 1000))
+}
@@ -1,11 +1,19 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	magicLinkPurpose = "magic_link"
+	refreshTokenType = "refresh"
+)
+
 func GenerateJWT(email string, ID uint, role string, expiry uint) (string, error) {
 	claims := jwt.MapClaims{
 		"email": email,
@@ -16,3 +24,90 @@ func GenerateJWT(email string, ID uint, role string, expiry uint) (string, error
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte("secret"))
 }
+
+// GenerateRefreshToken signs a long-lived token that can only be exchanged
+// for a new access token at the refresh endpoint, never used as a bearer
+// token.
+func GenerateRefreshToken(email string, ID uint, role string, expiry uint) (string, error) {
+	claims := jwt.MapClaims{
+		"email": email,
+		"ID":    ID,
+		"exp":   time.Now().Add(time.Hour * time.Duration(expiry)).Unix(),
+		"role":  role,
+		"typ":   refreshTokenType,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte("secret"))
+}
+
+// ParseRefreshToken verifies the signature, expiry and type of a refresh
+// token and returns the user ID it was issued for.
+func ParseRefreshToken(tokenString string) (uint, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return 0, errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !IsRefreshToken(claims) {
+		return 0, errors.New("invalid token claims")
+	}
+	ID, ok := claims["ID"].(float64)
+	if !ok {
+		return 0, errors.New("invalid token claims")
+	}
+	return uint(ID), nil
+}
+
+// IsRefreshToken reports whether the claims are those of a refresh token,
+// which bearer authentication must reject.
+func IsRefreshToken(claims map[string]interface{}) bool {
+	return claims["typ"] == refreshTokenType
+}
+
+// GenerateMagicLinkToken signs a short-lived token that can only be exchanged
+// at the magic link login endpoint, never used as a bearer token.
+func GenerateMagicLinkToken(ID uint, jti string, expiry time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"ID":      ID,
+		"jti":     jti,
+		"purpose": magicLinkPurpose,
+		"exp":     time.Now().Add(expiry).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte("secret"))
+}
+
+// ParseMagicLinkToken verifies the signature, expiry and purpose of a magic
+// link token and returns the user ID and token ID it was issued for.
+func ParseMagicLinkToken(tokenString string) (uint, string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return 0, "", errors.New("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != magicLinkPurpose {
+		return 0, "", errors.New("invalid token claims")
+	}
+	ID, ok := claims["ID"].(float64)
+	if !ok {
+		return 0, "", errors.New("invalid token claims")
+	}
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return 0, "", errors.New("invalid token claims")
+	}
+	return uint(ID), jti, nil
+}
+
+// GenerateRandomString returns a hex encoded string of n random bytes.
+func GenerateRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshToken(t *testing.T) {
+	refresh, err := GenerateRefreshToken("user@example.com", 42, "user", 1)
+	assert.NoError(t, err)
+	userID, err := ParseRefreshToken(refresh)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), userID)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(refresh, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, IsRefreshToken(claims), "bearer authentication must reject it")
+
+	access, _ := GenerateJWT("user@example.com", 42, "user", 1)
+	_, err = ParseRefreshToken(access)
+	assert.Error(t, err, "an access token is not a refresh token")
+
+	expired, _ := GenerateRefreshToken("user@example.com", 42, "user", 0)
+	_, err = ParseRefreshToken(expired)
+	assert.Error(t, err, "expired")
+}
@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/Ansalps/UserEcommerceClean/internal/models"
+)
+
+// AvatarSizes maps thumbnail names to their edge length in pixels.
+var AvatarSizes = map[string]int{
+	"small":  64,
+	"medium": 256,
+	"large":  512,
+}
+
+// maxImagePixels guards against decompression bombs: small files that decode
+// to huge images.
+const maxImagePixels = 25_000_000
+
+// ProcessAvatar decodes an uploaded JPEG, PNG or GIF, applies its EXIF
+// orientation, crops it to a centred square and encodes one thumbnail per
+// AvatarSizes entry. The thumbnails are re-encoded from raw pixels, so EXIF
+// and any other metadata in the upload is dropped. It returns the thumbnails
+// with their content type and file extension.
+func ProcessAvatar(data []byte) (map[string][]byte, string, string, error) {
+	src, format, err := decodeImage(data)
+	if err != nil {
+		return nil, "", "", err
+	}
+	thumbnails := make(map[string][]byte, len(AvatarSizes))
+	for name, size := range AvatarSizes {
+		thumbnails[name], err = encodeImage(SquareThumbnail(src, size), format)
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+	contentType, ext := imageType(format)
+	return thumbnails, contentType, ext, nil
+}
+
+// ProcessReviewImage decodes an uploaded JPEG, PNG or GIF like ProcessAvatar
+// but keeps its aspect ratio, scaling it down to at most maxSide pixels on
+// the longer edge. It returns the re-encoded image with its content type and
+// file extension.
+func ProcessReviewImage(data []byte, maxSide int) ([]byte, string, string, error) {
+	src, format, err := decodeImage(data)
+	if err != nil {
+		return nil, "", "", err
+	}
+	encoded, err := encodeImage(FitImage(src, maxSide), format)
+	if err != nil {
+		return nil, "", "", err
+	}
+	contentType, ext := imageType(format)
+	return encoded, contentType, ext, nil
+}
+
+// decodeImage decodes data into upright RGBA pixels and returns its format.
+func decodeImage(data []byte) (*image.RGBA, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New(models.InvalidImage)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, "", errors.New(models.ImageTooLarge)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New(models.InvalidImage)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	return orient(img, orientation), format, nil
+}
+
+// encodeImage encodes JPEG uploads as JPEG and everything else as PNG, which
+// keeps the transparency of PNG and GIF uploads.
+func encodeImage(img *image.RGBA, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+func imageType(format string) (string, string) {
+	if format == "jpeg" {
+		return "image/jpeg", "jpg"
+	}
+	return "image/png", "png"
+}
+
+// SquareThumbnail crops the centre square of src and scales it to size x size
+// by averaging the source pixels covered by each destination pixel.
+func SquareThumbnail(src *image.RGBA, size int) *image.RGBA {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2))
+	return resample(src, crop, size, size)
+}
+
+// FitImage scales src down so neither edge exceeds maxSide, keeping its
+// aspect ratio. Images that already fit are returned unchanged.
+func FitImage(src *image.RGBA, maxSide int) *image.RGBA {
+	b := src.Bounds()
+	if b.Dx() <= maxSide && b.Dy() <= maxSide {
+		return src
+	}
+	width, height := maxSide, max(1, b.Dy()*maxSide/b.Dx())
+	if b.Dy() > b.Dx() {
+		width, height = max(1, b.Dx()*maxSide/b.Dy()), maxSide
+	}
+	return resample(src, b, width, height)
+}
+
+// resample scales the area of src to width x height by averaging the source
+// pixels covered by each destination pixel.
+func resample(src *image.RGBA, area image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		sy0 := area.Min.Y + y*area.Dy()/height
+		sy1 := area.Min.Y + (y+1)*area.Dy()/height
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < width; x++ {
+			sx0 := area.Min.X + x*area.Dx()/width
+			sx1 := area.Min.X + (x+1)*area.Dx()/width
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+			var sum [4]uint64
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += uint64(src.Pix[row+c])
+					}
+					row += 4
+				}
+			}
+			n := uint64((sy1 - sy0) * (sx1 - sx0))
+			offset := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[offset+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// orient converts img to RGBA, rotating and flipping it as described by the
+// EXIF orientation value (1-8) so it displays upright without metadata.
+func orient(img image.Image, orientation int) *image.RGBA {
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation tag of a JPEG file, or 1 when
+// the file has none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// metadata segments all come before the start of scan
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		if marker == 0xE1 {
+			if orientation := exifOrientation(data[i+4 : i+2+size]); orientation != 0 {
+				return orientation
+			}
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(segment []byte) int {
+	if len(segment) < 14 || string(segment[:6]) != "Exif\x00\x00" {
+		return 0
+	}
+	tiff := segment[6:]
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 0
+		}
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jpegWithOrientation encodes a 40x20 image whose left half is red and right
+// half is blue, and inserts an EXIF segment with the given orientation.
+func jpegWithOrientation(t *testing.T, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x < 20 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	// little endian TIFF header followed by an IFD with a single orientation entry
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 1, 0}
+	entry := make([]byte, 12)
+	binary.LittleEndian.PutUint16(entry[0:], 0x0112)
+	binary.LittleEndian.PutUint16(entry[2:], 3)
+	binary.LittleEndian.PutUint32(entry[4:], 1)
+	binary.LittleEndian.PutUint16(entry[8:], orientation)
+	tiff = append(tiff, entry...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessAvatar(t *testing.T) {
+	data := jpegWithOrientation(t, 6)
+	assert.Equal(t, 6, jpegOrientation(data))
+
+	thumbnails, contentType, ext, err := ProcessAvatar(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Equal(t, "jpg", ext)
+	assert.Len(t, thumbnails, len(AvatarSizes))
+
+	for name, size := range AvatarSizes {
+		thumb := thumbnails[name]
+		assert.False(t, bytes.Contains(thumb, []byte("Exif")), "%s thumbnail still has EXIF", name)
+		img, err := jpeg.Decode(bytes.NewReader(thumb))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, size, size), img.Bounds())
+
+		// rotated 90 degrees clockwise, so red must now be on top and blue at the bottom
+		r, _, b, _ := img.At(size/2, size/8).RGBA()
+		assert.Greater(t, r, b, "%s thumbnail top should be red", name)
+		r, _, b, _ = img.At(size/2, size-size/8).RGBA()
+		assert.Greater(t, b, r, "%s thumbnail bottom should be blue", name)
+	}
+}
+
+func TestProcessAvatarRejectsInvalidImage(t *testing.T) {
+	_, _, _, err := ProcessAvatar([]byte("GIF89a not really a gif"))
+	assert.Error(t, err)
+}
+
+func TestProcessReviewImage(t *testing.T) {
+	// 40x20 rotated to 20x40, then scaled to fit 10 pixels
+	data, contentType, ext, err := ProcessReviewImage(jpegWithOrientation(t, 6), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Equal(t, "jpg", ext)
+	assert.False(t, bytes.Contains(data, []byte("Exif")))
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 5, 10), img.Bounds())
+
+	// images that already fit keep their size
+	data, _, _, err = ProcessReviewImage(jpegWithOrientation(t, 1), 100)
+	assert.NoError(t, err)
+	img, err = jpeg.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// postalCodePatterns holds postal code formats by ISO 3166-1 alpha-2 country code.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"IN": regexp.MustCompile(`^[1-9][0-9]{5}$`),
+	"US": regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] ?[0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}$`),
+	"DE": regexp.MustCompile(`^[0-9]{5}$`),
+	"FR": regexp.MustCompile(`^[0-9]{5}$`),
+	"AU": regexp.MustCompile(`^[0-9]{4}$`),
+	"JP": regexp.MustCompile(`^[0-9]{3}-?[0-9]{4}$`),
+	"AE": regexp.MustCompile(`^[0-9]{1,6}$`),
+	"SG": regexp.MustCompile(`^[0-9]{6}$`),
+	"NL": regexp.MustCompile(`^[1-9][0-9]{3} ?[A-Z]{2}$`),
+	"BR": regexp.MustCompile(`^[0-9]{5}-?[0-9]{3}$`),
+}
+
+// genericPostalCode is used for countries without a specific pattern.
+var genericPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,10}[A-Z0-9]$`)
+
+// ValidatePostalCode reports whether code is a well formed postal code for the country.
+func ValidatePostalCode(country, code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	pattern, ok := postalCodePatterns[strings.ToUpper(country)]
+	if !ok {
+		pattern = genericPostalCode
+	}
+	return pattern.MatchString(code)
+}
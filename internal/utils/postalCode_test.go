@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePostalCode(t *testing.T) {
+	tests := []struct {
+		country string
+		code    string
+		valid   bool
+	}{
+		{"IN", "560001", true},
+		{"IN", "060001", false},
+		{"IN", "56000", false},
+		{"in", "110011", true},
+		{"US", "94105", true},
+		{"US", "94105-1234", true},
+		{"US", "9410", false},
+		{"GB", "SW1A 1AA", true},
+		{"GB", "sw1a1aa", true},
+		{"CA", "K1A 0B1", true},
+		{"CA", "D1A 0B1", false},
+		{"NL", "1012 AB", true},
+		{"AE", "12345", true},
+		{"AE", "", false},
+		{"AE", "  ", false},
+		{"ZZ", "AB-123", true},
+		{"ZZ", "!", false},
+	}
+	for _, test := range tests {
+		t.Run(test.country+" "+test.code, func(t *testing.T) {
+			assert.Equal(t, test.valid, ValidatePostalCode(test.country, test.code))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// SignValue appends an HMAC signature to value so it can be handed to a
+// client, e.g. in a cookie, and verified when it comes back.
+func SignValue(value string) string {
+	return value + "." + signature(value)
+}
+
+// VerifySignedValue returns the original value if the signature is valid.
+func VerifySignedValue(signed string) (string, bool) {
+	i := strings.LastIndex(signed, ".")
+	if i <= 0 {
+		return "", false
+	}
+	value := signed[:i]
+	if !hmac.Equal([]byte(signed[i+1:]), []byte(signature(value))) {
+		return "", false
+	}
+	return value, true
+}
+
+func signature(value string) string {
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
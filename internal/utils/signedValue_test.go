@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignedValue(t *testing.T) {
+	signed := SignValue("guest.token")
+	value, ok := VerifySignedValue(signed)
+	assert.True(t, ok)
+	assert.Equal(t, "guest.token", value)
+
+	for _, tampered := range []string{"", "guest.token", "other" + signed[len("guest.token"):], signed + "x"} {
+		_, ok := VerifySignedValue(tampered)
+		assert.False(t, ok, tampered)
+	}
+}
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify turns a name into a lowercase, hyphen separated URL segment. Names
+// without any ASCII letters or digits give an empty slug.
+func Slugify(name string) string {
+	return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// IDSlug is the slug used for a record whose name gives an empty slug, such
+// as "category-12".
+func IDSlug(prefix string, id uint) string {
+	return fmt.Sprintf("%s-%d", prefix, id)
+}
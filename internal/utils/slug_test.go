@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		slug string
+	}{
+		{"Men's T-Shirts", "men-s-t-shirts"},
+		{"  Home & Kitchen  ", "home-kitchen"},
+		{"Café Crème", "caf-cr-me"},
+		{"जूते", ""},
+		{"---", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.slug, Slugify(test.name))
+		})
+	}
+}
+
+func TestIDSlug(t *testing.T) {
+	assert.Equal(t, "category-12", IDSlug("category", 12))
+}